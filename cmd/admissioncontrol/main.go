@@ -3,11 +3,17 @@ package main
 import (
 	"errors"
 	"os"
+	"strings"
+	"time"
 
 	admissioncontrol "github.com/EdgeNet-project/edgenet/pkg/admissioncontrol"
+	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/signals"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/klog"
 )
 
@@ -34,5 +40,36 @@ func main() {
 	webhook.KeyFile = tlsKey
 	webhook.Codecs = serializer.NewCodecFactory(runtime.NewScheme())
 	webhook.Runtime = containerRuntime
+	var authentication string
+	if authentication = strings.TrimSpace(os.Getenv("AUTHENTICATION_STRATEGY")); authentication != "kubeconfig" {
+		authentication = "serviceaccount"
+	}
+	config, err := bootstrap.GetRestConfig(authentication)
+	if err != nil {
+		klog.Fatalf("Error running admission control webhook: %s", err.Error())
+	}
+	kubeclientset, err := bootstrap.CreateKubeClientset(config)
+	if err != nil {
+		klog.Fatalf("Error running admission control webhook: %s", err.Error())
+	}
+	edgenetclientset, err := bootstrap.CreateEdgeNetClientset(config)
+	if err != nil {
+		klog.Fatalf("Error running admission control webhook: %s", err.Error())
+	}
+
+	// Informers keep the objects that pods are checked against in cache, away from the path of every request
+	stopCh := signals.SetupSignalHandler()
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
+	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Second*30)
+	webhook.NamespaceLister = kubeInformerFactory.Core().V1().Namespaces().Lister()
+	webhook.TenantLister = edgenetInformerFactory.Core().V1alpha1().Tenants().Lister()
+	webhook.NodeLister = kubeInformerFactory.Core().V1().Nodes().Lister()
+	webhook.SliceLister = edgenetInformerFactory.Core().V1alpha1().Slices().Lister()
+	webhook.SliceClassLister = edgenetInformerFactory.Core().V1alpha1().SliceClasses().Lister()
+	webhook.ClusterRoleBindingLister = kubeInformerFactory.Rbac().V1().ClusterRoleBindings().Lister()
+	kubeInformerFactory.Start(stopCh)
+	edgenetInformerFactory.Start(stopCh)
+	kubeInformerFactory.WaitForCacheSync(stopCh)
+	edgenetInformerFactory.WaitForCacheSync(stopCh)
 	webhook.RunServer()
 }
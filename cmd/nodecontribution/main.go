@@ -10,6 +10,7 @@ import (
 	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
 	"github.com/EdgeNet-project/edgenet/pkg/controller/core/v1alpha1/nodecontribution"
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 	"github.com/EdgeNet-project/edgenet/pkg/signals"
 
 	kubeinformers "k8s.io/client-go/informers"
@@ -22,8 +23,18 @@ func main() {
 	flag.String("ssh-path", "/edgenet/.ssh", "Path to the SSH keys")
 	flag.String("configs-path", "/edgenet/configs", "Path to the config files")
 	flag.String("ca-path", "/etc/kubernetes/pki/ca.crt", "Path to the CA")
-	flag.String("aws-id-path", "/edgenet/aws/id", "Path to the AWS ID")
-	flag.String("aws-secret-path", "/edgenet/aws/secret", "Path to the AWS key")
+	flag.Duration("not-ready-threshold", 15*time.Minute, "How long a contributed node can be not ready before a warning event is recorded")
+	awsIDPath := flag.String("aws-id-path", "/edgenet/aws/id", "Path to the AWS ID")
+	awsSecretPath := flag.String("aws-secret-path", "/edgenet/aws/secret", "Path to the AWS key")
+	domain := flag.String("domain", "", "Domain under which the hostnames of contributed nodes are registered, such as edge-net.io")
+	dnsProviderName := flag.String("dns-provider", multiprovider.DNSProviderRoute53, "DNS provider registering node hostnames: route53, namecheap, rfc2136 or memory")
+	route53HostedZone := flag.String("route53-hosted-zone", "", "ID of the Route53 hosted zone of the domain, required by the route53 provider")
+	awsRegion := flag.String("aws-region", "us-east-1", "Region of the Route53 API")
+	rfc2136Server := flag.String("rfc2136-server", "", "Address of the DNS server accepting dynamic updates, such as ns1.edge-net.io:53")
+	rfc2136Zone := flag.String("rfc2136-zone", "", "Zone to update, the domain name if empty")
+	rfc2136KeyName := flag.String("rfc2136-tsig-key-name", "", "Name of the TSIG key signing the updates, unsigned if empty")
+	rfc2136KeyAlgorithm := flag.String("rfc2136-tsig-algorithm", "hmac-sha256", "Algorithm of the TSIG key: hmac-sha1, hmac-sha256 or hmac-sha512")
+	rfc2136KeySecretPath := flag.String("rfc2136-tsig-secret-path", "/edgenet/rfc2136/secret", "Path to the base64 encoded TSIG secret")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -50,15 +61,29 @@ func main() {
 	// Start the controller to provide the functionalities of nodecontribution resource
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Hour*3)
 	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, 0)
-	hostedZone := strings.TrimSpace(os.Getenv("ROUTE53_HOSTED_ZONE"))
-	domain := strings.TrimSpace(os.Getenv("DOMAIN_NAME"))
+	dnsProvider, err := multiprovider.NewDNSProvider(multiprovider.DNSConfig{
+		Provider:             *dnsProviderName,
+		Domain:               *domain,
+		Route53HostedZone:    *route53HostedZone,
+		Route53Region:        *awsRegion,
+		Route53IDPath:        *awsIDPath,
+		Route53SecretPath:    *awsSecretPath,
+		RFC2136Server:        *rfc2136Server,
+		RFC2136Zone:          *rfc2136Zone,
+		RFC2136KeyName:       *rfc2136KeyName,
+		RFC2136KeyAlgorithm:  *rfc2136KeyAlgorithm,
+		RFC2136KeySecretPath: *rfc2136KeySecretPath,
+	})
+	if err != nil {
+		klog.Fatalf("Error setting up the DNS provider: %s", err.Error())
+	}
 
 	controller := nodecontribution.NewController(kubeclientset,
 		edgenetclientset,
 		kubeInformerFactory.Core().V1().Nodes(),
 		edgenetInformerFactory.Core().V1alpha1().NodeContributions(),
-		hostedZone,
-		domain)
+		dnsProvider,
+		*domain)
 
 	kubeInformerFactory.Start(stopCh)
 	edgenetInformerFactory.Start(stopCh)
@@ -28,6 +28,7 @@ import (
 
 	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
 	"github.com/EdgeNet-project/edgenet/pkg/controller/core/v1/nodelabeler"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 	"github.com/EdgeNet-project/edgenet/pkg/signals"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/klog"
@@ -36,6 +37,11 @@ import (
 func main() {
 	klog.InitFlags(nil)
 	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	geoIPProviderName := flag.String("geoip-provider", multiprovider.GeoIPProviderMaxmind, "Geolocation provider locating the nodes: maxmind, mmdb or static")
+	geoIPCacheTTL := flag.Duration("geoip-cache-ttl", 30*24*time.Hour, "How long the locations found by the MaxMind web service are cached, 0 to disable the cache")
+	geoIPCityDBPath := flag.String("geoip-city-db", "/edgenet/geoip/GeoLite2-City.mmdb", "Path to the GeoLite2 City database of the mmdb provider")
+	geoIPASNDBPath := flag.String("geoip-asn-db", "/edgenet/geoip/GeoLite2-ASN.mmdb", "Path to the GeoLite2 ASN database of the mmdb provider")
+	geoIPStaticPath := flag.String("geoip-static", "", "Path to a JSON file mapping addresses or ranges to locations, looked up before the provider")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -66,6 +72,20 @@ func main() {
 	maxmindAccountId := strings.TrimSpace(os.Getenv("MAXMIND_ACCOUNT_ID"))
 	maxmindLicenseKey := strings.TrimSpace(os.Getenv("MAXMIND_LICENSE_KEY"))
 
+	geoIPProvider, err := multiprovider.NewGeoIPProvider(kubeclientset, multiprovider.GeoIPConfig{
+		Provider:          *geoIPProviderName,
+		MaxmindURL:        maxmindUrl,
+		MaxmindAccountID:  maxmindAccountId,
+		MaxmindLicenseKey: maxmindLicenseKey,
+		CacheTTL:          *geoIPCacheTTL,
+		CityDBPath:        *geoIPCityDBPath,
+		ASNDBPath:         *geoIPASNDBPath,
+		StaticPath:        *geoIPStaticPath,
+	})
+	if err != nil {
+		klog.Fatalf("Error setting up the geolocation provider: %s", err.Error())
+	}
+
 	// Start the controller to provide the functionalities of nodelabeler resource
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
 
@@ -73,9 +93,7 @@ func main() {
 		kubeclientset,
 		edgenetclientset,
 		kubeInformerFactory.Core().V1().Nodes(),
-		maxmindUrl,
-		maxmindAccountId,
-		maxmindLicenseKey,
+		geoIPProvider,
 	)
 
 	kubeInformerFactory.Start(stopCh)
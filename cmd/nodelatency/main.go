@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EdgeNet-project/edgenet/pkg/controller/networking/v1alpha1/nodelatency"
+
+	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/signals"
+
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/klog"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	flag.String("mode", "controller", "Either controller to label the nodes with their latency classes, or agent to measure the round-trip times from this node")
+	flag.Int("probe-port", 7061, "Port on which the agents answer the probes of each other")
+	flag.Duration("probe-interval", 5*time.Minute, "Period at which the agent measures the round-trip times")
+	flag.String("landmarks", "", "Landmarks the agent measures the round-trip times to, as name=host:port separated by commas")
+	flag.Duration("low-latency", 20*time.Millisecond, "Round-trip time up to which the latency is low")
+	flag.Duration("high-latency", 100*time.Millisecond, "Round-trip time from which the latency is high")
+	flag.Duration("latency-expiry", 30*time.Minute, "Age of the measurements after which the latency labels of a node are removed")
+	flag.Parse()
+
+	stopCh := signals.SetupSignalHandler()
+	var authentication string
+	if authentication = strings.TrimSpace(os.Getenv("AUTHENTICATION_STRATEGY")); authentication != "kubeconfig" {
+		authentication = "serviceaccount"
+	}
+	config, err := bootstrap.GetRestConfig(authentication)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+	kubeclientset, err := bootstrap.CreateKubeClientset(config)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+	edgenetclientset, err := bootstrap.CreateEdgeNetClientset(config)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
+	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Minute*5)
+
+	if mode := flag.Lookup("mode").Value.String(); mode == "agent" {
+		nodeName := strings.TrimSpace(os.Getenv("NODENAME"))
+		if nodeName == "" {
+			if nodeName, err = os.Hostname(); err != nil {
+				log.Println(err.Error())
+				panic(err.Error())
+			}
+		}
+		agent, err := nodelatency.NewAgent(
+			edgenetclientset,
+			kubeInformerFactory.Core().V1().Nodes(),
+			nodeName,
+		)
+		if err != nil {
+			klog.Fatalf("Error creating agent: %s", err.Error())
+		}
+
+		kubeInformerFactory.Start(stopCh)
+
+		if err = agent.Run(stopCh); err != nil {
+			klog.Fatalf("Error running agent: %s", err.Error())
+		}
+		return
+	}
+
+	controller := nodelatency.NewController(
+		kubeclientset,
+		edgenetclientset,
+		edgenetInformerFactory.Networking().V1alpha1().NodeLatencies(),
+	)
+
+	edgenetInformerFactory.Start(stopCh)
+
+	if err = controller.Run(2, stopCh); err != nil {
+		klog.Fatalf("Error running controller: %s", err.Error())
+	}
+}
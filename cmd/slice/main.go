@@ -12,12 +12,17 @@ import (
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
 	"github.com/EdgeNet-project/edgenet/pkg/signals"
 
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/klog"
 )
 
 func main() {
 	klog.InitFlags(nil)
 	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	flag.String("smtp-path", "/edgenet/credentials/smtp.yaml", "Path to the SMTP credentials to send email")
+	flag.String("template-path", "/edgenet/assets/templates/email", "Path to the email templates")
+	flag.Duration("expiry-notice", 24*time.Hour, "How long before the expiry of a slice its tenant is notified")
+	flag.Duration("grace-period", time.Minute, "How long the workloads on an expired slice are warned before its nodes are returned")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -42,13 +47,16 @@ func main() {
 	}
 
 	// Start the controller to provide the functionalities of slice resource
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
 	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Second*30)
 
 	controller := slice.NewController(kubeclientset,
 		edgenetclientset,
 		edgenetInformerFactory.Core().V1alpha1().SliceClaims(),
-		edgenetInformerFactory.Core().V1alpha1().Slices())
+		edgenetInformerFactory.Core().V1alpha1().Slices(),
+		kubeInformerFactory.Core().V1().Nodes())
 
+	kubeInformerFactory.Start(stopCh)
 	edgenetInformerFactory.Start(stopCh)
 
 	if err = controller.Run(1, stopCh); err != nil {
@@ -21,6 +21,7 @@ import (
 func main() {
 	klog.InitFlags(nil)
 	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	baselineEgress := flag.Bool("baseline-egress", false, "Restrict the egress of tenants that have no network profile set, as the baseline profile does")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -62,7 +63,8 @@ func main() {
 		kubeInformerFactory.Core().V1().Secrets(),
 		kubeInformerFactory.Core().V1().ConfigMaps(),
 		kubeInformerFactory.Core().V1().ServiceAccounts(),
-		edgenetInformerFactory.Core().V1alpha1().SubNamespaces())
+		edgenetInformerFactory.Core().V1alpha1().SubNamespaces(),
+		*baselineEgress)
 
 	kubeInformerFactory.Start(stopCh)
 	edgenetInformerFactory.Start(stopCh)
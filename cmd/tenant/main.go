@@ -18,6 +18,7 @@ import (
 func main() {
 	klog.InitFlags(nil)
 	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	baselineEgress := flag.Bool("baseline-egress", false, "Restrict the egress of tenants that have no network profile set, as the baseline profile does")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -52,7 +53,8 @@ func main() {
 	controller := tenant.NewController(kubeclientset,
 		edgenetclientset,
 		antreaclientset,
-		edgenetInformerFactory.Core().V1alpha1().Tenants())
+		edgenetInformerFactory.Core().V1alpha1().Tenants(),
+		*baselineEgress)
 
 	edgenetInformerFactory.Start(stopCh)
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EdgeNet-project/edgenet/pkg/controller/networking/v1alpha1/vpnaddresspool"
+
+	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/signals"
+
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/klog"
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	flag.Parse()
+
+	stopCh := signals.SetupSignalHandler()
+	var authentication string
+	if authentication = strings.TrimSpace(os.Getenv("AUTHENTICATION_STRATEGY")); authentication != "kubeconfig" {
+		authentication = "serviceaccount"
+	}
+	config, err := bootstrap.GetRestConfig(authentication)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+	kubeclientset, err := bootstrap.CreateKubeClientset(config)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+	edgenetclientset, err := bootstrap.CreateEdgeNetClientset(config)
+	if err != nil {
+		log.Println(err.Error())
+		panic(err.Error())
+	}
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
+	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Minute*10)
+
+	controller := vpnaddresspool.NewController(
+		kubeclientset,
+		edgenetclientset,
+		edgenetInformerFactory.Networking().V1alpha1().VPNAddressPools(),
+		edgenetInformerFactory.Networking().V1alpha1().VPNPeers(),
+	)
+
+	kubeInformerFactory.Start(stopCh)
+	edgenetInformerFactory.Start(stopCh)
+
+	if err = controller.Run(2, stopCh); err != nil {
+		klog.Fatalf("Error running controller: %s", err.Error())
+	}
+}
@@ -20,6 +20,9 @@ import (
 func main() {
 	klog.InitFlags(nil)
 	flag.String("kubeconfig-path", bootstrap.GetDefaultKubeconfigPath(), "Path to the kubeconfig file's directory")
+	flag.Duration("resync-period", 5*time.Minute, "Period at which the whole WireGuard interface is compared with the VPN peers")
+	flag.String("mode", "hub", "Either hub to configure all peers on the interface of a head node, or mesh to configure the mesh peers of this node")
+	flag.Bool("dry-run", false, "Report the changes to the WireGuard interface without applying them")
 	flag.Parse()
 
 	stopCh := signals.SetupSignalHandler()
@@ -44,21 +47,33 @@ func main() {
 	}
 
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, time.Second*30)
+	secretInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeclientset, time.Second*30, kubeinformers.WithNamespace(vpnpeer.PresharedKeyNamespace))
 	edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, 0)
 
 	linkName := strings.TrimSpace(os.Getenv("LINKNAME"))
 	if linkName == "" {
 		linkName = "edgenetmesh0"
 	}
+	nodeName := strings.TrimSpace(os.Getenv("NODENAME"))
+	if nodeName == "" {
+		if nodeName, err = os.Hostname(); err != nil {
+			log.Println(err.Error())
+			panic(err.Error())
+		}
+	}
 
 	controller := vpnpeer.NewController(
 		kubeclientset,
 		edgenetclientset,
 		edgenetInformerFactory.Networking().V1alpha1().VPNPeers(),
+		kubeInformerFactory.Core().V1().Nodes(),
+		secretInformerFactory.Core().V1().Secrets(),
 		linkName,
+		nodeName,
 	)
 
 	kubeInformerFactory.Start(stopCh)
+	secretInformerFactory.Start(stopCh)
 	edgenetInformerFactory.Start(stopCh)
 
 	if err = controller.Run(2, stopCh); err != nil {
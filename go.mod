@@ -8,11 +8,13 @@ require (
 	github.com/billputer/go-namecheap v0.0.0-20191113012015-80fb801c9a11
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/oschwald/maxminddb-golang v1.12.0
 	github.com/savaki/geoip2 v0.0.0-20150727150920-9968b08fbf39
 	github.com/sirupsen/logrus v1.8.1
 	github.com/slack-go/slack v0.10.2
 	github.com/xhit/go-simple-mail/v2 v2.10.0
 	golang.org/x/crypto v0.17.0
+	golang.org/x/net v0.17.0
 	golang.zx2c4.com/wireguard/wgctrl v0.0.0-20210506160403-92e472f520a5
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.21.2
@@ -57,7 +59,6 @@ require (
 	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/net v0.17.0 // indirect
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/term v0.15.0 // indirect
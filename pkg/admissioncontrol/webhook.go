@@ -13,17 +13,27 @@ import (
 
 	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
 	registrationv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/registration/v1alpha1"
+	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/selection"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
 	"k8s.io/klog/v2"
 )
 
 const (
+	queued   = "Queued"
 	reserved = "Reserved"
 	bound    = "Bound"
 )
@@ -34,6 +44,16 @@ type Webhook struct {
 	Codecs   serializer.CodecFactory
 	Runtime  string
 	Port     string
+	// NamespaceLister and TenantLister look up the tenants that pods belong to
+	NamespaceLister corelisters.NamespaceLister
+	TenantLister    listers.TenantLister
+	// NodeLister looks up the limitations of the nodes that pods are bound to
+	NodeLister corelisters.NodeLister
+	// SliceLister and SliceClassLister look up the runtime class that pods of a slice run with
+	SliceLister      listers.SliceLister
+	SliceClassLister listers.SliceClassLister
+	// ClusterRoleBindingLister looks up the cluster admins, who alone can enable or disable tenants
+	ClusterRoleBindingLister rbaclisters.ClusterRoleBindingLister
 }
 
 func (wh *Webhook) RunServer() {
@@ -45,6 +65,8 @@ func (wh *Webhook) RunServer() {
 
 	http.HandleFunc("/mutate/pod", wh.mutatePod)
 	http.HandleFunc("/validate/pod", wh.validatePod)
+	http.HandleFunc("/validate/pod-binding", wh.validatePodBinding)
+	http.HandleFunc("/validate/tenant", wh.validateTenant)
 	http.HandleFunc("/validate/tenant-request", wh.validateTenantRequest)
 	http.HandleFunc("/validate/cluster-role-request", wh.validateClusterRoleRequest)
 	http.HandleFunc("/validate/role-request", wh.validateRoleRequest)
@@ -128,7 +150,16 @@ func (wh *Webhook) mutatePod(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if slice, sliceExists := pod.Spec.NodeSelector["edge-net.io/slice"]; sliceExists && slice == "none" {
+	if wh.allowsLimitedNodes(pod, admissionReviewRequest.Request.Namespace) {
+		patchOperation["toleration"] = "add"
+	}
+
+	runtimeClassName := wh.Runtime
+	slice, sliceExists := pod.Spec.NodeSelector["edge-net.io/slice"]
+	if sliceExists && slice != "none" {
+		runtimeClassName = wh.getSliceRuntimeClassName(slice)
+	}
+	if sliceExists && (slice == "none" || runtimeClassName != "") {
 		if pod.Spec.RuntimeClassName == nil {
 			patchOperation["runtime"] = "add"
 		} else {
@@ -142,6 +173,7 @@ func (wh *Webhook) mutatePod(w http.ResponseWriter, r *http.Request) {
 	_, ingressExists := patchOperation["ingress"]
 	_, egressExists := patchOperation["egress"]
 	_, runtimeExists := patchOperation["runtime"]
+	_, tolerationExists := patchOperation["toleration"]
 
 	var patchItems []string
 	if ingressExists {
@@ -153,9 +185,17 @@ func (wh *Webhook) mutatePod(w http.ResponseWriter, r *http.Request) {
 		patchItems = append(patchItems, egress)
 	}
 	if runtimeExists {
-		runtime := fmt.Sprintf(`{"op":"%s","path":"/spec/runtimeClassName","value":"%s"}`, patchOperation["runtime"], wh.Runtime)
+		runtime := fmt.Sprintf(`{"op":"%s","path":"/spec/runtimeClassName","value":"%s"}`, patchOperation["runtime"], runtimeClassName)
 		patchItems = append(patchItems, runtime)
 	}
+	if tolerationExists {
+		toleration, _ := json.Marshal(multiprovider.LimitationToleration())
+		if pod.Spec.Tolerations == nil {
+			patchItems = append(patchItems, fmt.Sprintf(`{"op":"add","path":"/spec/tolerations","value":[%s]}`, toleration))
+		} else {
+			patchItems = append(patchItems, fmt.Sprintf(`{"op":"add","path":"/spec/tolerations/-","value":%s}`, toleration))
+		}
+	}
 	patch := fmt.Sprintf(`[%s]`, strings.Join(patchItems, ","))
 	patchType := admissionv1.PatchTypeJSONPatch
 	admissionResponse.PatchType = &patchType
@@ -263,6 +303,23 @@ func (wh *Webhook) validatePod(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	if admissionReviewRequest.Request.Operation == admissionv1.Create {
+		if suspended, message := wh.isTenantSuspended(admissionReviewRequest.Request.Namespace); suspended {
+			admissionResponse.Allowed = false
+			admissionResponse.Result = &metav1.Status{
+				Message: message,
+			}
+		}
+	}
+	// The pods of system daemon sets run on every node, limited or not
+	if ownerRef := metav1.GetControllerOf(pod); pod.Spec.NodeName != "" && (ownerRef == nil || ownerRef.Kind != "DaemonSet") {
+		if allowed, message := wh.isAllowedOnNode(pod.Spec.NodeName, admissionReviewRequest.Request.Namespace); !allowed {
+			admissionResponse.Allowed = false
+			admissionResponse.Result = &metav1.Status{
+				Message: message,
+			}
+		}
+	}
 
 	var admissionReviewResponse admissionv1.AdmissionReview
 	admissionReviewResponse.Response = admissionResponse
@@ -280,6 +337,288 @@ func (wh *Webhook) validatePod(w http.ResponseWriter, r *http.Request) {
 	w.Write(resp)
 }
 
+func (wh *Webhook) validatePodBinding(w http.ResponseWriter, r *http.Request) {
+	klog.Infoln("Pod binding: message on validate received")
+	deserializer := wh.Codecs.UniversalDeserializer()
+	admissionReviewRequest, err := admissionReviewFromRequest(r, deserializer)
+	if err != nil {
+		klog.Errorf("Pod binding admission review error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	podResource := metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	if admissionReviewRequest.Request.Resource != podResource || admissionReviewRequest.Request.SubResource != "binding" {
+		err := fmt.Errorf("pod binding wrong resource kind: %v", admissionReviewRequest.Request.Resource.Resource)
+		klog.Error(err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	rawRequest := admissionReviewRequest.Request.Object.Raw
+	binding := new(corev1.Binding)
+	if _, _, err := deserializer.Decode(rawRequest, nil, binding); err != nil {
+		klog.Errorf("pod binding decode error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	admissionResponse := new(admissionv1.AdmissionResponse)
+	admissionResponse.Allowed = true
+
+	if allowed, message := wh.isAllowedOnNode(binding.Target.Name, admissionReviewRequest.Request.Namespace); !allowed {
+		admissionResponse.Allowed = false
+		admissionResponse.Result = &metav1.Status{
+			Message: message,
+		}
+	}
+
+	var admissionReviewResponse admissionv1.AdmissionReview
+	admissionReviewResponse.Response = admissionResponse
+	admissionReviewResponse.SetGroupVersionKind(admissionReviewRequest.GroupVersionKind())
+	admissionReviewResponse.Response.UID = admissionReviewRequest.Request.UID
+
+	resp, err := json.Marshal(admissionReviewResponse)
+	if err != nil {
+		klog.Errorf("pod binding decode error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// isAllowedOnNode checks the limitations of the node contribution against the namespace and its tenant.
+// It returns the reason of the rejection otherwise.
+func (wh *Webhook) isAllowedOnNode(nodeName, namespace string) (bool, string) {
+	if wh.NodeLister == nil || wh.NamespaceLister == nil {
+		return true, ""
+	}
+	node, err := wh.NodeLister.Get(nodeName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, ""
+		}
+		klog.Errorf("node %s cannot be checked against limitations: %v", nodeName, err)
+		return false, fmt.Sprintf("node %s cannot be checked against its limitations", nodeName)
+	}
+	// Most nodes have no limitations, which spares looking up the namespace
+	if !multiprovider.IsLimited(node) {
+		return true, ""
+	}
+	var tenant string
+	namespaceObj, err := wh.NamespaceLister.Get(namespace)
+	if err == nil {
+		tenant = namespaceObj.GetLabels()["edge-net.io/tenant"]
+	} else if !k8serrors.IsNotFound(err) {
+		klog.Errorf("namespace %s cannot be checked against limitations: %v", namespace, err)
+		return false, fmt.Sprintf("namespace %s cannot be checked against the limitations of node %s", namespace, nodeName)
+	}
+	if !multiprovider.IsAllowedOnNode(node, namespace, tenant) {
+		return false, fmt.Sprintf("node %s is limited to other tenants and namespaces", nodeName)
+	}
+	return true, ""
+}
+
+// allowsLimitedNodes tells whether some nodes with limitations allow the namespace or its tenant, in which case the
+// pod needs to tolerate the taint of these nodes to be scheduled there
+func (wh *Webhook) allowsLimitedNodes(pod *corev1.Pod, namespace string) bool {
+	if wh.NodeLister == nil || wh.NamespaceLister == nil {
+		return false
+	}
+	for _, toleration := range pod.Spec.Tolerations {
+		if toleration.Key == multiprovider.LimitationTaintKey || (toleration.Key == "" && toleration.Operator == corev1.TolerationOpExists) {
+			return false
+		}
+	}
+	keys := []string{multiprovider.NamespaceLimitationLabelPrefix + namespace}
+	if namespaceObj, err := wh.NamespaceLister.Get(namespace); err == nil {
+		if tenant := namespaceObj.GetLabels()["edge-net.io/tenant"]; tenant != "" {
+			keys = append(keys, multiprovider.TenantLimitationLabelPrefix+tenant)
+		}
+	}
+	for _, key := range keys {
+		requirement, err := labels.NewRequirement(key, selection.Exists, nil)
+		if err != nil {
+			continue
+		}
+		if nodeRaw, err := wh.NodeLister.List(labels.NewSelector().Add(*requirement)); err == nil && len(nodeRaw) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isTenantSuspended tells whether the tenant that the namespace belongs to is suspended, in which case no pod can be
+// created in its namespaces. It returns the reason of the rejection otherwise.
+func (wh *Webhook) isTenantSuspended(namespace string) (bool, string) {
+	if wh.NamespaceLister == nil || wh.TenantLister == nil {
+		return false, ""
+	}
+	namespaceObj, err := wh.NamespaceLister.Get(namespace)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, ""
+		}
+		klog.Errorf("namespace %s cannot be checked for a suspended tenant: %v", namespace, err)
+		return true, fmt.Sprintf("namespace %s cannot be checked for a suspended tenant", namespace)
+	}
+	tenantName, elementExists := namespaceObj.GetLabels()["edge-net.io/tenant"]
+	if !elementExists {
+		return false, ""
+	}
+	tenant, err := wh.TenantLister.Get(tenantName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, ""
+		}
+		klog.Errorf("tenant %s cannot be checked for suspension: %v", tenantName, err)
+		return true, fmt.Sprintf("tenant %s cannot be checked for suspension", tenantName)
+	}
+	if !tenant.Spec.Enabled {
+		return true, fmt.Sprintf("tenant %s is suspended", tenantName)
+	}
+	return false, ""
+}
+
+// getSliceRuntimeClassName returns the runtime class that the class of the slice injects into the pods of the slice.
+// It returns an empty string if the class leaves the runtime as is.
+func (wh *Webhook) getSliceRuntimeClassName(sliceName string) string {
+	if wh.SliceLister == nil || wh.SliceClassLister == nil {
+		return ""
+	}
+	slice, err := wh.SliceLister.Get(sliceName)
+	if err != nil {
+		klog.Errorf("slice %s cannot be checked for its runtime class: %v", sliceName, err)
+		return ""
+	}
+	sliceClass, err := wh.SliceClassLister.Get(slice.Spec.SliceClassName)
+	if err != nil {
+		// The built-in classes work without an object and leave the runtime as is
+		if !k8serrors.IsNotFound(err) || (slice.Spec.SliceClassName != corev1alpha1.SliceClassNode && slice.Spec.SliceClassName != corev1alpha1.SliceClassResource) {
+			klog.Errorf("slice class %s cannot be checked for its runtime class: %v", slice.Spec.SliceClassName, err)
+		}
+		return ""
+	}
+	return sliceClass.Spec.RuntimeClassName
+}
+
+func (wh *Webhook) validateTenant(w http.ResponseWriter, r *http.Request) {
+	klog.Infoln("Tenant: message on validate received")
+	deserializer := wh.Codecs.UniversalDeserializer()
+	admissionReviewRequest, err := admissionReviewFromRequest(r, deserializer)
+	if err != nil {
+		klog.Errorf("Tenant admission review error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	tenantResource := metav1.GroupVersionResource{Group: "core.edgenet.io", Version: "v1alpha1", Resource: "tenants"}
+	if admissionReviewRequest.Request.Resource != tenantResource {
+		err := fmt.Errorf("tenant wrong resource kind: %v", admissionReviewRequest.Request.Resource.Resource)
+		klog.Error(err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	rawRequest := admissionReviewRequest.Request.Object.Raw
+	tenant := new(corev1alpha1.Tenant)
+	if _, _, err := deserializer.Decode(rawRequest, nil, tenant); err != nil {
+		klog.Errorf("tenant decode error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	admissionResponse := new(admissionv1.AdmissionResponse)
+	admissionResponse.Allowed = true
+
+	if admissionReviewRequest.Request.Operation == "UPDATE" || admissionReviewRequest.Request.Operation == "PATCH" {
+		oldObjectRaw := admissionReviewRequest.Request.OldObject.Raw
+		oldTenant := new(corev1alpha1.Tenant)
+		if _, _, err := deserializer.Decode(oldObjectRaw, nil, oldTenant); err != nil {
+			klog.Errorf("old tenant decode error: %v", err)
+			w.WriteHeader(400)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		// The owner can update the tenant object, but the suspension of a tenant is up to the cluster admins
+		if oldTenant.Spec.Enabled != tenant.Spec.Enabled && !wh.isClusterAdmin(admissionReviewRequest.Request.UserInfo) {
+			admissionResponse.Allowed = false
+			admissionResponse.Result = &metav1.Status{
+				Message: "only cluster admins can enable or disable a tenant",
+			}
+		}
+	}
+
+	var admissionReviewResponse admissionv1.AdmissionReview
+	admissionReviewResponse.Response = admissionResponse
+	admissionReviewResponse.SetGroupVersionKind(admissionReviewRequest.GroupVersionKind())
+	admissionReviewResponse.Response.UID = admissionReviewRequest.Request.UID
+
+	resp, err := json.Marshal(admissionReviewResponse)
+	if err != nil {
+		klog.Errorf("tenant decode error: %v", err)
+		w.WriteHeader(400)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// isClusterAdmin tells whether the user belongs to the system:masters group, runs as an EdgeNet service account,
+// or is bound to the cluster-admin cluster role
+func (wh *Webhook) isClusterAdmin(userInfo authenticationv1.UserInfo) bool {
+	for _, group := range userInfo.Groups {
+		if group == "system:masters" {
+			return true
+		}
+	}
+	if strings.HasPrefix(userInfo.Username, "system:serviceaccount:edgenet:") {
+		return true
+	}
+	if wh.ClusterRoleBindingLister == nil {
+		return false
+	}
+	clusterRoleBindings, err := wh.ClusterRoleBindingLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("cluster role binding list error: %v", err)
+		return false
+	}
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		if clusterRoleBinding.RoleRef.Kind != "ClusterRole" || clusterRoleBinding.RoleRef.Name != "cluster-admin" {
+			continue
+		}
+		for _, subject := range clusterRoleBinding.Subjects {
+			switch subject.Kind {
+			case rbacv1.UserKind:
+				if subject.Name == userInfo.Username {
+					return true
+				}
+			case rbacv1.GroupKind:
+				for _, group := range userInfo.Groups {
+					if subject.Name == group {
+						return true
+					}
+				}
+			case rbacv1.ServiceAccountKind:
+				if fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name) == userInfo.Username {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (wh *Webhook) validateTenantRequest(w http.ResponseWriter, r *http.Request) {
 	klog.Infoln("TenantRequest: message on validate received")
 	deserializer := wh.Codecs.UniversalDeserializer()
@@ -604,7 +943,7 @@ func (wh *Webhook) validateSlice(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte(err.Error()))
 			return
 		}
-		if oldSlice.Status.State == reserved || oldSlice.Status.State == bound {
+		if oldSlice.Status.State == queued || oldSlice.Status.State == reserved || oldSlice.Status.State == bound {
 			if oldSlice.Spec.SliceClassName != slice.Spec.SliceClassName {
 				admissionResponse.Allowed = false
 				admissionResponse.Result = &metav1.Status{
@@ -617,6 +956,12 @@ func (wh *Webhook) validateSlice(w http.ResponseWriter, r *http.Request) {
 					Message: "node selector cannot be changed after nodes are reserved",
 				}
 			}
+			if !reflect.DeepEqual(oldSlice.Spec.Start, slice.Spec.Start) {
+				admissionResponse.Allowed = false
+				admissionResponse.Result = &metav1.Status{
+					Message: "start cannot be changed after nodes are booked or reserved",
+				}
+			}
 			if oldSlice.Spec.ClaimRef != slice.Spec.ClaimRef && oldSlice.Status.State == bound {
 				admissionResponse.Allowed = false
 				admissionResponse.Result = &metav1.Status{
@@ -702,6 +1047,12 @@ func (wh *Webhook) validateSliceClaim(w http.ResponseWriter, r *http.Request) {
 				Message: "slice name cannot be changed after creation",
 			}
 		}
+		if !reflect.DeepEqual(oldSliceClaim.Spec.Start, sliceclaim.Spec.Start) {
+			admissionResponse.Allowed = false
+			admissionResponse.Result = &metav1.Status{
+				Message: "start cannot be changed after creation",
+			}
+		}
 	}
 
 	var admissionReviewResponse admissionv1.AdmissionReview
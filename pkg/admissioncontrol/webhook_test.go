@@ -0,0 +1,108 @@
+package admissioncontrol
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newWebhook(t *testing.T, clusterRoleBindings ...*rbacv1.ClusterRoleBinding) *Webhook {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		util.OK(t, indexer.Add(clusterRoleBinding))
+	}
+	return &Webhook{
+		Codecs:                   serializer.NewCodecFactory(runtime.NewScheme()),
+		ClusterRoleBindingLister: rbaclisters.NewClusterRoleBindingLister(indexer),
+	}
+}
+
+// review sends the update of a tenant to the handler on behalf of the user and returns the response
+func review(t *testing.T, handler http.HandlerFunc, userInfo authenticationv1.UserInfo, oldTenant, tenant *corev1alpha1.Tenant) *admissionv1.AdmissionResponse {
+	oldObject, err := json.Marshal(oldTenant)
+	util.OK(t, err)
+	object, err := json.Marshal(tenant)
+	util.OK(t, err)
+	admissionReview := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "review",
+			Resource:  metav1.GroupVersionResource{Group: "core.edgenet.io", Version: "v1alpha1", Resource: "tenants"},
+			Operation: admissionv1.Update,
+			UserInfo:  userInfo,
+			Object:    runtime.RawExtension{Raw: object},
+			OldObject: runtime.RawExtension{Raw: oldObject},
+		},
+	}
+	body, err := json.Marshal(admissionReview)
+	util.OK(t, err)
+	request := httptest.NewRequest(http.MethodPost, "/validate/tenant", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	util.Equals(t, http.StatusOK, recorder.Code)
+
+	admissionReviewResponse := new(admissionv1.AdmissionReview)
+	util.OK(t, json.Unmarshal(recorder.Body.Bytes(), admissionReviewResponse))
+	return admissionReviewResponse.Response
+}
+
+func TestValidateTenant(t *testing.T) {
+	suspended := &corev1alpha1.Tenant{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "core.edgenet.io/v1alpha1", Kind: "Tenant"},
+		ObjectMeta: metav1.ObjectMeta{Name: "lip6"},
+		Spec: corev1alpha1.TenantSpec{
+			FullName: "LIP6",
+			Contact:  corev1alpha1.Contact{Email: "john.doe@edge-net.org"},
+			Enabled:  false,
+		},
+	}
+	enabled := suspended.DeepCopy()
+	enabled.Spec.Enabled = true
+	described := suspended.DeepCopy()
+	described.Spec.Description = "Suspended until further notice"
+	clusterAdmins := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "edgenet:admins"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "jane.doe@edge-net.org"}},
+	}
+	owner := authenticationv1.UserInfo{Username: "john.doe@edge-net.org", Groups: []string{"system:authenticated"}}
+
+	cases := map[string]struct {
+		userInfo authenticationv1.UserInfo
+		tenant   *corev1alpha1.Tenant
+		allowed  bool
+	}{
+		"owner enables":                 {owner, enabled, false},
+		"owner updates description":     {owner, described, true},
+		"cluster admin enables":         {authenticationv1.UserInfo{Username: "jane.doe@edge-net.org"}, enabled, true},
+		"system master enables":         {authenticationv1.UserInfo{Username: "kubernetes-admin", Groups: []string{"system:masters"}}, enabled, true},
+		"edgenet service enables":       {authenticationv1.UserInfo{Username: "system:serviceaccount:edgenet:tenant"}, enabled, true},
+		"other service account enables": {authenticationv1.UserInfo{Username: "system:serviceaccount:lip6:default"}, enabled, false},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			wh := newWebhook(t, clusterAdmins)
+			admissionResponse := review(t, wh.validateTenant, tc.userInfo, suspended, tc.tenant)
+			util.Equals(t, "review", string(admissionResponse.UID))
+			util.Equals(t, tc.allowed, admissionResponse.Allowed)
+			if !tc.allowed {
+				util.Equals(t, "only cluster admins can enable or disable a tenant", admissionResponse.Result.Message)
+			}
+		})
+	}
+}
@@ -70,11 +70,15 @@ type Workloads struct {
 // TODO: In the future we might want to add custom sectorization? this is already doable
 // with LabelSelector
 type Selector struct {
-	// Name of the selector. This can be City, State, Country, Continent, or Polygon
+	// Name of the selector. This can be City, State, Country, Continent, Polygon, Distance, or Latency
 	Name string `json:"name"`
 	// Value of the selector. For example; if the name of the selector is 'City'
 	// then the value can be the city name. For example; if the name of
 	// the selector is 'Polygon' then the value can be the GeoJSON representation of the polygon.
+	// If the name of the selector is 'Distance' then the value is the latitude, the longitude,
+	// and the radius in kilometers of a circle, separated by commas, such as '48.8566,2.3522,50'.
+	// If the name of the selector is 'Latency' then the value is a latency class, which is low, medium, high,
+	// or unreachable, such as 'low' for the nodes close to the others, or a landmark and a class, such as 'paris=low'.
 	Value []string `json:"value"`
 	// Operator means basic mathematical operators such as 'In', 'NotIn', 'Exists', 'NotExsists' etc...
 	Operator corev1.NodeSelectorOperator `json:"operator"`
@@ -62,6 +62,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SliceList{},
 		&SliceClaim{},
 		&SliceClaimList{},
+		&SliceClass{},
+		&SliceClassList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
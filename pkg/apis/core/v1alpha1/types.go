@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"fmt"
 	"hash/adler32"
+	"math"
 	"strings"
 	"time"
 
@@ -39,6 +40,7 @@ const (
 	StatusBound       = "Bound" // Also used for slice claim
 	StatusReserved    = "Reserved"
 	StatusProvisioned = "Provisioned"
+	StatusQueued      = "Queued" // Also used for slice claim
 	// Subnamespace
 	StatusPartitioned         = "Partitioned"
 	StatusSubnamespaceCreated = "Created"
@@ -46,14 +48,51 @@ const (
 	// Tenant
 	StatusCoreNamespaceCreated = "Created"
 	StatusEstablished          = "Established" // Also used for subnamespace
+	StatusTerminating          = "Terminating"
+	StatusSuspended            = "Suspended"
+	StatusSuspending           = "Suspending"
+	StatusResuming             = "Resuming"
 	// Tenant resource quota
 	StatusQuotaCreated = "Created"
 	StatusApplied      = "Applied"
 	// Node contribution
-	StatusAccessed = "Node Accessed"
-	StatusReady    = "Ready"
+	StatusBootstrapping   = "Bootstrapping"
+	StatusAccessed        = "Node Accessed"
+	StatusReady           = "Ready"
+	StatusDecommissioning = "Decommissioning"
 )
 
+// Isolation levels of a slice class
+const (
+	IsolationExclusive = "Exclusive"
+	IsolationShared    = "Shared"
+)
+
+// Placement strategies of a node selector
+const (
+	PlacementRandom                = "Random"
+	PlacementSpread                = "Spread"
+	PlacementPack                  = "Pack"
+	PlacementDiverse               = "Diverse"
+	PlacementLeastRecentlyReserved = "LeastRecentlyReserved"
+)
+
+// Notices of the expiry of a slice that its tenant has received
+const (
+	ExpiryNoticeUpcoming = "Upcoming"
+	ExpiryNoticeExpired  = "Expired"
+)
+
+// Names of the slice classes that are available without a SliceClass resource
+const (
+	SliceClassNode     = "Node"
+	SliceClassResource = "Resource"
+)
+
+// SliceClassBuiltInMaxRenewals is the maximum number of renewals of the built-in slice classes, which puts no
+// practical limit on renewing their slices
+const SliceClassBuiltInMaxRenewals = math.MaxInt32
+
 // Values of string constants subject to repetitive use
 const (
 	DynamicStr                        = "Dynamic"
@@ -62,6 +101,13 @@ const (
 	TenantCollaboratorClusterRoleName = "edgenet:tenant-collaborator"
 )
 
+// Network profiles that isolate the namespaces of a tenant
+const (
+	NetworkProfileRestricted = "restricted"
+	NetworkProfileBaseline   = "baseline"
+	NetworkProfilePrivileged = "privileged"
+)
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -93,6 +139,10 @@ type TenantSpec struct {
 	// Whether cluster-level network policies will be applied to tenant namespaces
 	// for security purposes.
 	ClusterNetworkPolicy bool `json:"clusternetworkpolicy"`
+	// Network isolation of the tenant namespaces. Restricted only allows intra-tenant
+	// traffic, baseline additionally allows traffic from and to the public Internet,
+	// and privileged allows all kind of traffic. Baseline applies when it is empty.
+	NetworkProfile string `json:"networkprofile,omitempty"`
 	// If the tenant is active then this field is true.
 	Enabled bool `json:"enabled"`
 	// Description provides additional information about the tenant.
@@ -127,7 +177,7 @@ type Contact struct {
 
 // TenantStatus is the status for a Tenant resource
 type TenantStatus struct {
-	// The state can be 'Established' or 'Failure'.
+	// The state can be 'Established', 'Suspending', 'Suspended', 'Resuming', 'Terminating', or 'Failure'.
 	State string `json:"state"`
 	// Additional description can be located here.
 	Message string `json:"message"`
@@ -152,6 +202,14 @@ func (t Tenant) MakeOwnerReference() metav1.OwnerReference {
 	return *metav1.NewControllerRef(&t.ObjectMeta, SchemeGroupVersion.WithKind("Tenant"))
 }
 
+// GetNetworkProfile returns the network profile of the tenant, falling back to baseline.
+func (t Tenant) GetNetworkProfile() string {
+	if t.Spec.NetworkProfile == "" {
+		return NetworkProfileBaseline
+	}
+	return t.Spec.NetworkProfile
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -328,11 +386,37 @@ type NodeContributionSpec struct {
 	Port int `json:"port"`
 	// SSH username.
 	User string `json:"user"`
+	// SHA256 fingerprint of the SSH host key, as printed by ssh-keygen -l.
+	// The host key is trusted on first use when it is empty.
+	HostKeyFingerprint string `json:"hostkeyfingerprint,omitempty"`
+	// Bootstrap strategy making the host join the cluster, which can be 'kubeadm', 'k3s', or 'pull'.
+	// The k3s strategy requires the control plane to run k3s. In pull mode, the agent running on the node
+	// fetches its join command itself. Defaults to kubeadm.
+	Bootstrap string `json:"bootstrap,omitempty"`
 	// To enable/disable scheduling on the contributed node.
 	Enabled bool `json:"enabled"`
+	// AutoRecovery makes the controller join the host again, with an exponential backoff, when the node
+	// drops out of the cluster after it was ready. Defaults to true.
+	AutoRecovery *bool `json:"autoRecovery,omitempty"`
 	// Each contribution can have none or many limitations. This field denotese these
 	// limitations.
 	Limitations []Limitations `json:"limitations"`
+	// Location declares where the node is, for hosts whose addresses do not tell it, such as those
+	// behind carrier-grade NAT or a proxy. The node labeler then uses it rather than locating the addresses.
+	Location *NodeLocation `json:"location,omitempty"`
+}
+
+// NodeLocation is the location declared for a node
+type NodeLocation struct {
+	// Latitude and longitude in decimal degrees.
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
+	City      string `json:"city,omitempty"`
+	// ISO 3166-1 code of the country.
+	CountryISO string `json:"countryISO,omitempty"`
+	// ISO 3166-2 code of the subdivision, without the country code.
+	StateISO  string `json:"stateISO,omitempty"`
+	Continent string `json:"continent,omitempty"`
 }
 
 // Limitations describes which tenants and namespaces can make use of node
@@ -353,6 +437,91 @@ type NodeContributionStatus struct {
 	Failed int `json:"failed"`
 	// UpdateTimestamp is the last time the status was updated.
 	UpdateTimestamp *metav1.Time `json:"updateTimestamp"`
+	// HostKeyFingerprint is the fingerprint of the SSH host key the controller trusts.
+	HostKeyFingerprint string `json:"hostKeyFingerprint,omitempty"`
+	// ResetTimestamp is the time the decommission reset the host or gave up on it for being unreachable.
+	ResetTimestamp *metav1.Time `json:"resetTimestamp,omitempty"`
+	// Bootstrap reports the progress of the last bootstrap of the node.
+	Bootstrap *BootstrapStatus `json:"bootstrap,omitempty"`
+	// Availability reports how reliable the node has been.
+	Availability *AvailabilityStatus `json:"availability,omitempty"`
+	// Recovery reports the attempts to join the node again after it dropped out of the cluster.
+	Recovery *RecoveryStatus `json:"recovery,omitempty"`
+}
+
+// Bootstrap strategies of a node contribution
+const (
+	BootstrapKubeadm = "kubeadm"
+	BootstrapK3s     = "k3s"
+	BootstrapPull    = "pull"
+)
+
+// Values of BootstrapStep.State
+const (
+	StepPending   = "Pending"
+	StepRunning   = "Running"
+	StepSucceeded = "Succeeded"
+	StepFailed    = "Failed"
+)
+
+// BootstrapStatus is the progress of a node bootstrap
+type BootstrapStatus struct {
+	// Strategy used to bootstrap the node.
+	Strategy string `json:"strategy"`
+	// Steps of the strategy in the order they run.
+	Steps []BootstrapStep `json:"steps"`
+}
+
+// BootstrapStep is the progress of a single step of a node bootstrap
+type BootstrapStep struct {
+	// Name of the step.
+	Name string `json:"name"`
+	// This can be 'Pending', 'Running', 'Succeeded', or 'Failed'.
+	State string `json:"state"`
+	// Message contains the output of the step when it fails.
+	Message string `json:"message,omitempty"`
+	// TimeoutSeconds is the time the step is allowed to take.
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+	// StartTimestamp is the time the step started.
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// CompletionTimestamp is the time the step succeeded or failed.
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+}
+
+// AvailabilityStatus is the track record of the readiness of a contributed node
+type AvailabilityStatus struct {
+	// LastSeen is the last time the node was seen ready.
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// NotReadySince is the time the node stopped being ready, empty while the node is ready.
+	NotReadySince *metav1.Time `json:"notReadySince,omitempty"`
+	// NotReadyNotified tells whether the warning about a node not ready for too long has been recorded.
+	NotReadyNotified bool `json:"notReadyNotified,omitempty"`
+	// Uptime percentages of the node over the last 24 hours, 7 days, and 30 days.
+	Uptime24h string `json:"uptime24h,omitempty"`
+	Uptime7d  string `json:"uptime7d,omitempty"`
+	Uptime30d string `json:"uptime30d,omitempty"`
+	// FlapCount is the number of times the node stopped being ready over the last 24 hours.
+	FlapCount int `json:"flapCount"`
+	// Transitions of the Ready condition over the last 30 days, which the figures above derive from.
+	Transitions []ReadinessTransition `json:"transitions,omitempty"`
+}
+
+// ReadinessTransition is a change of the Ready condition of a node
+type ReadinessTransition struct {
+	// Ready tells whether the node became ready or not ready.
+	Ready bool `json:"ready"`
+	// Timestamp is the time of the change.
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// RecoveryStatus is the progress of the automatic recovery of a node that dropped out of the cluster
+type RecoveryStatus struct {
+	// Attempts is the number of times the controller tried to join the node again.
+	Attempts int `json:"attempts"`
+	// LastAttemptTimestamp is the time the last attempt started.
+	LastAttemptTimestamp *metav1.Time `json:"lastAttemptTimestamp,omitempty"`
+	// NextAttemptTimestamp is the earliest time of the next attempt if the last one fails.
+	NextAttemptTimestamp *metav1.Time `json:"nextAttemptTimestamp,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -373,6 +542,19 @@ func (nc NodeContribution) MakeOwnerReference() metav1.OwnerReference {
 	return *metav1.NewControllerRef(&nc.ObjectMeta, SchemeGroupVersion.WithKind("NodeContribution"))
 }
 
+// GetBootstrap returns the bootstrap strategy of the contribution, kubeadm if not set.
+func (nc NodeContribution) GetBootstrap() string {
+	if nc.Spec.Bootstrap == "" {
+		return BootstrapKubeadm
+	}
+	return nc.Spec.Bootstrap
+}
+
+// GetAutoRecovery tells whether the node joins the cluster again automatically, true if not set.
+func (nc NodeContribution) GetAutoRecovery() bool {
+	return nc.Spec.AutoRecovery == nil || *nc.Spec.AutoRecovery
+}
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -501,13 +683,15 @@ type Slice struct {
 
 // SliceSpec is the spec for a slice resource
 type SliceSpec struct {
-	// Name of the SliceClass required by the claim. This can be 'Node', or 'Resource'.
+	// Name of the SliceClass required by the claim. This can be 'Node', 'Resource', or the name of a SliceClass.
 	SliceClassName string `json:"sliceclassname"`
 	// ClaimRef is part of a bi-directional binding between Slice and SliceClaim.
 	// Expected to be non-nil when bound.
 	ClaimRef *corev1.ObjectReference `json:"claimref"`
 	// A selector for nodes to reserve.
 	NodeSelector NodeSelector `json:"nodeselector"`
+	// Start of an advance reservation. The nodes are booked until then, and reserved at the start.
+	Start *metav1.Time `json:"start,omitempty"`
 }
 
 // NodeSelector is a selector for nodes to reserve.
@@ -518,6 +702,21 @@ type NodeSelector struct {
 	Count int `json:"nodecount"`
 	// Resources represents the minimum resources each selected node should have.
 	Resources corev1.ResourceRequirements `json:"resources"`
+	// Placement is the strategy to pick the nodes among those that match a case.
+	Placement Placement `json:"placement,omitempty"`
+	// AutoHeal replaces the reserved nodes that are not ready with other feasible nodes.
+	AutoHeal bool `json:"autoheal,omitempty"`
+}
+
+// Placement is a strategy to pick nodes among the feasible ones.
+type Placement struct {
+	// Strategy can be 'Random', 'Spread' to spread the nodes across the values of the topology key, 'Pack' to pack
+	// them into as few values of the topology key as possible, 'Diverse' to pick the nodes farthest from each other,
+	// or 'LeastRecentlyReserved' to pick the nodes that slices have reserved the least recently.
+	Strategy string `json:"strategy,omitempty"`
+	// TopologyKey is the node label that Spread and Pack use, such as edge-net.io/country-iso, edge-net.io/asn,
+	// or edge-net.io/city. It defaults to edge-net.io/country-iso for Spread, and to edge-net.io/city for Pack.
+	TopologyKey string `json:"topologykey,omitempty"`
 }
 
 // SliceStatus is the status for a slice resource
@@ -530,6 +729,32 @@ type SliceStatus struct {
 	Failed int `json:"failed"`
 	// Expiration date of the slice.
 	Expiry *metav1.Time `json:"expiry"`
+	// Nodes picked for the slice, along with the rationale of the placement.
+	Nodes []SliceNode `json:"nodes,omitempty"`
+	// Renewals is the number of times the expiry has been extended.
+	Renewals int `json:"renewals,omitempty"`
+	// Notice is the last notice of the expiry the tenant has received. This can be 'Upcoming', or 'Expired'.
+	Notice string `json:"notice,omitempty"`
+	// RenewalRefusal is the last renewal refused, which is reported once.
+	RenewalRefusal *SliceRenewalRefusal `json:"renewalrefusal,omitempty"`
+}
+
+// SliceNode is a node picked for a slice.
+type SliceNode struct {
+	// Name of the node.
+	Name string `json:"name"`
+	// Reason explains why the placement strategy picked the node.
+	Reason string `json:"reason"`
+	// Ready tells whether the node is ready.
+	Ready bool `json:"ready"`
+}
+
+// SliceRenewalRefusal is a renewal of a slice that has been refused.
+type SliceRenewalRefusal struct {
+	// Expiry the slice claim asked for.
+	Expiry metav1.Time `json:"expiry"`
+	// Message tells why the renewal has been refused.
+	Message string `json:"message"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -567,7 +792,7 @@ type SliceClaim struct {
 
 // SliceClaimSpec is the spec for a slice claim resource
 type SliceClaimSpec struct {
-	// Name of the SliceClass required by the claim. This can be 'Node', or 'Resource'.
+	// Name of the SliceClass required by the claim. This can be 'Node', 'Resource', or the name of a SliceClass.
 	SliceClassName string `json:"sliceclassname"`
 	// SliceName is the binding reference to the Slice backing this claim.
 	SliceName string `json:"slicename"`
@@ -575,6 +800,8 @@ type SliceClaimSpec struct {
 	NodeSelector NodeSelector `json:"nodeselector"`
 	// Expiration date of the slice.
 	SliceExpiry *metav1.Time `json:"expiry"`
+	// Start of an advance reservation, which ends at the expiration date of the slice. The claim is queued until then.
+	Start *metav1.Time `json:"start,omitempty"`
 }
 
 // SliceClaimStatus is the status for a slice claim resource
@@ -623,3 +850,102 @@ func hash(strs ...string) string {
 	adler32 := adler32.Checksum([]byte(str))
 	return fmt.Sprintf("%x", adler32)
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SliceClass describes a slice class resource
+type SliceClass struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the slice class resource spec
+	Spec SliceClassSpec `json:"spec"`
+}
+
+// SliceClassSpec is the spec for a slice class resource
+type SliceClassSpec struct {
+	// Isolation of the nodes of a slice. This can be 'Exclusive' to evict the workloads of the others from the nodes,
+	// or 'Shared' to let those already running finish.
+	Isolation string `json:"isolation"`
+	// Runtime class injected into the pods that run on the slice, such as gVisor or Kata.
+	RuntimeClassName string `json:"runtimeclassname,omitempty"`
+	// Maximum lifetime of a slice, from its creation to its expiry.
+	MaxDuration *metav1.Duration `json:"maxduration,omitempty"`
+	// Tenants allowed to claim slices of this class. All tenants are allowed when empty.
+	AllowedTenants []string `json:"allowedtenants,omitempty"`
+	// Policy that restricts the nodes a slice of this class can reserve.
+	NodeSelectionPolicy NodeSelectionPolicy `json:"nodeselectionpolicy,omitempty"`
+	// Maximum number of times a slice can be renewed, each renewal lasting the maximum duration at most. Slices of
+	// this class cannot be renewed when zero.
+	MaxRenewals int `json:"maxrenewals,omitempty"`
+}
+
+// NodeSelectionPolicy restricts the nodes a slice can reserve.
+type NodeSelectionPolicy struct {
+	// A selector that the nodes must match on top of the node selector of the slice.
+	Selector *corev1.NodeSelector `json:"selector,omitempty"`
+	// Maximum number of nodes a slice can reserve in total. There is no limit when zero.
+	MaxNodeCount int `json:"maxnodecount,omitempty"`
+	// Placement of the slices that do not set a placement strategy.
+	Placement Placement `json:"placement,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SliceClassList is a list of slice class resources
+type SliceClassList struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ListMeta `json:"metadata"`
+	// SliceClassList is a list of SliceClass resources. This element contains
+	// SliceClass resources.
+	Items []SliceClass `json:"items"`
+}
+
+// IsExclusive tells whether the slices of this class evict the workloads of the others from their nodes.
+func (sc SliceClass) IsExclusive() bool {
+	return sc.Spec.Isolation != IsolationShared
+}
+
+// IsAllowed tells whether the tenant can claim slices of this class.
+func (sc SliceClass) IsAllowed(tenant string) bool {
+	if len(sc.Spec.AllowedTenants) == 0 {
+		return true
+	}
+	for _, allowedTenant := range sc.Spec.AllowedTenants {
+		if strings.EqualFold(allowedTenant, tenant) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRenewable tells whether a slice of this class that has been renewed the given number of times can be renewed
+// again.
+func (sc SliceClass) IsRenewable(renewals int) bool {
+	return renewals < sc.Spec.MaxRenewals
+}
+
+// GetMaxExpiry returns the latest expiry date of a slice of this class created at the given time, or nil if the
+// class puts no limit on the duration.
+func (sc SliceClass) GetMaxExpiry(creation time.Time) *metav1.Time {
+	if sc.Spec.MaxDuration == nil {
+		return nil
+	}
+	return &metav1.Time{Time: creation.Add(sc.Spec.MaxDuration.Duration)}
+}
+
+// GetMaxRenewedExpiry returns the latest expiry date a renewal can extend a slice of this class that started at the
+// given time and has been renewed the given number of times to, or nil if the class puts no limit on the duration.
+// Each renewal adds the maximum duration at most to the lifetime of the slice.
+func (sc SliceClass) GetMaxRenewedExpiry(start time.Time, renewals int) *metav1.Time {
+	if sc.Spec.MaxDuration == nil {
+		return nil
+	}
+	return &metav1.Time{Time: start.Add(time.Duration(renewals+2) * sc.Spec.MaxDuration.Duration)}
+}
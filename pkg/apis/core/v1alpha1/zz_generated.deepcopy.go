@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	v1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -43,6 +44,84 @@ func (in *Address) DeepCopy() *Address {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AvailabilityStatus) DeepCopyInto(out *AvailabilityStatus) {
+	*out = *in
+	if in.LastSeen != nil {
+		in, out := &in.LastSeen, &out.LastSeen
+		*out = (*in).DeepCopy()
+	}
+	if in.NotReadySince != nil {
+		in, out := &in.NotReadySince, &out.NotReadySince
+		*out = (*in).DeepCopy()
+	}
+	if in.Transitions != nil {
+		in, out := &in.Transitions, &out.Transitions
+		*out = make([]ReadinessTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AvailabilityStatus.
+func (in *AvailabilityStatus) DeepCopy() *AvailabilityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AvailabilityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapStatus) DeepCopyInto(out *BootstrapStatus) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]BootstrapStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapStatus.
+func (in *BootstrapStatus) DeepCopy() *BootstrapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapStep) DeepCopyInto(out *BootstrapStep) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapStep.
+func (in *BootstrapStep) DeepCopy() *BootstrapStep {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Contact) DeepCopyInto(out *Contact) {
 	*out = *in
@@ -144,11 +223,21 @@ func (in *NodeContributionSpec) DeepCopyInto(out *NodeContributionSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AutoRecovery != nil {
+		in, out := &in.AutoRecovery, &out.AutoRecovery
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Limitations != nil {
 		in, out := &in.Limitations, &out.Limitations
 		*out = make([]Limitations, len(*in))
 		copy(*out, *in)
 	}
+	if in.Location != nil {
+		in, out := &in.Location, &out.Location
+		*out = new(NodeLocation)
+		**out = **in
+	}
 	return
 }
 
@@ -169,6 +258,25 @@ func (in *NodeContributionStatus) DeepCopyInto(out *NodeContributionStatus) {
 		in, out := &in.UpdateTimestamp, &out.UpdateTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.ResetTimestamp != nil {
+		in, out := &in.ResetTimestamp, &out.ResetTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(BootstrapStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Availability != nil {
+		in, out := &in.Availability, &out.Availability
+		*out = new(AvailabilityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(RecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -182,11 +290,50 @@ func (in *NodeContributionStatus) DeepCopy() *NodeContributionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocation) DeepCopyInto(out *NodeLocation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocation.
+func (in *NodeLocation) DeepCopy() *NodeLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSelectionPolicy) DeepCopyInto(out *NodeSelectionPolicy) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Placement = in.Placement
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSelectionPolicy.
+func (in *NodeSelectionPolicy) DeepCopy() *NodeSelectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSelectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeSelector) DeepCopyInto(out *NodeSelector) {
 	*out = *in
 	in.Selector.DeepCopyInto(&out.Selector)
 	in.Resources.DeepCopyInto(&out.Resources)
+	out.Placement = in.Placement
 	return
 }
 
@@ -200,6 +347,63 @@ func (in *NodeSelector) DeepCopy() *NodeSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Placement) DeepCopyInto(out *Placement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Placement.
+func (in *Placement) DeepCopy() *Placement {
+	if in == nil {
+		return nil
+	}
+	out := new(Placement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessTransition) DeepCopyInto(out *ReadinessTransition) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessTransition.
+func (in *ReadinessTransition) DeepCopy() *ReadinessTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryStatus) DeepCopyInto(out *RecoveryStatus) {
+	*out = *in
+	if in.LastAttemptTimestamp != nil {
+		in, out := &in.LastAttemptTimestamp, &out.LastAttemptTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTimestamp != nil {
+		in, out := &in.NextAttemptTimestamp, &out.NextAttemptTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryStatus.
+func (in *RecoveryStatus) DeepCopy() *RecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceTuning) DeepCopyInto(out *ResourceTuning) {
 	*out = *in
@@ -324,6 +528,10 @@ func (in *SliceClaimSpec) DeepCopyInto(out *SliceClaimSpec) {
 		in, out := &in.SliceExpiry, &out.SliceExpiry
 		*out = (*in).DeepCopy()
 	}
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -353,6 +561,93 @@ func (in *SliceClaimStatus) DeepCopy() *SliceClaimStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceClass) DeepCopyInto(out *SliceClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceClass.
+func (in *SliceClass) DeepCopy() *SliceClass {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SliceClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceClassList) DeepCopyInto(out *SliceClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SliceClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceClassList.
+func (in *SliceClassList) DeepCopy() *SliceClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SliceClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceClassSpec) DeepCopyInto(out *SliceClassSpec) {
+	*out = *in
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AllowedTenants != nil {
+		in, out := &in.AllowedTenants, &out.AllowedTenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.NodeSelectionPolicy.DeepCopyInto(&out.NodeSelectionPolicy)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceClassSpec.
+func (in *SliceClassSpec) DeepCopy() *SliceClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SliceList) DeepCopyInto(out *SliceList) {
 	*out = *in
@@ -386,6 +681,39 @@ func (in *SliceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceNode) DeepCopyInto(out *SliceNode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceNode.
+func (in *SliceNode) DeepCopy() *SliceNode {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SliceRenewalRefusal) DeepCopyInto(out *SliceRenewalRefusal) {
+	*out = *in
+	in.Expiry.DeepCopyInto(&out.Expiry)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SliceRenewalRefusal.
+func (in *SliceRenewalRefusal) DeepCopy() *SliceRenewalRefusal {
+	if in == nil {
+		return nil
+	}
+	out := new(SliceRenewalRefusal)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SliceSpec) DeepCopyInto(out *SliceSpec) {
 	*out = *in
@@ -395,6 +723,10 @@ func (in *SliceSpec) DeepCopyInto(out *SliceSpec) {
 		**out = **in
 	}
 	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -415,6 +747,16 @@ func (in *SliceStatus) DeepCopyInto(out *SliceStatus) {
 		in, out := &in.Expiry, &out.Expiry
 		*out = (*in).DeepCopy()
 	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]SliceNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.RenewalRefusal != nil {
+		in, out := &in.RenewalRefusal, &out.RenewalRefusal
+		*out = new(SliceRenewalRefusal)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
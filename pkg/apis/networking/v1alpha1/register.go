@@ -49,6 +49,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&VPNPeer{},
 		&VPNPeerList{},
+		&VPNAddressPool{},
+		&VPNAddressPoolList{},
+		&NodeLatency{},
+		&NodeLatencyList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
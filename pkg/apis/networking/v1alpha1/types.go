@@ -20,6 +20,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Values of Status.State
+const (
+	StatusReady  = "Ready"
+	StatusFailed = "Failure"
+)
+
+// Topologies of the VPN mesh
+const (
+	MeshTopologyFull    = "Full"
+	MeshTopologyPartial = "Partial"
+)
+
+// Condition types of VPNPeer
+const (
+	// ConditionConnected tells whether the peer completed a WireGuard handshake recently
+	ConditionConnected = "Connected"
+	// ConditionAddressesAssigned tells whether the peer has its addresses or a pool to get them from
+	ConditionAddressesAssigned = "AddressesAssigned"
+)
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -32,20 +52,62 @@ type VPNPeer struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// Spec is the vpnpeer resource spec
 	Spec VPNPeerSpec `json:"spec"`
+	// Status is the vpnpeer resource status
+	Status VPNPeerStatus `json:"status,omitempty"`
 }
 
 // VPNPeerSpec is the spec for a VPNPeer resource
 type VPNPeerSpec struct {
-	// IPv4 address of VPN peer.
-	AddressV4 string `json:"addressV4"`
-	// IPv6 address of VPN peer.
-	AddressV6 string `json:"addressV6"`
+	// Name of the node the peer belongs to, the name of the peer if empty.
+	NodeName string `json:"nodeName,omitempty"`
+	// Address pool to assign the addresses from, the default pool if empty.
+	Pool string `json:"pool,omitempty"`
+	// IPv4 address of VPN peer. It is assigned from the pool when empty.
+	AddressV4 string `json:"addressV4,omitempty"`
+	// IPv6 address of VPN peer. It is assigned from the pool when empty.
+	AddressV6 string `json:"addressV6,omitempty"`
 	// Endpoint address of the VPN tunnel.
 	EndpointAddress *string `json:"endpointAddress"`
 	// Endpoint port of the VPN tunnel.
 	EndpointPort *int `json:"endpointPort"`
 	// VPN public key of the peer.
 	PublicKey string `json:"publicKey"`
+	// VPN public key that replaces the current one. The peer is configured with both keys, and the addresses move to
+	// the new key once it completes a handshake more recent than the current key.
+	NextPublicKey string `json:"nextPublicKey,omitempty"`
+	// Name of the secret in the edgenet namespace that holds the preshared key of the peer.
+	PresharedKeySecret string `json:"presharedKeySecret,omitempty"`
+	// Interval in seconds at which keepalive packets are sent to the peer, 5 if not set. 0 disables them.
+	PersistentKeepalive *int `json:"persistentKeepalive,omitempty"`
+	// Ranges in CIDR notation routed through the peer besides its addresses, such as the local subnet of the node.
+	AllowedIPs []string `json:"allowedIPs,omitempty"`
+	// Mesh makes the node of the peer connect directly to the other mesh peers it selects, rather than only
+	// through the head node.
+	Mesh *VPNPeerMesh `json:"mesh,omitempty"`
+}
+
+// VPNPeerMesh describes which mesh peers a peer connects to directly. Two peers connect when both select each other.
+type VPNPeerMesh struct {
+	// This can be 'Full' to connect to all mesh peers, or 'Partial' to connect to the mesh peers whose nodes the
+	// selector matches.
+	Topology string `json:"topology"`
+	// Selects the nodes of the peers to connect to in a partial mesh, such as the nodes of the same region.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// VPNPeerStatus is the status for a VPNPeer resource, as seen by the WireGuard interface of the head node
+// that last completed a handshake with the peer
+type VPNPeerStatus struct {
+	// Time of the most recent handshake with the peer.
+	LatestHandshake *metav1.Time `json:"latestHandshake,omitempty"`
+	// Number of bytes received from the peer.
+	ReceiveBytes int64 `json:"receiveBytes"`
+	// Number of bytes sent to the peer.
+	TransmitBytes int64 `json:"transmitBytes"`
+	// Endpoint of the peer as observed by the kernel, which can differ from the one in the spec behind a NAT.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Conditions of the peer, such as Connected.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -59,3 +121,121 @@ type VPNPeerList struct {
 	// VPNPeerList is a list of VPNPeer resources thus, VPNPeers are contained here.
 	Items []VPNPeer `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VPNAddressPool describes the address ranges the VPN peers get their addresses from
+type VPNAddressPool struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the vpnaddresspool resource spec
+	Spec VPNAddressPoolSpec `json:"spec"`
+	// Status is the vpnaddresspool resource status
+	Status VPNAddressPoolStatus `json:"status,omitempty"`
+}
+
+// VPNAddressPoolSpec is the spec for a VPNAddressPool resource
+type VPNAddressPoolSpec struct {
+	// IPv4 range of the pool in CIDR notation.
+	CIDRV4 string `json:"cidrV4"`
+	// IPv6 range of the pool in CIDR notation.
+	CIDRV6 string `json:"cidrV6"`
+	// Addresses of the ranges that are never assigned, such as the address of the VPN server.
+	Reserved []string `json:"reserved,omitempty"`
+	// The peers that do not name a pool get their addresses from the default pool.
+	Default bool `json:"default,omitempty"`
+}
+
+// VPNAddressPoolStatus is the status for a VPNAddressPool resource
+type VPNAddressPoolStatus struct {
+	// This can be 'Ready' or 'Failure'.
+	State string `json:"state"`
+	// Message contains additional information.
+	Message string `json:"message"`
+	// Number of IPv4 addresses of the pool in use.
+	AllocatedV4 int `json:"allocatedV4"`
+	// Number of IPv6 addresses of the pool in use.
+	AllocatedV6 int `json:"allocatedV6"`
+	// Addresses of the pool that more than one peer claims.
+	Conflicts []AddressConflict `json:"conflicts,omitempty"`
+}
+
+// AddressConflict is an address that more than one peer claims
+type AddressConflict struct {
+	// The address in conflict.
+	Address string `json:"address"`
+	// Names of the peers claiming the address. The first one, created before the others, keeps it.
+	Peers []string `json:"peers"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VPNAddressPoolList is a list of VPNAddressPool resources
+type VPNAddressPoolList struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ListMeta `json:"metadata"`
+	// VPNAddressPoolList is a list of VPNAddressPool resources thus, VPNAddressPools are contained here.
+	Items []VPNAddressPool `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeLatency holds the round-trip times measured from a node to the other nodes and to the landmarks
+type NodeLatency struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec is the nodelatency resource spec
+	Spec NodeLatencySpec `json:"spec"`
+	// Status is the nodelatency resource status
+	Status NodeLatencyStatus `json:"status,omitempty"`
+}
+
+// NodeLatencySpec is the spec for a NodeLatency resource
+type NodeLatencySpec struct {
+	// Name of the node the round-trip times are measured from.
+	Node string `json:"node"`
+}
+
+// NodeLatencyStatus is the status for a NodeLatency resource
+type NodeLatencyStatus struct {
+	// Round-trip times to the other nodes of the cluster.
+	Nodes []LatencyMeasurement `json:"nodes,omitempty"`
+	// Round-trip times to the landmarks, which are well-known hosts outside of the cluster.
+	Landmarks []LatencyMeasurement `json:"landmarks,omitempty"`
+	// UpdateTimestamp is the last time the round-trip times were measured.
+	UpdateTimestamp *metav1.Time `json:"updateTimestamp,omitempty"`
+}
+
+// LatencyMeasurement is the round-trip time to a node or a landmark
+type LatencyMeasurement struct {
+	// Name of the node or the landmark.
+	Name string `json:"name"`
+	// Address and port the round-trip time is measured to.
+	Address string `json:"address"`
+	// Median round-trip time of the probes, which is empty if none got an answer.
+	RTT *metav1.Duration `json:"rtt,omitempty"`
+	// Percentage of the probes that got no answer.
+	Loss int `json:"loss"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeLatencyList is a list of NodeLatency resources
+type NodeLatencyList struct {
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta `json:",inline"`
+	// ObjectMeta contains the metadata for the particular object, including
+	metav1.ListMeta `json:"metadata"`
+	// NodeLatencyList is a list of NodeLatency resources thus, NodeLatencies are contained here.
+	Items []NodeLatency `json:"items"`
+}
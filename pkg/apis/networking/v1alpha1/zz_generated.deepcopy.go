@@ -22,15 +22,275 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddressConflict) DeepCopyInto(out *AddressConflict) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddressConflict.
+func (in *AddressConflict) DeepCopy() *AddressConflict {
+	if in == nil {
+		return nil
+	}
+	out := new(AddressConflict)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyMeasurement) DeepCopyInto(out *LatencyMeasurement) {
+	*out = *in
+	if in.RTT != nil {
+		in, out := &in.RTT, &out.RTT
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyMeasurement.
+func (in *LatencyMeasurement) DeepCopy() *LatencyMeasurement {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyMeasurement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLatency) DeepCopyInto(out *NodeLatency) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLatency.
+func (in *NodeLatency) DeepCopy() *NodeLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeLatency) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLatencyList) DeepCopyInto(out *NodeLatencyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeLatency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLatencyList.
+func (in *NodeLatencyList) DeepCopy() *NodeLatencyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLatencyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeLatencyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLatencySpec) DeepCopyInto(out *NodeLatencySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLatencySpec.
+func (in *NodeLatencySpec) DeepCopy() *NodeLatencySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLatencySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLatencyStatus) DeepCopyInto(out *NodeLatencyStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]LatencyMeasurement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Landmarks != nil {
+		in, out := &in.Landmarks, &out.Landmarks
+		*out = make([]LatencyMeasurement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateTimestamp != nil {
+		in, out := &in.UpdateTimestamp, &out.UpdateTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLatencyStatus.
+func (in *NodeLatencyStatus) DeepCopy() *NodeLatencyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLatencyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNAddressPool) DeepCopyInto(out *VPNAddressPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNAddressPool.
+func (in *VPNAddressPool) DeepCopy() *VPNAddressPool {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNAddressPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VPNAddressPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNAddressPoolList) DeepCopyInto(out *VPNAddressPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VPNAddressPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNAddressPoolList.
+func (in *VPNAddressPoolList) DeepCopy() *VPNAddressPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNAddressPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VPNAddressPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNAddressPoolSpec) DeepCopyInto(out *VPNAddressPoolSpec) {
+	*out = *in
+	if in.Reserved != nil {
+		in, out := &in.Reserved, &out.Reserved
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNAddressPoolSpec.
+func (in *VPNAddressPoolSpec) DeepCopy() *VPNAddressPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNAddressPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNAddressPoolStatus) DeepCopyInto(out *VPNAddressPoolStatus) {
+	*out = *in
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]AddressConflict, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNAddressPoolStatus.
+func (in *VPNAddressPoolStatus) DeepCopy() *VPNAddressPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNAddressPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VPNPeer) DeepCopyInto(out *VPNPeer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -85,6 +345,27 @@ func (in *VPNPeerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNPeerMesh) DeepCopyInto(out *VPNPeerMesh) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNPeerMesh.
+func (in *VPNPeerMesh) DeepCopy() *VPNPeerMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNPeerMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VPNPeerSpec) DeepCopyInto(out *VPNPeerSpec) {
 	*out = *in
@@ -98,6 +379,21 @@ func (in *VPNPeerSpec) DeepCopyInto(out *VPNPeerSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.PersistentKeepalive != nil {
+		in, out := &in.PersistentKeepalive, &out.PersistentKeepalive
+		*out = new(int)
+		**out = **in
+	}
+	if in.AllowedIPs != nil {
+		in, out := &in.AllowedIPs, &out.AllowedIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mesh != nil {
+		in, out := &in.Mesh, &out.Mesh
+		*out = new(VPNPeerMesh)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -110,3 +406,30 @@ func (in *VPNPeerSpec) DeepCopy() *VPNPeerSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNPeerStatus) DeepCopyInto(out *VPNPeerStatus) {
+	*out = *in
+	if in.LatestHandshake != nil {
+		in, out := &in.LatestHandshake, &out.LatestHandshake
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNPeerStatus.
+func (in *VPNPeerStatus) DeepCopy() *VPNPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -66,6 +66,9 @@ type TenantRequestSpec struct {
 	// Whether cluster-level network policies will be applied to tenant namespaces
 	// for security purposes.
 	ClusterNetworkPolicy bool `json:"clusternetworkpolicy"`
+	// Network isolation of the tenant namespaces, which can be 'restricted', 'baseline',
+	// or 'privileged'. Baseline applies when it is empty.
+	NetworkProfile string `json:"networkprofile,omitempty"`
 	// Requested allocation of certain resource types. Resource types are
 	// kubernetes default resource types.
 	ResourceAllocation map[corev1.ResourceName]resource.Quantity `json:"resourceallocation"`
@@ -701,13 +701,16 @@ func TestUpdate(t *testing.T) {
 	nodeParis := g.nodeObj.DeepCopy()
 	nodeParis.SetName("edgenet.planet-lab.eu")
 	nodeParis.ObjectMeta.Labels = map[string]string{
-		"kubernetes.io/hostname":  "edgenet.planet-lab.eu",
-		"edge-net.io/city":        "Paris",
-		"edge-net.io/country-iso": "FR",
-		"edge-net.io/state-iso":   "IDF",
-		"edge-net.io/continent":   "Europe",
-		"edge-net.io/lon":         "e2.34",
-		"edge-net.io/lat":         "n48.86",
+		"kubernetes.io/hostname":    "edgenet.planet-lab.eu",
+		"edge-net.io/city":          "Paris",
+		"edge-net.io/country-iso":   "FR",
+		"edge-net.io/state-iso":     "IDF",
+		"edge-net.io/continent":     "Europe",
+		"edge-net.io/lon":           "e2.34",
+		"edge-net.io/lat":           "n48.86",
+		"edge-net.io/geohash-3":     "u09",
+		"edge-net.io/latency-class": "low",
+		"latency.edge-net.io/paris": "low",
 	}
 	kubeclientset.CoreV1().Nodes().Create(context.TODO(), nodeParis.DeepCopy(), metav1.CreateOptions{})
 	nodeCollegePark := g.nodeObj.DeepCopy()
@@ -726,13 +729,15 @@ func TestUpdate(t *testing.T) {
 	nodeSeaside := g.nodeObj.DeepCopy()
 	nodeSeaside.SetName("nps-1.edge-net.io")
 	nodeSeaside.ObjectMeta.Labels = map[string]string{
-		"kubernetes.io/hostname":  "nps-1.edge-net.io",
-		"edge-net.io/city":        "Seaside",
-		"edge-net.io/country-iso": "US",
-		"edge-net.io/state-iso":   "CA",
-		"edge-net.io/continent":   "North America",
-		"edge-net.io/lon":         "w-121.79",
-		"edge-net.io/lat":         "n36.62",
+		"kubernetes.io/hostname":    "nps-1.edge-net.io",
+		"edge-net.io/city":          "Seaside",
+		"edge-net.io/country-iso":   "US",
+		"edge-net.io/state-iso":     "CA",
+		"edge-net.io/continent":     "North America",
+		"edge-net.io/lon":           "w-121.79",
+		"edge-net.io/lat":           "n36.62",
+		"edge-net.io/latency-class": "high",
+		"latency.edge-net.io/paris": "high",
 	}
 	kubeclientset.CoreV1().Nodes().Create(context.TODO(), nodeSeaside.DeepCopy(), metav1.CreateOptions{})
 
@@ -810,6 +815,23 @@ func TestUpdate(t *testing.T) {
 	paris.Name = "Polygon"
 	polygonParis := []appsv1alpha1.Selector{paris}
 
+	nearParis := g.selector
+	nearParis.Value = []string{"48.8566,2.3522,50"}
+	nearParis.Quantity = 1
+	nearParis.Name = "Distance"
+	distanceParis := []appsv1alpha1.Selector{nearParis}
+	nearParis.Operator = "NotIn"
+	distanceParisOut := []appsv1alpha1.Selector{nearParis}
+
+	lowLatency := g.selector
+	lowLatency.Value = []string{"low"}
+	lowLatency.Quantity = 1
+	lowLatency.Name = "Latency"
+	latencyLow := []appsv1alpha1.Selector{lowLatency}
+	lowLatency.Value = []string{"paris=low"}
+	lowLatency.Operator = "NotIn"
+	latencyParisOut := []appsv1alpha1.Selector{lowLatency}
+
 	countryUScityParis := []appsv1alpha1.Selector{us, paris}
 
 	paris.Quantity = 4
@@ -824,6 +846,10 @@ func TestUpdate(t *testing.T) {
 	}{
 		"city/seaside":          {citySeaside, success, [][]string{{nodeSeaside.GetName()}}},
 		"polygon/paris":         {polygonParis, success, [][]string{{nodeParis.GetName()}}},
+		"distance/paris":        {distanceParis, success, [][]string{{nodeParis.GetName()}}},
+		"distance/paris/out":    {distanceParisOut, success, [][]string{{nodeSeaside.GetName()}}},
+		"latency/low":           {latencyLow, success, [][]string{{nodeParis.GetName()}}},
+		"latency/paris/out":     {latencyParisOut, success, [][]string{{nodeSeaside.GetName()}}},
 		"state/ca":              {stateCA, success, [][]string{{nodeSeaside.GetName()}}},
 		"country/us/all":        {countryUSAll, success, [][]string{{nodeSeaside.GetName()}}},
 		"country/us/out":        {countryUSOut, success, [][]string{{nodeParis.GetName()}}},
@@ -32,6 +32,7 @@ import (
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/apps/v1alpha3"
 	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/apps/v1alpha3"
 	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
 	"github.com/EdgeNet-project/edgenet/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
@@ -71,6 +72,10 @@ const (
 	messageExtendedWorkloadInUse  = "Workload is owned by another selective deployment: %s %s"
 	failureGeoJSON                = "GeoJSON Error"
 	messageGeoJSONError           = "GeoJSON has a format error"
+	failureDistance               = "Distance Error"
+	messageDistanceError          = "Distance must be a latitude, a longitude and a radius in kilometers"
+	failureLatency                = "Latency Error"
+	messageLatencyError           = "Latency must be a class, which can follow a landmark and an equal sign"
 	failureFewerNodes             = "Fewer nodes issue"
 	messageFewerNodes             = "The number of nodes found is lower than desired"
 	failure                       = "Failure"
@@ -438,6 +443,10 @@ func (c *Controller) applyCriteria(selectivedeploymentCopy *appsv1alpha3.Selecti
 					}
 				}
 			} else {
+				// The suspension of the tenant holds the workload until the tenant is resumed
+				if multitenancy.IsSuspended(actualDeployment.GetAnnotations()) {
+					continue
+				}
 				if hasOwner := checkOwnerReferences(selectivedeploymentCopy, actualDeployment.GetOwnerReferences()); !hasOwner {
 					desiredPodTemplate, isFailed := c.configureWorkload(selectivedeploymentCopy, actualDeployment.Spec.Template, deployment.Spec.Template, ownerReferences)
 					if isFailed {
@@ -486,6 +495,10 @@ func (c *Controller) applyCriteria(selectivedeploymentCopy *appsv1alpha3.Selecti
 					}
 				}
 			} else {
+				// The suspension of the tenant holds the workload until the tenant is resumed
+				if multitenancy.IsSuspended(actualDaemonset.GetAnnotations()) {
+					continue
+				}
 				if hasOwner := checkOwnerReferences(selectivedeploymentCopy, actualDaemonset.GetOwnerReferences()); !hasOwner {
 					desiredPodTemplate, isFailed := c.configureWorkload(selectivedeploymentCopy, actualDaemonset.Spec.Template, daemonset.Spec.Template, ownerReferences)
 					if isFailed {
@@ -534,6 +547,10 @@ func (c *Controller) applyCriteria(selectivedeploymentCopy *appsv1alpha3.Selecti
 					}
 				}
 			} else {
+				// The suspension of the tenant holds the workload until the tenant is resumed
+				if multitenancy.IsSuspended(actualStatefulset.GetAnnotations()) {
+					continue
+				}
 				if hasOwner := checkOwnerReferences(selectivedeploymentCopy, actualStatefulset.GetOwnerReferences()); !hasOwner {
 					desiredPodTemplate, isFailed := c.configureWorkload(selectivedeploymentCopy, actualStatefulset.Spec.Template, statefulset.Spec.Template, ownerReferences)
 					if isFailed {
@@ -582,6 +599,10 @@ func (c *Controller) applyCriteria(selectivedeploymentCopy *appsv1alpha3.Selecti
 					}
 				}
 			} else {
+				// The suspension of the tenant holds the workload until the tenant is resumed
+				if multitenancy.IsSuspended(actualJob.GetAnnotations()) {
+					continue
+				}
 				if hasOwner := checkOwnerReferences(selectivedeploymentCopy, actualJob.GetOwnerReferences()); !hasOwner {
 					desiredPodTemplate, isFailed := c.configureWorkload(selectivedeploymentCopy, actualJob.Spec.Template, job.Spec.Template, ownerReferences)
 					if isFailed {
@@ -630,6 +651,10 @@ func (c *Controller) applyCriteria(selectivedeploymentCopy *appsv1alpha3.Selecti
 					}
 				}
 			} else {
+				// The suspension of the tenant holds the workload until the tenant is resumed
+				if multitenancy.IsSuspended(actualCronjob.GetAnnotations()) {
+					continue
+				}
 				if hasOwner := checkOwnerReferences(selectivedeploymentCopy, actualCronjob.GetOwnerReferences()); !hasOwner {
 					desiredPodTemplate, isFailed := c.configureWorkload(selectivedeploymentCopy, actualCronjob.Spec.JobTemplate.Spec.Template, cronjob.Spec.JobTemplate.Spec.Template, ownerReferences)
 					if isFailed {
@@ -753,6 +778,26 @@ func (c *Controller) setFilter(selectivedeploymentCopy *appsv1alpha3.SelectiveDe
 				return false
 			}
 			for _, selectorValue := range selectorRow.Value {
+				var distance *multiprovider.DistanceSelector
+				if selectorName == "distance" {
+					if distance, err = multiprovider.ParseDistanceSelector(selectorValue); err != nil {
+						c.recorder.Event(selectivedeploymentCopy, corev1.EventTypeWarning, failureDistance, messageDistanceError)
+						selectivedeploymentCopy.Status.State = failure
+						selectivedeploymentCopy.Status.Message = messageDistanceError
+						isFailed = true
+						continue
+					}
+				}
+				var latencyKey, latencyClass string
+				if selectorName == "latency" {
+					if latencyKey, latencyClass, err = multiprovider.ParseLatencySelector(selectorValue); err != nil {
+						c.recorder.Event(selectivedeploymentCopy, corev1.EventTypeWarning, failureLatency, messageLatencyError)
+						selectivedeploymentCopy.Status.State = failure
+						selectivedeploymentCopy.Status.Message = messageLatencyError
+						isFailed = true
+						continue
+					}
+				}
 				// The loop to process each node separately
 				for _, nodeRow := range nodesRaw {
 					taintBlock := false
@@ -780,6 +825,33 @@ func (c *Controller) setFilter(selectivedeploymentCopy *appsv1alpha3.SelectiveDe
 									matchNodeList = append(matchNodeList, nodeRow.Labels["kubernetes.io/hostname"])
 								}
 							}
+						case "distance":
+							if _, _, ok := multiprovider.GetNodeCoordinates(nodeRow.Labels); ok {
+								// The geohash labels narrow the nodes down before measuring how far they are
+								within := distance.Matches(nodeRow.Labels)
+								if within && selectorRow.Operator == "In" {
+									if !checkActualList(nodeRow.Labels["kubernetes.io/hostname"]) {
+										matchNodeList = append(matchNodeList, nodeRow.Labels["kubernetes.io/hostname"])
+									}
+								} else if !within && selectorRow.Operator == "NotIn" {
+									if !checkActualList(nodeRow.Labels["kubernetes.io/hostname"]) {
+										matchNodeList = append(matchNodeList, nodeRow.Labels["kubernetes.io/hostname"])
+									}
+								}
+							}
+						case "latency":
+							// The nodes whose round-trip times are not measured have no latency labels to compare
+							if class, ok := nodeRow.Labels[latencyKey]; ok {
+								if class == latencyClass && selectorRow.Operator == "In" {
+									if !checkActualList(nodeRow.Labels["kubernetes.io/hostname"]) {
+										matchNodeList = append(matchNodeList, nodeRow.Labels["kubernetes.io/hostname"])
+									}
+								} else if class != latencyClass && selectorRow.Operator == "NotIn" {
+									if !checkActualList(nodeRow.Labels["kubernetes.io/hostname"]) {
+										matchNodeList = append(matchNodeList, nodeRow.Labels["kubernetes.io/hostname"])
+									}
+								}
+							}
 						case "polygon":
 							var polygon [][]float64
 							err = json.Unmarshal([]byte(selectorValue), &polygon)
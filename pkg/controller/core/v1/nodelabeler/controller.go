@@ -45,9 +45,8 @@ type Controller struct {
 	// Kubernetes API.
 	recorder record.EventRecorder
 
-	maxmindURL        string
-	maxmindAccountID  string
-	maxmindLicenseKey string
+	// geoIPProvider locates the nodes by their addresses
+	geoIPProvider multiprovider.GeoIPProvider
 }
 
 // NewController returns a new controller
@@ -55,9 +54,7 @@ func NewController(
 	kubeclientset kubernetes.Interface,
 	edgenetclientset clientset.Interface,
 	informer coreinformers.NodeInformer,
-	maxmindURL string,
-	maxmindAccountID string,
-	maxmindLicenseKey string,
+	geoIPProvider multiprovider.GeoIPProvider,
 ) *Controller {
 	// Create event broadcaster
 	utilruntime.Must(scheme.AddToScheme(scheme.Scheme))
@@ -68,15 +65,13 @@ func NewController(
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
 
 	controller := &Controller{
-		kubeclientset:     kubeclientset,
-		edgenetclientset:  edgenetclientset,
-		lister:            informer.Lister(),
-		synced:            informer.Informer().HasSynced,
-		workqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "NodeLabeler"),
-		recorder:          recorder,
-		maxmindURL:        maxmindURL,
-		maxmindAccountID:  maxmindAccountID,
-		maxmindLicenseKey: maxmindLicenseKey,
+		kubeclientset:    kubeclientset,
+		edgenetclientset: edgenetclientset,
+		lister:           informer.Lister(),
+		synced:           informer.Informer().HasSynced,
+		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "NodeLabeler"),
+		recorder:         recorder,
+		geoIPProvider:    geoIPProvider,
 	}
 
 	klog.Infoln("Setting up event handlers")
@@ -85,8 +80,9 @@ func NewController(
 	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.enqueueNodelabeler,
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			updated := multiprovider.CompareIPAddresses(oldObj.(*corev1.Node), newObj.(*corev1.Node))
-			if updated {
+			oldNode, newNode := oldObj.(*corev1.Node), newObj.(*corev1.Node)
+			updated := multiprovider.CompareIPAddresses(oldNode, newNode)
+			if updated || oldNode.GetAnnotations()[multiprovider.LocationAnnotation] != newNode.GetAnnotations()[multiprovider.LocationAnnotation] {
 				controller.enqueueNodelabeler(newObj)
 			}
 		},
@@ -192,11 +188,31 @@ func (c *Controller) setNodeGeolocation(obj interface{}) {
 	klog.V(4).Infoln("Handler.ObjectCreated")
 	nodeObj := obj.(*corev1.Node)
 
-	internalIP, externalIP := multiprovider.GetNodeIPAddresses(nodeObj)
-	result := false
-
 	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, nil, nil)
 
+	// A declared location prevails over the measured one, which still provides the network labels
+	measured := c.locateNode(nodeObj)
+	declared, err := multiprovider.GetDeclaredLocation(nodeObj)
+	if err != nil {
+		klog.Infof("Ignoring the location declared for %s: %s", nodeObj.Name, err)
+		c.recorder.Event(nodeObj, corev1.EventTypeWarning, "InvalidLocation", err.Error())
+	}
+	if declared != nil {
+		if measured != nil {
+			declared.ISP, declared.AS, declared.ASN = measured.ISP, measured.AS, measured.ASN
+		}
+		multiproviderManager.SetNodeGeolocation(nodeObj.Name, declared, multiprovider.LocationSourceDeclared)
+	} else if measured != nil {
+		multiproviderManager.SetNodeGeolocation(nodeObj.Name, measured, multiprovider.LocationSourceMeasured)
+	}
+}
+
+// locateNode returns the location of the first address of the node with meaningful coordinates, or that of the last
+// address located if all are at zero latitude and longitude
+func (c *Controller) locateNode(nodeObj *corev1.Node) *multiprovider.GeoLocation {
+	internalIP, externalIP := multiprovider.GetNodeIPAddresses(nodeObj)
+	addresses := []string{}
+
 	// 1. Use the VPNPeer endpoint address if available.
 	peer, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), nodeObj.Name, v1.GetOptions{})
 	if err != nil {
@@ -207,38 +223,34 @@ func (c *Controller) setNodeGeolocation(obj interface{}) {
 		)
 	} else {
 		klog.V(4).Infof("VPNPeer endpoint IP: %s", *peer.Spec.EndpointAddress)
-		result = multiproviderManager.GetGeolocationByIP(
-			c.maxmindURL,
-			c.maxmindAccountID,
-			c.maxmindLicenseKey,
-			nodeObj.Name,
-			*peer.Spec.EndpointAddress,
-		)
+		addresses = append(addresses, *peer.Spec.EndpointAddress)
 	}
-
 	// 2. Otherwise use the node external IP if available.
-	if externalIP != "" && !result {
-		klog.V(4).Infof("External IP: %s", externalIP)
-		result = multiproviderManager.GetGeolocationByIP(
-			c.maxmindURL,
-			c.maxmindAccountID,
-			c.maxmindLicenseKey,
-			nodeObj.Name,
-			externalIP,
-		)
+	if externalIP != "" {
+		addresses = append(addresses, externalIP)
 	}
-
 	// 3. Otherwise use the node internal IP if available.
-	if internalIP != "" && !result {
-		klog.V(4).Infof("Internal IP: %s", internalIP)
-		multiproviderManager.GetGeolocationByIP(
-			c.maxmindURL,
-			c.maxmindAccountID,
-			c.maxmindLicenseKey,
-			nodeObj.Name,
-			internalIP,
-		)
+	if internalIP != "" {
+		addresses = append(addresses, internalIP)
+	}
+
+	var found *multiprovider.GeoLocation
+	for _, address := range addresses {
+		location, err := c.geoIPProvider.Locate(address)
+		if err != nil {
+			klog.Infof("Failed to locate %s: %s", address, err)
+			continue
+		}
+		if location == nil {
+			continue
+		}
+		found = location
+		// Zero value typically means there isn't any result meaningful
+		if location.Longitude != 0 || location.Latitude != 0 {
+			break
+		}
 	}
+	return found
 }
 
 func (c *Controller) enqueueNodelabeler(obj interface{}) {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 
 	"github.com/EdgeNet-project/edgenet/pkg/signals"
 	"github.com/EdgeNet-project/edgenet/pkg/util"
@@ -63,9 +64,7 @@ func TestMain(m *testing.M) {
 			kubeclientset,
 			edgenetclientset,
 			kubeInformerFactory.Core().V1().Nodes(),
-			ts.URL+"/",
-			"null-account-id",
-			"null-license-key",
+			multiprovider.NewMaxmindGeoIPProvider(ts.URL+"/", "null-account-id", "null-license-key"),
 		)
 
 		kubeInformerFactory.Start(stopCh)
@@ -135,16 +134,25 @@ func TestAssigningGeoLabels(t *testing.T) {
 		},
 	}
 	expectedLabelsFR := map[string]string{
-		"kubernetes.io/hostname":  "fr.edge-net.io",
-		"edge-net.io/continent":   "Europe",
-		"edge-net.io/state-iso":   "IDF",
-		"edge-net.io/country-iso": "FR",
-		"edge-net.io/city":        "Pantin",
-		"edge-net.io/lat":         "n48.895800",
-		"edge-net.io/lon":         "e2.406400",
-		"edge-net.io/isp":         "Renater",
-		"edge-net.io/as":          "Renater",
-		"edge-net.io/asn":         "1307",
+		"kubernetes.io/hostname":      "fr.edge-net.io",
+		"edge-net.io/continent":       "Europe",
+		"edge-net.io/state-iso":       "IDF",
+		"edge-net.io/country-iso":     "FR",
+		"edge-net.io/city":            "Pantin",
+		"edge-net.io/lat":             "n48.895800",
+		"edge-net.io/lon":             "e2.406400",
+		"edge-net.io/isp":             "Renater",
+		"edge-net.io/as":              "Renater",
+		"edge-net.io/asn":             "1307",
+		"edge-net.io/location-source": "measured",
+		"edge-net.io/geohash-1":       "u",
+		"edge-net.io/geohash-2":       "u0",
+		"edge-net.io/geohash-3":       "u09",
+		"edge-net.io/geohash-4":       "u09w",
+		"edge-net.io/geohash-5":       "u09wn",
+		"edge-net.io/geohash-6":       "u09wnv",
+		"edge-net.io/region":          "Europe.FR.IDF",
+		"edge-net.io/zone":            "Europe.FR.IDF.Pantin",
 	}
 
 	// Create the US Node
@@ -162,24 +170,67 @@ func TestAssigningGeoLabels(t *testing.T) {
 		},
 	}
 	expectedLabelsUS := map[string]string{
-		"kubernetes.io/hostname":  "us.edge-net.io",
-		"edge-net.io/continent":   "North_America",
-		"edge-net.io/state-iso":   "MD",
-		"edge-net.io/country-iso": "US",
-		"edge-net.io/city":        "College_Park",
-		"edge-net.io/lat":         "n38.996500",
-		"edge-net.io/lon":         "w-76.934000",
-		"edge-net.io/isp":         "University_of_Maryland",
-		"edge-net.io/as":          "MAX-GIGAPOP",
-		"edge-net.io/asn":         "10886",
+		"kubernetes.io/hostname":      "us.edge-net.io",
+		"edge-net.io/continent":       "North_America",
+		"edge-net.io/state-iso":       "MD",
+		"edge-net.io/country-iso":     "US",
+		"edge-net.io/city":            "College_Park",
+		"edge-net.io/lat":             "n38.996500",
+		"edge-net.io/lon":             "w-76.934000",
+		"edge-net.io/isp":             "University_of_Maryland",
+		"edge-net.io/as":              "MAX-GIGAPOP",
+		"edge-net.io/asn":             "10886",
+		"edge-net.io/location-source": "measured",
+		"edge-net.io/geohash-1":       "d",
+		"edge-net.io/geohash-2":       "dq",
+		"edge-net.io/geohash-3":       "dqc",
+		"edge-net.io/geohash-4":       "dqcm",
+		"edge-net.io/geohash-5":       "dqcmc",
+		"edge-net.io/geohash-6":       "dqcmc7",
+		"edge-net.io/region":          "North_America.US.MD",
+		"edge-net.io/zone":            "North_America.US.MD.College_Park",
+	}
+
+	// Create a node behind a proxy in Maryland, whose contributor declares it in Paris
+	nodeDeclared := nodeUS.DeepCopy()
+	nodeDeclared.ObjectMeta = metav1.ObjectMeta{
+		Name: "declared.edge-net.io",
+		Labels: map[string]string{
+			"kubernetes.io/hostname": "declared.edge-net.io",
+		},
+		Annotations: map[string]string{
+			multiprovider.LocationAnnotation: `{"latitude": "48.846600", "longitude": "2.357200", "city": "Paris", "countryISO": "FR", "continent": "Europe"}`,
+		},
+	}
+	expectedLabelsDeclared := map[string]string{
+		"kubernetes.io/hostname":      "declared.edge-net.io",
+		"edge-net.io/continent":       "Europe",
+		"edge-net.io/state-iso":       "FR",
+		"edge-net.io/country-iso":     "FR",
+		"edge-net.io/city":            "Paris",
+		"edge-net.io/lat":             "n48.846600",
+		"edge-net.io/lon":             "e2.357200",
+		"edge-net.io/isp":             "University_of_Maryland",
+		"edge-net.io/as":              "MAX-GIGAPOP",
+		"edge-net.io/asn":             "10886",
+		"edge-net.io/location-source": "declared",
+		"edge-net.io/geohash-1":       "u",
+		"edge-net.io/geohash-2":       "u0",
+		"edge-net.io/geohash-3":       "u09",
+		"edge-net.io/geohash-4":       "u09t",
+		"edge-net.io/geohash-5":       "u09tv",
+		"edge-net.io/geohash-6":       "u09tvs",
+		"edge-net.io/region":          "Europe.FR",
+		"edge-net.io/zone":            "Europe.FR.Paris",
 	}
 
 	cases := map[string]struct {
 		Node     *corev1.Node
 		Expected map[string]string
 	}{
-		"fr": {nodeFR, expectedLabelsFR},
-		"us": {nodeUS, expectedLabelsUS},
+		"fr":       {nodeFR, expectedLabelsFR},
+		"us":       {nodeUS, expectedLabelsUS},
+		"declared": {nodeDeclared, expectedLabelsDeclared},
 	}
 
 	for k, tc := range cases {
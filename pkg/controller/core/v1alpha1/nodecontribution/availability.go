@@ -0,0 +1,271 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecontribution
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+const (
+	// availabilityInterval is the period at which the availability figures are refreshed
+	availabilityInterval = 5 * time.Minute
+	// lastSeenResolution is the granularity of the last time a ready node was seen, which keeps the refresh of
+	// the figures from writing the status of every ready node on each tick
+	lastSeenResolution = time.Hour
+	// availabilityWindow is the longest period the uptime is computed over
+	availabilityWindow = 30 * 24 * time.Hour
+	// maxTransitions bounds the history of a node that keeps flapping
+	maxTransitions = 256
+
+	reasonNodeNotReady     = "NodeNotReady"
+	messageNotReadyTooLong = "Node has not been ready for more than %s"
+)
+
+// getNotReadyThreshold returns how long a node can be not ready before an event warns about it
+func getNotReadyThreshold() time.Duration {
+	threshold := 15 * time.Minute
+	if flag.Lookup("not-ready-threshold") != nil {
+		threshold = flag.Lookup("not-ready-threshold").Value.(flag.Getter).Get().(time.Duration)
+	}
+	return threshold
+}
+
+// enqueueNode puts the name of a node whose readiness has changed onto the node work queue, away from the
+// informer event handlers
+func (c *Controller) enqueueNode(node *corev1.Node) {
+	c.nodeWorkqueue.Add(node.GetName())
+}
+
+// runNodeWorker records the readiness of the nodes taken from the node work queue
+func (c *Controller) runNodeWorker() {
+	for c.processNextNode() {
+	}
+}
+
+// processNextNode observes a single node from the node work queue and requeues it on failure
+func (c *Controller) processNextNode() bool {
+	obj, shutdown := c.nodeWorkqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.nodeWorkqueue.Done(obj)
+	if err := c.observeNode(obj.(string)); err != nil {
+		klog.Infoln(err)
+		c.nodeWorkqueue.AddRateLimited(obj)
+		return true
+	}
+	c.nodeWorkqueue.Forget(obj)
+	return true
+}
+
+// observeNode records the current readiness of a node in the availability of its contribution, a node that has
+// left the cluster being not ready
+func (c *Controller) observeNode(nodeName string) error {
+	name := strings.TrimSuffix(nodeName, "."+c.domainName)
+	if nodecontribution, err := c.nodecontributionsLister.Get(name); err != nil || nodecontribution.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	ready := false
+	if node, err := c.nodesLister.Get(nodeName); err == nil {
+		ready = multiprovider.GetConditionReadyStatus(node) == string(corev1.ConditionTrue)
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return c.updateAvailability(name, &ready, time.Now())
+}
+
+// trackAvailability refreshes the availability figures of all contributions, which also warns about the nodes
+// that have crossed the not ready threshold
+func (c *Controller) trackAvailability() {
+	nodecontributions, err := c.nodecontributionsLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	now := time.Now()
+	for _, nodecontribution := range nodecontributions {
+		if nodecontribution.GetDeletionTimestamp() != nil {
+			continue
+		}
+		nodeName := fmt.Sprintf("%s.%s", nodecontribution.GetName(), c.domainName)
+		var ready *bool
+		if node, err := c.nodesLister.Get(nodeName); err == nil {
+			isReady := multiprovider.GetConditionReadyStatus(node) == string(corev1.ConditionTrue)
+			ready = &isReady
+		} else if errors.IsNotFound(err) && nodecontribution.Status.Availability != nil {
+			// A node that was tracked and then left the cluster is not ready
+			isReady := false
+			ready = &isReady
+		}
+		if ready == nil && nodecontribution.Status.Availability == nil {
+			continue
+		}
+		if err := c.updateAvailability(nodecontribution.GetName(), ready, now); err != nil {
+			klog.Infoln(err)
+		}
+	}
+}
+
+// updateAvailability records the readiness of the node if known, then refreshes the figures derived from the history.
+// It warns once the node has been not ready for longer than the threshold.
+func (c *Controller) updateAvailability(name string, ready *bool, now time.Time) error {
+	threshold := getNotReadyThreshold()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		nodecontribution, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		nodecontributionCopy := nodecontribution.DeepCopy()
+		if nodecontributionCopy.Status.Availability == nil {
+			nodecontributionCopy.Status.Availability = new(corev1alpha1.AvailabilityStatus)
+		}
+		if ready != nil {
+			recordTransition(nodecontributionCopy.Status.Availability, *ready, now)
+		}
+		summarizeAvailability(nodecontributionCopy.Status.Availability, now)
+		// The status remembers the warning, so that it is recorded once per outage whatever restarts in between
+		warn := exceedsNotReadyThreshold(nodecontributionCopy.Status.Availability, now, threshold)
+		if warn {
+			nodecontributionCopy.Status.Availability.NotReadyNotified = true
+		}
+		if !availabilityChanged(nodecontribution.Status.Availability, nodecontributionCopy.Status.Availability) {
+			return nil
+		}
+		nodecontributionUpdated, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().UpdateStatus(context.TODO(), nodecontributionCopy, metav1.UpdateOptions{})
+		if err == nil && warn {
+			c.recorder.Eventf(nodecontributionUpdated, corev1.EventTypeWarning, reasonNodeNotReady, messageNotReadyTooLong, threshold)
+		}
+		return err
+	})
+}
+
+// availabilityChanged tells whether the availability differs in more than the uptime figures. These shift on every
+// refresh, so they are only worth a status write along with a change of readiness, of the last time the node was
+// seen, or of the warning.
+func availabilityChanged(old, new *corev1alpha1.AvailabilityStatus) bool {
+	if old == nil {
+		return true
+	}
+	oldCopy, newCopy := old.DeepCopy(), new.DeepCopy()
+	for _, availability := range []*corev1alpha1.AvailabilityStatus{oldCopy, newCopy} {
+		availability.Uptime24h, availability.Uptime7d, availability.Uptime30d = "", "", ""
+	}
+	return !equality.Semantic.DeepEqual(oldCopy, newCopy)
+}
+
+// exceedsNotReadyThreshold tells whether the node has been not ready for longer than the threshold without a warning
+func exceedsNotReadyThreshold(availability *corev1alpha1.AvailabilityStatus, now time.Time, threshold time.Duration) bool {
+	return availability.NotReadySince != nil && !availability.NotReadyNotified && now.Sub(availability.NotReadySince.Time) >= threshold
+}
+
+// recordTransition appends the readiness to the history if it differs from the last one
+func recordTransition(availability *corev1alpha1.AvailabilityStatus, ready bool, at time.Time) {
+	if length := len(availability.Transitions); length != 0 && availability.Transitions[length-1].Ready == ready {
+		return
+	}
+	timestamp := metav1.NewTime(at)
+	availability.Transitions = append(availability.Transitions, corev1alpha1.ReadinessTransition{Ready: ready, Timestamp: timestamp})
+	availability.NotReadyNotified = false
+	if ready {
+		availability.NotReadySince = nil
+	} else {
+		availability.NotReadySince = &timestamp
+	}
+}
+
+// summarizeAvailability drops the history that went out of the window and computes the figures over the rest
+func summarizeAvailability(availability *corev1alpha1.AvailabilityStatus, now time.Time) {
+	transitions := availability.Transitions
+	// Keep the last transition before the window as it tells the readiness at the start of the window
+	start := 0
+	for i, transition := range transitions {
+		if !transition.Timestamp.Time.After(now.Add(-availabilityWindow)) {
+			start = i
+		}
+	}
+	transitions = transitions[start:]
+	if len(transitions) > maxTransitions {
+		transitions = transitions[len(transitions)-maxTransitions:]
+	}
+	availability.Transitions = transitions
+	if len(transitions) == 0 {
+		return
+	}
+	if transitions[len(transitions)-1].Ready {
+		lastSeen := metav1.NewTime(now.Truncate(lastSeenResolution))
+		availability.LastSeen = &lastSeen
+	} else if len(transitions) > 1 {
+		lastSeen := transitions[len(transitions)-1].Timestamp
+		availability.LastSeen = &lastSeen
+	}
+	availability.Uptime24h = uptime(transitions, now.Add(-24*time.Hour), now)
+	availability.Uptime7d = uptime(transitions, now.Add(-7*24*time.Hour), now)
+	availability.Uptime30d = uptime(transitions, now.Add(-availabilityWindow), now)
+	availability.FlapCount = 0
+	for i := 1; i < len(transitions); i++ {
+		if !transitions[i].Ready && transitions[i-1].Ready && transitions[i].Timestamp.Time.After(now.Add(-24*time.Hour)) {
+			availability.FlapCount++
+		}
+	}
+}
+
+// uptime returns the percentage of time the node was ready between from and to. The time before the first
+// transition is unknown, so it does not count.
+func uptime(transitions []corev1alpha1.ReadinessTransition, from, to time.Time) string {
+	if transitions[0].Timestamp.Time.After(from) {
+		from = transitions[0].Timestamp.Time
+	}
+	observed := to.Sub(from)
+	if observed <= 0 {
+		if transitions[len(transitions)-1].Ready {
+			return "100.00"
+		}
+		return "0.00"
+	}
+	var ready time.Duration
+	for i, transition := range transitions {
+		if !transition.Ready {
+			continue
+		}
+		begin, end := transition.Timestamp.Time, to
+		if i+1 < len(transitions) {
+			end = transitions[i+1].Timestamp.Time
+		}
+		if begin.Before(from) {
+			begin = from
+		}
+		if end.After(begin) {
+			ready += end.Sub(begin)
+		}
+	}
+	return fmt.Sprintf("%.2f", float64(ready)/float64(observed)*100)
+}
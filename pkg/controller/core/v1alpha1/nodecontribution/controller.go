@@ -17,10 +17,14 @@ limitations under the License.
 package nodecontribution
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -45,6 +49,7 @@ import (
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 )
@@ -55,6 +60,8 @@ const controllerAgentName = "nodecontribution-controller"
 const (
 	backoffLimit = 3
 	dailyLimit   = 24
+	// The finalizer holds the node contribution object until the node leaves the cluster cleanly
+	nodecontributionFinalizer = "edge-net.io/nodecontribution-decommission"
 
 	successSynced  = "Synced"
 	setupProcedure = "Setup"
@@ -67,14 +74,34 @@ const (
 	messageDonePatch            = "Node is patched"
 	messageInvalidHost          = "Host field must be an IP Address"
 	messageSchedulingFailed     = "Scheduling configuration failed"
+	messageLimitationsFailed    = "Node limitations cannot be applied"
+	messageLocationFailed       = "Node location cannot be declared"
 	messageUnready              = "Node is unready"
 	messageSSHFailed            = "SSH handshake failed"
+	messageHostKeyMismatch      = "Host key does not match the fingerprint in the spec"
+	messageHostKeyChanged       = "Host key has changed since the first connection, possible man-in-the-middle attack"
 	messageJoinFailed           = "Node cannot join the cluster"
+	messageBootstrapping        = "Node bootstrap in progress"
+	messageBootstrapFailed      = "Node bootstrap failed"
+	messageStepSucceeded        = "Bootstrap step %s succeeded"
 	messageOwnerReferenceNotSet = "Owner reference is not set"
 	messageSuccessful           = "Node is up and running"
 	messageReconciled           = "Reconciliation is done"
 	messageReconciliation       = "Reconciliation in progress"
 	messageFailed               = "Procedure failed"
+
+	messageFinalizerFailed       = "Node contribution finalizer could not be updated"
+	messageDecommissioning       = "Node decommissioning in progress"
+	messageDraining              = "Waiting for the pods on the node to be evicted"
+	messageEvictionBlocked       = "Waiting for pod disruption budgets to allow evicting the pods on the node"
+	messageResetSkipped          = "Node is unreachable, reset skipped"
+	messageDoneReset             = "Node is reset"
+	messageCordonFailed          = "Node cannot be cordoned"
+	messageDrainFailed           = "Node cannot be drained"
+	messageNodeDeletionFailed    = "Node cannot be removed from the cluster"
+	messageTokenRevocationFailed = "Bootstrap tokens of the node cannot be revoked"
+	messageDNSDeletionFailed     = "DNS record of the node cannot be removed"
+	messageVPNPeerDeletionFailed = "VPN peer of the node cannot be removed"
 )
 
 // Controller is the controller implementation for Node Contribution resources
@@ -96,12 +123,18 @@ type Controller struct {
 	// time, and makes it easy to ensure we are never processing the same item
 	// simultaneously in two different workers.
 	workqueue workqueue.RateLimitingInterface
+	// nodeWorkqueue holds the names of the nodes whose readiness is to be recorded in the availability of
+	// their contribution
+	nodeWorkqueue workqueue.RateLimitingInterface
 	// recorder is an event recorder for recording Event resources to the
 	// Kubernetes API.
 	recorder             record.EventRecorder
-	route53hostedZone    string
 	domainName           string
+	dnsProvider          multiprovider.DNSProvider
 	multiproviderManager *multiprovider.Manager
+	// busy holds the node contributions whose bootstrap or reset runs in the background over SSH
+	busy     map[string]bool
+	busyLock sync.Mutex
 }
 
 // NewController returns a new controller
@@ -110,7 +143,8 @@ func NewController(
 	edgenetclientset clientset.Interface,
 	nodeInformer coreinformers.NodeInformer,
 	nodecontributionInformer informers.NodeContributionInformer,
-	hostedZone, domain string) *Controller {
+	dnsProvider multiprovider.DNSProvider,
+	domain string) *Controller {
 
 	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
 	klog.Info("Creating event broadcaster")
@@ -129,10 +163,12 @@ func NewController(
 		nodecontributionsLister: nodecontributionInformer.Lister(),
 		nodecontributionsSynced: nodecontributionInformer.Informer().HasSynced,
 		workqueue:               workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "NodeContributions"),
+		nodeWorkqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "ContributedNodes"),
 		recorder:                recorder,
-		route53hostedZone:       hostedZone,
 		domainName:              domain,
+		dnsProvider:             dnsProvider,
 		multiproviderManager:    multiproviderManager,
+		busy:                    make(map[string]bool),
 	}
 
 	klog.Infoln("Setting up event handlers")
@@ -142,6 +178,7 @@ func NewController(
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueNodeContribution(new)
 		},
+		DeleteFunc: controller.deleteDNSRecord,
 	})
 
 	// Below sets incentives for those who contribute nodes to the cluster by indicating tenant.
@@ -200,6 +237,8 @@ func NewController(
 					return
 				}
 			}
+			controller.enqueueJoiningNodeContribution(nodeObj)
+			controller.enqueueNode(nodeObj)
 			if string(corev1.ConditionTrue) == multiprovider.GetConditionReadyStatus(nodeObj) {
 				setIncentives("incentive", nodeObj.GetName(), nodeObj.GetOwnerReferences(), nodeObj.Status.Capacity.Cpu(), nodeObj.Status.Capacity.Memory())
 			}
@@ -209,6 +248,9 @@ func NewController(
 			newObj := new.(*corev1.Node)
 			oldReady := multiprovider.GetConditionReadyStatus(oldObj)
 			newReady := multiprovider.GetConditionReadyStatus(newObj)
+			if (oldReady == string(corev1.ConditionTrue)) != (newReady == string(corev1.ConditionTrue)) {
+				controller.enqueueNode(newObj)
+			}
 			if (oldReady == string(corev1.ConditionFalse) && newReady == string(corev1.ConditionTrue)) ||
 				(oldReady == string(corev1.ConditionUnknown) && newReady == string(corev1.ConditionTrue)) {
 				setIncentives("incentive", newObj.GetName(), newObj.GetOwnerReferences(), newObj.Status.Capacity.Cpu(), newObj.Status.Capacity.Memory())
@@ -219,7 +261,17 @@ func NewController(
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
-			nodeObj := obj.(*corev1.Node)
+			nodeObj, ok := obj.(*corev1.Node)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if nodeObj, ok = tombstone.Obj.(*corev1.Node); !ok {
+					return
+				}
+			}
+			controller.enqueueNode(nodeObj)
 			ready := multiprovider.GetConditionReadyStatus(nodeObj)
 			if ready == string(corev1.ConditionTrue) {
 				setIncentives("disincentive", nodeObj.GetName(), nodeObj.GetOwnerReferences(), nil, nil)
@@ -238,6 +290,7 @@ func NewController(
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
+	defer c.nodeWorkqueue.ShutDown()
 
 	klog.Infoln("Starting Node Contribution controller")
 
@@ -252,6 +305,8 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	for i := 0; i < threadiness; i++ {
 		go wait.Until(c.runWorker, time.Second, stopCh)
 	}
+	go wait.Until(c.runNodeWorker, time.Second, stopCh)
+	go wait.Until(c.trackAvailability, availabilityInterval, stopCh)
 
 	klog.Infoln("Started workers")
 	<-stopCh
@@ -355,6 +410,34 @@ func (c *Controller) enqueueNodeContributionAfter(obj interface{}, after time.Du
 	c.workqueue.AddAfter(key, after)
 }
 
+// enqueueJoiningNodeContribution enqueues the contribution of a node that has just registered
+// if its bootstrap is in progress
+func (c *Controller) enqueueJoiningNodeContribution(node *corev1.Node) {
+	nodecontribution, err := c.nodecontributionsLister.Get(strings.TrimSuffix(node.GetName(), "."+c.domainName))
+	if err == nil && nodecontribution.Status.State == corev1alpha1.StatusBootstrapping {
+		c.enqueueNodeContribution(nodecontribution)
+	}
+}
+
+// deleteDNSRecord removes the hostname of a deleted NodeContribution from the DNS provider
+func (c *Controller) deleteDNSRecord(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	nodecontribution, ok := obj.(*corev1alpha1.NodeContribution)
+	if !ok {
+		return
+	}
+	recordType := multiprovider.GetRecordType(nodecontribution.Spec.Host)
+	if recordType == "" {
+		return
+	}
+	nodeName := fmt.Sprintf("%s.%s", nodecontribution.GetName(), c.domainName)
+	if err := c.dnsProvider.DeleteRecord(nodeName, nodecontribution.Spec.Host, recordType); err != nil {
+		klog.Infof("Couldn't delete the DNS record of %s: %s", nodeName, err)
+	}
+}
+
 // handleObject will take any resource implementing metav1.Object and attempt
 // to find the NodeContribution resource that 'owns' it. It does this by looking at the
 // objects metadata.ownerReferences field for an appropriate OwnerReference.
@@ -394,6 +477,33 @@ func (c *Controller) handleObject(obj interface{}) {
 }
 
 func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.NodeContribution) {
+	nodeName := fmt.Sprintf("%s.%s", nodecontributionCopy.GetName(), c.domainName)
+	if nodecontributionCopy.GetDeletionTimestamp() != nil {
+		if hasFinalizer(nodecontributionCopy.GetFinalizers(), nodecontributionFinalizer) {
+			c.decommission(nodecontributionCopy, nodeName)
+		}
+		return
+	}
+	if !hasFinalizer(nodecontributionCopy.GetFinalizers(), nodecontributionFinalizer) {
+		// Deleting the node contribution now waits for the controller to decommission the node
+		nodecontributionCopy.SetFinalizers(append(nodecontributionCopy.GetFinalizers(), nodecontributionFinalizer))
+		nodecontributionUpdated, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().Update(context.TODO(), nodecontributionCopy, metav1.UpdateOptions{})
+		if err != nil {
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageFinalizerFailed)
+			klog.Infoln(err)
+			return
+		}
+		nodecontributionCopy = nodecontributionUpdated
+	}
+	if nodecontributionCopy.Status.State == corev1alpha1.StatusFailed && isRecovering(nodecontributionCopy) {
+		// The recovery has its own backoff, which does not give up
+		if wait := time.Until(nodecontributionCopy.Status.Recovery.NextAttemptTimestamp.Time); wait > 0 {
+			c.enqueueNodeContributionAfter(nodecontributionCopy, wait)
+			return
+		}
+		c.recover(nodecontributionCopy)
+		return
+	}
 	if nodecontributionCopy.Status.UpdateTimestamp != nil && nodecontributionCopy.Status.UpdateTimestamp.Add(24*time.Hour).After(time.Now()) {
 		if exceedsBackoffLimit := nodecontributionCopy.Status.Failed >= backoffLimit; exceedsBackoffLimit {
 			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageFailed)
@@ -409,10 +519,14 @@ func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.
 		return
 	}
 
-	nodeName := fmt.Sprintf("%s.%s", nodecontributionCopy.GetName(), c.domainName)
 	switch nodecontributionCopy.Status.State {
 	case corev1alpha1.StatusReady:
-		if contributedNode, isJoined, isReady, _ := c.getNodeInfo(nodecontributionCopy.GetCreationTimestamp(), nodeName); !isJoined {
+		if contributedNode, isJoined, isReady, _ := c.getNodeInfo(getJoinTimestamp(nodecontributionCopy), nodeName); !isJoined {
+			if nodecontributionCopy.GetAutoRecovery() {
+				nodecontributionCopy.Status.Recovery = nil
+				c.recover(nodecontributionCopy)
+				return
+			}
 			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusReconciliation, messageReconciliation)
 			nodecontributionCopy.Status.State = corev1alpha1.StatusReconciliation
 			nodecontributionCopy.Status.Message = messageReconciliation
@@ -425,12 +539,20 @@ func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.
 			c.updateStatus(context.TODO(), nodecontributionCopy)
 			return
 		} else {
-			if contributedNode.Spec.Unschedulable != !nodecontributionCopy.Spec.Enabled {
-				if err := c.multiproviderManager.SetNodeScheduling(nodeName, !nodecontributionCopy.Spec.Enabled); err != nil {
+			if unschedulable := c.isUnschedulable(nodecontributionCopy); contributedNode.Spec.Unschedulable != unschedulable {
+				if err := c.multiproviderManager.SetNodeScheduling(nodeName, unschedulable); err != nil {
 					nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
 					nodecontributionCopy.Status.Message = messageReconciliation
 				}
 			}
+			if !multiprovider.LimitationsApplied(contributedNode, nodecontributionCopy.Spec.Limitations) {
+				nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
+				nodecontributionCopy.Status.Message = messageReconciliation
+			}
+			if !multiprovider.LocationApplied(contributedNode, nodecontributionCopy.Spec.Location) {
+				nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
+				nodecontributionCopy.Status.Message = messageReconciliation
+			}
 			if ownerRef := metav1.GetControllerOf(contributedNode); (ownerRef == nil) || (ownerRef != nil && ownerRef.Kind != "NodeContribution") {
 				nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
 				nodecontributionCopy.Status.Message = messageReconciliation
@@ -460,8 +582,55 @@ func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.
 			}
 			c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, corev1alpha1.StatusReconciliation, messageReconciled)
 		}
+	case corev1alpha1.StatusBootstrapping:
+		if c.isBusy(nodecontributionCopy.GetName()) {
+			// The bootstrap running in the background reports its own progress
+			return
+		}
+		bootstrapper, err := c.multiproviderManager.NewNodeBootstrapper(nodecontributionCopy.GetBootstrap())
+		if err != nil || nodecontributionCopy.Status.Bootstrap == nil {
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageBootstrapFailed)
+			nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
+			nodecontributionCopy.Status.Message = messageBootstrapFailed
+			c.updateStatus(context.TODO(), nodecontributionCopy)
+			return
+		}
+		step := getRunningStep(nodecontributionCopy.Status.Bootstrap)
+		if _, isJoined, _, _ := c.getNodeInfo(getJoinTimestamp(nodecontributionCopy), nodeName); isJoined {
+			if step != nil {
+				now := metav1.Now()
+				step.State = corev1alpha1.StepSucceeded
+				step.CompletionTimestamp = &now
+			}
+			if err := bootstrapper.Cleanup(nodeName); err != nil {
+				klog.Infoln(err)
+			}
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneKubeadm)
+			nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
+			nodecontributionCopy.Status.Message = messageDoneKubeadm
+			c.updateStatus(context.TODO(), nodecontributionCopy)
+			return
+		}
+		// A bootstrap interrupted by a restart leaves its step running until the step exceeds its timeout
+		if step == nil || step.StartTimestamp == nil || step.StartTimestamp.Add(time.Duration(step.TimeoutSeconds)*time.Second).Before(time.Now()) {
+			if step != nil {
+				now := metav1.Now()
+				step.State = corev1alpha1.StepFailed
+				step.Message = "Timed out"
+				step.CompletionTimestamp = &now
+			}
+			if err := bootstrapper.Cleanup(nodeName); err != nil {
+				klog.Infoln(err)
+			}
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageJoinFailed)
+			nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
+			nodecontributionCopy.Status.Message = messageJoinFailed
+			c.updateStatus(context.TODO(), nodecontributionCopy)
+			return
+		}
+		c.enqueueNodeContributionAfter(nodecontributionCopy, 1*time.Minute)
 	case corev1alpha1.StatusAccessed:
-		if _, isJoined, isReady, hasTimedOut := c.getNodeInfo(nodecontributionCopy.GetCreationTimestamp(), nodeName); !isJoined {
+		if _, isJoined, isReady, hasTimedOut := c.getNodeInfo(getJoinTimestamp(nodecontributionCopy), nodeName); !isJoined {
 			if hasTimedOut {
 				c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageJoinFailed)
 				nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
@@ -485,32 +654,8 @@ func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.
 				c.enqueueNodeContributionAfter(nodecontributionCopy, 10*time.Minute)
 			} else {
 				klog.Infof("DNS configuration started: %s", nodeName)
-				// Use AWS Route53 for registration
-				awsIDPath := "/edgenet/aws/id"
-				if flag.Lookup("aws-id-path") != nil {
-					awsIDPath = flag.Lookup("aws-id-path").Value.(flag.Getter).Get().(string)
-				}
-				awsSecretPath := "/edgenet/aws/secret"
-				if flag.Lookup("aws-secret-path") != nil {
-					awsSecretPath = flag.Lookup("aws-secret-path").Value.(flag.Getter).Get().(string)
-				}
-				awsID, err := os.ReadFile(awsIDPath)
-				if err != nil {
-					c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageDNSFailed)
-					nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
-					nodecontributionCopy.Status.Message = messageDNSFailed
-					c.updateStatus(context.TODO(), nodecontributionCopy)
-					return
-				}
-				awsSecret, err := os.ReadFile(awsSecretPath)
-				if err != nil {
-					c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageDNSFailed)
-					nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
-					nodecontributionCopy.Status.Message = messageDNSFailed
-					c.updateStatus(context.TODO(), nodecontributionCopy)
-					return
-				}
-				if updated, _ := multiprovider.SetHostnameRoute53(awsID, awsSecret, c.route53hostedZone, nodeName, nodecontributionCopy.Spec.Host, recordType); !updated {
+				if err := c.dnsProvider.SetRecord(nodeName, nodecontributionCopy.Spec.Host, recordType); err != nil {
+					klog.Infoln(err)
 					c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageDNSFailed)
 					nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
 					nodecontributionCopy.Status.Message = messageDNSFailed
@@ -520,80 +665,184 @@ func (c *Controller) processNodeContribution(nodecontributionCopy *corev1alpha1.
 				c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, corev1alpha1.StatusReady, messageSuccessful)
 				nodecontributionCopy.Status.State = corev1alpha1.StatusReady
 				nodecontributionCopy.Status.Message = messageSuccessful
+				nodecontributionCopy.Status.Recovery = nil
 				c.updateStatus(context.TODO(), nodecontributionCopy)
 			}
 		}
 	default:
-		if _, isJoined, isReady, _ := c.getNodeInfo(nodecontributionCopy.GetCreationTimestamp(), nodeName); isJoined && isReady {
+		if c.isBusy(nodecontributionCopy.GetName()) {
+			return
+		}
+		if _, isJoined, isReady, _ := c.getNodeInfo(getJoinTimestamp(nodecontributionCopy), nodeName); isJoined && isReady {
 			c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneKubeadm)
 			nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
 			nodecontributionCopy.Status.Message = messageDoneKubeadm
 			c.updateStatus(context.TODO(), nodecontributionCopy)
 			return
 		}
-		// TODO: Include HostKeyCallback
-		signer, _, ok := getSSHConfigurations()
-		if !ok {
-			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageSSHFailed)
+		bootstrapper, err := c.multiproviderManager.NewNodeBootstrapper(nodecontributionCopy.GetBootstrap())
+		if err != nil {
+			klog.Infoln(err)
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageBootstrapFailed)
 			nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
-			nodecontributionCopy.Status.Message = messageSSHFailed
+			nodecontributionCopy.Status.Message = messageBootstrapFailed
 			c.updateStatus(context.TODO(), nodecontributionCopy)
 			return
 		}
-		config := &ssh.ClientConfig{
-			User:            nodecontributionCopy.Spec.User,
-			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
-			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			Timeout:         15 * time.Second,
-		}
-		addr := fmt.Sprintf("%s:%d", nodecontributionCopy.Spec.Host, nodecontributionCopy.Spec.Port)
-		klog.Infof("Establish SSH connection: %s", nodeName)
-		conn := new(ssh.Client)
-		isSuccessful := false
-		isConnected := make(chan bool, 1)
-		go c.sshDialRoutine(conn, config, addr, isConnected)
-		select {
-		case isSuccessful = <-isConnected:
-			break
-		case <-time.After(15 * time.Second):
-			break
-		}
-		if !isSuccessful {
-			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageSSHFailed)
+		steps, err := bootstrapper.Prepare(nodeName)
+		if err != nil {
+			klog.Infoln(err)
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageBootstrapFailed)
 			nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
-			nodecontributionCopy.Status.Message = messageSSHFailed
+			nodecontributionCopy.Status.Message = messageBootstrapFailed
 			c.updateStatus(context.TODO(), nodecontributionCopy)
 			return
 		}
-		c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneSSH)
-		defer conn.Close()
-		isCompleted := make(chan bool, 1)
-		isSuccessful = false
-		go c.join(conn, nodeName, isCompleted)
-		select {
-		case isSuccessful = <-isCompleted:
-			break
-		case <-time.After(5 * time.Minute):
+		// The steps take minutes, which would hold the worker and the other contributions in the queue
+		c.setBusy(nodecontributionCopy.GetName(), true)
+		go c.bootstrap(nodecontributionCopy, nodeName, bootstrapper, steps)
+	}
+}
+
+// dialNode establishes the SSH connection to the contributed host after verifying its host key.
+// It reports the failure in the status and returns nil if the connection cannot be established.
+func (c *Controller) dialNode(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string) *ssh.Client {
+	conn, hostKeyFingerprint, err := c.connect(nodecontributionCopy, nodeName)
+	if err != nil {
+		klog.Infoln(err)
+		message := messageSSHFailed
+		if multiprovider.IsHostKeyMismatch(err) {
+			message = messageHostKeyMismatch
+		} else if multiprovider.IsHostKeyChanged(err) {
+			message = messageHostKeyChanged
+		}
+		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, message)
+		nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
+		nodecontributionCopy.Status.Message = message
+		c.updateStatus(context.TODO(), nodecontributionCopy)
+		return nil
+	}
+	c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneSSH)
+	nodecontributionCopy.Status.HostKeyFingerprint = hostKeyFingerprint
+	return conn
+}
+
+// connect dials the contributed host and returns the connection along with the fingerprint of its host key
+func (c *Controller) connect(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string) (*ssh.Client, string, error) {
+	signer, ok := getSSHConfigurations()
+	if !ok {
+		return nil, "", fmt.Errorf("SSH key to access %s is not available", nodeName)
+	}
+	// Keep the fingerprint of the presented key to report it in the status
+	hostKeyFingerprint := make(chan string, 1)
+	verifyHostKey := c.multiproviderManager.HostKeyCallback(nodecontributionCopy.Spec.HostKeyFingerprint)
+	config := &ssh.ClientConfig{
+		User: nodecontributionCopy.Spec.User,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if err := verifyHostKey(hostname, remote, key); err != nil {
+				return err
+			}
+			// The callback may run more than once, so the fingerprint of a later handshake must not block it
+			select {
+			case hostKeyFingerprint <- ssh.FingerprintSHA256(key):
+			default:
+			}
+			return nil
+		},
+		Timeout: 15 * time.Second,
+	}
+	addr := fmt.Sprintf("%s:%d", nodecontributionCopy.Spec.Host, nodecontributionCopy.Spec.Port)
+	klog.Infof("Establish SSH connection: %s", nodeName)
+	conn := new(ssh.Client)
+	dialErr := fmt.Errorf("SSH connection to %s timed out", addr)
+	isConnected := make(chan error, 1)
+	go c.sshDialRoutine(conn, config, addr, isConnected)
+	select {
+	case dialErr = <-isConnected:
+		break
+	case <-time.After(15 * time.Second):
+		break
+	}
+	if dialErr != nil {
+		return nil, "", dialErr
+	}
+	return conn, <-hostKeyFingerprint, nil
+}
+
+// bootstrap connects to the node and runs the steps one after the other, reporting their progress in the status.
+// It runs in the background and returns once all steps succeed, a step fails, or a step waits for the node to join
+// by itself, which the bootstrapping state then follows up.
+func (c *Controller) bootstrap(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string, bootstrapper multiprovider.NodeBootstrapper, steps []multiprovider.BootstrapCommand) {
+	defer c.setBusy(nodecontributionCopy.GetName(), false)
+	nodecontributionCopy.Status.Bootstrap = &corev1alpha1.BootstrapStatus{Strategy: nodecontributionCopy.GetBootstrap()}
+	for _, step := range steps {
+		nodecontributionCopy.Status.Bootstrap.Steps = append(nodecontributionCopy.Status.Bootstrap.Steps, corev1alpha1.BootstrapStep{
+			Name:           step.Name,
+			State:          corev1alpha1.StepPending,
+			TimeoutSeconds: int64(step.Timeout.Seconds()),
+		})
+	}
+	nodecontributionCopy.Status.State = corev1alpha1.StatusBootstrapping
+	nodecontributionCopy.Status.Message = messageBootstrapping
+	c.updateStatus(context.TODO(), nodecontributionCopy)
+	var conn *ssh.Client
+	for _, step := range steps {
+		if step.Command != "" {
+			if conn = c.dialNode(nodecontributionCopy, nodeName); conn == nil {
+				if err := bootstrapper.Cleanup(nodeName); err != nil {
+					klog.Infoln(err)
+				}
+				return
+			}
+			defer conn.Close()
 			break
 		}
-		if !isSuccessful {
-			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageJoinFailed)
+	}
+	for i, step := range steps {
+		stepStatus := &nodecontributionCopy.Status.Bootstrap.Steps[i]
+		start := metav1.Now()
+		stepStatus.State = corev1alpha1.StepRunning
+		stepStatus.StartTimestamp = &start
+		c.updateStatus(context.TODO(), nodecontributionCopy)
+		if step.Command == "" {
+			// The bootstrapping state follows up the step until the node registers or the step times out
+			c.enqueueNodeContributionAfter(nodecontributionCopy, 1*time.Minute)
+			return
+		}
+		klog.Infof("Run bootstrap step %s: %s", step.Name, nodeName)
+		output, err := runCommand(conn, step.Command, step.Stdin, step.Timeout)
+		completion := metav1.Now()
+		stepStatus.CompletionTimestamp = &completion
+		if err != nil {
+			klog.Infoln(err)
+			stepStatus.State = corev1alpha1.StepFailed
+			stepStatus.Message = fmt.Sprintf("%s: %s", err, tail(output, 512))
+			if err := bootstrapper.Cleanup(nodeName); err != nil {
+				klog.Infoln(err)
+			}
+			c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageBootstrapFailed)
 			nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
-			nodecontributionCopy.Status.Message = messageJoinFailed
+			nodecontributionCopy.Status.Message = messageBootstrapFailed
 			c.updateStatus(context.TODO(), nodecontributionCopy)
 			return
 		}
-		c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneKubeadm)
-		nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
-		nodecontributionCopy.Status.Message = messageDoneKubeadm
-		c.updateStatus(context.TODO(), nodecontributionCopy)
+		stepStatus.State = corev1alpha1.StepSucceeded
+		c.recorder.Eventf(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageStepSucceeded, step.Name)
+	}
+	if err := bootstrapper.Cleanup(nodeName); err != nil {
+		klog.Infoln(err)
 	}
+	c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneKubeadm)
+	nodecontributionCopy.Status.State = corev1alpha1.StatusAccessed
+	nodecontributionCopy.Status.Message = messageDoneKubeadm
+	c.updateStatus(context.TODO(), nodecontributionCopy)
 }
 
 func (c *Controller) syncResources(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string) bool {
 	klog.Infof("Patch node and set owner references: %s", nodeName)
-	// Set the node as schedulable or unschedulable according to the node contribution
-	if err := c.multiproviderManager.SetNodeScheduling(nodeName, !nodecontributionCopy.Spec.Enabled); err != nil {
+	// Set the node as schedulable or unschedulable according to the node contribution and its tenant
+	if err := c.multiproviderManager.SetNodeScheduling(nodeName, c.isUnschedulable(nodecontributionCopy)); err != nil {
 		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageSchedulingFailed)
 		nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
 		nodecontributionCopy.Status.Message = messageSchedulingFailed
@@ -602,6 +851,26 @@ func (c *Controller) syncResources(nodecontributionCopy *corev1alpha1.NodeContri
 	}
 	c.recorder.Event(nodecontributionCopy, corev1.EventTypeNormal, setupProcedure, messageDoneSchedulingPatch)
 
+	// Keep away the workloads of the tenants and namespaces that the contributor does not share the node with
+	if err := c.multiproviderManager.SetNodeLimitations(nodeName, nodecontributionCopy.Spec.Limitations); err != nil {
+		klog.Infoln(err)
+		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageLimitationsFailed)
+		nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
+		nodecontributionCopy.Status.Message = messageLimitationsFailed
+		c.updateStatus(context.TODO(), nodecontributionCopy)
+		return false
+	}
+
+	// Let the node labeler know where the node is when the contributor declares it
+	if err := c.multiproviderManager.SetNodeLocation(nodeName, nodecontributionCopy.Spec.Location); err != nil {
+		klog.Infoln(err)
+		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageLocationFailed)
+		nodecontributionCopy.Status.State = corev1alpha1.StatusFailed
+		nodecontributionCopy.Status.Message = messageLocationFailed
+		c.updateStatus(context.TODO(), nodecontributionCopy)
+		return false
+	}
+
 	ownerReferences := c.formOwnerReferences(nodecontributionCopy)
 	if err := c.multiproviderManager.SetOwnerReferences(nodeName, ownerReferences); err != nil {
 		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusFailed, messageOwnerReferenceNotSet)
@@ -611,7 +880,7 @@ func (c *Controller) syncResources(nodecontributionCopy *corev1alpha1.NodeContri
 		return false
 	}
 
-	if vpnPeer, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), nodecontributionCopy.GetName(), metav1.GetOptions{}); err == nil {
+	if vpnPeer, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), nodeName, metav1.GetOptions{}); err == nil {
 		vpnPeerCopy := vpnPeer.DeepCopy()
 		vpnPeerCopy.SetOwnerReferences(ownerReferences)
 		if _, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Update(context.TODO(), vpnPeerCopy, metav1.UpdateOptions{}); err != nil {
@@ -626,6 +895,20 @@ func (c *Controller) syncResources(nodecontributionCopy *corev1alpha1.NodeContri
 	return true
 }
 
+// isUnschedulable tells whether the node is to be cordoned, which is the case when
+// either the contribution or the contributing tenant is disabled
+func (c *Controller) isUnschedulable(nodecontributionCopy *corev1alpha1.NodeContribution) bool {
+	if !nodecontributionCopy.Spec.Enabled {
+		return true
+	}
+	if nodecontributionCopy.Spec.Tenant != nil {
+		if contributorTenant, err := c.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), *nodecontributionCopy.Spec.Tenant, metav1.GetOptions{}); err == nil {
+			return !contributorTenant.Spec.Enabled
+		}
+	}
+	return false
+}
+
 func (c *Controller) formOwnerReferences(nodecontributionCopy *corev1alpha1.NodeContribution) []metav1.OwnerReference {
 	ownerReference := nodecontributionCopy.MakeOwnerReference()
 	takeControl := true
@@ -639,36 +922,36 @@ func (c *Controller) formOwnerReferences(nodecontributionCopy *corev1alpha1.Node
 	}
 	return ownerReferences
 }
-func (c *Controller) getNodeInfo(contributionCreationTimestamp metav1.Time, nodeName string) (contributedNode *corev1.Node, isJoined bool, isReady bool, hasTimedOut bool) {
+func (c *Controller) getNodeInfo(joinTimestamp metav1.Time, nodeName string) (contributedNode *corev1.Node, isJoined bool, isReady bool, hasTimedOut bool) {
 	var err error
 	if contributedNode, err = c.nodesLister.Get(nodeName); err == nil {
 		if multiprovider.GetConditionReadyStatus(contributedNode) == string(corev1.ConditionTrue) {
 			isJoined, isReady = true, true
 		} else {
 			isJoined = true
-			if contributionCreationTimestamp.Add(10 * time.Minute).Before(time.Now()) {
+			if joinTimestamp.Add(10 * time.Minute).Before(time.Now()) {
 				hasTimedOut = true
 			}
 		}
 	} else {
-		if contributionCreationTimestamp.Add(5 * time.Minute).Before(time.Now()) {
+		if joinTimestamp.Add(5 * time.Minute).Before(time.Now()) {
 			hasTimedOut = true
 		}
 	}
 	return contributedNode, isJoined, isReady, hasTimedOut
 }
 
-func (c *Controller) sshDialRoutine(conn *ssh.Client, config *ssh.ClientConfig, addr string, done chan<- bool) {
+func (c *Controller) sshDialRoutine(conn *ssh.Client, config *ssh.ClientConfig, addr string, done chan<- error) {
 	clientConn, err := ssh.Dial("tcp", addr, config)
 	if err != nil {
-		done <- false
+		done <- err
 		return
 	}
 	*conn = *clientConn
-	done <- true
+	done <- nil
 }
 
-func getSSHConfigurations() (ssh.Signer, ssh.HostKeyCallback, bool) {
+func getSSHConfigurations() (ssh.Signer, bool) {
 	// Set the client config according to the node contribution,
 	// with the maximum time of 15 seconds to establist the connection.
 	// Get the SSH Private Key of the control plane node
@@ -679,98 +962,271 @@ func getSSHConfigurations() (ssh.Signer, ssh.HostKeyCallback, bool) {
 	key, err := os.ReadFile(fmt.Sprintf("%s/id_rsa", sshPath))
 	if err != nil {
 		klog.Infoln(err)
-		return nil, nil, false
+		return nil, false
 	}
 	signer, err := ssh.ParsePrivateKey(key)
 	if err != nil {
 		klog.Infoln(err)
-		return nil, nil, false
+		return nil, false
 	}
-	/*hostkeyCallback, err := knownhosts.New(fmt.Sprintf("%s/known_hosts", sshPath))
-	if err != nil {
-		klog.Infoln(err)
-		return nil, nil, false
-	}*/
-	return signer, nil, true
+	return signer, true
 }
 
-// join creates a token and runs kubeadm join command
-func (c *Controller) join(conn *ssh.Client, nodeName string, done chan<- bool) {
-	commands := []string{
-		"sudo su",
-		"kubeadm reset -f",
-		c.multiproviderManager.CreateJoinToken("30m", nodeName),
-	}
+// runCommand runs the command with root privileges in a new session, feeding it the standard input, and returns its
+// combined output
+func runCommand(conn *ssh.Client, command, stdin string, timeout time.Duration) (string, error) {
 	sess, err := startSession(conn)
 	if err != nil {
-		klog.Info(err)
-		done <- false
-		return
+		return "", err
 	}
 	defer sess.Close()
-	// StdinPipe for commands
-	stdin, err := sess.StdinPipe()
+	sess.Stdin = strings.NewReader(stdin)
+	var output bytes.Buffer
+	sess.Stdout = &output
+	sess.Stderr = &output
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Run(fmt.Sprintf("sudo sh -c '%s'", strings.ReplaceAll(command, "'", `'\''`)))
+	}()
+	select {
+	case err := <-done:
+		return output.String(), err
+	case <-time.After(timeout):
+		sess.Close()
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// tail returns the end of the output, which is where the cause of a failure usually shows
+func tail(output string, length int) string {
+	output = strings.TrimSpace(output)
+	if len(output) > length {
+		return output[len(output)-length:]
+	}
+	return output
+}
+
+// isBusy tells whether the bootstrap or the reset of the node contribution is running in the background
+func (c *Controller) isBusy(name string) bool {
+	c.busyLock.Lock()
+	defer c.busyLock.Unlock()
+	return c.busy[name]
+}
+
+// setBusy marks the start or the end of the bootstrap or the reset of the node contribution
+func (c *Controller) setBusy(name string, running bool) {
+	c.busyLock.Lock()
+	defer c.busyLock.Unlock()
+	if running {
+		c.busy[name] = true
+	} else {
+		delete(c.busy, name)
+	}
+}
+
+// getRunningStep returns the step in progress if any
+func getRunningStep(bootstrap *corev1alpha1.BootstrapStatus) *corev1alpha1.BootstrapStep {
+	for i := range bootstrap.Steps {
+		if bootstrap.Steps[i].State == corev1alpha1.StepRunning {
+			return &bootstrap.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Start a new session in the connection
+func startSession(conn *ssh.Client) (*ssh.Session, error) {
+	sess, err := conn.NewSession()
 	if err != nil {
 		klog.Info(err)
-		done <- false
+		return nil, err
+	}
+	return sess, nil
+}
+
+// decommission walks the node out of the cluster and removes the finalizer once everything the contribution set up is gone.
+// Each step is safe to repeat, so a failed step is retried from scratch on the next sync.
+func (c *Controller) decommission(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string) {
+	if c.isBusy(nodecontributionCopy.GetName()) {
+		// The status update at the end of the bootstrap or the reset brings the node contribution back
 		return
 	}
-	//sess.Stdout = os.Stdout
-	sess.Stderr = os.Stderr
-	sess, err = startShell(sess)
-	if err != nil {
-		klog.Info(err)
-		done <- false
+	if nodecontributionCopy.Status.State != corev1alpha1.StatusDecommissioning {
+		c.reportDecommission(nodecontributionCopy, corev1.EventTypeNormal, messageDecommissioning)
+	}
+	node, err := c.kubeclientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		c.failDecommission(nodecontributionCopy, messageNodeDeletionFailed)
 		return
 	}
-	// Run commands sequentially
-	for _, cmd := range commands {
-		_, err = fmt.Fprintf(stdin, "%s\n", cmd)
+	if err == nil {
+		if !node.Spec.Unschedulable {
+			if err := c.multiproviderManager.SetNodeScheduling(nodeName, true); err != nil {
+				klog.Infoln(err)
+				c.failDecommission(nodecontributionCopy, messageCordonFailed)
+				return
+			}
+		}
+		remaining, blocked, err := c.multiproviderManager.DrainNode(nodeName)
 		if err != nil {
-			klog.Info(err)
-			done <- false
+			klog.Infoln(err)
+			c.failDecommission(nodecontributionCopy, messageDrainFailed)
+			return
+		}
+		if remaining != 0 {
+			message := messageDraining
+			if blocked {
+				message = messageEvictionBlocked
+			}
+			if nodecontributionCopy.Status.Message != message {
+				c.reportDecommission(nodecontributionCopy, corev1.EventTypeNormal, message)
+			}
+			c.enqueueNodeContributionAfter(nodecontributionCopy, 10*time.Second)
+			return
+		}
+		// The reset over SSH takes minutes, so it runs in the background and reports its end in the status
+		if nodecontributionCopy.Status.ResetTimestamp == nil {
+			c.setBusy(nodecontributionCopy.GetName(), true)
+			go c.reset(nodecontributionCopy, nodeName)
+			return
+		}
+		if err := c.kubeclientset.CoreV1().Nodes().Delete(context.TODO(), nodeName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Infoln(err)
+			c.failDecommission(nodecontributionCopy, messageNodeDeletionFailed)
 			return
 		}
 	}
-	stdin.Close()
-	// Wait for session to finish
-	err = sess.Wait()
-	if err != nil {
-		klog.Info(err)
-		done <- false
+	if bootstrapper, err := c.multiproviderManager.NewNodeBootstrapper(nodecontributionCopy.GetBootstrap()); err == nil {
+		if err := bootstrapper.Cleanup(nodeName); err != nil {
+			klog.Infoln(err)
+		}
+	}
+	if err := c.multiproviderManager.RevokeBootstrapTokens(nodeName); err != nil {
+		klog.Infoln(err)
+		c.failDecommission(nodecontributionCopy, messageTokenRevocationFailed)
+		return
+	}
+	if recordType := multiprovider.GetRecordType(nodecontributionCopy.Spec.Host); recordType != "" {
+		if err := c.dnsProvider.DeleteRecord(nodeName, nodecontributionCopy.Spec.Host, recordType); err != nil {
+			klog.Infoln(err)
+			c.failDecommission(nodecontributionCopy, messageDNSDeletionFailed)
+			return
+		}
+	}
+	// VPN peers are named after the node
+	if err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Delete(context.TODO(), nodeName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Infoln(err)
+		c.failDecommission(nodecontributionCopy, messageVPNPeerDeletionFailed)
 		return
 	}
-	done <- true
+	// A host contributed again later on may come with a new key
+	if err := c.multiproviderManager.DeleteKnownHostKeys(fmt.Sprintf("%s:%d", nodecontributionCopy.Spec.Host, nodecontributionCopy.Spec.Port)); err != nil {
+		klog.Infoln(err)
+	}
+
+	nodecontributionCopy.SetFinalizers(removeFinalizer(nodecontributionCopy.GetFinalizers(), nodecontributionFinalizer))
+	if _, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().Update(context.TODO(), nodecontributionCopy, metav1.UpdateOptions{}); err != nil {
+		klog.Infoln(err)
+		c.failDecommission(nodecontributionCopy, messageFinalizerFailed)
+	}
 }
 
-// Start a new session in the connection
-func startSession(conn *ssh.Client) (*ssh.Session, error) {
-	sess, err := conn.NewSession()
+// reset undoes the join on the host if it is reachable. An unreachable host does not hold the decommission back.
+// It runs in the background and reports in the status that the decommission can go on.
+func (c *Controller) reset(nodecontributionCopy *corev1alpha1.NodeContribution, nodeName string) {
+	defer c.setBusy(nodecontributionCopy.GetName(), false)
+	bootstrapper, err := c.multiproviderManager.NewNodeBootstrapper(nodecontributionCopy.GetBootstrap())
 	if err != nil {
-		klog.Info(err)
-		return nil, err
+		klog.Infoln(err)
+		c.finishReset(nodecontributionCopy, corev1.EventTypeWarning, messageResetSkipped)
+		return
 	}
-	return sess, nil
+	conn, _, err := c.connect(nodecontributionCopy, nodeName)
+	if err != nil {
+		klog.Infoln(err)
+		c.finishReset(nodecontributionCopy, corev1.EventTypeWarning, messageResetSkipped)
+		return
+	}
+	defer conn.Close()
+	step := bootstrapper.Reset()
+	if output, err := runCommand(conn, step.Command, step.Stdin, step.Timeout); err != nil {
+		klog.Infof("%s: %s", err, tail(output, 512))
+		c.finishReset(nodecontributionCopy, corev1.EventTypeWarning, messageResetSkipped)
+		return
+	}
+	c.finishReset(nodecontributionCopy, corev1.EventTypeNormal, messageDoneReset)
 }
 
-// Start a shell in the session
-func startShell(sess *ssh.Session) (*ssh.Session, error) {
-	// Start remote shell
-	if err := sess.Shell(); err != nil {
-		klog.Info(err)
-		return nil, err
+// finishReset records the end of the reset, which the decommission checks so as not to run it again whatever
+// message a later step leaves in the status
+func (c *Controller) finishReset(nodecontributionCopy *corev1alpha1.NodeContribution, eventType, message string) {
+	now := metav1.Now()
+	nodecontributionCopy.Status.ResetTimestamp = &now
+	c.reportDecommission(nodecontributionCopy, eventType, message)
+}
+
+// reportDecommission records the progress of the decommission in the status
+func (c *Controller) reportDecommission(nodecontributionCopy *corev1alpha1.NodeContribution, eventType, message string) {
+	c.recorder.Event(nodecontributionCopy, eventType, corev1alpha1.StatusDecommissioning, message)
+	nodecontributionCopy.Status.State = corev1alpha1.StatusDecommissioning
+	nodecontributionCopy.Status.Message = message
+	c.updateStatus(context.TODO(), nodecontributionCopy)
+}
+
+// failDecommission reports the step at which the decommission got stuck and schedules another attempt
+func (c *Controller) failDecommission(nodecontributionCopy *corev1alpha1.NodeContribution, message string) {
+	if nodecontributionCopy.Status.Message != message {
+		c.reportDecommission(nodecontributionCopy, corev1.EventTypeWarning, message)
+	} else {
+		c.recorder.Event(nodecontributionCopy, corev1.EventTypeWarning, corev1alpha1.StatusDecommissioning, message)
 	}
-	return sess, nil
+	c.enqueueNodeContributionAfter(nodecontributionCopy, 30*time.Second)
+}
+
+func hasFinalizer(finalizers []string, finalizer string) bool {
+	for _, item := range finalizers {
+		if item == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	remaining := []string{}
+	for _, item := range finalizers {
+		if item != finalizer {
+			remaining = append(remaining, item)
+		}
+	}
+	return remaining
 }
 
-// updateStatus calls the API to update the slice status.
+// updateStatus calls the API to update the node contribution status. The node worker and the background bootstraps
+// write the status too, so on a conflict it gets the latest version, keeps the availability the node worker owns,
+// and tries again with the rest of the status.
 func (c *Controller) updateStatus(ctx context.Context, nodecontributionCopy *corev1alpha1.NodeContribution) {
 	if nodecontributionCopy.Status.State == corev1alpha1.StatusFailed {
 		nodecontributionCopy.Status.Failed++
 		now := metav1.Now()
 		nodecontributionCopy.Status.UpdateTimestamp = &now
 	}
-	if _, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().UpdateStatus(ctx, nodecontributionCopy, metav1.UpdateOptions{}); err != nil {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().UpdateStatus(ctx, nodecontributionCopy, metav1.UpdateOptions{})
+		if err == nil {
+			// Successive updates within the same processing need the latest version
+			nodecontributionCopy.SetResourceVersion(updated.GetResourceVersion())
+			return nil
+		}
+		if errors.IsConflict(err) {
+			if latest, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().Get(ctx, nodecontributionCopy.GetName(), metav1.GetOptions{}); err == nil {
+				nodecontributionCopy.SetResourceVersion(latest.GetResourceVersion())
+				nodecontributionCopy.Status.Availability = latest.Status.Availability
+			}
+		}
+		return err
+	})
+	if err != nil {
 		klog.Infoln(err)
 	}
 }
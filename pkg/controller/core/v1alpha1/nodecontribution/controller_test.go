@@ -2,6 +2,7 @@ package nodecontribution
 
 import (
 	"context"
+	"flag"
 	"io"
 	"log"
 	"os"
@@ -9,20 +10,25 @@ import (
 	"time"
 
 	corev1alpha "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
 	"github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
 	edgenettestclient "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 	"github.com/EdgeNet-project/edgenet/pkg/signals"
 	"github.com/EdgeNet-project/edgenet/pkg/util"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/klog"
 )
 
@@ -35,11 +41,14 @@ type TestGroup struct {
 
 var kubeclientset kubernetes.Interface = testclient.NewSimpleClientset()
 var edgenetclientset versioned.Interface = edgenettestclient.NewSimpleClientset()
+var dnsProvider = multiprovider.NewMemoryDNSProvider()
 
 func TestMain(m *testing.M) {
 	klog.SetOutput(io.Discard)
 	log.SetOutput(io.Discard)
 	logrus.SetOutput(io.Discard)
+	flag.String("kubeconfig-path", "../../../../../configs/public.cfg", "Set kubeconfig path.")
+	flag.String("ca-path", "../../../../../configs/ca_sample.crt", "Set CA path.")
 
 	stopCh := signals.SetupSignalHandler()
 
@@ -49,7 +58,7 @@ func TestMain(m *testing.M) {
 	controller := NewController(kubeclientset,
 		edgenetclientset,
 		kubeInformerFactory.Core().V1().Nodes(),
-		edgenetInformerFactory.Core().V1alpha1().NodeContributions(), "", "")
+		edgenetInformerFactory.Core().V1alpha1().NodeContributions(), dnsProvider, "edge-net.io")
 
 	kubeInformerFactory.Start(stopCh)
 	edgenetInformerFactory.Start(stopCh)
@@ -203,6 +212,445 @@ func TestStartController(t *testing.T) {
 	util.Equals(t, expectedCPU, cpuQuota)
 }
 
+func TestDNSRecord(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	node := g.nodeObj
+	node.SetName("dns-0000.edge-net.io")
+	node.SetOwnerReferences(nil)
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "dns-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:    "10.0.0.10",
+			Port:    22,
+			User:    "edgenet",
+			Enabled: true,
+		},
+		Status: corev1alpha.NodeContributionStatus{
+			State: corev1alpha.StatusAccessed,
+		},
+	}
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, corev1alpha.StatusReady, nodecontributionCopy.Status.State)
+	address, exists := dnsProvider.Lookup(node.GetName(), "A")
+	util.Equals(t, true, exists)
+	util.Equals(t, nodecontribution.Spec.Host, address)
+
+	edgenetclientset.CoreV1alpha1().NodeContributions().Delete(context.TODO(), nodecontribution.GetName(), metav1.DeleteOptions{})
+	time.Sleep(250 * time.Millisecond)
+	_, exists = dnsProvider.Lookup(node.GetName(), "A")
+	util.Equals(t, false, exists)
+}
+
+func TestLimitations(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	node := g.nodeObj
+	node.SetName("limited-0000.edge-net.io")
+	node.SetOwnerReferences(nil)
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "limited-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:        "10.0.0.12",
+			Port:        22,
+			User:        "edgenet",
+			Enabled:     true,
+			Limitations: []corev1alpha.Limitations{{Kind: "Tenant", Indentifier: "lip6"}},
+		},
+		Status: corev1alpha.NodeContributionStatus{
+			State: corev1alpha.StatusAccessed,
+		},
+	}
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, corev1alpha.StatusReady, nodecontributionCopy.Status.State)
+	limitedNode, err := kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, true, multiprovider.LimitationsApplied(limitedNode, nodecontribution.Spec.Limitations))
+	util.Equals(t, false, multiprovider.IsAllowedOnNode(limitedNode, "edgenet", "edgenet"))
+	util.Equals(t, true, multiprovider.IsAllowedOnNode(limitedNode, "lip6", "lip6"))
+
+	nodecontributionCopy.Spec.Limitations = nil
+	edgenetclientset.CoreV1alpha1().NodeContributions().Update(context.TODO(), nodecontributionCopy, metav1.UpdateOptions{})
+	time.Sleep(500 * time.Millisecond)
+	nodecontributionCopy, err = edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, corev1alpha.StatusReady, nodecontributionCopy.Status.State)
+	limitedNode, err = kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, true, multiprovider.IsAllowedOnNode(limitedNode, "edgenet", "edgenet"))
+}
+
+func TestBootstrap(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+	edgenetNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "edgenet"}}
+	kubeclientset.CoreV1().Namespaces().Create(context.TODO(), edgenetNamespace, metav1.CreateOptions{})
+
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "bootstrap-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:      "10.0.0.11",
+			Port:      22,
+			User:      "edgenet",
+			Enabled:   true,
+			Bootstrap: corev1alpha.BootstrapKubeadm,
+		},
+	}
+	t.Run("kubeadm", func(t *testing.T) {
+		edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+		time.Sleep(250 * time.Millisecond)
+		nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		// The steps are listed before the connection, which fails without an SSH key
+		util.Equals(t, corev1alpha.StatusFailed, nodecontributionCopy.Status.State)
+		util.Equals(t, messageSSHFailed, nodecontributionCopy.Status.Message)
+		util.NotEquals(t, (*corev1alpha.BootstrapStatus)(nil), nodecontributionCopy.Status.Bootstrap)
+		util.Equals(t, corev1alpha.BootstrapKubeadm, nodecontributionCopy.Status.Bootstrap.Strategy)
+		util.Equals(t, 2, len(nodecontributionCopy.Status.Bootstrap.Steps))
+		util.Equals(t, corev1alpha.StepPending, nodecontributionCopy.Status.Bootstrap.Steps[0].State)
+	})
+	t.Run("k3s without k3s control plane", func(t *testing.T) {
+		nodecontributionCopy := nodecontribution.DeepCopy()
+		nodecontributionCopy.SetName("bootstrap-0001")
+		nodecontributionCopy.Spec.Bootstrap = corev1alpha.BootstrapK3s
+		edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontributionCopy, metav1.CreateOptions{})
+		time.Sleep(250 * time.Millisecond)
+		nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), "bootstrap-0001", metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, corev1alpha.StatusFailed, nodecontributionCopy.Status.State)
+		util.Equals(t, messageBootstrapFailed, nodecontributionCopy.Status.Message)
+	})
+}
+
+func TestPullBootstrap(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+	edgenetNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "edgenet"}}
+	kubeclientset.CoreV1().Namespaces().Create(context.TODO(), edgenetNamespace, metav1.CreateOptions{})
+
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pull-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:      "10.0.0.11",
+			Port:      22,
+			User:      "edgenet",
+			Enabled:   true,
+			Bootstrap: corev1alpha.BootstrapPull,
+		},
+	}
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	// Nothing runs over SSH, which would fail without an SSH key
+	nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, corev1alpha.StatusBootstrapping, nodecontributionCopy.Status.State)
+	util.NotEquals(t, (*corev1alpha.BootstrapStatus)(nil), nodecontributionCopy.Status.Bootstrap)
+	util.Equals(t, corev1alpha.BootstrapPull, nodecontributionCopy.Status.Bootstrap.Strategy)
+	util.Equals(t, 1, len(nodecontributionCopy.Status.Bootstrap.Steps))
+	util.Equals(t, corev1alpha.StepRunning, nodecontributionCopy.Status.Bootstrap.Steps[0].State)
+	_, err = kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), multiprovider.JoinSecretPrefix+"pull-0000.edge-net.io", metav1.GetOptions{})
+	util.OK(t, err)
+
+	node := g.nodeObj
+	node.SetName("pull-0000.edge-net.io")
+	node.SetOwnerReferences(nil)
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(500 * time.Millisecond)
+	nodecontributionCopy, err = edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, corev1alpha.StatusReady, nodecontributionCopy.Status.State)
+	util.Equals(t, corev1alpha.StepSucceeded, nodecontributionCopy.Status.Bootstrap.Steps[0].State)
+	_, err = kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), multiprovider.JoinSecretPrefix+"pull-0000.edge-net.io", metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+}
+
+func TestRecovery(t *testing.T) {
+	util.Equals(t, 2*time.Minute, recoveryBackoff(1))
+	util.Equals(t, 8*time.Minute, recoveryBackoff(3))
+	util.Equals(t, 6*time.Hour, recoveryBackoff(20))
+
+	g := TestGroup{}
+	g.Init()
+	edgenetNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "edgenet"}}
+	kubeclientset.CoreV1().Namespaces().Create(context.TODO(), edgenetNamespace, metav1.CreateOptions{})
+	disabled := false
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "recovery-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:      "10.0.0.14",
+			Port:      22,
+			User:      "edgenet",
+			Enabled:   true,
+			Bootstrap: corev1alpha.BootstrapKubeadm,
+		},
+		Status: corev1alpha.NodeContributionStatus{
+			State: corev1alpha.StatusReady,
+		},
+	}
+
+	t.Run("rejoin", func(t *testing.T) {
+		nodecontributionCopy := nodecontribution.DeepCopy()
+		edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontributionCopy, metav1.CreateOptions{})
+		time.Sleep(500 * time.Millisecond)
+		nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.NotEquals(t, (*corev1alpha.RecoveryStatus)(nil), nodecontributionCopy.Status.Recovery)
+		util.Equals(t, 1, nodecontributionCopy.Status.Recovery.Attempts)
+
+		node := g.nodeObj
+		node.SetName("recovery-0000.edge-net.io")
+		node.SetOwnerReferences(nil)
+		kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+		// The next attempt finds the node back in the cluster
+		past := metav1.NewTime(time.Now().Add(-time.Minute))
+		nodecontributionCopy.Status.Recovery.NextAttemptTimestamp = &past
+		edgenetclientset.CoreV1alpha1().NodeContributions().UpdateStatus(context.TODO(), nodecontributionCopy, metav1.UpdateOptions{})
+		time.Sleep(500 * time.Millisecond)
+		nodecontributionCopy, err = edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, corev1alpha.StatusReady, nodecontributionCopy.Status.State)
+		util.Equals(t, (*corev1alpha.RecoveryStatus)(nil), nodecontributionCopy.Status.Recovery)
+	})
+	t.Run("backoff", func(t *testing.T) {
+		nodecontributionCopy := nodecontribution.DeepCopy()
+		nodecontributionCopy.SetName("recovery-0001")
+		edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontributionCopy, metav1.CreateOptions{})
+		time.Sleep(500 * time.Millisecond)
+		nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), "recovery-0001", metav1.GetOptions{})
+		util.OK(t, err)
+		// The host is unreachable, so the next attempt waits for the backoff
+		util.Equals(t, corev1alpha.StatusFailed, nodecontributionCopy.Status.State)
+		util.NotEquals(t, (*corev1alpha.RecoveryStatus)(nil), nodecontributionCopy.Status.Recovery)
+		util.Equals(t, 1, nodecontributionCopy.Status.Recovery.Attempts)
+		util.Equals(t, true, nodecontributionCopy.Status.Recovery.NextAttemptTimestamp.After(time.Now()))
+	})
+	t.Run("opt-out", func(t *testing.T) {
+		nodecontributionCopy := nodecontribution.DeepCopy()
+		nodecontributionCopy.SetName("recovery-0002")
+		nodecontributionCopy.Spec.AutoRecovery = &disabled
+		edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontributionCopy, metav1.CreateOptions{})
+		time.Sleep(500 * time.Millisecond)
+		nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), "recovery-0002", metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, corev1alpha.StatusFailed, nodecontributionCopy.Status.State)
+		util.Equals(t, (*corev1alpha.RecoveryStatus)(nil), nodecontributionCopy.Status.Recovery)
+	})
+}
+
+func TestDecommission(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	// The fake clientset does not implement evictions, so an eviction removes the pod right away
+	kubeclientset.(*testclient.Clientset).PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(metav1.Object)
+		err := kubeclientset.(*testclient.Clientset).Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.GetNamespace(), eviction.GetName())
+		return true, nil, err
+	})
+
+	node := g.nodeObj
+	node.SetName("decom-0000.edge-net.io")
+	node.SetOwnerReferences(nil)
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: node.GetName()}}
+	kubeclientset.CoreV1().Pods(pod.GetNamespace()).Create(context.TODO(), pod, metav1.CreateOptions{})
+	daemonSetReference := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", UID: "agent", Controller: new(bool)}
+	*daemonSetReference.Controller = true
+	daemonPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", OwnerReferences: []metav1.OwnerReference{daemonSetReference}}, Spec: corev1.PodSpec{NodeName: node.GetName()}}
+	kubeclientset.CoreV1().Pods(daemonPod.GetNamespace()).Create(context.TODO(), daemonPod, metav1.CreateOptions{})
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bootstrap-token-abcdef", Namespace: metav1.NamespaceSystem},
+		Type:       corev1.SecretTypeBootstrapToken,
+		Data:       map[string][]byte{"description": []byte("EdgeNet token for adding node called decom-0000.edge-net.io")},
+	}
+	kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).Create(context.TODO(), token, metav1.CreateOptions{})
+	vpnPeer := &networkingv1alpha1.VPNPeer{ObjectMeta: metav1.ObjectMeta{Name: node.GetName()}}
+	edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), vpnPeer, metav1.CreateOptions{})
+	dnsProvider.SetRecord(node.GetName(), "10.0.0.13", "A")
+
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "decom-0000",
+			Finalizers:        []string{nodecontributionFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:    "10.0.0.13",
+			Port:    22,
+			User:    "edgenet",
+			Enabled: true,
+		},
+		Status: corev1alpha.NodeContributionStatus{
+			State: corev1alpha.StatusReady,
+		},
+	}
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(500 * time.Millisecond)
+
+	nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 0, len(nodecontributionCopy.GetFinalizers()))
+	util.Equals(t, corev1alpha.StatusDecommissioning, nodecontributionCopy.Status.State)
+	_, err = kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, err = kubeclientset.CoreV1().Pods(pod.GetNamespace()).Get(context.TODO(), pod.GetName(), metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, err = kubeclientset.CoreV1().Pods(daemonPod.GetNamespace()).Get(context.TODO(), daemonPod.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	_, err = kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).Get(context.TODO(), token.GetName(), metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), vpnPeer.GetName(), metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, exists := dnsProvider.Lookup(node.GetName(), "A")
+	util.Equals(t, false, exists)
+	// The host is unreachable, which still counts as the end of the reset
+	util.NotEquals(t, (*metav1.Time)(nil), nodecontributionCopy.Status.ResetTimestamp)
+
+	// A step failing after the reset leaves its own message in the status but does not bring the reset back
+	node.SetName("decom-0001.edge-net.io")
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	resetTimestamp := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	nodecontribution.SetName("decom-0001")
+	nodecontribution.Status.State = corev1alpha.StatusDecommissioning
+	nodecontribution.Status.Message = messageNodeDeletionFailed
+	nodecontribution.Status.ResetTimestamp = &resetTimestamp
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(500 * time.Millisecond)
+
+	nodecontributionCopy, err = edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 0, len(nodecontributionCopy.GetFinalizers()))
+	util.Equals(t, true, nodecontributionCopy.Status.ResetTimestamp.Equal(&resetTimestamp))
+	_, err = kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+}
+
+func TestAvailability(t *testing.T) {
+	now := time.Now()
+	t.Run("uptime", func(t *testing.T) {
+		availability := &corev1alpha.AvailabilityStatus{}
+		recordTransition(availability, true, now.Add(-48*time.Hour))
+		recordTransition(availability, true, now.Add(-36*time.Hour))
+		recordTransition(availability, false, now.Add(-6*time.Hour))
+		recordTransition(availability, true, now.Add(-3*time.Hour))
+		recordTransition(availability, false, now.Add(-1*time.Hour))
+		summarizeAvailability(availability, now)
+		util.Equals(t, 4, len(availability.Transitions))
+		util.Equals(t, "83.33", availability.Uptime24h)
+		util.Equals(t, "91.67", availability.Uptime7d)
+		util.Equals(t, "91.67", availability.Uptime30d)
+		util.Equals(t, 2, availability.FlapCount)
+		util.Equals(t, now.Add(-1*time.Hour).Unix(), availability.NotReadySince.Unix())
+		util.Equals(t, now.Add(-1*time.Hour).Unix(), availability.LastSeen.Unix())
+	})
+	t.Run("window", func(t *testing.T) {
+		availability := &corev1alpha.AvailabilityStatus{}
+		recordTransition(availability, false, now.Add(-40*24*time.Hour))
+		recordTransition(availability, true, now.Add(-35*24*time.Hour))
+		recordTransition(availability, false, now.Add(-12*time.Hour))
+		recordTransition(availability, true, now.Add(-6*time.Hour))
+		summarizeAvailability(availability, now)
+		util.Equals(t, 3, len(availability.Transitions))
+		util.Equals(t, "75.00", availability.Uptime24h)
+		util.Equals(t, 1, availability.FlapCount)
+		util.Equals(t, true, availability.NotReadySince == nil)
+		util.Equals(t, now.Truncate(lastSeenResolution).Unix(), availability.LastSeen.Unix())
+	})
+	t.Run("changes", func(t *testing.T) {
+		availability := &corev1alpha.AvailabilityStatus{}
+		recordTransition(availability, true, now.Truncate(lastSeenResolution).Add(-6*time.Hour))
+		summarizeAvailability(availability, now.Truncate(lastSeenResolution))
+		refreshed := availability.DeepCopy()
+		recordTransition(refreshed, true, now)
+		summarizeAvailability(refreshed, now.Truncate(lastSeenResolution).Add(lastSeenResolution-time.Second))
+		// Only the uptime figures moved within the same hour
+		util.Equals(t, false, availabilityChanged(availability, refreshed))
+		summarizeAvailability(refreshed, now.Truncate(lastSeenResolution).Add(lastSeenResolution))
+		util.Equals(t, true, availabilityChanged(availability, refreshed))
+		refreshed = availability.DeepCopy()
+		recordTransition(refreshed, false, now)
+		util.Equals(t, true, availabilityChanged(availability, refreshed))
+		util.Equals(t, true, availabilityChanged(nil, availability))
+	})
+	t.Run("threshold", func(t *testing.T) {
+		availability := &corev1alpha.AvailabilityStatus{}
+		recordTransition(availability, false, now.Add(-10*time.Minute))
+		util.Equals(t, false, exceedsNotReadyThreshold(availability, now, 15*time.Minute))
+		util.Equals(t, true, exceedsNotReadyThreshold(availability, now.Add(5*time.Minute), 15*time.Minute))
+		availability.NotReadyNotified = true
+		util.Equals(t, false, exceedsNotReadyThreshold(availability, now.Add(time.Hour), 15*time.Minute))
+		recordTransition(availability, true, now)
+		recordTransition(availability, false, now)
+		util.Equals(t, false, availability.NotReadyNotified)
+		util.Equals(t, true, exceedsNotReadyThreshold(availability, now.Add(time.Hour), 15*time.Minute))
+	})
+
+	g := TestGroup{}
+	g.Init()
+	nodecontribution := corev1alpha.NodeContribution{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "available-0000",
+		},
+		Spec: corev1alpha.NodeContributionSpec{
+			Host:    "10.0.0.13",
+			Port:    22,
+			User:    "edgenet",
+			Enabled: true,
+		},
+		Status: corev1alpha.NodeContributionStatus{
+			State: corev1alpha.StatusReady,
+		},
+	}
+	edgenetclientset.CoreV1alpha1().NodeContributions().Create(context.TODO(), nodecontribution.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	node := g.nodeObj
+	node.SetName("available-0000.edge-net.io")
+	node.SetOwnerReferences(nil)
+	kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	nodecontributionCopy, err := edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.NotEquals(t, nil, nodecontributionCopy.Status.Availability)
+	util.Equals(t, 1, len(nodecontributionCopy.Status.Availability.Transitions))
+	util.Equals(t, "100.00", nodecontributionCopy.Status.Availability.Uptime24h)
+
+	node.Status.Conditions[0].Status = corev1.ConditionFalse
+	kubeclientset.CoreV1().Nodes().Update(context.TODO(), node.DeepCopy(), metav1.UpdateOptions{})
+	time.Sleep(250 * time.Millisecond)
+	nodecontributionCopy, err = edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), nodecontribution.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 2, len(nodecontributionCopy.Status.Availability.Transitions))
+	util.NotEquals(t, nil, nodecontributionCopy.Status.Availability.NotReadySince)
+	util.Equals(t, 1, nodecontributionCopy.Status.Availability.FlapCount)
+}
+
 func getQuotas(claimRaw map[string]corev1alpha.ResourceTuning) (int64, int64) {
 	var cpuQuota int64
 	var memoryQuota int64
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecontribution
+
+import (
+	"context"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The delay between recovery attempts doubles from recoveryBaseDelay up to recoveryMaxDelay
+	recoveryBaseDelay = 2 * time.Minute
+	recoveryMaxDelay  = 6 * time.Hour
+
+	reasonRecovery  = "Recovery"
+	messageRecovery = "Node dropped out of the cluster, joining it again (attempt %d)"
+)
+
+// isRecovering tells whether the node contribution is being recovered automatically
+func isRecovering(nodecontribution *corev1alpha1.NodeContribution) bool {
+	return nodecontribution.Status.Recovery != nil && nodecontribution.Status.Recovery.NextAttemptTimestamp != nil &&
+		nodecontribution.GetAutoRecovery()
+}
+
+// recover starts a new attempt to join the node to the cluster. The reconciliation state leads the node
+// contribution into the bootstrap procedure, as when it was created.
+func (c *Controller) recover(nodecontributionCopy *corev1alpha1.NodeContribution) {
+	if nodecontributionCopy.Status.Recovery == nil {
+		nodecontributionCopy.Status.Recovery = new(corev1alpha1.RecoveryStatus)
+	}
+	recovery := nodecontributionCopy.Status.Recovery
+	recovery.Attempts++
+	now := metav1.Now()
+	next := metav1.NewTime(now.Add(recoveryBackoff(recovery.Attempts)))
+	recovery.LastAttemptTimestamp = &now
+	recovery.NextAttemptTimestamp = &next
+	c.recorder.Eventf(nodecontributionCopy, corev1.EventTypeWarning, reasonRecovery, messageRecovery, recovery.Attempts)
+	nodecontributionCopy.Status.State = corev1alpha1.StatusReconciliation
+	nodecontributionCopy.Status.Message = messageReconciliation
+	// The backoff limit of the regular procedure does not apply to recovery attempts
+	nodecontributionCopy.Status.Failed = 0
+	c.updateStatus(context.TODO(), nodecontributionCopy)
+}
+
+// recoveryBackoff returns the delay after the start of an attempt before the next one can start
+func recoveryBackoff(attempts int) time.Duration {
+	backoff := recoveryBaseDelay
+	for i := 1; i < attempts && backoff < recoveryMaxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > recoveryMaxDelay {
+		backoff = recoveryMaxDelay
+	}
+	return backoff
+}
+
+// getJoinTimestamp returns the time the node is expected to join the cluster from, which is the start of the
+// last recovery attempt if any, or the creation of the node contribution.
+func getJoinTimestamp(nodecontribution *corev1alpha1.NodeContribution) metav1.Time {
+	if isRecovering(nodecontribution) && nodecontribution.Status.Recovery.LastAttemptTimestamp != nil {
+		return *nodecontribution.Status.Recovery.LastAttemptTimestamp
+	}
+	return nodecontribution.GetCreationTimestamp()
+}
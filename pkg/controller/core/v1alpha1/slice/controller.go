@@ -20,8 +20,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
-	"strings"
 	"time"
 
 	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
@@ -30,15 +28,20 @@ import (
 	edgenetscheme "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/scheme"
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/core/v1alpha1"
 	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -55,9 +58,21 @@ const (
 	successBound       = "Bound"
 	successProvisioned = "Provisioned"
 	successExpired     = "Expired"
+	successLimited     = "Expiry Limited"
+	successQueued      = "Queued"
+	successHealed      = "Healed"
+	successRenewed     = "Renewed"
+	successShortened   = "Expiry Shortened"
 	failureBound       = "Bound Failed"
 	failureSlice       = "Slice Failed"
 	failurePatch       = "Patch Failed"
+	failureSelector    = "Selector Invalid"
+	failureClass       = "Class Failed"
+	failureBooking     = "Booking Failed"
+	failureHeal        = "Heal Failed"
+	failureRenewal     = "Renewal Failed"
+	warningExpiring    = "Expiring"
+	warningGracePeriod = "Grace Period"
 
 	messageResourceSynced = "Slice synced successfully"
 	messageProvisioned    = "Desired resources are provisioned"
@@ -67,6 +82,26 @@ const (
 	messageExpired        = "Slice deleted successfully"
 	messageSliceFailed    = "There are no adequate resources to slice"
 	messagePatchFailed    = "Node patch operation has failed"
+	messageSelectorFailed = "Node selector term is invalid: %s"
+	messageClassFailed    = "Slice class %s cannot be found"
+	messageNodeCount      = "Slice class allows %d nodes at most"
+	messageExpiryLimited  = "Expiry is limited to the maximum duration of the slice class"
+	messagePlacement      = "Nodes cannot be placed: %s"
+	messageAlreadyPicked  = "Reserved for the slice already"
+	messageQueued         = "Nodes are booked for the slice starting at %s"
+	messageBookingFailed  = "Nodes are booked by other reservations for the period"
+	messageBookingLost    = "Booked nodes are not available at the start"
+	messageHealed         = "Node %s is not ready, replaced with %s"
+	messageHealFailed     = "Node %s is not ready, and no feasible node can replace it"
+	messageRenewed        = "Expiry is extended to %s, renewal %d"
+	messageShortened      = "Expiry is brought forward to %s as the slice claim asks"
+	messageRenewalLimit   = "Slice class allows %d renewals at most"
+	messageRenewalExpiry  = "Renewal exceeds the maximum duration of slice class %s"
+	messageNotRenewable   = "Slice class %s does not allow the tenant to renew"
+	messageRenewalBooked  = "Nodes are booked by other reservations for the renewal period"
+	messageExpiring       = "Slice expires at %s"
+	messageGracePeriod    = "Slice has expired, nodes are returned at %s"
+	messagePodGracePeriod = "Slice %s has expired, node %s is returned at %s"
 	messageReconciliation = "Reconciliation in progress"
 )
 
@@ -83,6 +118,9 @@ type Controller struct {
 	slicesLister listers.SliceLister
 	slicesSynced cache.InformerSynced
 
+	nodesLister corelisters.NodeLister
+	nodesSynced cache.InformerSynced
+
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
 	// means we can ensure we only process a fixed amount of resources at a
@@ -99,7 +137,8 @@ func NewController(
 	kubeclientset kubernetes.Interface,
 	edgenetclientset clientset.Interface,
 	sliceClaimInformer informers.SliceClaimInformer,
-	sliceInformer informers.SliceInformer) *Controller {
+	sliceInformer informers.SliceInformer,
+	nodeInformer coreinformers.NodeInformer) *Controller {
 
 	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
 	klog.Info("Creating event broadcaster")
@@ -115,6 +154,8 @@ func NewController(
 		sliceClaimsSynced: sliceClaimInformer.Informer().HasSynced,
 		slicesLister:      sliceInformer.Lister(),
 		slicesSynced:      sliceInformer.Informer().HasSynced,
+		nodesLister:       nodeInformer.Lister(),
+		nodesSynced:       nodeInformer.Informer().HasSynced,
 		workqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Slices"),
 		recorder:          recorder,
 	}
@@ -150,6 +191,16 @@ func NewController(
 		DeleteFunc: controller.handleObject,
 	})
 
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			newNode := new.(*corev1.Node)
+			oldNode := old.(*corev1.Node)
+			if isNodeReady(newNode) != isNodeReady(oldNode) {
+				controller.handleNode(new)
+			}
+		},
+	})
+
 	return controller
 }
 
@@ -166,7 +217,8 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	klog.Infoln("Waiting for informer caches to sync")
 	if ok := cache.WaitForCacheSync(stopCh,
 		c.sliceClaimsSynced,
-		c.slicesSynced); !ok {
+		c.slicesSynced,
+		c.nodesSynced); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -315,8 +367,38 @@ func (c *Controller) handleObject(obj interface{}) {
 	}
 }
 
+// handleNode enqueues the slice that the node is reserved for, if any, so that the slice status follows the readiness
+// of its nodes
+func (c *Controller) handleNode(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("error decoding node, invalid type"))
+		return
+	}
+	sliceName := node.GetLabels()["edge-net.io/pre-reservation"]
+	if sliceName == "" || sliceName == "none" {
+		return
+	}
+	if slice, err := c.slicesLister.Get(sliceName); err == nil {
+		c.enqueueSlice(slice)
+	}
+}
+
 func (c *Controller) processSlice(sliceCopy *corev1alpha1.Slice) {
+	holdsNodes := sliceCopy.Status.State == corev1alpha1.StatusReserved || sliceCopy.Status.State == corev1alpha1.StatusBound || sliceCopy.Status.State == corev1alpha1.StatusProvisioned
+	// A slice can be renewed until its nodes are returned
+	if holdsNodes {
+		if isChanged := c.renewSlice(sliceCopy); isChanged {
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+	}
 	if sliceCopy.Status.Expiry != nil && time.Until(sliceCopy.Status.Expiry.Time) <= 0 {
+		if holdsNodes {
+			if inGracePeriod := c.warnExpiry(sliceCopy); inGracePeriod {
+				return
+			}
+		}
 		c.recorder.Event(sliceCopy, corev1.EventTypeWarning, successExpired, messageExpired)
 		c.edgenetclientset.CoreV1alpha1().Slices().Delete(context.TODO(), sliceCopy.GetName(), metav1.DeleteOptions{})
 		return
@@ -326,7 +408,36 @@ func (c *Controller) processSlice(sliceCopy *corev1alpha1.Slice) {
 		return
 	}
 
+	if holdsNodes {
+		if isChanged := c.checkNodes(sliceCopy); isChanged {
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+		if isNoticed := c.noticeExpiry(sliceCopy); isNoticed {
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+	}
+
 	switch sliceCopy.Status.State {
+	case corev1alpha1.StatusQueued:
+		if sliceCopy.Spec.Start != nil {
+			if untilStart := time.Until(sliceCopy.Spec.Start.Time); untilStart > 0 {
+				c.enqueueSliceAfter(sliceCopy, untilStart)
+				return
+			}
+		}
+		if isReserved := c.reserveBookedNodes(sliceCopy); !isReserved {
+			c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failureBooking, messageBookingLost)
+			sliceCopy.Status.State = corev1alpha1.StatusFailed
+			sliceCopy.Status.Message = messageBookingLost
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+		c.recorder.Event(sliceCopy, corev1.EventTypeNormal, corev1alpha1.StatusReserved, messageReserved)
+		sliceCopy.Status.State = corev1alpha1.StatusReserved
+		sliceCopy.Status.Message = messageReserved
+		c.updateStatus(context.TODO(), sliceCopy)
 	case corev1alpha1.StatusProvisioned:
 		if ok := c.checkSliceStatus(sliceCopy, "slice"); ok {
 			if sliceCopy.Spec.ClaimRef != nil {
@@ -398,7 +509,7 @@ func (c *Controller) processSlice(sliceCopy *corev1alpha1.Slice) {
 						return
 					}
 				} else {
-					if sliceClaimCopy.Status.State == corev1alpha1.StatusRequested {
+					if sliceClaimCopy.Status.State == corev1alpha1.StatusRequested || sliceClaimCopy.Status.State == corev1alpha1.StatusQueued {
 						ownerReferences := sliceClaimCopy.GetOwnerReferences()
 						sliceOwnerReference := sliceCopy.MakeOwnerReference()
 						takeControl := true
@@ -421,7 +532,45 @@ func (c *Controller) processSlice(sliceCopy *corev1alpha1.Slice) {
 			c.updateStatus(context.TODO(), sliceCopy)
 		}
 	default:
-		if isReserved := c.preReserveNodes(sliceCopy); !isReserved {
+		multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
+		sliceClass, err := multitenancyManager.GetSliceClass(sliceCopy.Spec.SliceClassName)
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureClass, messageClassFailed, sliceCopy.Spec.SliceClassName)
+			sliceCopy.Status.State = corev1alpha1.StatusFailed
+			sliceCopy.Status.Message = fmt.Sprintf(messageClassFailed, sliceCopy.Spec.SliceClassName)
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+		// A slice lasts as long as the class allows from its start, and each renewal it has got extends that limit
+		start := sliceCopy.GetCreationTimestamp().Time
+		if sliceCopy.Spec.Start != nil {
+			start = sliceCopy.Spec.Start.Time
+		} else if start.IsZero() {
+			start = time.Now()
+		}
+		maxExpiry := sliceClass.GetMaxExpiry(start)
+		if sliceCopy.Status.Renewals > 0 {
+			maxExpiry = sliceClass.GetMaxRenewedExpiry(start, sliceCopy.Status.Renewals-1)
+		}
+		if maxExpiry != nil && (sliceCopy.Status.Expiry == nil || sliceCopy.Status.Expiry.After(maxExpiry.Time)) {
+			c.recorder.Event(sliceCopy, corev1.EventTypeNormal, successLimited, messageExpiryLimited)
+			sliceCopy.Status.Expiry = maxExpiry
+		}
+		if isAdvance := sliceCopy.Spec.Start != nil && sliceCopy.Spec.Start.After(time.Now()); isAdvance {
+			if isBooked := c.bookNodes(sliceCopy, sliceClass); !isBooked {
+				c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failureBooking, messageBookingFailed)
+				sliceCopy.Status.State = corev1alpha1.StatusFailed
+				sliceCopy.Status.Message = messageBookingFailed
+				c.updateStatus(context.TODO(), sliceCopy)
+				return
+			}
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeNormal, successQueued, messageQueued, sliceCopy.Spec.Start.Format(time.RFC3339))
+			sliceCopy.Status.State = corev1alpha1.StatusQueued
+			sliceCopy.Status.Message = fmt.Sprintf(messageQueued, sliceCopy.Spec.Start.Format(time.RFC3339))
+			c.updateStatus(context.TODO(), sliceCopy)
+			return
+		}
+		if isReserved := c.preReserveNodes(sliceCopy, sliceClass); !isReserved {
 			c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failureSlice, messageSliceFailed)
 			sliceCopy.Status.State = corev1alpha1.StatusFailed
 			sliceCopy.Status.Message = messageSliceFailed
@@ -435,13 +584,24 @@ func (c *Controller) processSlice(sliceCopy *corev1alpha1.Slice) {
 	}
 }
 
+// provisionSlice makes the pre-reserved nodes part of the slice. The workloads of the others running on the nodes
+// are evicted unless the slice class shares the nodes, in which case they are let finish.
 func (c *Controller) provisionSlice(sliceCopy *corev1alpha1.Slice) (bool, bool) {
+	multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
+	sliceClass, err := multitenancyManager.GetSliceClass(sliceCopy.Spec.SliceClassName)
+	if err != nil {
+		klog.Infoln(err)
+		return false, false
+	}
 	if nodeRaw, err := c.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/pre-reservation=%s", sliceCopy.GetName())}); err == nil {
 		isSliceIsolated := true
 		for _, nodeRow := range nodeRaw.Items {
 			if err := c.patchNode("slice", sliceCopy.GetName(), nodeRow.GetName()); err != nil {
 				return false, false
 			}
+			if !sliceClass.IsExclusive() {
+				continue
+			}
 			if podRaw, err := c.kubeclientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.namespace!=kube-system,metadata.namespace!=edgenet,spec.nodeName=%s", nodeRow.GetName())}); err == nil {
 			isolationLoop:
 				for _, podRow := range podRaw.Items {
@@ -467,51 +627,42 @@ func (c *Controller) provisionSlice(sliceCopy *corev1alpha1.Slice) (bool, bool)
 	return false, false
 }
 
-func (c *Controller) preReserveNodes(sliceCopy *corev1alpha1.Slice) bool {
+// preReserveNodes picks the nodes of each node selector term among those the slice class allows with the placement
+// strategy, and labels them as pre-reserved for the slice. The status of the slice lists the nodes picked and why.
+func (c *Controller) preReserveNodes(sliceCopy *corev1alpha1.Slice, sliceClass *corev1alpha1.SliceClass) bool {
+	classSelector, placement, ok := c.getNodeSelection(sliceCopy, sliceClass)
+	if !ok {
+		return false
+	}
+	nodeRaw, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return false
+	}
+	// The nodes booked by advance reservations starting before the slice expires are not candidates
+	bookedNodes := c.getBookedNodes(sliceCopy, time.Now(), sliceCopy.Status.Expiry)
+	var sliceNodes []corev1alpha1.SliceNode
+	placedNodes := make(map[string]bool)
 	for _, nodeSelectorTerm := range sliceCopy.Spec.NodeSelector.Selector.NodeSelectorTerms {
-		var labelSelector string
-		var fieldSelector string
-		for _, matchExpression := range nodeSelectorTerm.MatchExpressions {
-			if labelSelector != "" {
-				labelSelector = labelSelector + ","
-			}
-			if matchExpression.Operator == "In" || matchExpression.Operator == "NotIn" {
-				labelSelector = fmt.Sprintf("%s%s %s (%s)", labelSelector, matchExpression.Key, strings.ToLower(string(matchExpression.Operator)), strings.Join(matchExpression.Values, ","))
-			} else if matchExpression.Operator == "Exists" {
-				labelSelector = fmt.Sprintf("%s%s", labelSelector, matchExpression.Key)
-			} else if matchExpression.Operator == "DoesNotExist" {
-				labelSelector = fmt.Sprintf("%s!%s", labelSelector, matchExpression.Key)
-			} else {
-				// TO-DO: Handle Gt and Lt operaters later.
-				continue
-			}
+		term, err := newNodeSelectorTerm(nodeSelectorTerm)
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messageSelectorFailed, err.Error())
+			return false
 		}
-		for _, matchField := range nodeSelectorTerm.MatchFields {
-			if fieldSelector != "" {
-				fieldSelector = fieldSelector + ","
-			}
-			if matchField.Operator == "In" || matchField.Operator == "NotIn" {
-				fieldSelector = fmt.Sprintf("%s%s %s (%s)", fieldSelector, matchField.Key, strings.ToLower(string(matchField.Operator)), strings.Join(matchField.Values, ","))
-			} else if matchField.Operator == "Exists" {
-				fieldSelector = fmt.Sprintf("%s%s", fieldSelector, matchField.Key)
-			} else if matchField.Operator == "DoesNotExist" {
-				fieldSelector = fmt.Sprintf("%s!%s", fieldSelector, matchField.Key)
-			} else {
-				// TO-DO: Handle Gt and Lt operaters later.
-				continue
+		var matchingNodes []corev1.Node
+		for _, nodeRow := range nodeRaw {
+			if term.Match(nodeRow) && classSelector.Match(nodeRow) {
+				matchingNodes = append(matchingNodes, *nodeRow)
 			}
 		}
 
-		var nodeList []string
-		var associatedNodeList []string
-		if nodeRaw, err := c.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector}); err == nil {
-			associatedNodeList, nodeList = c.getFeasibleNodes(sliceCopy, nodeRaw)
-		}
-		if len(nodeList)+len(associatedNodeList) < sliceCopy.Spec.NodeSelector.Count {
-			return false
-		}
+		associatedNodeList, nodeList := c.getFeasibleNodes(sliceCopy, matchingNodes)
 		var pickedNodeList []string
-		pickedNodeList = append(pickedNodeList, associatedNodeList...)
+		for _, nodeName := range associatedNodeList {
+			if !placedNodes[nodeName] {
+				pickedNodeList = append(pickedNodeList, nodeName)
+			}
+		}
 		if len(pickedNodeList) > sliceCopy.Spec.NodeSelector.Count {
 			for i := sliceCopy.Spec.NodeSelector.Count; i < len(pickedNodeList); i++ {
 				if err := c.patchNode("return", "", pickedNodeList[i]); err != nil {
@@ -520,13 +671,35 @@ func (c *Controller) preReserveNodes(sliceCopy *corev1alpha1.Slice) bool {
 			}
 			pickedNodeList = pickedNodeList[:sliceCopy.Spec.NodeSelector.Count]
 		}
-		for i := len(pickedNodeList); i < sliceCopy.Spec.NodeSelector.Count; i++ {
-			rand.Seed(time.Now().UnixNano())
-			randomSelect := rand.Intn(len(nodeList))
-			pickedNodeList = append(pickedNodeList, nodeList[randomSelect])
-			nodeList[randomSelect] = nodeList[len(nodeList)-1]
-			nodeList = nodeList[:len(nodeList)-1]
+		// The nodes picked for the previous terms are not candidates anymore, and those already reserved for the
+		// slice count towards the placement of the others
+		isFeasible := make(map[string]bool)
+		for _, nodeName := range nodeList {
+			isFeasible[nodeName] = true
 		}
+		var pickedNodes, candidates []*corev1.Node
+		for i, nodeRow := range matchingNodes {
+			if contains, _ := util.Contains(pickedNodeList, nodeRow.GetName()); contains {
+				pickedNodes = append(pickedNodes, &matchingNodes[i])
+			} else if isFeasible[nodeRow.GetName()] && !placedNodes[nodeRow.GetName()] && !bookedNodes[nodeRow.GetName()] {
+				candidates = append(candidates, &matchingNodes[i])
+			}
+		}
+		if len(pickedNodeList)+len(candidates) < sliceCopy.Spec.NodeSelector.Count {
+			return false
+		}
+		for _, nodeName := range pickedNodeList {
+			sliceNodes = append(sliceNodes, corev1alpha1.SliceNode{Name: nodeName, Reason: messageAlreadyPicked})
+		}
+		placed, err := placeNodes(placement, pickedNodes, candidates, sliceCopy.Spec.NodeSelector.Count-len(pickedNodeList))
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messagePlacement, err.Error())
+			return false
+		}
+		for _, sliceNode := range placed {
+			pickedNodeList = append(pickedNodeList, sliceNode.Name)
+		}
+		sliceNodes = append(sliceNodes, placed...)
 
 		isPatched := true
 		for i := 0; i < len(pickedNodeList); i++ {
@@ -544,15 +717,39 @@ func (c *Controller) preReserveNodes(sliceCopy *corev1alpha1.Slice) bool {
 			}
 			return false
 		}
+		for _, nodeName := range pickedNodeList {
+			placedNodes[nodeName] = true
+		}
 	}
+	sliceCopy.Status.Nodes = sliceNodes
 	return true
 }
 
-func (c *Controller) getFeasibleNodes(sliceCopy *corev1alpha1.Slice, nodeRaw *corev1.NodeList) ([]string, []string) {
+// getNodeSelection returns the node selector of the slice class along with the placement of the slice, which falls
+// back to that of the class. It fails when the slice asks for more nodes than the class allows.
+func (c *Controller) getNodeSelection(sliceCopy *corev1alpha1.Slice, sliceClass *corev1alpha1.SliceClass) (*nodeSelector, corev1alpha1.Placement, bool) {
+	policy := sliceClass.Spec.NodeSelectionPolicy
+	placement := sliceCopy.Spec.NodeSelector.Placement
+	if placement.Strategy == "" {
+		placement = policy.Placement
+	}
+	if policy.MaxNodeCount != 0 && len(sliceCopy.Spec.NodeSelector.Selector.NodeSelectorTerms)*sliceCopy.Spec.NodeSelector.Count > policy.MaxNodeCount {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureClass, messageNodeCount, policy.MaxNodeCount)
+		return nil, placement, false
+	}
+	classSelector, err := newNodeSelector(policy.Selector)
+	if err != nil {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messageSelectorFailed, err.Error())
+		return nil, placement, false
+	}
+	return classSelector, placement, true
+}
+
+func (c *Controller) getFeasibleNodes(sliceCopy *corev1alpha1.Slice, nodes []corev1.Node) ([]string, []string) {
 	var associatedNodeList []string
 	var nodeList []string
 nodeLoop:
-	for _, nodeRow := range nodeRaw.Items {
+	for _, nodeRow := range nodes {
 		nodeLabels := nodeRow.GetLabels()
 		if nodeLabels["edge-net.io/access"] == "private" || nodeLabels["edge-net.io/slice"] != "none" || nodeLabels["edge-net.io/pre-reservation"] != "none" {
 			if nodeLabels["edge-net.io/slice"] == sliceCopy.GetName() || nodeLabels["edge-net.io/pre-reservation"] == sliceCopy.GetName() {
@@ -578,7 +775,7 @@ nodeLoop:
 
 func (c *Controller) checkSliceStatus(sliceCopy *corev1alpha1.Slice, phase string) bool {
 	if nodeRaw, err := c.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/%s=%s", phase, sliceCopy.GetName())}); err == nil {
-		associatedNodeList, _ := c.getFeasibleNodes(sliceCopy, nodeRaw)
+		associatedNodeList, _ := c.getFeasibleNodes(sliceCopy, nodeRaw.Items)
 		if len(associatedNodeList) == sliceCopy.Spec.NodeSelector.Count {
 			return true
 		}
@@ -639,6 +836,11 @@ func (c *Controller) patchNode(phase, slice, node string) error {
 	bytes, _ := json.Marshal(patchArr)
 
 	_, err = c.kubeclientset.CoreV1().Nodes().Patch(context.TODO(), node, types.JSONPatchType, bytes, metav1.PatchOptions{})
+	if err == nil && phase == "pre-reservation" {
+		// The placement strategies prefer the nodes reserved the least recently
+		annotation := fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, lastReservationAnnotation, time.Now().Format(time.RFC3339))
+		_, err = c.kubeclientset.CoreV1().Nodes().Patch(context.TODO(), node, types.MergePatchType, []byte(annotation), metav1.PatchOptions{})
+	}
 	if err != nil {
 		klog.Infoln(err.Error())
 	}
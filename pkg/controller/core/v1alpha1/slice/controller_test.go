@@ -0,0 +1,167 @@
+package slice
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	edgenetfake "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+// fixture runs the slice controller against fake clientsets, with the informers keeping its listers up to date
+type fixture struct {
+	t *testing.T
+
+	kubeclientset    *k8sfake.Clientset
+	edgenetclientset *edgenetfake.Clientset
+	recorder         *record.FakeRecorder
+	controller       *Controller
+}
+
+func newFixture(t *testing.T, kubeobjects, edgenetobjects []runtime.Object) *fixture {
+	f := &fixture{t: t}
+	f.kubeclientset = k8sfake.NewSimpleClientset(kubeobjects...)
+	f.edgenetclientset = edgenetfake.NewSimpleClientset(edgenetobjects...)
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(f.kubeclientset, 0)
+	edgenetInformerFactory := informers.NewSharedInformerFactory(f.edgenetclientset, 0)
+
+	f.controller = NewController(f.kubeclientset,
+		f.edgenetclientset,
+		edgenetInformerFactory.Core().V1alpha1().SliceClaims(),
+		edgenetInformerFactory.Core().V1alpha1().Slices(),
+		kubeInformerFactory.Core().V1().Nodes())
+	f.recorder = record.NewFakeRecorder(100)
+	f.controller.recorder = f.recorder
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() {
+		close(stopCh)
+		f.controller.workqueue.ShutDown()
+	})
+	kubeInformerFactory.Start(stopCh)
+	edgenetInformerFactory.Start(stopCh)
+	kubeInformerFactory.WaitForCacheSync(stopCh)
+	edgenetInformerFactory.WaitForCacheSync(stopCh)
+	return f
+}
+
+// process runs the controller once on the slice as the cache holds it, and returns the slice as it is afterwards,
+// nil if it is gone
+func (f *fixture) process(name string) *corev1alpha1.Slice {
+	slice, err := f.controller.slicesLister.Get(name)
+	util.OK(f.t, err)
+	f.controller.processSlice(slice.DeepCopy())
+	// Let the informers catch up with the changes
+	time.Sleep(100 * time.Millisecond)
+	sliceCopy, err := f.edgenetclientset.CoreV1alpha1().Slices().Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	util.OK(f.t, err)
+	return sliceCopy
+}
+
+// events returns the events recorded since the last call
+func (f *fixture) events() []string {
+	var events []string
+	for {
+		select {
+		case event := <-f.recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// hasEvent tells whether the events contain one that starts with the given type, reason and message
+func hasEvent(events []string, event string) bool {
+	for _, recorded := range events {
+		if strings.HasPrefix(recorded, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// node returns the node as the API holds it
+func (f *fixture) node(name string) *corev1.Node {
+	node, err := f.kubeclientset.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	util.OK(f.t, err)
+	return node
+}
+
+// newNode returns a ready node that no slice holds, which has the capacity the slices of the tests ask for
+func newNode(name, country string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"edge-net.io/access":          "public",
+				"edge-net.io/slice":           "none",
+				"edge-net.io/pre-reservation": "none",
+				"edge-net.io/country-iso":     country,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Capacity:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// newSlice returns a slice of the node class that asks for count nodes in the country, which expires at the expiry
+func newSlice(name, country string, count int, expiry time.Time) *corev1alpha1.Slice {
+	sliceExpiry := metav1.NewTime(expiry)
+	return &corev1alpha1.Slice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(name),
+		},
+		Spec: corev1alpha1.SliceSpec{
+			SliceClassName: corev1alpha1.SliceClassNode,
+			NodeSelector: corev1alpha1.NodeSelector{
+				Selector: corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{Key: "edge-net.io/country-iso", Operator: corev1.NodeSelectorOpIn, Values: []string{country}},
+							},
+						},
+					},
+				},
+				Count: count,
+				Resources: corev1.ResourceRequirements{
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+		},
+		Status: corev1alpha1.SliceStatus{
+			Expiry: &sliceExpiry,
+		},
+	}
+}
+
+// sliceNodeNames returns the names of the nodes listed in the status of the slice
+func sliceNodeNames(slice *corev1alpha1.Slice) []string {
+	var names []string
+	for _, sliceNode := range slice.Status.Nodes {
+		names = append(names, sliceNode.Name)
+	}
+	return names
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
+	"github.com/EdgeNet-project/edgenet/pkg/notification"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+// getExpiryPeriods returns how long before the expiry of a slice its tenant is notified, and how long after the
+// expiry its workloads can wrap up before the nodes are returned
+func getExpiryPeriods() (time.Duration, time.Duration) {
+	notice, gracePeriod := 24*time.Hour, time.Minute
+	if flag.Lookup("expiry-notice") != nil {
+		notice = flag.Lookup("expiry-notice").Value.(flag.Getter).Get().(time.Duration)
+	}
+	if flag.Lookup("grace-period") != nil {
+		gracePeriod = flag.Lookup("grace-period").Value.(flag.Getter).Get().(time.Duration)
+	}
+	return notice, gracePeriod
+}
+
+// renewSlice brings the expiry of the slice in line with that of its slice claim. A later expiry is a renewal, which
+// the slice class bounds with its maximum number of renewals and its maximum duration, each renewal adding the
+// latter at most to the lifetime of the slice counted from its start. A renewal also fails when other reservations
+// have booked the nodes of the slice for the renewal period. A refused renewal is recorded in the status so as to be
+// reported once. An earlier expiry is applied right away. It tells whether the status has changed.
+func (c *Controller) renewSlice(sliceCopy *corev1alpha1.Slice) bool {
+	if sliceCopy.Spec.ClaimRef == nil {
+		return false
+	}
+	sliceClaim, err := c.sliceClaimsLister.SliceClaims(sliceCopy.Spec.ClaimRef.Namespace).Get(sliceCopy.Spec.ClaimRef.Name)
+	if err != nil || sliceClaim.GetUID() != sliceCopy.Spec.ClaimRef.UID {
+		return false
+	}
+	expiry := sliceClaim.Spec.SliceExpiry
+	if expiry == nil || !expiry.After(time.Now()) || (sliceCopy.Status.Expiry != nil && expiry.Equal(sliceCopy.Status.Expiry)) {
+		return false
+	}
+	if sliceCopy.Status.Expiry == nil || expiry.Before(sliceCopy.Status.Expiry) {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeNormal, successShortened, messageShortened, expiry.Format(time.RFC3339))
+		sliceCopy.Status.Expiry = expiry.DeepCopy()
+		sliceCopy.Status.Notice = ""
+		sliceCopy.Status.RenewalRefusal = nil
+		return true
+	}
+
+	refuse := func(message string) bool {
+		if refusal := sliceCopy.Status.RenewalRefusal; refusal != nil && refusal.Expiry.Equal(expiry) && refusal.Message == message {
+			return false
+		}
+		c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failureRenewal, message)
+		c.recorder.Event(sliceClaim, corev1.EventTypeWarning, failureRenewal, message)
+		sliceCopy.Status.RenewalRefusal = &corev1alpha1.SliceRenewalRefusal{Expiry: *expiry.DeepCopy(), Message: message}
+		return true
+	}
+	multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
+	sliceClass, err := multitenancyManager.GetSliceClass(sliceCopy.Spec.SliceClassName)
+	if err != nil {
+		return refuse(fmt.Sprintf(messageClassFailed, sliceCopy.Spec.SliceClassName))
+	}
+	if !sliceClass.IsRenewable(sliceCopy.Status.Renewals) {
+		return refuse(fmt.Sprintf(messageRenewalLimit, sliceClass.Spec.MaxRenewals))
+	}
+	start := sliceCopy.GetCreationTimestamp().Time
+	if sliceCopy.Spec.Start != nil {
+		start = sliceCopy.Spec.Start.Time
+	}
+	if maxExpiry := sliceClass.GetMaxRenewedExpiry(start, sliceCopy.Status.Renewals); maxExpiry != nil && expiry.After(maxExpiry.Time) {
+		return refuse(fmt.Sprintf(messageRenewalExpiry, sliceCopy.Spec.SliceClassName))
+	}
+	namespace, err := c.kubeclientset.CoreV1().Namespaces().Get(context.TODO(), sliceClaim.GetNamespace(), metav1.GetOptions{})
+	if err != nil {
+		klog.Infoln(err)
+		return false
+	}
+	if !sliceClass.IsAllowed(namespace.GetLabels()["edge-net.io/tenant"]) {
+		return refuse(fmt.Sprintf(messageNotRenewable, sliceCopy.Spec.SliceClassName))
+	}
+	bookedNodes := c.getBookedNodes(sliceCopy, time.Now(), expiry)
+	for _, sliceNode := range sliceCopy.Status.Nodes {
+		if bookedNodes[sliceNode.Name] {
+			return refuse(messageRenewalBooked)
+		}
+	}
+
+	sliceCopy.Status.Renewals++
+	c.recorder.Eventf(sliceCopy, corev1.EventTypeNormal, successRenewed, messageRenewed, expiry.Format(time.RFC3339), sliceCopy.Status.Renewals)
+	c.recorder.Eventf(sliceClaim, corev1.EventTypeNormal, successRenewed, messageRenewed, expiry.Format(time.RFC3339), sliceCopy.Status.Renewals)
+	sliceCopy.Status.Expiry = expiry.DeepCopy()
+	sliceCopy.Status.Notice = ""
+	sliceCopy.Status.RenewalRefusal = nil
+	return true
+}
+
+// noticeExpiry notifies the tenant that the slice is about to expire, once the expiry is within the notice period.
+// It tells whether the notice has been sent.
+func (c *Controller) noticeExpiry(sliceCopy *corev1alpha1.Slice) bool {
+	if sliceCopy.Status.Expiry == nil || sliceCopy.Status.Notice != "" {
+		return false
+	}
+	notice, _ := getExpiryPeriods()
+	if untilNotice := time.Until(sliceCopy.Status.Expiry.Add(-notice)); untilNotice > 0 {
+		c.enqueueSliceAfter(sliceCopy, untilNotice)
+		return false
+	}
+	c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, warningExpiring, messageExpiring, sliceCopy.Status.Expiry.Format(time.RFC3339))
+	c.notifyTenant(sliceCopy, "[EdgeNet] Slice expiring", "slice-expiring", "")
+	sliceCopy.Status.Notice = corev1alpha1.ExpiryNoticeUpcoming
+	return true
+}
+
+// warnExpiry holds the nodes of the expired slice during the grace period. It warns the workloads running on the
+// nodes and notifies the tenant once, then waits for the end of the period. It tells whether the grace period lasts.
+func (c *Controller) warnExpiry(sliceCopy *corev1alpha1.Slice) bool {
+	_, gracePeriod := getExpiryPeriods()
+	release := sliceCopy.Status.Expiry.Add(gracePeriod)
+	untilRelease := time.Until(release)
+	if untilRelease <= 0 {
+		return false
+	}
+	if sliceCopy.Status.Notice != corev1alpha1.ExpiryNoticeExpired {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, warningGracePeriod, messageGracePeriod, release.Format(time.RFC3339))
+		c.warnPods(sliceCopy, release)
+		c.notifyTenant(sliceCopy, "[EdgeNet] Slice expired", "slice-expired", release.Format(time.RFC3339))
+		sliceCopy.Status.Notice = corev1alpha1.ExpiryNoticeExpired
+		c.updateStatus(context.TODO(), sliceCopy)
+	}
+	c.enqueueSliceAfter(sliceCopy, untilRelease)
+	return true
+}
+
+// warnPods records a warning event on the workloads of the others running on the nodes of the slice
+func (c *Controller) warnPods(sliceCopy *corev1alpha1.Slice, release time.Time) {
+	nodeRaw, err := c.nodesLister.List(labels.SelectorFromSet(labels.Set{"edge-net.io/pre-reservation": sliceCopy.GetName()}))
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	for _, nodeRow := range nodeRaw {
+		podRaw, err := c.kubeclientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.namespace!=kube-system,metadata.namespace!=edgenet,spec.nodeName=%s", nodeRow.GetName())})
+		if err != nil {
+			klog.Infoln(err)
+			continue
+		}
+		for i := range podRaw.Items {
+			c.recorder.Eventf(&podRaw.Items[i], corev1.EventTypeWarning, warningGracePeriod, messagePodGracePeriod, sliceCopy.GetName(), nodeRow.GetName(), release.Format(time.RFC3339))
+		}
+	}
+}
+
+// notifyTenant sends an email about the expiry of the slice to the contact of the tenant that claimed it
+func (c *Controller) notifyTenant(sliceCopy *corev1alpha1.Slice, subject, purpose, release string) {
+	if sliceCopy.Spec.ClaimRef == nil {
+		return
+	}
+	namespace, err := c.kubeclientset.CoreV1().Namespaces().Get(context.TODO(), sliceCopy.Spec.ClaimRef.Namespace, metav1.GetOptions{})
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	tenant, err := c.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), namespace.GetLabels()["edge-net.io/tenant"], metav1.GetOptions{})
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	systemNamespace, err := c.kubeclientset.CoreV1().Namespaces().Get(context.TODO(), "kube-system", metav1.GetOptions{})
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+
+	contact := tenant.Spec.Contact
+	content := new(notification.Content)
+	content.Init(contact.FirstName, contact.LastName, contact.Email, subject, string(systemNamespace.GetUID()), []string{contact.Email})
+	content.SliceExpiry = new(notification.SliceExpiry)
+	content.SliceExpiry.Slice = sliceCopy.GetName()
+	content.SliceExpiry.SliceClaim = sliceCopy.Spec.ClaimRef.Name
+	content.SliceExpiry.Namespace = sliceCopy.Spec.ClaimRef.Namespace
+	content.SliceExpiry.Expiry = sliceCopy.Status.Expiry.Format(time.RFC3339)
+	content.SliceExpiry.Release = release
+	if err := content.SendNotification(purpose); err != nil {
+		klog.Infoln(err)
+	}
+}
@@ -0,0 +1,254 @@
+package slice
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRenewal(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	gold := &corev1alpha1.SliceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+		Spec: corev1alpha1.SliceClassSpec{
+			Isolation:   corev1alpha1.IsolationExclusive,
+			MaxDuration: &metav1.Duration{Duration: 24 * time.Hour},
+			MaxRenewals: 1,
+		},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "lip6", Labels: map[string]string{"edge-net.io/tenant": "lip6"}}}
+	// The slice started an hour ago and lasts the maximum duration of its class, a renewal can extend it to 48 hours
+	// from its start at most
+	newObjects := func(claimExpiry time.Time, renewals int) ([]runtime.Object, []runtime.Object) {
+		sliceClaimExpiry := metav1.NewTime(claimExpiry)
+		sliceClaim := &corev1alpha1.SliceClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "lip6", UID: "claim"},
+			Spec:       corev1alpha1.SliceClaimSpec{SliceClassName: "gold", SliceName: "renew", SliceExpiry: &sliceClaimExpiry},
+		}
+		slice := newSlice("renew", "FR", 1, now.Add(23*time.Hour))
+		slice.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Hour)))
+		slice.Spec.SliceClassName = "gold"
+		slice.Spec.ClaimRef = &corev1.ObjectReference{Kind: "SliceClaim", Namespace: "lip6", Name: "claim", UID: "claim"}
+		slice.Status.State = corev1alpha1.StatusReserved
+		slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Ready: true}}
+		slice.Status.Notice = corev1alpha1.ExpiryNoticeUpcoming
+		slice.Status.Renewals = renewals
+		node := newNode("fr-1.edge-net.io", "FR")
+		node.Labels["edge-net.io/pre-reservation"] = "renew"
+		return []runtime.Object{namespace, node}, []runtime.Object{gold, sliceClaim, slice}
+	}
+	refused := func(message string) string {
+		return fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, failureRenewal, message)
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		expiry := now.Add(40 * time.Hour)
+		kubeobjects, edgenetobjects := newObjects(expiry, 0)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("renew")
+		util.Equals(t, expiry.Unix(), sliceCopy.Status.Expiry.Unix())
+		util.Equals(t, 1, sliceCopy.Status.Renewals)
+		util.Equals(t, "", sliceCopy.Status.Notice)
+		util.Equals(t, (*corev1alpha1.SliceRenewalRefusal)(nil), sliceCopy.Status.RenewalRefusal)
+		util.Equals(t, corev1alpha1.StatusReserved, sliceCopy.Status.State)
+		util.Assert(t, hasEvent(f.events(), fmt.Sprintf("%s %s %s", corev1.EventTypeNormal, successRenewed, fmt.Sprintf(messageRenewed, expiry.Format(time.RFC3339), 1))), "no renewed event")
+	})
+	t.Run("shortened", func(t *testing.T) {
+		expiry := now.Add(6 * time.Hour)
+		kubeobjects, edgenetobjects := newObjects(expiry, 0)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("renew")
+		util.Equals(t, expiry.Unix(), sliceCopy.Status.Expiry.Unix())
+		util.Equals(t, 0, sliceCopy.Status.Renewals)
+	})
+
+	cases := map[string]struct {
+		expiry   time.Duration
+		renewals int
+		booked   bool
+		message  string
+	}{
+		"renewal limit":    {40 * time.Hour, 1, false, fmt.Sprintf(messageRenewalLimit, 1)},
+		"maximum duration": {48 * time.Hour, 0, false, fmt.Sprintf(messageRenewalExpiry, "gold")},
+		"booked nodes":     {40 * time.Hour, 0, true, messageRenewalBooked},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			expiry := now.Add(tc.expiry)
+			kubeobjects, edgenetobjects := newObjects(expiry, tc.renewals)
+			if tc.booked {
+				// An advance reservation starting after the current expiry has booked the node
+				booked := newSlice("booked", "FR", 1, now.Add(30*time.Hour))
+				booked.Spec.Start = &metav1.Time{Time: now.Add(24 * time.Hour)}
+				booked.Status.State = corev1alpha1.StatusQueued
+				booked.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io"}}
+				edgenetobjects = append(edgenetobjects, booked)
+			}
+			f := newFixture(t, kubeobjects, edgenetobjects)
+			sliceCopy := f.process("renew")
+			util.Equals(t, now.Add(23*time.Hour).Unix(), sliceCopy.Status.Expiry.Unix())
+			util.Equals(t, tc.renewals, sliceCopy.Status.Renewals)
+			util.Equals(t, tc.message, sliceCopy.Status.RenewalRefusal.Message)
+			util.Equals(t, expiry.Unix(), sliceCopy.Status.RenewalRefusal.Expiry.Unix())
+			util.Assert(t, hasEvent(f.events(), refused(tc.message)), "no renewal failed event")
+
+			// The refusal is reported once
+			sliceCopy = f.process("renew")
+			util.Equals(t, tc.message, sliceCopy.Status.RenewalRefusal.Message)
+			util.Assert(t, !hasEvent(f.events(), refused(tc.message)), "renewal failed event recorded again")
+		})
+	}
+
+	t.Run("built-in class", func(t *testing.T) {
+		// The built-in classes put no limit on the duration, nor on the number of renewals
+		expiry := now.Add(240 * time.Hour)
+		kubeobjects, edgenetobjects := newObjects(expiry, 5)
+		edgenetobjects = edgenetobjects[1:]
+		edgenetobjects[0].(*corev1alpha1.SliceClaim).Spec.SliceClassName = corev1alpha1.SliceClassNode
+		edgenetobjects[1].(*corev1alpha1.Slice).Spec.SliceClassName = corev1alpha1.SliceClassNode
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("renew")
+		util.Equals(t, expiry.Unix(), sliceCopy.Status.Expiry.Unix())
+		util.Equals(t, 6, sliceCopy.Status.Renewals)
+		util.Equals(t, (*corev1alpha1.SliceRenewalRefusal)(nil), sliceCopy.Status.RenewalRefusal)
+	})
+	t.Run("refused again", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(now.Add(48*time.Hour), 0)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		f.process("renew")
+		util.Assert(t, hasEvent(f.events(), refused(fmt.Sprintf(messageRenewalExpiry, "gold"))), "no renewal failed event")
+
+		// The slice claim asks for another expiry, which is refused as well
+		sliceClaim, err := f.edgenetclientset.CoreV1alpha1().SliceClaims("lip6").Get(context.TODO(), "claim", metav1.GetOptions{})
+		util.OK(t, err)
+		expiry := metav1.NewTime(now.Add(72 * time.Hour))
+		sliceClaim.Spec.SliceExpiry = &expiry
+		_, err = f.edgenetclientset.CoreV1alpha1().SliceClaims("lip6").Update(context.TODO(), sliceClaim, metav1.UpdateOptions{})
+		util.OK(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		sliceCopy := f.process("renew")
+		util.Equals(t, expiry.Unix(), sliceCopy.Status.RenewalRefusal.Expiry.Unix())
+		util.Assert(t, hasEvent(f.events(), refused(fmt.Sprintf(messageRenewalExpiry, "gold"))), "no renewal failed event")
+	})
+}
+
+func TestRenewedSliceReconciliation(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	gold := &corev1alpha1.SliceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+		Spec: corev1alpha1.SliceClassSpec{
+			Isolation:   corev1alpha1.IsolationExclusive,
+			MaxDuration: &metav1.Duration{Duration: 24 * time.Hour},
+			MaxRenewals: 2,
+		},
+	}
+	// newObjects returns a slice in reconciliation that started at the given time and has been renewed once, which
+	// lets it last 48 hours from its start
+	newObjects := func(created, start time.Time, advance bool) ([]runtime.Object, []runtime.Object) {
+		slice := newSlice("renewed", "FR", 1, start.Add(40*time.Hour))
+		slice.SetCreationTimestamp(metav1.NewTime(created))
+		if advance {
+			slice.Spec.Start = &metav1.Time{Time: start}
+		}
+		slice.Spec.SliceClassName = "gold"
+		slice.Status.State = corev1alpha1.StatusReconciliation
+		slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Ready: true}}
+		slice.Status.Renewals = 1
+		node := newNode("fr-1.edge-net.io", "FR")
+		node.Labels["edge-net.io/pre-reservation"] = "renewed"
+		return []runtime.Object{node}, []runtime.Object{gold, slice}
+	}
+	limited := fmt.Sprintf("%s %s %s", corev1.EventTypeNormal, successLimited, messageExpiryLimited)
+
+	cases := map[string]struct {
+		created time.Time
+		start   time.Time
+		advance bool
+	}{
+		"slice":                       {now.Add(-30 * time.Hour), now.Add(-30 * time.Hour), false},
+		"started advance reservation": {now.Add(-50 * time.Hour), now.Add(-30 * time.Hour), true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			kubeobjects, edgenetobjects := newObjects(tc.created, tc.start, tc.advance)
+			f := newFixture(t, kubeobjects, edgenetobjects)
+			sliceCopy := f.process("renewed")
+			util.Equals(t, tc.start.Add(40*time.Hour).Unix(), sliceCopy.Status.Expiry.Unix())
+			util.Equals(t, corev1alpha1.StatusReserved, sliceCopy.Status.State)
+			util.Assert(t, !hasEvent(f.events(), limited), "renewed expiry limited")
+		})
+	}
+	t.Run("beyond the renewals", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(now.Add(-30*time.Hour), now.Add(-30*time.Hour), false)
+		edgenetobjects[1].(*corev1alpha1.Slice).Status.Expiry = &metav1.Time{Time: now.Add(30 * time.Hour)}
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("renewed")
+		util.Equals(t, now.Add(18*time.Hour).Unix(), sliceCopy.Status.Expiry.Unix())
+		util.Assert(t, hasEvent(f.events(), limited), "no limited event")
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	// newObjects returns a slice that holds a node and expires in the given time
+	newObjects := func(untilExpiry time.Duration, notice string) ([]runtime.Object, []runtime.Object) {
+		slice := newSlice("expiry", "FR", 1, time.Now().Add(untilExpiry))
+		slice.Status.State = corev1alpha1.StatusReserved
+		slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Ready: true}}
+		slice.Status.Notice = notice
+		node := newNode("fr-1.edge-net.io", "FR")
+		node.Labels["edge-net.io/pre-reservation"] = "expiry"
+		return []runtime.Object{node}, []runtime.Object{slice}
+	}
+	expiring := fmt.Sprintf("%s %s", corev1.EventTypeWarning, warningExpiring)
+	gracePeriod := fmt.Sprintf("%s %s", corev1.EventTypeWarning, warningGracePeriod)
+
+	t.Run("before the notice", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(48*time.Hour, "")
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("expiry")
+		util.Equals(t, "", sliceCopy.Status.Notice)
+		util.Assert(t, !hasEvent(f.events(), expiring), "expiring event recorded")
+	})
+	t.Run("notice", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(2*time.Hour, "")
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("expiry")
+		util.Equals(t, corev1alpha1.ExpiryNoticeUpcoming, sliceCopy.Status.Notice)
+		util.Assert(t, hasEvent(f.events(), expiring), "no expiring event")
+
+		// The tenant is noticed once
+		sliceCopy = f.process("expiry")
+		util.Equals(t, corev1alpha1.ExpiryNoticeUpcoming, sliceCopy.Status.Notice)
+		util.Assert(t, !hasEvent(f.events(), expiring), "expiring event recorded again")
+	})
+	t.Run("grace period", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(-10*time.Second, corev1alpha1.ExpiryNoticeUpcoming)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("expiry")
+		util.Equals(t, corev1alpha1.ExpiryNoticeExpired, sliceCopy.Status.Notice)
+		util.Assert(t, hasEvent(f.events(), gracePeriod), "no grace period event")
+		util.Equals(t, "expiry", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+
+		// The slice holds its node until the end of the grace period, and warns once
+		sliceCopy = f.process("expiry")
+		util.Equals(t, corev1alpha1.ExpiryNoticeExpired, sliceCopy.Status.Notice)
+		util.Assert(t, !hasEvent(f.events(), gracePeriod), "grace period event recorded again")
+		util.Equals(t, "expiry", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+	t.Run("release", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(-2*time.Minute, corev1alpha1.ExpiryNoticeExpired)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		util.Equals(t, (*corev1alpha1.Slice)(nil), f.process("expiry"))
+		util.Assert(t, hasEvent(f.events(), fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, successExpired, messageExpired)), "no expired event")
+		util.Equals(t, "none", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+// checkNodes keeps the nodes of the slice in its status up to date along with their readiness, and replaces those
+// that are not ready when the slice heals automatically. It tells whether the status has changed.
+func (c *Controller) checkNodes(sliceCopy *corev1alpha1.Slice) bool {
+	phase := "pre-reservation"
+	if sliceCopy.Status.State == corev1alpha1.StatusProvisioned {
+		phase = "slice"
+	}
+	nodeRaw, err := c.nodesLister.List(labels.SelectorFromSet(labels.Set{fmt.Sprintf("edge-net.io/%s", phase): sliceCopy.GetName()}))
+	if err != nil {
+		klog.Infoln(err)
+		return false
+	}
+	nodes := make(map[string]*corev1.Node)
+	for _, nodeRow := range nodeRaw {
+		nodes[nodeRow.GetName()] = nodeRow
+	}
+
+	var sliceNodes []corev1alpha1.SliceNode
+	for _, sliceNode := range sliceCopy.Status.Nodes {
+		if node, exists := nodes[sliceNode.Name]; exists {
+			sliceNode.Ready = isNodeReady(node)
+			sliceNodes = append(sliceNodes, sliceNode)
+			delete(nodes, sliceNode.Name)
+		}
+	}
+	// The slices reserved before their status listed the nodes
+	var unlistedNodes []string
+	for nodeName := range nodes {
+		unlistedNodes = append(unlistedNodes, nodeName)
+	}
+	sort.Strings(unlistedNodes)
+	for _, nodeName := range unlistedNodes {
+		sliceNodes = append(sliceNodes, corev1alpha1.SliceNode{Name: nodeName, Reason: messageAlreadyPicked, Ready: isNodeReady(nodes[nodeName])})
+	}
+
+	if sliceCopy.Spec.NodeSelector.AutoHeal {
+		for i, sliceNode := range sliceNodes {
+			if sliceNode.Ready {
+				continue
+			}
+			if replacement, ok := c.replaceNode(sliceCopy, sliceNode.Name, sliceNodes, phase); ok {
+				sliceNodes[i] = replacement
+			}
+		}
+	}
+	isChanged := !reflect.DeepEqual(sliceCopy.Status.Nodes, sliceNodes)
+	sliceCopy.Status.Nodes = sliceNodes
+	return isChanged
+}
+
+// replaceNode picks a ready node to replace the given one with the placement strategy, among the feasible nodes that
+// match the node selector terms the node matches. It moves the labels of the slice from the node to the replacement,
+// and evicts the workloads of the others from the replacement once the slice is provisioned on an exclusive class.
+func (c *Controller) replaceNode(sliceCopy *corev1alpha1.Slice, nodeName string, sliceNodes []corev1alpha1.SliceNode, phase string) (corev1alpha1.SliceNode, bool) {
+	node, err := c.nodesLister.Get(nodeName)
+	if err != nil {
+		klog.Infoln(err)
+		return corev1alpha1.SliceNode{}, false
+	}
+	multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
+	sliceClass, err := multitenancyManager.GetSliceClass(sliceCopy.Spec.SliceClassName)
+	if err != nil {
+		klog.Infoln(err)
+		return corev1alpha1.SliceNode{}, false
+	}
+	classSelector, placement, ok := c.getNodeSelection(sliceCopy, sliceClass)
+	if !ok {
+		return corev1alpha1.SliceNode{}, false
+	}
+	// The replacement matches all the terms that the node matches, or any term if the node does not match them
+	// anymore
+	var terms, matchedTerms []*nodeSelectorTerm
+	for _, nodeSelectorTerm := range sliceCopy.Spec.NodeSelector.Selector.NodeSelectorTerms {
+		term, err := newNodeSelectorTerm(nodeSelectorTerm)
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messageSelectorFailed, err.Error())
+			return corev1alpha1.SliceNode{}, false
+		}
+		terms = append(terms, term)
+		if term.Match(node) {
+			matchedTerms = append(matchedTerms, term)
+		}
+	}
+	matches := func(node *corev1.Node) bool {
+		if len(matchedTerms) == 0 {
+			for _, term := range terms {
+				if term.Match(node) {
+					return true
+				}
+			}
+			return false
+		}
+		for _, term := range matchedTerms {
+			if !term.Match(node) {
+				return false
+			}
+		}
+		return true
+	}
+
+	isListed := make(map[string]bool)
+	for _, sliceNode := range sliceNodes {
+		isListed[sliceNode.Name] = true
+	}
+	nodeRaw, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return corev1alpha1.SliceNode{}, false
+	}
+	bookedNodes := c.getBookedNodes(sliceCopy, time.Now(), sliceCopy.Status.Expiry)
+	var pickedNodes []*corev1.Node
+	var matchingNodes []corev1.Node
+	for _, nodeRow := range nodeRaw {
+		if isListed[nodeRow.GetName()] {
+			if nodeRow.GetName() != nodeName {
+				pickedNodes = append(pickedNodes, nodeRow)
+			}
+			continue
+		}
+		if isNodeReady(nodeRow) && !bookedNodes[nodeRow.GetName()] && classSelector.Match(nodeRow) && matches(nodeRow) {
+			matchingNodes = append(matchingNodes, *nodeRow)
+		}
+	}
+	_, nodeList := c.getFeasibleNodes(sliceCopy, matchingNodes)
+	isFeasible := make(map[string]bool)
+	for _, feasibleNode := range nodeList {
+		isFeasible[feasibleNode] = true
+	}
+	var candidates []*corev1.Node
+	for i, nodeRow := range matchingNodes {
+		if isFeasible[nodeRow.GetName()] {
+			candidates = append(candidates, &matchingNodes[i])
+		}
+	}
+	if len(candidates) == 0 {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureHeal, messageHealFailed, nodeName)
+		return corev1alpha1.SliceNode{}, false
+	}
+	placed, err := placeNodes(placement, pickedNodes, candidates, 1)
+	if err != nil {
+		c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messagePlacement, err.Error())
+		return corev1alpha1.SliceNode{}, false
+	}
+
+	replacement := placed[0]
+	if err := c.patchNode(phase, sliceCopy.GetName(), replacement.Name); err != nil {
+		c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failurePatch, messagePatchFailed)
+		return corev1alpha1.SliceNode{}, false
+	}
+	if err := c.patchNode("return", "", nodeName); err != nil {
+		c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failurePatch, messagePatchFailed)
+	}
+	if phase == "slice" && sliceClass.IsExclusive() {
+		c.evictPods(replacement.Name)
+	}
+	c.recorder.Eventf(sliceCopy, corev1.EventTypeNormal, successHealed, messageHealed, nodeName, replacement.Name)
+	replacement.Reason = fmt.Sprintf("Replaced %s, which was not ready. %s", nodeName, replacement.Reason)
+	replacement.Ready = true
+	return replacement, true
+}
+
+// evictPods deletes the workloads of the others running on the node with a grace period, as provisionSlice does
+func (c *Controller) evictPods(nodeName string) {
+	podRaw, err := c.kubeclientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.namespace!=kube-system,metadata.namespace!=edgenet,spec.nodeName=%s", nodeName)})
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	var gracePeriod int64 = 60
+	for _, podRow := range podRaw.Items {
+		if err := c.kubeclientset.CoreV1().Pods(podRow.GetNamespace()).Delete(context.TODO(), podRow.GetName(), metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}); err != nil {
+			klog.Infoln(err)
+		}
+	}
+}
+
+// isNodeReady tells whether the node reports the ready condition
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
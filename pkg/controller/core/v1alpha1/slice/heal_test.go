@@ -0,0 +1,121 @@
+package slice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestIsNodeReady(t *testing.T) {
+	node := func(conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{Status: corev1.NodeStatus{Conditions: conditions}}
+	}
+	ready := func(status corev1.ConditionStatus) corev1.NodeCondition {
+		return corev1.NodeCondition{Type: corev1.NodeReady, Status: status}
+	}
+	pressure := corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue}
+
+	cases := map[string]struct {
+		node     *corev1.Node
+		expected bool
+	}{
+		"ready":         {node(pressure, ready(corev1.ConditionTrue)), true},
+		"not ready":     {node(ready(corev1.ConditionFalse)), false},
+		"unknown":       {node(ready(corev1.ConditionUnknown)), false},
+		"no conditions": {node(), false},
+		"no readiness":  {node(pressure), false},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			util.Equals(t, tc.expected, isNodeReady(tc.node))
+		})
+	}
+}
+
+func TestHeal(t *testing.T) {
+	// The slice holds the first node in France, the second one is free, and so is the one in Germany
+	newObjects := func(autoHeal bool) ([]runtime.Object, []runtime.Object) {
+		reserved := newNode("fr-1.edge-net.io", "FR")
+		reserved.Labels["edge-net.io/pre-reservation"] = "heal"
+		slice := newSlice("heal", "FR", 1, time.Now().Add(72*time.Hour))
+		slice.Spec.NodeSelector.AutoHeal = autoHeal
+		slice.Status.State = corev1alpha1.StatusReserved
+		slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Reason: "Picked", Ready: true}}
+		return []runtime.Object{reserved, newNode("fr-2.edge-net.io", "FR"), newNode("de-1.edge-net.io", "DE")}, []runtime.Object{slice}
+	}
+	// notReady drives the node to NotReady, and lets the controller know
+	notReady := func(f *fixture, name string) {
+		node := f.node(name)
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}}
+		_, err := f.kubeclientset.CoreV1().Nodes().UpdateStatus(context.TODO(), node, metav1.UpdateOptions{})
+		util.OK(t, err)
+		time.Sleep(100 * time.Millisecond)
+	}
+	notHealed := func(t *testing.T, f *fixture) {
+		sliceCopy := f.process("heal")
+		util.Equals(t, []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Reason: "Picked", Ready: false}}, sliceCopy.Status.Nodes)
+		util.Equals(t, corev1alpha1.StatusReserved, sliceCopy.Status.State)
+		nodeRaw, err := f.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: "edge-net.io/pre-reservation=heal"})
+		util.OK(t, err)
+		util.Equals(t, 1, len(nodeRaw.Items))
+		util.Equals(t, "fr-1.edge-net.io", nodeRaw.Items[0].GetName())
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(true)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		sliceCopy := f.process("heal")
+		util.Equals(t, []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Reason: "Picked", Ready: true}}, sliceCopy.Status.Nodes)
+		util.Equals(t, "none", f.node("fr-2.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+	t.Run("replaced", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(true)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		notReady(f, "fr-1.edge-net.io")
+		sliceCopy := f.process("heal")
+		// The node in Germany does not match the node selector
+		util.Equals(t, []string{"fr-2.edge-net.io"}, sliceNodeNames(sliceCopy))
+		util.Equals(t, true, sliceCopy.Status.Nodes[0].Ready)
+		util.Assert(t, strings.HasPrefix(sliceCopy.Status.Nodes[0].Reason, "Replaced fr-1.edge-net.io, which was not ready."), "unexpected reason: %s", sliceCopy.Status.Nodes[0].Reason)
+		util.Equals(t, corev1alpha1.StatusReserved, sliceCopy.Status.State)
+		util.Equals(t, "heal", f.node("fr-2.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+		util.Equals(t, "none", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+		util.Equals(t, "none", f.node("de-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+		util.Assert(t, hasEvent(f.events(), fmt.Sprintf("%s %s %s", corev1.EventTypeNormal, successHealed, fmt.Sprintf(messageHealed, "fr-1.edge-net.io", "fr-2.edge-net.io"))), "no healed event")
+	})
+	t.Run("no auto heal", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(false)
+		f := newFixture(t, kubeobjects, edgenetobjects)
+		notReady(f, "fr-1.edge-net.io")
+		notHealed(t, f)
+	})
+	t.Run("no node matching the selector", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(true)
+		// Only the node in Germany is free
+		f := newFixture(t, []runtime.Object{kubeobjects[0], kubeobjects[2]}, edgenetobjects)
+		notReady(f, "fr-1.edge-net.io")
+		notHealed(t, f)
+		util.Assert(t, hasEvent(f.events(), fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, failureHeal, fmt.Sprintf(messageHealFailed, "fr-1.edge-net.io"))), "no heal failed event")
+	})
+	t.Run("booked node", func(t *testing.T) {
+		kubeobjects, edgenetobjects := newObjects(true)
+		// An advance reservation starting before the slice expires has booked the free node in France
+		booked := newSlice("booked", "FR", 1, time.Now().Add(96*time.Hour))
+		booked.Spec.Start = &metav1.Time{Time: time.Now().Add(time.Hour)}
+		booked.Status.State = corev1alpha1.StatusQueued
+		booked.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-2.edge-net.io"}}
+		f := newFixture(t, kubeobjects, append(edgenetobjects, booked))
+		notReady(f, "fr-1.edge-net.io")
+		notHealed(t, f)
+		util.Assert(t, hasEvent(f.events(), fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, failureHeal, fmt.Sprintf(messageHealFailed, "fr-1.edge-net.io"))), "no heal failed event")
+	})
+}
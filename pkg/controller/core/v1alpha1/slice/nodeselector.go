@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"fmt"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// nodeSelectorOperators maps the operators of node selector requirements to those of label selectors
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// nodeSelectorTerm matches the nodes against a node selector term the way the scheduler does with node affinity.
+// The match expressions apply to the labels of the node, and the match fields to the name of the node, which is the
+// only field the scheduler supports.
+type nodeSelectorTerm struct {
+	labelSelector labels.Selector
+	matchFields   []corev1.NodeSelectorRequirement
+	empty         bool
+}
+
+// newNodeSelectorTerm parses the term, and returns an error if a requirement is invalid, such as a Gt or Lt
+// requirement whose value is not an integer
+func newNodeSelectorTerm(term corev1.NodeSelectorTerm) (*nodeSelectorTerm, error) {
+	parsedTerm := &nodeSelectorTerm{
+		labelSelector: labels.NewSelector(),
+		matchFields:   term.MatchFields,
+		empty:         len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0,
+	}
+	for _, expression := range term.MatchExpressions {
+		operator, ok := nodeSelectorOperators[expression.Operator]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid node selector operator", expression.Operator)
+		}
+		requirement, err := labels.NewRequirement(expression.Key, operator, expression.Values)
+		if err != nil {
+			return nil, err
+		}
+		parsedTerm.labelSelector = parsedTerm.labelSelector.Add(*requirement)
+	}
+	for _, field := range term.MatchFields {
+		if field.Key != "metadata.name" {
+			return nil, fmt.Errorf("%q is not a supported field, only metadata.name is", field.Key)
+		}
+		if field.Operator != corev1.NodeSelectorOpIn && field.Operator != corev1.NodeSelectorOpNotIn {
+			return nil, fmt.Errorf("%q is not a valid field selector operator, only In and NotIn are", field.Operator)
+		}
+		if len(field.Values) == 0 {
+			return nil, fmt.Errorf("field selector on %s has no values", field.Key)
+		}
+	}
+	return parsedTerm, nil
+}
+
+// Match tells whether the node meets all requirements of the term. A term without requirements matches no node.
+func (t *nodeSelectorTerm) Match(node *corev1.Node) bool {
+	if t.empty {
+		return false
+	}
+	if !t.labelSelector.Matches(labels.Set(node.GetLabels())) {
+		return false
+	}
+	for _, field := range t.matchFields {
+		if contains, _ := util.Contains(field.Values, node.GetName()); contains != (field.Operator == corev1.NodeSelectorOpIn) {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSelector matches the nodes against the terms of a node selector, which are ORed as the scheduler does
+type nodeSelector struct {
+	terms        []*nodeSelectorTerm
+	unrestricted bool
+}
+
+// newNodeSelector parses the terms of the node selector. A nil node selector puts no restriction on the nodes.
+func newNodeSelector(selector *corev1.NodeSelector) (*nodeSelector, error) {
+	if selector == nil {
+		return &nodeSelector{unrestricted: true}, nil
+	}
+	parsedSelector := new(nodeSelector)
+	for _, term := range selector.NodeSelectorTerms {
+		parsedTerm, err := newNodeSelectorTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		parsedSelector.terms = append(parsedSelector.terms, parsedTerm)
+	}
+	return parsedSelector, nil
+}
+
+// Match tells whether the node meets any term of the node selector
+func (s *nodeSelector) Match(node *corev1.Node) bool {
+	if s.unrestricted {
+		return true
+	}
+	for _, term := range s.terms {
+		if term.Match(node) {
+			return true
+		}
+	}
+	return false
+}
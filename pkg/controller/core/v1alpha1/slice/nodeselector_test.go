@@ -0,0 +1,106 @@
+package slice
+
+import (
+	"testing"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeSelectorTerm(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gpu-1.edge-net.io",
+			Labels: map[string]string{
+				"edge-net.io/country-iso": "FR",
+				"gpu-count":               "4",
+				"kernel-version":          "515",
+			},
+		},
+	}
+	requirement := func(key string, operator corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: key, Operator: operator, Values: values}
+	}
+
+	cases := map[string]struct {
+		term     corev1.NodeSelectorTerm
+		expected bool
+	}{
+		"in":                 {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("edge-net.io/country-iso", corev1.NodeSelectorOpIn, "US", "FR")}}, true},
+		"in/other":           {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("edge-net.io/country-iso", corev1.NodeSelectorOpIn, "US")}}, false},
+		"notin":              {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("edge-net.io/country-iso", corev1.NodeSelectorOpNotIn, "US")}}, true},
+		"notin/same":         {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("edge-net.io/country-iso", corev1.NodeSelectorOpNotIn, "FR")}}, false},
+		"notin/missing":      {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("edge-net.io/city", corev1.NodeSelectorOpNotIn, "Paris")}}, true},
+		"exists":             {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpExists)}}, true},
+		"exists/missing":     {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("fpga-count", corev1.NodeSelectorOpExists)}}, false},
+		"doesnotexist":       {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("fpga-count", corev1.NodeSelectorOpDoesNotExist)}}, true},
+		"doesnotexist/found": {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpDoesNotExist)}}, false},
+		"gt":                 {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpGt, "2")}}, true},
+		"gt/equal":           {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpGt, "4")}}, false},
+		"gt/missing":         {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("fpga-count", corev1.NodeSelectorOpGt, "0")}}, false},
+		"lt":                 {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("kernel-version", corev1.NodeSelectorOpLt, "600")}}, true},
+		"lt/greater":         {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("kernel-version", corev1.NodeSelectorOpLt, "500")}}, false},
+		"all":                {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpGt, "2"), requirement("kernel-version", corev1.NodeSelectorOpGt, "515")}}, false},
+		"field/in":           {corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpIn, "gpu-1.edge-net.io")}}, true},
+		"field/notin":        {corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpNotIn, "gpu-1.edge-net.io")}}, false},
+		"field/expression":   {corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpExists)}, MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpNotIn, "gpu-2.edge-net.io")}}, true},
+		"empty":              {corev1.NodeSelectorTerm{}, false},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			term, err := newNodeSelectorTerm(tc.term)
+			util.OK(t, err)
+			util.Equals(t, tc.expected, term.Match(node))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		invalid := map[string]corev1.NodeSelectorTerm{
+			"operator":     {MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", "Equals", "4")}},
+			"gt/text":      {MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpGt, "many")}},
+			"lt/values":    {MatchExpressions: []corev1.NodeSelectorRequirement{requirement("kernel-version", corev1.NodeSelectorOpLt, "500", "600")}},
+			"exists/value": {MatchExpressions: []corev1.NodeSelectorRequirement{requirement("gpu-count", corev1.NodeSelectorOpExists, "4")}},
+			"field/key":    {MatchFields: []corev1.NodeSelectorRequirement{requirement("spec.unschedulable", corev1.NodeSelectorOpIn, "true")}},
+			"field/gt":     {MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpGt, "1")}},
+			"field/values": {MatchFields: []corev1.NodeSelectorRequirement{requirement("metadata.name", corev1.NodeSelectorOpIn)}},
+		}
+		for k, term := range invalid {
+			t.Run(k, func(t *testing.T) {
+				_, err := newNodeSelectorTerm(term)
+				util.Equals(t, true, err != nil)
+			})
+		}
+	})
+}
+
+func TestNodeSelector(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "gpu-1.edge-net.io",
+			Labels: map[string]string{"edge-net.io/country-iso": "FR"},
+		},
+	}
+	term := func(values ...string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "edge-net.io/country-iso", Operator: corev1.NodeSelectorOpIn, Values: values}}}
+	}
+
+	cases := map[string]struct {
+		selector *corev1.NodeSelector
+		expected bool
+	}{
+		"nil":         {nil, true},
+		"no terms":    {&corev1.NodeSelector{}, false},
+		"match":       {&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{term("FR")}}, true},
+		"mismatch":    {&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{term("US")}}, false},
+		"any of them": {&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{term("US"), term("FR")}}, true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			selector, err := newNodeSelector(tc.selector)
+			util.OK(t, err)
+			util.Equals(t, tc.expected, selector.Match(node))
+		})
+	}
+}
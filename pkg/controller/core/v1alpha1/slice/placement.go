@@ -0,0 +1,229 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// lastReservationAnnotation holds the time a slice has last pre-reserved the node
+const lastReservationAnnotation = "edge-net.io/last-reservation"
+
+// defaultTopologyKeys are the node labels that the strategies use when the placement does not set a topology key
+var defaultTopologyKeys = map[string]string{
+	corev1alpha1.PlacementSpread: "edge-net.io/country-iso",
+	corev1alpha1.PlacementPack:   "edge-net.io/city",
+}
+
+// placeNodes picks count nodes among the candidates with the strategy of the placement, taking the nodes already
+// picked for the slice into account. It tells why it has picked each node, and expects enough candidates.
+func placeNodes(placement corev1alpha1.Placement, picked, candidates []*corev1.Node, count int) ([]corev1alpha1.SliceNode, error) {
+	candidates = append([]*corev1.Node(nil), candidates...)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].GetName() < candidates[j].GetName() })
+	topologyKey := placement.TopologyKey
+	if topologyKey == "" {
+		topologyKey = defaultTopologyKeys[placement.Strategy]
+	}
+
+	switch placement.Strategy {
+	case "", corev1alpha1.PlacementRandom:
+		return placeRandomly(candidates, count), nil
+	case corev1alpha1.PlacementSpread:
+		return spread(topologyKey, picked, candidates, count), nil
+	case corev1alpha1.PlacementPack:
+		return pack(topologyKey, picked, candidates, count), nil
+	case corev1alpha1.PlacementDiverse:
+		return diversify(picked, candidates, count), nil
+	case corev1alpha1.PlacementLeastRecentlyReserved:
+		return placeLeastRecentlyReserved(candidates, count), nil
+	}
+	return nil, fmt.Errorf("%q is not a placement strategy", placement.Strategy)
+}
+
+// placeRandomly picks the nodes at random
+func placeRandomly(candidates []*corev1.Node, count int) []corev1alpha1.SliceNode {
+	var placed []corev1alpha1.SliceNode
+	for _, i := range rand.Perm(len(candidates))[:count] {
+		placed = append(placed, corev1alpha1.SliceNode{Name: candidates[i].GetName(), Reason: "Picked at random"})
+	}
+	return placed
+}
+
+// spread picks the nodes one by one from the value of the topology key that has the fewest nodes picked so far
+func spread(topologyKey string, picked, candidates []*corev1.Node, count int) []corev1alpha1.SliceNode {
+	counts := make(map[string]int)
+	for _, node := range picked {
+		counts[node.GetLabels()[topologyKey]]++
+	}
+	var placed []corev1alpha1.SliceNode
+	for len(placed) < count {
+		best := 0
+		for i, node := range candidates {
+			if counts[node.GetLabels()[topologyKey]] < counts[candidates[best].GetLabels()[topologyKey]] {
+				best = i
+			}
+		}
+		value := candidates[best].GetLabels()[topologyKey]
+		placed = append(placed, corev1alpha1.SliceNode{
+			Name:   candidates[best].GetName(),
+			Reason: fmt.Sprintf("Spread over %s, %s had %d nodes picked", topologyKey, describeTopology(value), counts[value]),
+		})
+		counts[value]++
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	return placed
+}
+
+// pack picks the nodes from as few values of the topology key as possible, beginning with the values of the nodes
+// already picked, then with those that have the most candidates. The nodes without the label come last.
+func pack(topologyKey string, picked, candidates []*corev1.Node, count int) []corev1alpha1.SliceNode {
+	groups := make(map[string][]*corev1.Node)
+	var values []string
+	for _, node := range candidates {
+		value := node.GetLabels()[topologyKey]
+		if _, exists := groups[value]; !exists {
+			values = append(values, value)
+		}
+		groups[value] = append(groups[value], node)
+	}
+	counts := make(map[string]int)
+	for _, node := range picked {
+		counts[node.GetLabels()[topologyKey]]++
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		if (values[i] == "") != (values[j] == "") {
+			return values[j] == ""
+		}
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return len(groups[values[i]]) > len(groups[values[j]])
+	})
+
+	var placed []corev1alpha1.SliceNode
+	for _, value := range values {
+		for _, node := range groups[value] {
+			if len(placed) == count {
+				return placed
+			}
+			placed = append(placed, corev1alpha1.SliceNode{
+				Name:   node.GetName(),
+				Reason: fmt.Sprintf("Packed into %s, which holds %d of the feasible nodes", describeTopology(value), len(groups[value])),
+			})
+		}
+	}
+	return placed
+}
+
+// describeTopology returns how the reasons refer to a value of the topology key
+func describeTopology(value string) string {
+	if value == "" {
+		return "the nodes without the topology label"
+	}
+	return value
+}
+
+// diversify picks the nodes one by one, each being the farthest from the nearest node picked so far. Without nodes
+// picked, it begins with the node the farthest from the others. The nodes without coordinates come last.
+func diversify(picked, candidates []*corev1.Node, count int) []corev1alpha1.SliceNode {
+	type point struct{ latitude, longitude float64 }
+	var pickedPoints []point
+	for _, node := range picked {
+		if latitude, longitude, ok := multiprovider.GetNodeCoordinates(node.GetLabels()); ok {
+			pickedPoints = append(pickedPoints, point{latitude, longitude})
+		}
+	}
+	var located []*corev1.Node
+	var points []point
+	var unlocated []*corev1.Node
+	for _, node := range candidates {
+		if latitude, longitude, ok := multiprovider.GetNodeCoordinates(node.GetLabels()); ok {
+			located = append(located, node)
+			points = append(points, point{latitude, longitude})
+		} else {
+			unlocated = append(unlocated, node)
+		}
+	}
+
+	var placed []corev1alpha1.SliceNode
+	for len(placed) < count && len(located) != 0 {
+		best, bestDistance := 0, -1.0
+		for i, candidate := range points {
+			distance := math.Inf(1)
+			if len(pickedPoints) == 0 {
+				distance = 0
+				for _, other := range points {
+					distance += multiprovider.Distance(candidate.latitude, candidate.longitude, other.latitude, other.longitude)
+				}
+			}
+			for _, other := range pickedPoints {
+				distance = math.Min(distance, multiprovider.Distance(candidate.latitude, candidate.longitude, other.latitude, other.longitude))
+			}
+			if distance > bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		reason := "Farthest from the other feasible nodes"
+		if len(pickedPoints) != 0 {
+			reason = fmt.Sprintf("Farthest from the nodes picked, %.0f km from the nearest", bestDistance)
+		}
+		placed = append(placed, corev1alpha1.SliceNode{Name: located[best].GetName(), Reason: reason})
+		pickedPoints = append(pickedPoints, points[best])
+		located = append(located[:best], located[best+1:]...)
+		points = append(points[:best], points[best+1:]...)
+	}
+	for _, node := range unlocated {
+		if len(placed) == count {
+			break
+		}
+		placed = append(placed, corev1alpha1.SliceNode{Name: node.GetName(), Reason: "Location unknown, picked after the located nodes"})
+	}
+	return placed
+}
+
+// placeLeastRecentlyReserved picks the nodes that have never been reserved first, then those reserved the least
+// recently
+func placeLeastRecentlyReserved(candidates []*corev1.Node, count int) []corev1alpha1.SliceNode {
+	reservations := make(map[string]time.Time)
+	for _, node := range candidates {
+		if lastReservation, err := time.Parse(time.RFC3339, node.GetAnnotations()[lastReservationAnnotation]); err == nil {
+			reservations[node.GetName()] = lastReservation
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return reservations[candidates[i].GetName()].Before(reservations[candidates[j].GetName()])
+	})
+
+	var placed []corev1alpha1.SliceNode
+	for _, node := range candidates[:count] {
+		reason := "Never reserved"
+		if lastReservation, ok := reservations[node.GetName()]; ok {
+			reason = fmt.Sprintf("Last reserved at %s", lastReservation.Format(time.RFC3339))
+		}
+		placed = append(placed, corev1alpha1.SliceNode{Name: node.GetName(), Reason: reason})
+	}
+	return placed
+}
@@ -0,0 +1,87 @@
+package slice
+
+import (
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlaceNodes(t *testing.T) {
+	node := func(name, country, asn, city, lat, lon string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					"edge-net.io/country-iso": country,
+					"edge-net.io/asn":         asn,
+					"edge-net.io/city":        city,
+					"edge-net.io/lat":         lat,
+					"edge-net.io/lon":         lon,
+				},
+			},
+		}
+	}
+	paris1 := node("fr-1.edge-net.io", "FR", "3215", "paris", "n48.856600", "e2.352200")
+	paris2 := node("fr-2.edge-net.io", "FR", "12322", "paris", "n48.856600", "e2.352200")
+	paris3 := node("fr-3.edge-net.io", "FR", "3215", "paris", "n48.860000", "e2.340000")
+	lyon := node("fr-4.edge-net.io", "FR", "3215", "lyon", "n45.764000", "e4.835700")
+	newYork := node("us-1.edge-net.io", "US", "7922", "new-york", "n40.712800", "w-74.006000")
+	tokyo := node("jp-1.edge-net.io", "JP", "2516", "tokyo", "n35.676200", "e139.650300")
+	candidates := []*corev1.Node{paris1, paris2, paris3, lyon, newYork, tokyo}
+	names := func(placed []corev1alpha1.SliceNode) []string {
+		var names []string
+		for _, sliceNode := range placed {
+			names = append(names, sliceNode.Name)
+		}
+		return names
+	}
+
+	cases := map[string]struct {
+		placement corev1alpha1.Placement
+		picked    []*corev1.Node
+		count     int
+		expected  []string
+	}{
+		"spread/countries":      {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementSpread}, nil, 3, []string{"fr-1.edge-net.io", "jp-1.edge-net.io", "us-1.edge-net.io"}},
+		"spread/picked":         {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementSpread}, []*corev1.Node{tokyo}, 2, []string{"fr-1.edge-net.io", "us-1.edge-net.io"}},
+		"spread/asns":           {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementSpread, TopologyKey: "edge-net.io/asn"}, nil, 3, []string{"fr-1.edge-net.io", "fr-2.edge-net.io", "jp-1.edge-net.io"}},
+		"pack/city":             {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementPack}, nil, 3, []string{"fr-1.edge-net.io", "fr-2.edge-net.io", "fr-3.edge-net.io"}},
+		"pack/overflow":         {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementPack, TopologyKey: "edge-net.io/country-iso"}, nil, 5, []string{"fr-1.edge-net.io", "fr-2.edge-net.io", "fr-3.edge-net.io", "fr-4.edge-net.io", "jp-1.edge-net.io"}},
+		"pack/picked":           {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementPack}, []*corev1.Node{tokyo}, 1, []string{"jp-1.edge-net.io"}},
+		"diverse":               {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementDiverse}, nil, 3, []string{"jp-1.edge-net.io", "us-1.edge-net.io", "fr-4.edge-net.io"}},
+		"diverse/picked":        {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementDiverse}, []*corev1.Node{newYork, lyon}, 1, []string{"jp-1.edge-net.io"}},
+		"leastrecentlyreserved": {corev1alpha1.Placement{Strategy: corev1alpha1.PlacementLeastRecentlyReserved}, nil, 6, []string{"fr-3.edge-net.io", "fr-4.edge-net.io", "jp-1.edge-net.io", "us-1.edge-net.io", "fr-2.edge-net.io", "fr-1.edge-net.io"}},
+	}
+	paris1.Annotations = map[string]string{lastReservationAnnotation: time.Now().Format(time.RFC3339)}
+	paris2.Annotations = map[string]string{lastReservationAnnotation: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			placed, err := placeNodes(tc.placement, tc.picked, candidates, tc.count)
+			util.OK(t, err)
+			util.Equals(t, tc.expected, names(placed))
+			for _, sliceNode := range placed {
+				util.Equals(t, true, sliceNode.Reason != "")
+			}
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		placed, err := placeNodes(corev1alpha1.Placement{}, nil, candidates, 4)
+		util.OK(t, err)
+		util.Equals(t, 4, len(placed))
+		picked := make(map[string]bool)
+		for _, sliceNode := range placed {
+			picked[sliceNode.Name] = true
+		}
+		util.Equals(t, 4, len(picked))
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := placeNodes(corev1alpha1.Placement{Strategy: "Nearest"}, nil, candidates, 1)
+		util.Equals(t, true, err != nil)
+	})
+}
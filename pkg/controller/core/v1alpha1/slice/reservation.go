@@ -0,0 +1,170 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+// bookNodes picks the nodes of each node selector term for an advance reservation as preReserveNodes does, but among
+// the nodes free for the whole period of the slice, and without labelling them. The status of the slice lists the
+// nodes booked, which the other slices cannot pick for an overlapping period.
+func (c *Controller) bookNodes(sliceCopy *corev1alpha1.Slice, sliceClass *corev1alpha1.SliceClass) bool {
+	classSelector, placement, ok := c.getNodeSelection(sliceCopy, sliceClass)
+	if !ok {
+		return false
+	}
+	nodeRaw, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return false
+	}
+	start := sliceCopy.Spec.Start.Time
+	bookedNodes := c.getBookedNodes(sliceCopy, start, sliceCopy.Status.Expiry)
+	var sliceNodes []corev1alpha1.SliceNode
+	for _, nodeSelectorTerm := range sliceCopy.Spec.NodeSelector.Selector.NodeSelectorTerms {
+		term, err := newNodeSelectorTerm(nodeSelectorTerm)
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messageSelectorFailed, err.Error())
+			return false
+		}
+		var matchingNodes []corev1.Node
+		for _, nodeRow := range nodeRaw {
+			if term.Match(nodeRow) && classSelector.Match(nodeRow) && !bookedNodes[nodeRow.GetName()] {
+				matchingNodes = append(matchingNodes, *c.getNodeAtStart(nodeRow, start))
+			}
+		}
+
+		_, nodeList := c.getFeasibleNodes(sliceCopy, matchingNodes)
+		isFeasible := make(map[string]bool)
+		for _, nodeName := range nodeList {
+			isFeasible[nodeName] = true
+		}
+		var candidates []*corev1.Node
+		for i, nodeRow := range matchingNodes {
+			if isFeasible[nodeRow.GetName()] {
+				candidates = append(candidates, &matchingNodes[i])
+			}
+		}
+		if len(candidates) < sliceCopy.Spec.NodeSelector.Count {
+			return false
+		}
+		placed, err := placeNodes(placement, nil, candidates, sliceCopy.Spec.NodeSelector.Count)
+		if err != nil {
+			c.recorder.Eventf(sliceCopy, corev1.EventTypeWarning, failureSelector, messagePlacement, err.Error())
+			return false
+		}
+		// The nodes booked for this term are not candidates for the next ones
+		for _, sliceNode := range placed {
+			bookedNodes[sliceNode.Name] = true
+		}
+		sliceNodes = append(sliceNodes, placed...)
+	}
+	sliceCopy.Status.Nodes = sliceNodes
+	return true
+}
+
+// reserveBookedNodes labels the nodes booked for the slice as pre-reserved once the slice starts. It fails when any
+// of them is not free anymore.
+func (c *Controller) reserveBookedNodes(sliceCopy *corev1alpha1.Slice) bool {
+	var nodes []corev1.Node
+	for _, sliceNode := range sliceCopy.Status.Nodes {
+		node, err := c.nodesLister.Get(sliceNode.Name)
+		if err != nil {
+			klog.Infoln(err)
+			return false
+		}
+		nodes = append(nodes, *node)
+	}
+	associatedNodeList, nodeList := c.getFeasibleNodes(sliceCopy, nodes)
+	isFree := make(map[string]bool)
+	for _, nodeName := range append(associatedNodeList, nodeList...) {
+		isFree[nodeName] = true
+	}
+	for _, sliceNode := range sliceCopy.Status.Nodes {
+		if !isFree[sliceNode.Name] {
+			return false
+		}
+	}
+
+	for i, sliceNode := range sliceCopy.Status.Nodes {
+		if err := c.patchNode("pre-reservation", sliceCopy.GetName(), sliceNode.Name); err != nil {
+			c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failurePatch, messagePatchFailed)
+			for _, patchedNode := range sliceCopy.Status.Nodes[:i] {
+				if err := c.patchNode("return", "", patchedNode.Name); err != nil {
+					c.recorder.Event(sliceCopy, corev1.EventTypeWarning, failurePatch, messagePatchFailed)
+				}
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// getBookedNodes returns the nodes that the other queued slices have booked for a period overlapping with the one
+// from start to end
+func (c *Controller) getBookedNodes(sliceCopy *corev1alpha1.Slice, start time.Time, end *metav1.Time) map[string]bool {
+	bookedNodes := make(map[string]bool)
+	sliceRaw, err := c.slicesLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return bookedNodes
+	}
+	for _, sliceRow := range sliceRaw {
+		if sliceRow.GetName() == sliceCopy.GetName() || sliceRow.Status.State != corev1alpha1.StatusQueued || sliceRow.Spec.Start == nil {
+			continue
+		}
+		if overlaps(start, end, sliceRow.Spec.Start.Time, sliceRow.Status.Expiry) {
+			for _, sliceNode := range sliceRow.Status.Nodes {
+				bookedNodes[sliceNode.Name] = true
+			}
+		}
+	}
+	return bookedNodes
+}
+
+// getNodeAtStart returns a copy of the node as it will be at the start of an advance reservation, that is, free if
+// the slice holding the node expires by then
+func (c *Controller) getNodeAtStart(node *corev1.Node, start time.Time) *corev1.Node {
+	nodeCopy := node.DeepCopy()
+	nodeLabels := nodeCopy.GetLabels()
+	holder := nodeLabels["edge-net.io/slice"]
+	if holder == "" || holder == "none" {
+		holder = nodeLabels["edge-net.io/pre-reservation"]
+	}
+	if holder == "" || holder == "none" {
+		return nodeCopy
+	}
+	if slice, err := c.slicesLister.Get(holder); err == nil && slice.Status.Expiry != nil && !slice.Status.Expiry.After(start) {
+		nodeLabels["edge-net.io/access"] = "public"
+		nodeLabels["edge-net.io/slice"] = "none"
+		nodeLabels["edge-net.io/pre-reservation"] = "none"
+	}
+	return nodeCopy
+}
+
+// overlaps tells whether two periods overlap, a period without an end lasting forever
+func overlaps(start time.Time, end *metav1.Time, otherStart time.Time, otherEnd *metav1.Time) bool {
+	return (end == nil || otherStart.Before(end.Time)) && (otherEnd == nil || start.Before(otherEnd.Time))
+}
@@ -0,0 +1,138 @@
+package slice
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestOverlaps(t *testing.T) {
+	tuesday := time.Date(2023, time.June, 6, 9, 0, 0, 0, time.UTC)
+	at := func(hours int) *metav1.Time {
+		end := metav1.NewTime(tuesday.Add(time.Duration(hours) * time.Hour))
+		return &end
+	}
+
+	cases := map[string]struct {
+		start      time.Time
+		end        *metav1.Time
+		otherStart time.Time
+		otherEnd   *metav1.Time
+		expected   bool
+	}{
+		"same":           {tuesday, at(8), tuesday, at(8), true},
+		"within":         {tuesday, at(8), tuesday.Add(2 * time.Hour), at(4), true},
+		"across start":   {tuesday, at(8), tuesday.Add(-time.Hour), at(1), true},
+		"before":         {tuesday, at(8), tuesday.Add(-2 * time.Hour), at(-1), false},
+		"back to back":   {tuesday, at(8), tuesday.Add(8 * time.Hour), at(10), false},
+		"after":          {tuesday, at(8), tuesday.Add(24 * time.Hour), at(32), false},
+		"endless":        {tuesday, nil, tuesday.Add(24 * time.Hour), at(32), true},
+		"other endless":  {tuesday, at(8), tuesday.Add(-24 * time.Hour), nil, true},
+		"endless before": {tuesday, at(8), tuesday.Add(8 * time.Hour), nil, false},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			util.Equals(t, tc.expected, overlaps(tc.start, tc.end, tc.otherStart, tc.otherEnd))
+			util.Equals(t, tc.expected, overlaps(tc.otherStart, tc.otherEnd, tc.start, tc.end))
+		})
+	}
+}
+
+func TestAdvanceReservation(t *testing.T) {
+	now := time.Now()
+	// A reservation from the first to the third hour has booked the first node
+	booked := newSlice("booked", "FR", 1, now.Add(3*time.Hour))
+	booked.Spec.Start = &metav1.Time{Time: now.Add(time.Hour)}
+	booked.Status.State = corev1alpha1.StatusQueued
+	booked.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io"}}
+
+	cases := map[string]struct {
+		start    time.Duration
+		count    int
+		state    string
+		expected []string
+	}{
+		"overlapping, too many nodes": {2 * time.Hour, 2, corev1alpha1.StatusFailed, nil},
+		"overlapping":                 {2 * time.Hour, 1, corev1alpha1.StatusQueued, []string{"fr-2.edge-net.io"}},
+		"back to back":                {3 * time.Hour, 2, corev1alpha1.StatusQueued, []string{"fr-1.edge-net.io", "fr-2.edge-net.io"}},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			slice := newSlice("advance", "FR", tc.count, now.Add(tc.start+2*time.Hour))
+			slice.Spec.Start = &metav1.Time{Time: now.Add(tc.start)}
+			f := newFixture(t,
+				[]runtime.Object{newNode("fr-1.edge-net.io", "FR"), newNode("fr-2.edge-net.io", "FR")},
+				[]runtime.Object{booked.DeepCopy(), slice})
+
+			sliceCopy := f.process(slice.GetName())
+			util.Equals(t, tc.state, sliceCopy.Status.State)
+			if tc.state == corev1alpha1.StatusFailed {
+				util.Equals(t, messageBookingFailed, sliceCopy.Status.Message)
+				return
+			}
+			util.Equals(t, fmt.Sprintf(messageQueued, slice.Spec.Start.Format(time.RFC3339)), sliceCopy.Status.Message)
+			nodeNames := sliceNodeNames(sliceCopy)
+			sort.Strings(nodeNames)
+			util.Equals(t, tc.expected, nodeNames)
+			// Booking a node does not reserve it before the start
+			for _, nodeName := range nodeNames {
+				util.Equals(t, "none", f.node(nodeName).GetLabels()["edge-net.io/pre-reservation"])
+			}
+		})
+	}
+}
+
+func TestAdvanceReservationStart(t *testing.T) {
+	queued := func(start time.Time) *corev1alpha1.Slice {
+		slice := newSlice("advance", "FR", 1, start.Add(2*time.Hour))
+		slice.Spec.Start = &metav1.Time{Time: start}
+		slice.Status.State = corev1alpha1.StatusQueued
+		slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io"}}
+		return slice
+	}
+
+	t.Run("before the start", func(t *testing.T) {
+		f := newFixture(t, []runtime.Object{newNode("fr-1.edge-net.io", "FR")}, []runtime.Object{queued(time.Now().Add(time.Hour))})
+		sliceCopy := f.process("advance")
+		util.Equals(t, corev1alpha1.StatusQueued, sliceCopy.Status.State)
+		util.Equals(t, "none", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+	t.Run("reserved at the start", func(t *testing.T) {
+		f := newFixture(t, []runtime.Object{newNode("fr-1.edge-net.io", "FR")}, []runtime.Object{queued(time.Now().Add(-time.Second))})
+		sliceCopy := f.process("advance")
+		util.Equals(t, corev1alpha1.StatusReserved, sliceCopy.Status.State)
+		util.Equals(t, messageReserved, sliceCopy.Status.Message)
+		util.Equals(t, "advance", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+	t.Run("booking lost", func(t *testing.T) {
+		node := newNode("fr-1.edge-net.io", "FR")
+		node.Labels["edge-net.io/pre-reservation"] = "other"
+		f := newFixture(t, []runtime.Object{node}, []runtime.Object{queued(time.Now().Add(-time.Second))})
+		sliceCopy := f.process("advance")
+		util.Equals(t, corev1alpha1.StatusFailed, sliceCopy.Status.State)
+		util.Equals(t, messageBookingLost, sliceCopy.Status.Message)
+		util.Equals(t, "other", f.node("fr-1.edge-net.io").GetLabels()["edge-net.io/pre-reservation"])
+	})
+}
+
+func TestReservationRelease(t *testing.T) {
+	// The slice expired long enough ago for the grace period to be over
+	slice := newSlice("expired", "FR", 1, time.Now().Add(-time.Hour))
+	slice.Status.State = corev1alpha1.StatusReserved
+	slice.Status.Nodes = []corev1alpha1.SliceNode{{Name: "fr-1.edge-net.io", Ready: true}}
+	node := newNode("fr-1.edge-net.io", "FR")
+	node.Labels["edge-net.io/pre-reservation"] = slice.GetName()
+	f := newFixture(t, []runtime.Object{node}, []runtime.Object{slice})
+
+	util.Equals(t, (*corev1alpha1.Slice)(nil), f.process(slice.GetName()))
+	// Deleting the slice returns its nodes
+	util.Equals(t, "none", f.node(node.GetName()).GetLabels()["edge-net.io/pre-reservation"])
+	util.Equals(t, "public", f.node(node.GetName()).GetLabels()["edge-net.io/access"])
+}
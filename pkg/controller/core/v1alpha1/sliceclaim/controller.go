@@ -59,6 +59,8 @@ const (
 	failureBound         = "Already Bound"
 	failureBinding       = "Binding Failed"
 	failureCreation      = "Creation Failed"
+	failureClass         = "Class Failed"
+	failurePeriod        = "Period Invalid"
 	pendingSlice         = "Not Bound"
 
 	messageResourceSynced = "Slice claim synced successfully"
@@ -70,6 +72,11 @@ const (
 	messageBoundAlready   = "Slice is bound to another claim already"
 	messageBindingFailed  = "Slice binding failed"
 	messageCreationFailed = "Slice creation failed"
+	messageClassFailed    = "Slice class %s cannot be found"
+	messageClassForbidden = "Slice class %s is not available to the tenant"
+	messageExpiryExceeded = "Expiry exceeds the maximum duration of slice class %s"
+	messagePeriodInvalid  = "Expiry must come after the start of the reservation"
+	messageQueued         = "Queued until the reservation starts at %s"
 	messageWaiting        = "Waiting for the slice"
 	messageReconciliation = "Reconciliation in progress"
 )
@@ -334,7 +341,7 @@ func (c *Controller) processSliceClaim(sliceclaimCopy *corev1alpha1.SliceClaim)
 				c.updateStatus(context.TODO(), sliceclaimCopy)
 				return
 			}
-		case corev1alpha1.StatusRequested:
+		case corev1alpha1.StatusRequested, corev1alpha1.StatusQueued:
 			if _, isSufficient := c.checkResourceAllocation(sliceclaimCopy, fmt.Sprintf("%s-quota", namespaceLabels["edge-net.io/kind"])); !isSufficient {
 				return
 			}
@@ -344,6 +351,11 @@ func (c *Controller) processSliceClaim(sliceclaimCopy *corev1alpha1.SliceClaim)
 					sliceclaimCopy.Status.State = corev1alpha1.StatusBound
 					sliceclaimCopy.Status.Message = messageBound
 					c.updateStatus(context.TODO(), sliceclaimCopy)
+				} else if slice.Status.State == corev1alpha1.StatusQueued && sliceclaimCopy.Status.State != corev1alpha1.StatusQueued {
+					c.recorder.Eventf(sliceclaimCopy, corev1.EventTypeNormal, corev1alpha1.StatusQueued, messageQueued, slice.Spec.Start.Format(time.RFC3339))
+					sliceclaimCopy.Status.State = corev1alpha1.StatusQueued
+					sliceclaimCopy.Status.Message = fmt.Sprintf(messageQueued, slice.Spec.Start.Format(time.RFC3339))
+					c.updateStatus(context.TODO(), sliceclaimCopy)
 				}
 				return
 			}
@@ -371,7 +383,7 @@ func (c *Controller) processSliceClaim(sliceclaimCopy *corev1alpha1.SliceClaim)
 			}
 
 			if strings.EqualFold(c.provisioning, corev1alpha1.DynamicStr) {
-				if isCreated := c.createSlice(sliceclaimCopy.Spec.SliceName, sliceclaimCopy.Spec.SliceClassName, sliceclaimCopy.Spec.NodeSelector, sliceclaimCopy.MakeObjectReference(), sliceclaimCopy.Spec.SliceExpiry); isCreated {
+				if isCreated := c.createSlice(sliceclaimCopy.Spec.SliceName, sliceclaimCopy.Spec.SliceClassName, sliceclaimCopy.Spec.NodeSelector, sliceclaimCopy.MakeObjectReference(), sliceclaimCopy.Spec.Start, sliceclaimCopy.Spec.SliceExpiry); isCreated {
 					c.recorder.Event(sliceclaimCopy, corev1.EventTypeNormal, successClaimed, messageClaimed)
 					sliceclaimCopy.Status.State = corev1alpha1.StatusRequested
 					sliceclaimCopy.Status.Message = messageWaiting
@@ -387,6 +399,16 @@ func (c *Controller) processSliceClaim(sliceclaimCopy *corev1alpha1.SliceClaim)
 			if _, isSufficient := c.checkResourceAllocation(sliceclaimCopy, fmt.Sprintf("%s-quota", namespaceLabels["edge-net.io/kind"])); !isSufficient {
 				return
 			}
+			if isEligible := c.checkSliceClass(sliceclaimCopy, namespaceLabels["edge-net.io/tenant"]); !isEligible {
+				return
+			}
+			if sliceclaimCopy.Spec.Start != nil && sliceclaimCopy.Spec.SliceExpiry != nil && !sliceclaimCopy.Spec.SliceExpiry.After(sliceclaimCopy.Spec.Start.Time) {
+				c.recorder.Event(sliceclaimCopy, corev1.EventTypeWarning, failurePeriod, messagePeriodInvalid)
+				sliceclaimCopy.Status.State = corev1alpha1.StatusFailed
+				sliceclaimCopy.Status.Message = messagePeriodInvalid
+				c.updateStatus(context.TODO(), sliceclaimCopy)
+				return
+			}
 			c.recorder.Event(sliceclaimCopy, corev1.EventTypeWarning, pendingSlice, messageWaiting)
 			sliceclaimCopy.Status.State = corev1alpha1.StatusPending
 			sliceclaimCopy.Status.Message = messageWaiting
@@ -424,12 +446,39 @@ func (c *Controller) checkResourceAllocation(sliceclaimCopy *corev1alpha1.SliceC
 	return true, true
 }
 
-func (c *Controller) createSlice(sliceName, sliceclaimClass string, sliceclaimNodeSelector corev1alpha1.NodeSelector, sliceclaimRef *corev1.ObjectReference, expiry *metav1.Time) bool {
+// checkSliceClass verifies that the tenant can claim a slice of the class, and that the claim does not ask for a slice
+// that lasts longer than the class allows from its start
+func (c *Controller) checkSliceClass(sliceclaimCopy *corev1alpha1.SliceClaim, tenant string) bool {
+	multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
+	sliceClass, err := multitenancyManager.GetSliceClass(sliceclaimCopy.Spec.SliceClassName)
+	start := time.Now()
+	if sliceclaimCopy.Spec.Start != nil && sliceclaimCopy.Spec.Start.After(start) {
+		start = sliceclaimCopy.Spec.Start.Time
+	}
+	var message string
+	if err != nil {
+		message = fmt.Sprintf(messageClassFailed, sliceclaimCopy.Spec.SliceClassName)
+	} else if !sliceClass.IsAllowed(tenant) {
+		message = fmt.Sprintf(messageClassForbidden, sliceclaimCopy.Spec.SliceClassName)
+	} else if maxExpiry := sliceClass.GetMaxExpiry(start); maxExpiry != nil && sliceclaimCopy.Spec.SliceExpiry != nil && sliceclaimCopy.Spec.SliceExpiry.After(maxExpiry.Time) {
+		message = fmt.Sprintf(messageExpiryExceeded, sliceclaimCopy.Spec.SliceClassName)
+	} else {
+		return true
+	}
+	c.recorder.Event(sliceclaimCopy, corev1.EventTypeWarning, failureClass, message)
+	sliceclaimCopy.Status.State = corev1alpha1.StatusFailed
+	sliceclaimCopy.Status.Message = message
+	c.updateStatus(context.TODO(), sliceclaimCopy)
+	return false
+}
+
+func (c *Controller) createSlice(sliceName, sliceclaimClass string, sliceclaimNodeSelector corev1alpha1.NodeSelector, sliceclaimRef *corev1.ObjectReference, start, expiry *metav1.Time) bool {
 	slice := new(corev1alpha1.Slice)
 	slice.SetName(sliceName)
 	slice.Spec.SliceClassName = sliceclaimClass
 	slice.Spec.NodeSelector = sliceclaimNodeSelector
 	slice.Spec.ClaimRef = sliceclaimRef
+	slice.Spec.Start = start
 	slice.Status.Expiry = expiry
 	if _, err := c.edgenetclientset.CoreV1alpha1().Slices().Create(context.TODO(), slice, metav1.CreateOptions{}); err == nil {
 		return true
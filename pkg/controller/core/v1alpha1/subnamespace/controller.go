@@ -85,6 +85,7 @@ const (
 	messageCreationFail        = "Subsidiary namespace cannot be created"
 	messageNSUpdateFail        = "Subsidiary namespace cannot be updated"
 	messageInheritanceFail     = "Inheritance from parent to child failed"
+	messageNetworkProfileFail  = "Network profile of the tenant cannot be applied"
 	messageCollision           = "Name is not available. Please choose another one."
 	messageSubnamespaceDeleted = "Last created child subnamespace has been deleted due to insufficient quota "
 	messageParentQuotaShortage = "Insufficient quota at the parent"
@@ -146,7 +147,8 @@ func NewController(
 	secretInformer coreinformers.SecretInformer,
 	configmapInformer coreinformers.ConfigMapInformer,
 	serviceaccountInformer coreinformers.ServiceAccountInformer,
-	subnamespaceInformer informers.SubNamespaceInformer) *Controller {
+	subnamespaceInformer informers.SubNamespaceInformer,
+	baselineEgress bool) *Controller {
 
 	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
 	klog.Info("Creating event broadcaster")
@@ -156,6 +158,7 @@ func NewController(
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
 
 	multitenancyManager := multitenancy.NewManager(kubeclientset, edgenetclientset)
+	multitenancyManager.SetBaselineEgress(baselineEgress)
 
 	controller := &Controller{
 		kubeclientset:         kubeclientset,
@@ -522,6 +525,9 @@ func (c *Controller) processSubNamespace(subnamespaceCopy *corev1alpha1.SubNames
 				if isInherited := c.handleInheritance(subnamespaceCopy, childNameHashed); !isInherited {
 					return
 				}
+				if isApplied := c.applyNetworkProfile(subnamespaceCopy, parentNamespaceLabels, childNameHashed); !isApplied {
+					return
+				}
 			}
 			c.recorder.Event(subnamespaceCopy, corev1.EventTypeNormal, corev1alpha1.StatusEstablished, messageEstablished)
 			subnamespaceCopy.Status.State = corev1alpha1.StatusEstablished
@@ -601,7 +607,7 @@ func (c *Controller) processSubNamespace(subnamespaceCopy *corev1alpha1.SubNames
 			c.updateStatus(context.TODO(), subnamespaceCopy)
 		case corev1alpha1.StatusPartitioned:
 			ownerReferences := []metav1.OwnerReference{multitenancy.MakeOwnerReferenceForNamespace(parentNamespace)}
-			if isCreated := c.makeSubsidiaryNamespace(subnamespaceCopy, parentNamespaceLabels["edge-net.io/tenant"], childNameHashed, parentNamespace.GetAnnotations(), ownerReferences); !isCreated {
+			if isCreated := c.makeSubsidiaryNamespace(subnamespaceCopy, parentNamespaceLabels, childNameHashed, parentNamespace.GetAnnotations(), ownerReferences); !isCreated {
 				return
 			}
 			c.recorder.Event(subnamespaceCopy, corev1.EventTypeNormal, corev1alpha1.StatusPartitioned, messageCreation)
@@ -673,6 +679,15 @@ func (c *Controller) processSubNamespace(subnamespaceCopy *corev1alpha1.SubNames
 }
 
 func (c *Controller) reconcile(subnamespaceCopy *corev1alpha1.SubNamespace, parentNamespace *corev1.Namespace, childNameHashed string) {
+	if subnamespaceCopy.Spec.Workspace != nil {
+		// The network profile of the tenant goes back in place if the child namespace lost it
+		if profile, err := c.getNetworkProfile(parentNamespace.GetLabels()["edge-net.io/tenant"]); err == nil {
+			if _, err := c.kubeclientset.NetworkingV1().NetworkPolicies(childNameHashed).Get(context.TODO(), profile, metav1.GetOptions{}); err != nil {
+				subnamespaceCopy.Status.State = corev1alpha1.StatusQuotaSet
+				subnamespaceCopy.Status.Message = messageReconciliation
+			}
+		}
+	}
 	if subnamespaceCopy.GetResourceAllocation() != nil {
 		if _, isQuotaSufficient, isReconciled := c.reconcileWithChildQuota(subnamespaceCopy, childNameHashed); !isReconciled || !isQuotaSufficient {
 			subnamespaceCopy.Status.State = corev1alpha1.StatusSubnamespaceCreated
@@ -936,7 +951,8 @@ func (c *Controller) returnParentResourceQuota(subnamespaceCopy *corev1alpha1.Su
 	return true
 }
 
-func (c *Controller) makeSubsidiaryNamespace(subnamespaceCopy *corev1alpha1.SubNamespace, tenant, childNameHashed string, parentAnnotations map[string]string, ownerReferences []metav1.OwnerReference) bool {
+func (c *Controller) makeSubsidiaryNamespace(subnamespaceCopy *corev1alpha1.SubNamespace, parentNamespaceLabels map[string]string, childNameHashed string, parentAnnotations map[string]string, ownerReferences []metav1.OwnerReference) bool {
+	tenant := parentNamespaceLabels["edge-net.io/tenant"]
 	var annotations map[string]string
 	if parentAnnotations != nil {
 		if value, elementExists := parentAnnotations["scheduler.alpha.kubernetes.io/node-selector"]; elementExists {
@@ -952,7 +968,9 @@ func (c *Controller) makeSubsidiaryNamespace(subnamespaceCopy *corev1alpha1.SubN
 	}
 	switch subnamespaceCopy.GetMode() {
 	case "workspace":
+		// The tenant and cluster UIDs let the network policies of the tenant select the child namespace
 		labels := map[string]string{"edge-net.io/generated": "true", "edge-net.io/kind": "sub", "edge-net.io/tenant": tenant,
+			"edge-net.io/tenant-uid": parentNamespaceLabels["edge-net.io/tenant-uid"], "edge-net.io/cluster-uid": parentNamespaceLabels["edge-net.io/cluster-uid"],
 			"edge-net.io/owner": subnamespaceCopy.GetName(), "edge-net.io/parent-namespace": subnamespaceCopy.GetNamespace()}
 		childNamespaceObj := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: childNameHashed, OwnerReferences: ownerReferences}}
 		childNamespaceObj.SetName(childNameHashed)
@@ -1015,6 +1033,10 @@ func (c *Controller) makeSubsidiaryNamespace(subnamespaceCopy *corev1alpha1.SubN
 		tenantRequest.SetLabels(labels)
 		tenantRequest.SetOwnerReferences(ownerReferences)
 		tenantRequest.Spec.Contact = subnamespaceCopy.Spec.Subtenant.Owner
+		// Subtenants are isolated the same way as their parent tenant
+		if profile, err := c.getNetworkProfile(tenant); err == nil {
+			tenantRequest.Spec.NetworkProfile = profile
+		}
 		if err := c.multitenancyManager.CreateTenant(tenantRequest); err != nil {
 			if errors.IsAlreadyExists(err) {
 				if subtenant, err := c.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), childNameHashed, metav1.GetOptions{}); err == nil {
@@ -1044,6 +1066,35 @@ func (c *Controller) makeSubsidiaryNamespace(subnamespaceCopy *corev1alpha1.SubN
 	return true
 }
 
+// applyNetworkProfile enforces the network profile of the tenant in the child namespace
+func (c *Controller) applyNetworkProfile(subnamespaceCopy *corev1alpha1.SubNamespace, parentNamespaceLabels map[string]string, childNameHashed string) bool {
+	tenant, err := c.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), parentNamespaceLabels["edge-net.io/tenant"], metav1.GetOptions{})
+	if err == nil {
+		err = c.multitenancyManager.ApplyNetworkProfile(childNameHashed, tenant, parentNamespaceLabels["edge-net.io/cluster-uid"])
+	}
+	if err != nil {
+		c.recorder.Event(subnamespaceCopy, corev1.EventTypeWarning, failureApplied, messageNetworkProfileFail)
+		subnamespaceCopy.Status.State = corev1alpha1.StatusFailed
+		subnamespaceCopy.Status.Message = messageNetworkProfileFail
+		c.updateStatus(context.TODO(), subnamespaceCopy)
+		klog.Infoln(err)
+		return false
+	}
+	return true
+}
+
+// getNetworkProfile returns the network profile of the tenant, or baseline if the namespace is not local to any tenant
+func (c *Controller) getNetworkProfile(tenant string) (string, error) {
+	tenantObj, err := c.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), tenant, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return corev1alpha1.NetworkProfileBaseline, nil
+		}
+		return "", err
+	}
+	return tenantObj.GetNetworkProfile(), nil
+}
+
 func (c *Controller) handleInheritance(subnamespaceCopy *corev1alpha1.SubNamespace, childNamespace string) bool {
 	done := true
 	if subnamespaceCopy.Spec.Workspace.Inheritance["rbac"] {
@@ -1155,9 +1206,11 @@ func (c *Controller) handleInheritance(subnamespaceCopy *corev1alpha1.SubNamespa
 			for k, v := range childItems {
 				inheritance.Child[k] = v.DeepCopy()
 			}
-			inheritance.Parent = make([]interface{}, len(parentRaw.Items))
-			for k, v := range parentRaw.Items {
-				inheritance.Parent[k] = v.DeepCopy()
+			// Network profile policies are enforced per namespace rather than copied from the parent
+			for _, v := range parentRaw.Items {
+				if _, isProfile := v.GetLabels()[multitenancy.NetworkProfileLabel]; !isProfile {
+					inheritance.Parent = append(inheritance.Parent, v.DeepCopy())
+				}
 			}
 			createList, updateList, deleteList := inheritance.GetOperationList()
 			if len(createList) > 0 {
@@ -64,7 +64,8 @@ func TestMain(m *testing.M) {
 		kubeInformerFactory.Core().V1().Secrets(),
 		kubeInformerFactory.Core().V1().ConfigMaps(),
 		kubeInformerFactory.Core().V1().ServiceAccounts(),
-		edgenetInformerFactory.Core().V1alpha1().SubNamespaces())
+		edgenetInformerFactory.Core().V1alpha1().SubNamespaces(),
+		false)
 
 	edgenetInformerFactory.Start(stopCh)
 
@@ -483,3 +484,44 @@ func TestQuota(t *testing.T) {
 	_, err = kubeclientset.CoreV1().Namespaces().Get(context.TODO(), childName3, metav1.GetOptions{})
 	util.Equals(t, true, errors.IsNotFound(err))
 }
+
+func TestNetworkProfile(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	tenant, err := edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), g.tenantObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	tenant.Spec.NetworkProfile = corev1alpha.NetworkProfileRestricted
+	_, err = edgenetclientset.CoreV1alpha1().Tenants().Update(context.TODO(), tenant, metav1.UpdateOptions{})
+	util.OK(t, err)
+	defer func() {
+		tenant.Spec.NetworkProfile = ""
+		edgenetclientset.CoreV1alpha1().Tenants().Update(context.TODO(), tenant, metav1.UpdateOptions{})
+	}()
+	// The policy enforcing the profile of the tenant in the parent must not be copied as is
+	multitenancyManager := multitenancy.NewManager(kubeclientset, edgenetclientset)
+	parentNetworkPolicy := multitenancyManager.MakeNetworkPolicy(g.tenantObj.GetName(), g.tenantObj, "")
+	kubeclientset.NetworkingV1().NetworkPolicies(g.tenantObj.GetName()).Create(context.TODO(), parentNetworkPolicy, metav1.CreateOptions{})
+	defer kubeclientset.NetworkingV1().NetworkPolicies(g.tenantObj.GetName()).Delete(context.TODO(), parentNetworkPolicy.GetName(), metav1.DeleteOptions{})
+
+	subnamespace := g.subNamespaceObj.DeepCopy()
+	subnamespace.SetName("network-profile")
+	subnamespace.SetUID("network-profile")
+	subnamespace.Spec.Workspace.ResourceAllocation["cpu"] = resource.MustParse("1000m")
+	subnamespace.Spec.Workspace.ResourceAllocation["memory"] = resource.MustParse("1Gi")
+	childName := subnamespace.GenerateChildName("")
+	_, err = edgenetclientset.CoreV1alpha1().SubNamespaces(g.tenantObj.GetName()).Create(context.TODO(), subnamespace, metav1.CreateOptions{})
+	util.OK(t, err)
+	defer edgenetclientset.CoreV1alpha1().SubNamespaces(g.tenantObj.GetName()).Delete(context.TODO(), subnamespace.GetName(), metav1.DeleteOptions{})
+	time.Sleep(750 * time.Millisecond)
+
+	networkPolicy, err := kubeclientset.NetworkingV1().NetworkPolicies(childName).Get(context.TODO(), corev1alpha.NetworkProfileRestricted, metav1.GetOptions{})
+	util.OK(t, err)
+	if err == nil {
+		util.Equals(t, multitenancyManager.MakeNetworkPolicy(childName, tenant, "").Spec, networkPolicy.Spec)
+	}
+	_, err = kubeclientset.NetworkingV1().NetworkPolicies(childName).Get(context.TODO(), corev1alpha.NetworkProfileBaseline, metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, err = kubeclientset.NetworkingV1().NetworkPolicies(childName).Get(context.TODO(), "edgenet-test", metav1.GetOptions{})
+	util.OK(t, err)
+}
@@ -18,7 +18,9 @@ package tenant
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
@@ -27,14 +29,15 @@ import (
 	edgenetscheme "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/scheme"
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/core/v1alpha1"
 	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
 
 	antreav1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
 	antrea "antrea.io/antrea/pkg/client/clientset/versioned"
 
 	corev1 "k8s.io/api/core/v1"
-	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -53,12 +56,17 @@ const controllerAgentName = "tenant-controller"
 // Definitions of the state of the tenant resource
 const (
 	backoffLimit = 3
+	// The finalizer holds the tenant object until everything it owns is cleaned up
+	tenantFinalizer = "edge-net.io/tenant-cleanup"
 
 	successSynced        = "Synced"
 	failureCreation      = "Not Created"
 	failureBinding       = "Binding Failed"
 	failureNetworkPolicy = "Not Applied"
 	failureDeletion      = "Not Removed"
+	failureSuspension    = "Not Suspended"
+	failureResumption    = "Not Resumed"
+	failureTermination   = "Not Terminated"
 
 	messageResourceSynced                   = "Tenant synced successfully"
 	messageEstablished                      = "Tenant established successfully"
@@ -73,6 +81,37 @@ const (
 	messageRoleBindingDeletionFailed        = "Role binding clean up failed"
 	messageRoleBindingCreationFailed        = "Role binding creation for tenant failed"
 	messageReconciliation                   = "Reconciliation in progress"
+
+	messageFinalizerFailed                    = "Tenant finalizer could not be updated"
+	messageNamespacesTerminating              = "Waiting for the namespaces of the tenant to be removed"
+	messageClusterNetworkPolicyDeleting       = "Removing the cluster network policy"
+	messageClusterRolesDeleting               = "Removing the cluster roles"
+	messageClusterRoleBindingsDeleting        = "Removing the cluster role bindings"
+	messageRoleBindingsDeleting               = "Removing the role bindings"
+	messageSliceClaimsDeleting                = "Removing the slice claims"
+	messageSubNamespacesDeleting              = "Removing the subsidiary namespaces"
+	messageOwnershipDeleting                  = "Removing the tenant ownership permissions"
+	messageClustersDeleting                   = "Removing the federated clusters"
+	messageResourceQuotaDeleting              = "Removing the tenant resource quota"
+	messageNodesReleasing                     = "Releasing the contributed nodes"
+	messageVPNPeersReleasing                  = "Releasing the VPN peers"
+	messageClusterNetworkPolicyDeletionFailed = "Cluster network policy clean up failed"
+	messageOwnershipDeletionFailed            = "Tenant ownership permissions clean up failed"
+	messageClusterDeletionFailed              = "Federated cluster clean up failed"
+	messageResourceQuotaDeletionFailed        = "Tenant resource quota clean up failed"
+	messageNodeReleaseFailed                  = "Releasing the contributed nodes failed"
+	messageVPNPeerReleaseFailed               = "Releasing the VPN peers failed"
+	messageNamespaceDeletionFailed            = "Namespace clean up failed"
+	messageSuspended                          = "Tenant suspended"
+	messageResumed                            = "Tenant resumed, reconciliation in progress"
+	messageWorkloadSuspensionFailed           = "Scaling down the tenant workloads failed"
+	messageWorkloadResumptionFailed           = "Scaling up the tenant workloads failed"
+	messageRoleBindingRevocationFailed        = "Revoking the tenant role bindings failed"
+	messageRoleBindingRestorationFailed       = "Restoring the tenant role bindings failed"
+	messageOwnershipRevocationFailed          = "Revoking the tenant ownership failed"
+	messageOwnershipRestorationFailed         = "Restoring the tenant ownership failed"
+	messageNodeCordonFailed                   = "Cordoning the contributed nodes failed"
+	messageNodeUncordonFailed                 = "Uncordoning the contributed nodes failed"
 )
 
 // Controller is the controller implementation for Tenant resources
@@ -86,6 +125,8 @@ type Controller struct {
 	tenantsLister listers.TenantLister
 	tenantsSynced cache.InformerSynced
 
+	multitenancyManager *multitenancy.Manager
+
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
 	// means we can ensure we only process a fixed amount of resources at a
@@ -102,7 +143,8 @@ func NewController(
 	kubeclientset kubernetes.Interface,
 	edgenetclientset clientset.Interface,
 	antreaclientset antrea.Interface,
-	tenantInformer informers.TenantInformer) *Controller {
+	tenantInformer informers.TenantInformer,
+	baselineEgress bool) *Controller {
 
 	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
 	klog.Infoln("Creating event broadcaster")
@@ -111,14 +153,18 @@ func NewController(
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
 
+	multitenancyManager := multitenancy.NewManager(kubeclientset, edgenetclientset)
+	multitenancyManager.SetBaselineEgress(baselineEgress)
+
 	controller := &Controller{
-		kubeclientset:    kubeclientset,
-		edgenetclientset: edgenetclientset,
-		antreaclientset:  antreaclientset,
-		tenantsLister:    tenantInformer.Lister(),
-		tenantsSynced:    tenantInformer.Informer().HasSynced,
-		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Tenants"),
-		recorder:         recorder,
+		kubeclientset:       kubeclientset,
+		edgenetclientset:    edgenetclientset,
+		antreaclientset:     antreaclientset,
+		tenantsLister:       tenantInformer.Lister(),
+		tenantsSynced:       tenantInformer.Informer().HasSynced,
+		multitenancyManager: multitenancyManager,
+		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Tenants"),
+		recorder:            recorder,
 	}
 
 	klog.Infoln("Setting up event handlers")
@@ -243,14 +289,47 @@ func (c *Controller) enqueueTenant(obj interface{}) {
 	c.workqueue.Add(key)
 }
 
+// enqueueTenantAfter takes a Tenant resource and converts it into a namespace/name
+// string which is then put onto the work queue after the given duration. This method should *not* be
+// passed resources of any type other than Tenant.
+func (c *Controller) enqueueTenantAfter(obj interface{}, after time.Duration) {
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.AddAfter(key, after)
+}
+
 func (c *Controller) processTenant(tenantCopy *corev1alpha1.Tenant) {
 	systemNamespace, err := c.kubeclientset.CoreV1().Namespaces().Get(context.TODO(), "kube-system", metav1.GetOptions{})
 	if err != nil {
 		klog.Infoln(err)
 		return
 	}
+	if tenantCopy.GetDeletionTimestamp() != nil {
+		if hasFinalizer(tenantCopy.GetFinalizers(), tenantFinalizer) {
+			c.terminate(tenantCopy, string(systemNamespace.GetUID()))
+		}
+		return
+	}
+	if !hasFinalizer(tenantCopy.GetFinalizers(), tenantFinalizer) {
+		// Deleting the tenant now waits for the controller to clean up what the tenant owns
+		tenantCopy.SetFinalizers(append(tenantCopy.GetFinalizers(), tenantFinalizer))
+		tenantUpdated, err := c.edgenetclientset.CoreV1alpha1().Tenants().Update(context.TODO(), tenantCopy, metav1.UpdateOptions{})
+		if err != nil {
+			c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureTermination, messageFinalizerFailed)
+			klog.Infoln(err)
+			return
+		}
+		tenantCopy = tenantUpdated
+	}
 	if exceedsBackoffLimit := tenantCopy.Status.Failed >= backoffLimit; exceedsBackoffLimit {
-		c.cleanup(tenantCopy, string(systemNamespace.GetUID()))
+		if err := c.cleanup(tenantCopy, string(systemNamespace.GetUID())); err != nil {
+			klog.Infoln(err)
+			c.enqueueTenantAfter(tenantCopy, 30*time.Second)
+		}
 		return
 	}
 
@@ -258,11 +337,13 @@ func (c *Controller) processTenant(tenantCopy *corev1alpha1.Tenant) {
 		// When a tenant is deleted, the owner references feature drives the namespace to be automatically removed
 		ownerReferences := []metav1.OwnerReference{tenantCopy.MakeOwnerReference()}
 		switch tenantCopy.Status.State {
+		case corev1alpha1.StatusSuspended, corev1alpha1.StatusSuspending, corev1alpha1.StatusResuming:
+			c.resume(tenantCopy)
 		case corev1alpha1.StatusEstablished:
-			c.reconcile(tenantCopy)
+			c.reconcile(tenantCopy, string(systemNamespace.GetUID()))
 		case corev1alpha1.StatusCoreNamespaceCreated:
 			// Apply network policies
-			if err := c.applyNetworkPolicy(tenantCopy.GetName(), string(tenantCopy.GetUID()), string(systemNamespace.GetUID()), tenantCopy.Spec.ClusterNetworkPolicy, ownerReferences); err != nil {
+			if err := c.applyNetworkPolicy(tenantCopy, string(systemNamespace.GetUID()), ownerReferences); err != nil {
 				c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureNetworkPolicy, messageNetworkPolicyFailed)
 				tenantCopy.Status.State = corev1alpha1.StatusFailed
 				tenantCopy.Status.Message = messageNetworkPolicyFailed
@@ -287,8 +368,7 @@ func (c *Controller) processTenant(tenantCopy *corev1alpha1.Tenant) {
 				return
 			}
 			// Create the cluster role and role binding for the tenant resource
-			multitenancyManager := multitenancy.NewManager(c.kubeclientset, c.edgenetclientset)
-			if err := multitenancyManager.GrantObjectOwnership("core.edgenet.io", "tenants", tenantCopy.GetName(), tenantCopy.Spec.Contact.Email, ownerReferences); err != nil {
+			if err := c.multitenancyManager.GrantObjectOwnership("core.edgenet.io", "tenants", tenantCopy.GetName(), tenantCopy.Spec.Contact.Email, ownerReferences); err != nil {
 				c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureCreation, messageRoleBindingCreationFailed)
 				tenantCopy.Status.State = corev1alpha1.StatusFailed
 				tenantCopy.Status.Message = messageRoleBindingCreationFailed
@@ -300,12 +380,12 @@ func (c *Controller) processTenant(tenantCopy *corev1alpha1.Tenant) {
 			tenantCopy.Status.Message = messageCreated
 			c.updateStatus(context.TODO(), tenantCopy)
 		}
-	} else {
-		c.cleanup(tenantCopy, string(systemNamespace.GetUID()))
+	} else if tenantCopy.Status.State != corev1alpha1.StatusSuspended {
+		c.suspend(tenantCopy)
 	}
 }
 
-func (c *Controller) reconcile(tenantCopy *corev1alpha1.Tenant) {
+func (c *Controller) reconcile(tenantCopy *corev1alpha1.Tenant, clusterUID string) {
 	// Reconcile with the owner permissions in the core namespace
 	if roleBinding, err := c.kubeclientset.RbacV1().RoleBindings(tenantCopy.GetName()).Get(context.TODO(), corev1alpha1.TenantOwnerClusterRoleName, metav1.GetOptions{}); err != nil {
 		tenantCopy.Status.State = corev1alpha1.StatusCoreNamespaceCreated
@@ -324,12 +404,35 @@ func (c *Controller) reconcile(tenantCopy *corev1alpha1.Tenant) {
 			}
 		}
 	}
-	// Reconcile with the network policies
-	if _, err := c.kubeclientset.NetworkingV1().NetworkPolicies(tenantCopy.GetName()).Get(context.TODO(), "baseline", metav1.GetOptions{}); err != nil {
-		tenantCopy.Status.State = corev1alpha1.StatusCoreNamespaceCreated
-		tenantCopy.Status.Message = messageCreated
+	// Reconcile with the network policies, which also picks up a change of the network profile and repairs the
+	// policies that drifted from the spec of the profile
+	profile := tenantCopy.GetNetworkProfile()
+	namespaces := []string{tenantCopy.GetName()}
+	if subNamespaceRaw, err := c.kubeclientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/kind=sub,edge-net.io/tenant=%s", tenantCopy.GetName())}); err == nil {
+		for _, subNamespace := range subNamespaceRaw.Items {
+			namespaces = append(namespaces, subNamespace.GetName())
+		}
 	}
-	if _, err := c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Get(context.TODO(), tenantCopy.GetName(), metav1.GetOptions{}); (err != nil && tenantCopy.Spec.ClusterNetworkPolicy) || (err == nil && !tenantCopy.Spec.ClusterNetworkPolicy) {
+	for _, namespace := range namespaces {
+		networkPolicy, err := c.kubeclientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), profile, metav1.GetOptions{})
+		if err != nil {
+			tenantCopy.Status.State = corev1alpha1.StatusCoreNamespaceCreated
+			tenantCopy.Status.Message = messageCreated
+			break
+		}
+		expected := c.multitenancyManager.MakeNetworkPolicy(namespace, tenantCopy, clusterUID)
+		if networkPolicy.GetLabels()[multitenancy.NetworkProfileLabel] != profile || !equality.Semantic.DeepEqual(networkPolicy.Spec, expected.Spec) {
+			if err := c.multitenancyManager.ApplyNetworkProfile(namespace, tenantCopy, clusterUID); err != nil {
+				c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureNetworkPolicy, messageNetworkPolicyFailed)
+				tenantCopy.Status.State = corev1alpha1.StatusCoreNamespaceCreated
+				tenantCopy.Status.Message = messageCreated
+				break
+			}
+		}
+	}
+	clusterNetworkPolicyEnabled := tenantCopy.Spec.ClusterNetworkPolicy && profile != corev1alpha1.NetworkProfilePrivileged
+	if clusterNetworkPolicy, err := c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Get(context.TODO(), tenantCopy.GetName(), metav1.GetOptions{}); (err != nil && clusterNetworkPolicyEnabled) ||
+		(err == nil && (!clusterNetworkPolicyEnabled || clusterNetworkPolicy.GetLabels()[multitenancy.NetworkProfileLabel] != profile)) {
 		tenantCopy.Status.State = corev1alpha1.StatusCoreNamespaceCreated
 		tenantCopy.Status.Message = messageCreated
 	}
@@ -404,155 +507,859 @@ func (c *Controller) configureOwnerPermissions(tenantCopy *corev1alpha1.Tenant)
 	return nil
 }
 
-func (c *Controller) applyNetworkPolicy(tenant, tenantUID, clusterUID string, clusterNetworkPolicyEnabled bool, ownerReferences []metav1.OwnerReference) error {
-	// TODO: Apply a network policy to the core namespace according to spec
+func (c *Controller) applyNetworkPolicy(tenantCopy *corev1alpha1.Tenant, clusterUID string, ownerReferences []metav1.OwnerReference) error {
+	// The network profile applies to the core namespace and to the subsidiary namespaces of the tenant alike
 	// Restricted only allows intra-tenant communication
-	// Baseline allows intra-tenant communication plus ingress from external traffic
+	// Baseline allows intra-tenant communication plus ingress from external traffic, and egress to it for the tenants
+	// that restrict their egress
 	// Privileged allows all kind of traffics
-	var err error
-	labelSelector := metav1.LabelSelector{
-		MatchLabels: map[string]string{
-			"edge-net.io/subtenant":   "false",
-			"edge-net.io/tenant":      tenant,
-			"edge-net.io/tenant-uid":  tenantUID,
-			"edge-net.io/cluster-uid": clusterUID,
-		},
+	profile := tenantCopy.GetNetworkProfile()
+	if err := c.multitenancyManager.ApplyNetworkProfile(tenantCopy.GetName(), tenantCopy, clusterUID); err != nil {
+		return err
+	}
+	subNamespaceRaw, err := c.kubeclientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/kind=sub,edge-net.io/tenant=%s", tenantCopy.GetName())})
+	if err != nil {
+		return err
 	}
+	for _, subNamespace := range subNamespaceRaw.Items {
+		// Subsidiary namespaces that predate the tenant UID labels would fall out of the namespace selector
+		if labels := subNamespace.GetLabels(); labels["edge-net.io/tenant-uid"] != string(tenantCopy.GetUID()) || labels["edge-net.io/cluster-uid"] != clusterUID {
+			subNamespaceCopy := subNamespace.DeepCopy()
+			subNamespaceCopy.Labels["edge-net.io/tenant-uid"] = string(tenantCopy.GetUID())
+			subNamespaceCopy.Labels["edge-net.io/cluster-uid"] = clusterUID
+			if _, err := c.kubeclientset.CoreV1().Namespaces().Update(context.TODO(), subNamespaceCopy, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+		if err := c.multitenancyManager.ApplyNetworkProfile(subNamespace.GetName(), tenantCopy, clusterUID); err != nil {
+			return err
+		}
+	}
+	if tenantCopy.Spec.ClusterNetworkPolicy && profile != corev1alpha1.NetworkProfilePrivileged {
+		clusterNetworkPolicy := makeClusterNetworkPolicy(tenantCopy, clusterUID, c.multitenancyManager.RestrictsEgress(tenantCopy), ownerReferences)
+		if _, err = c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Create(context.TODO(), clusterNetworkPolicy, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return err
+			}
+			current, err := c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Get(context.TODO(), tenantCopy.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			current.SetLabels(clusterNetworkPolicy.GetLabels())
+			current.Spec = clusterNetworkPolicy.Spec
+			if _, err = c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Update(context.TODO(), current, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+	} else {
+		c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Delete(context.TODO(), tenantCopy.GetName(), metav1.DeleteOptions{})
+	}
+	return nil
+}
+
+// makeClusterNetworkPolicy forms the cluster-level counterpart of the network profile, which tenants cannot override
+func makeClusterNetworkPolicy(tenantCopy *corev1alpha1.Tenant, clusterUID string, restrictsEgress bool, ownerReferences []metav1.OwnerReference) *antreav1alpha1.ClusterNetworkPolicy {
+	drop := antreav1alpha1.RuleActionDrop
+	allow := antreav1alpha1.RuleActionAllow
+	profile := tenantCopy.GetNetworkProfile()
+	labelSelector := multitenancy.TenantNamespaceSelector(tenantCopy.GetName(), string(tenantCopy.GetUID()), clusterUID)
 	port := intstr.IntOrString{IntVal: 1}
 	endPort := int32(32768)
-	networkPolicy := new(networkingv1.NetworkPolicy)
-	networkPolicy.SetName("baseline")
-	networkPolicy.SetNamespace(tenant)
-	networkPolicy.Spec.PolicyTypes = []networkingv1.PolicyType{"Ingress"}
-	networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+	ports := []antreav1alpha1.NetworkPolicyPort{
 		{
-			From: []networkingv1.NetworkPolicyPeer{
+			Port:    &port,
+			EndPort: &endPort,
+		},
+	}
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	var privatePeers []antreav1alpha1.NetworkPolicyPeer
+	for _, cidr := range multitenancy.PrivateCIDRs {
+		privatePeers = append(privatePeers, antreav1alpha1.NetworkPolicyPeer{IPBlock: &antreav1alpha1.IPBlock{CIDR: cidr}})
+	}
+	publicPeers := []antreav1alpha1.NetworkPolicyPeer{
+		{
+			IPBlock: &antreav1alpha1.IPBlock{
+				CIDR: "0.0.0.0/0",
+			},
+		},
+	}
+
+	clusterNetworkPolicy := new(antreav1alpha1.ClusterNetworkPolicy)
+	clusterNetworkPolicy.SetName(tenantCopy.GetName())
+	clusterNetworkPolicy.SetLabels(map[string]string{multitenancy.NetworkProfileLabel: profile})
+	clusterNetworkPolicy.SetOwnerReferences(ownerReferences)
+	clusterNetworkPolicy.Spec.Tier = "tenant"
+	clusterNetworkPolicy.Spec.Priority = 5
+	clusterNetworkPolicy.Spec.AppliedTo = []antreav1alpha1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: labelSelector,
+		},
+	}
+	clusterNetworkPolicy.Spec.Egress = []antreav1alpha1.Rule{
+		{
+			Action: &allow,
+			To: []antreav1alpha1.NetworkPolicyPeer{
 				{
-					NamespaceSelector: &labelSelector,
+					NamespaceSelector: labelSelector,
 				},
+			},
+		},
+		{
+			Action: &allow,
+			To: []antreav1alpha1.NetworkPolicyPeer{
 				{
-					IPBlock: &networkingv1.IPBlock{
-						CIDR:   "0.0.0.0/0",
-						Except: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
-					},
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
 				},
 			},
-			Ports: []networkingv1.NetworkPolicyPort{
+			Ports: []antreav1alpha1.NetworkPolicyPort{
+				{
+					Protocol: &udp,
+					Port:     &dnsPort,
+				},
 				{
-					Port:    &port,
-					EndPort: &endPort,
+					Protocol: &tcp,
+					Port:     &dnsPort,
 				},
 			},
 		},
 	}
-	if _, err = c.kubeclientset.NetworkingV1().NetworkPolicies(tenant).Create(context.TODO(), networkPolicy, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
-		return err
-	}
-	if clusterNetworkPolicyEnabled {
-		drop := antreav1alpha1.RuleActionDrop
-		allow := antreav1alpha1.RuleActionAllow
-		clusterNetworkPolicy := new(antreav1alpha1.ClusterNetworkPolicy)
-		clusterNetworkPolicy.SetName(tenant)
-		clusterNetworkPolicy.SetOwnerReferences(ownerReferences)
-		clusterNetworkPolicy.Spec.Tier = "tenant"
-		clusterNetworkPolicy.Spec.Priority = 5
+	if profile == corev1alpha1.NetworkProfileRestricted {
 		clusterNetworkPolicy.Spec.Ingress = []antreav1alpha1.Rule{
 			{
 				Action: &allow,
 				From: []antreav1alpha1.NetworkPolicyPeer{
 					{
-						NamespaceSelector: &labelSelector,
-					},
-				},
-				Ports: []antreav1alpha1.NetworkPolicyPort{
-					{
-						Port:    &port,
-						EndPort: &endPort,
+						NamespaceSelector: labelSelector,
 					},
 				},
 			},
 			{
 				Action: &drop,
-				From: []antreav1alpha1.NetworkPolicyPeer{
-					{
-						IPBlock: &antreav1alpha1.IPBlock{
-							CIDR: "10.0.0.0/8",
-						},
-					},
-					{
-						IPBlock: &antreav1alpha1.IPBlock{
-							CIDR: "172.16.0.0/12",
-						},
-					},
-					{
-						IPBlock: &antreav1alpha1.IPBlock{
-							CIDR: "192.168.0.0/16",
-						},
-					},
-				},
-				Ports: []antreav1alpha1.NetworkPolicyPort{
-					{
-						Port:    &port,
-						EndPort: &endPort,
-					},
-				},
+				From:   publicPeers,
 			},
-			{
-				Action: &allow,
-				From: []antreav1alpha1.NetworkPolicyPeer{
-					{
-						IPBlock: &antreav1alpha1.IPBlock{
-							CIDR: "0.0.0.0/0",
-						},
-					},
-				},
-				Ports: []antreav1alpha1.NetworkPolicyPort{
-					{
-						Port:    &port,
-						EndPort: &endPort,
-					},
+		}
+		clusterNetworkPolicy.Spec.Egress = append(clusterNetworkPolicy.Spec.Egress, antreav1alpha1.Rule{
+			Action: &drop,
+			To:     publicPeers,
+		})
+		return clusterNetworkPolicy
+	}
+	clusterNetworkPolicy.Spec.Ingress = []antreav1alpha1.Rule{
+		{
+			Action: &allow,
+			From: []antreav1alpha1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: labelSelector,
 				},
 			},
+			Ports: ports,
+		},
+		{
+			Action: &drop,
+			From:   privatePeers,
+			Ports:  ports,
+		},
+		{
+			Action: &allow,
+			From:   publicPeers,
+			Ports:  ports,
+		},
+	}
+	if !restrictsEgress {
+		clusterNetworkPolicy.Spec.Egress = nil
+		return clusterNetworkPolicy
+	}
+	clusterNetworkPolicy.Spec.Egress = append(clusterNetworkPolicy.Spec.Egress,
+		antreav1alpha1.Rule{
+			Action: &drop,
+			To:     privatePeers,
+		},
+		antreav1alpha1.Rule{
+			Action: &allow,
+			To:     publicPeers,
+		},
+	)
+	return clusterNetworkPolicy
+}
+
+// terminationStep is a part of the teardown of a tenant. The status of the tenant reports the step while it runs, and
+// the teardown picks up from the step it reports when it is retried.
+type terminationStep struct {
+	message string
+	failure string
+	run     func(tenantCopy *corev1alpha1.Tenant, clusterUID string) error
+}
+
+// errNamespacesTerminating tells that the teardown waits for the namespaces of the tenant to go away
+var errNamespacesTerminating = fmt.Errorf("namespaces of the tenant are terminating")
+
+// cleanupSteps delete all roles, role bindings, slice claims and subsidiary namespaces of the tenant
+func (c *Controller) cleanupSteps() []terminationStep {
+	return []terminationStep{
+		{messageClusterRolesDeleting, messageClusterRoleDeletionFailed, c.deleteClusterRoles},
+		{messageClusterRoleBindingsDeleting, messageClusterRoleBindingDeletionFailed, c.deleteClusterRoleBindings},
+		{messageRoleBindingsDeleting, messageRoleBindingDeletionFailed, c.deleteRoleBindings},
+		{messageSliceClaimsDeleting, messageSliceClaimDeletionFailed, c.deleteSliceClaims},
+		{messageSubNamespacesDeleting, messageSubNamespaceDeletionFailed, c.deleteSubNamespaces},
+	}
+}
+
+// terminationSteps walk through everything the tenant owns, the namespaces last
+func (c *Controller) terminationSteps() []terminationStep {
+	steps := []terminationStep{{messageClusterNetworkPolicyDeleting, messageClusterNetworkPolicyDeletionFailed, c.deleteClusterNetworkPolicy}}
+	steps = append(steps, c.cleanupSteps()...)
+	return append(steps,
+		terminationStep{messageOwnershipDeleting, messageOwnershipDeletionFailed, c.deleteOwnership},
+		terminationStep{messageClustersDeleting, messageClusterDeletionFailed, c.deleteClusters},
+		terminationStep{messageResourceQuotaDeleting, messageResourceQuotaDeletionFailed, c.deleteResourceQuota},
+		terminationStep{messageNodesReleasing, messageNodeReleaseFailed, c.releaseNodes},
+		terminationStep{messageVPNPeersReleasing, messageVPNPeerReleaseFailed, c.releaseVPNPeers},
+		terminationStep{messageNamespacesTerminating, messageNamespaceDeletionFailed, c.deleteNamespaces},
+	)
+}
+
+// cleanup runs the clean up steps of a tenant that exceeded the backoff limit and stops at the first one that fails
+func (c *Controller) cleanup(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	for _, step := range c.cleanupSteps() {
+		if err := step.run(tenantCopy, clusterUID); err != nil {
+			c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, step.failure)
+			return err
 		}
-		clusterNetworkPolicy.Spec.AppliedTo = []antreav1alpha1.NetworkPolicyPeer{
-			{
-				NamespaceSelector: &labelSelector,
-			},
+	}
+	return nil
+}
+
+// terminate runs the termination steps and removes the finalizer once all of them succeed. Each step is safe to
+// repeat, so a failed step is retried on the next sync, starting from the step the status reports.
+func (c *Controller) terminate(tenantCopy *corev1alpha1.Tenant, clusterUID string) {
+	steps := c.terminationSteps()
+	first := 0
+	if tenantCopy.Status.State == corev1alpha1.StatusTerminating {
+		for i, step := range steps {
+			if tenantCopy.Status.Message == step.message || tenantCopy.Status.Message == step.failure {
+				first = i
+				break
+			}
+		}
+	}
+	for _, step := range steps[first:] {
+		if tenantCopy.Status.State != corev1alpha1.StatusTerminating || tenantCopy.Status.Message != step.message {
+			c.recorder.Event(tenantCopy, corev1.EventTypeNormal, corev1alpha1.StatusTerminating, step.message)
+			tenantCopy.Status.State = corev1alpha1.StatusTerminating
+			tenantCopy.Status.Message = step.message
+			c.updateStatus(context.TODO(), tenantCopy)
+		}
+		if err := step.run(tenantCopy, clusterUID); err == errNamespacesTerminating {
+			c.enqueueTenantAfter(tenantCopy, 10*time.Second)
+			return
+		} else if err != nil {
+			klog.Infoln(err)
+			c.retryLater(tenantCopy, corev1alpha1.StatusTerminating, failureTermination, step.failure)
+			return
 		}
-		if _, err = c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Create(context.TODO(), clusterNetworkPolicy, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+	}
+
+	tenantCopy.SetFinalizers(removeFinalizer(tenantCopy.GetFinalizers(), tenantFinalizer))
+	if _, err := c.edgenetclientset.CoreV1alpha1().Tenants().Update(context.TODO(), tenantCopy, metav1.UpdateOptions{}); err != nil {
+		klog.Infoln(err)
+		c.retryLater(tenantCopy, corev1alpha1.StatusTerminating, failureTermination, messageFinalizerFailed)
+	}
+}
+
+func (c *Controller) deleteClusterNetworkPolicy(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	if err := c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Delete(context.TODO(), tenantCopy.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) deleteClusterRoles(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	return c.kubeclientset.RbacV1().ClusterRoles().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenantCopy.GetName(), string(tenantCopy.GetUID()), clusterUID)})
+}
+
+func (c *Controller) deleteClusterRoleBindings(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	return c.kubeclientset.RbacV1().ClusterRoleBindings().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenantCopy.GetName(), string(tenantCopy.GetUID()), clusterUID)})
+}
+
+func (c *Controller) deleteRoleBindings(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	return c.kubeclientset.RbacV1().RoleBindings(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{})
+}
+
+func (c *Controller) deleteSliceClaims(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	return c.edgenetclientset.CoreV1alpha1().SliceClaims(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{})
+}
+
+func (c *Controller) deleteSubNamespaces(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	return c.edgenetclientset.CoreV1alpha1().SubNamespaces(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{})
+}
+
+// deleteOwnership removes the object-specific owner permissions from GrantObjectOwnership, which do not carry the
+// tenant labels
+func (c *Controller) deleteOwnership(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	ownershipName := fmt.Sprintf("edgenet:tenants:%s-owner", tenantCopy.GetName())
+	if err := c.kubeclientset.RbacV1().ClusterRoleBindings().Delete(context.TODO(), ownershipName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := c.kubeclientset.RbacV1().ClusterRoles().Delete(context.TODO(), ownershipName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) deleteClusters(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	if err := c.edgenetclientset.FederationV1alpha1().Clusters(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) deleteResourceQuota(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	if err := c.edgenetclientset.CoreV1alpha1().TenantResourceQuotas().Delete(context.TODO(), tenantCopy.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// deleteNamespaces deletes the core namespace and the subsidiary namespaces in the tree, which are all labeled with
+// the tenant name, and returns errNamespacesTerminating until they are gone
+func (c *Controller) deleteNamespaces(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	namespaceList, err := c.kubeclientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s", tenantCopy.GetName())})
+	if err != nil {
+		return err
+	}
+	if len(namespaceList.Items) == 0 {
+		return nil
+	}
+	for _, namespace := range namespaceList.Items {
+		if namespace.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := c.kubeclientset.CoreV1().Namespaces().Delete(context.TODO(), namespace.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return errNamespacesTerminating
+}
+
+// suspend takes the tenant out of service without destroying any data. The owner loses the ownership of the tenant
+// object first, workloads are scaled to zero or stopped, the owner, admins, and collaborators lose their role bindings,
+// and the nodes the tenant contributes are cordoned. A suspension that fails halfway leaves the tenant in the
+// suspending state so that either suspend or resume finishes it.
+func (c *Controller) suspend(tenantCopy *corev1alpha1.Tenant) {
+	if err := c.revokeOwnership(tenantCopy); err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusSuspending, failureSuspension, messageOwnershipRevocationFailed)
+		return
+	}
+	namespaceList, err := c.kubeclientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s", tenantCopy.GetName())})
+	if err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusSuspending, failureSuspension, messageWorkloadSuspensionFailed)
+		return
+	}
+	for _, namespace := range namespaceList.Items {
+		if err := c.scaleDownWorkloads(namespace.GetName()); err != nil {
+			c.retryLater(tenantCopy, corev1alpha1.StatusSuspending, failureSuspension, messageWorkloadSuspensionFailed)
+			return
+		}
+		if err := c.revokeRoleBindings(namespace.GetName()); err != nil {
+			c.retryLater(tenantCopy, corev1alpha1.StatusSuspending, failureSuspension, messageRoleBindingRevocationFailed)
+			return
+		}
+	}
+	if err := c.setContributedNodeScheduling(tenantCopy, true); err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusSuspending, failureSuspension, messageNodeCordonFailed)
+		return
+	}
+	c.recorder.Event(tenantCopy, corev1.EventTypeNormal, corev1alpha1.StatusSuspended, messageSuspended)
+	tenantCopy.Status.State = corev1alpha1.StatusSuspended
+	tenantCopy.Status.Message = messageSuspended
+	c.updateStatus(context.TODO(), tenantCopy)
+}
+
+// resume reverses the suspension and hands the tenant over to reconciliation. A resumption that fails halfway leaves
+// the tenant in the resuming state so that either suspend or resume finishes it.
+func (c *Controller) resume(tenantCopy *corev1alpha1.Tenant) {
+	namespaceList, err := c.kubeclientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s", tenantCopy.GetName())})
+	if err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusResuming, failureResumption, messageWorkloadResumptionFailed)
+		return
+	}
+	for _, namespace := range namespaceList.Items {
+		if err := c.scaleUpWorkloads(namespace.GetName()); err != nil {
+			c.retryLater(tenantCopy, corev1alpha1.StatusResuming, failureResumption, messageWorkloadResumptionFailed)
+			return
+		}
+		if err := c.restoreRoleBindings(namespace.GetName()); err != nil {
+			c.retryLater(tenantCopy, corev1alpha1.StatusResuming, failureResumption, messageRoleBindingRestorationFailed)
+			return
+		}
+	}
+	if err := c.restoreOwnership(tenantCopy); err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusResuming, failureResumption, messageOwnershipRestorationFailed)
+		return
+	}
+	if err := c.setContributedNodeScheduling(tenantCopy, false); err != nil {
+		c.retryLater(tenantCopy, corev1alpha1.StatusResuming, failureResumption, messageNodeUncordonFailed)
+		return
+	}
+	c.recorder.Event(tenantCopy, corev1.EventTypeNormal, corev1alpha1.StatusReconciliation, messageResumed)
+	tenantCopy.Status.State = corev1alpha1.StatusReconciliation
+	tenantCopy.Status.Message = messageResumed
+	c.updateStatus(context.TODO(), tenantCopy)
+}
+
+// scaleDownWorkloads scales deployments and statefulsets to zero, keeps daemon sets off the nodes, suspends cron jobs
+// and jobs, recording the original values in annotations, and deletes the pods that no controller would bring back
+func (c *Controller) scaleDownWorkloads(namespace string) error {
+	deploymentList, err := c.kubeclientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deploymentList.Items {
+		if _, elementExists := deployment.GetAnnotations()[multitenancy.SuspendedReplicasAnnotation]; elementExists {
+			continue
+		}
+		deploymentCopy := deployment.DeepCopy()
+		deploymentCopy.SetAnnotations(withAnnotation(deploymentCopy.GetAnnotations(), multitenancy.SuspendedReplicasAnnotation, strconv.Itoa(int(replicasOf(deploymentCopy.Spec.Replicas)))))
+		zero := int32(0)
+		deploymentCopy.Spec.Replicas = &zero
+		if _, err := c.kubeclientset.AppsV1().Deployments(namespace).Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	statefulsetList, err := c.kubeclientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulset := range statefulsetList.Items {
+		if _, elementExists := statefulset.GetAnnotations()[multitenancy.SuspendedReplicasAnnotation]; elementExists {
+			continue
+		}
+		statefulsetCopy := statefulset.DeepCopy()
+		statefulsetCopy.SetAnnotations(withAnnotation(statefulsetCopy.GetAnnotations(), multitenancy.SuspendedReplicasAnnotation, strconv.Itoa(int(replicasOf(statefulsetCopy.Spec.Replicas)))))
+		zero := int32(0)
+		statefulsetCopy.Spec.Replicas = &zero
+		if _, err := c.kubeclientset.AppsV1().StatefulSets(namespace).Update(context.TODO(), statefulsetCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	daemonsetList, err := c.kubeclientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, daemonset := range daemonsetList.Items {
+		if _, elementExists := daemonset.GetAnnotations()[multitenancy.SuspendedNodeSelectorAnnotation]; elementExists {
+			continue
+		}
+		nodeSelector, err := json.Marshal(daemonset.Spec.Template.Spec.NodeSelector)
+		if err != nil {
+			return err
+		}
+		daemonsetCopy := daemonset.DeepCopy()
+		daemonsetCopy.SetAnnotations(withAnnotation(daemonsetCopy.GetAnnotations(), multitenancy.SuspendedNodeSelectorAnnotation, string(nodeSelector)))
+		// No node carries the label, so the daemon set controller removes the pods from every node
+		daemonsetCopy.Spec.Template.Spec.NodeSelector = map[string]string{multitenancy.SuspendedAnnotation: "true"}
+		if _, err := c.kubeclientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), daemonsetCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	cronjobList, err := c.kubeclientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronjob := range cronjobList.Items {
+		// Cron jobs that the tenant suspended on purpose stay as they are
+		if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+			continue
+		}
+		cronjobCopy := cronjob.DeepCopy()
+		cronjobCopy.SetAnnotations(withAnnotation(cronjobCopy.GetAnnotations(), multitenancy.SuspendedAnnotation, "true"))
+		suspend := true
+		cronjobCopy.Spec.Suspend = &suspend
+		if _, err := c.kubeclientset.BatchV1().CronJobs(namespace).Update(context.TODO(), cronjobCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	jobList, err := c.kubeclientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, job := range jobList.Items {
+		// Finished jobs and those that the tenant suspended on purpose stay as they are
+		if job.Status.CompletionTime != nil || (job.Spec.Suspend != nil && *job.Spec.Suspend) {
+			continue
+		}
+		jobCopy := job.DeepCopy()
+		jobCopy.SetAnnotations(withAnnotation(jobCopy.GetAnnotations(), multitenancy.SuspendedAnnotation, "true"))
+		suspend := true
+		jobCopy.Spec.Suspend = &suspend
+		if _, err := c.kubeclientset.BatchV1().Jobs(namespace).Update(context.TODO(), jobCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	podList, err := c.kubeclientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pod := range podList.Items {
+		if metav1.GetControllerOf(&pod) != nil {
+			continue
+		}
+		if err := c.kubeclientset.CoreV1().Pods(namespace).Delete(context.TODO(), pod.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 			return err
 		}
-	} else {
-		c.antreaclientset.CrdV1alpha1().ClusterNetworkPolicies().Delete(context.TODO(), tenant, metav1.DeleteOptions{})
 	}
 	return nil
 }
 
-func (c *Controller) cleanup(tenantCopy *corev1alpha1.Tenant, clusterUID string) {
-	// Delete all roles, role bindings, slices and subsidiary namespaces
-	if err := c.kubeclientset.RbacV1().ClusterRoles().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenantCopy.GetName(), string(tenantCopy.GetUID()), clusterUID)}); err != nil {
-		c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, messageClusterRoleDeletionFailed)
+// scaleUpWorkloads gives the workloads back the replicas, node selectors, and schedules they had before the suspension.
+// Deleted pods are not brought back.
+func (c *Controller) scaleUpWorkloads(namespace string) error {
+	deploymentList, err := c.kubeclientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
 	}
-	if err := c.kubeclientset.RbacV1().ClusterRoleBindings().DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenantCopy.GetName(), string(tenantCopy.GetUID()), clusterUID)}); err != nil {
-		c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, messageClusterRoleBindingDeletionFailed)
+	for _, deployment := range deploymentList.Items {
+		value, elementExists := deployment.GetAnnotations()[multitenancy.SuspendedReplicasAnnotation]
+		if !elementExists {
+			continue
+		}
+		deploymentCopy := deployment.DeepCopy()
+		if replicas, err := strconv.Atoi(value); err == nil {
+			original := int32(replicas)
+			deploymentCopy.Spec.Replicas = &original
+		}
+		delete(deploymentCopy.Annotations, multitenancy.SuspendedReplicasAnnotation)
+		if _, err := c.kubeclientset.AppsV1().Deployments(namespace).Update(context.TODO(), deploymentCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
 	}
-	if err := c.kubeclientset.RbacV1().RoleBindings(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
-		c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, messageRoleBindingDeletionFailed)
+	statefulsetList, err := c.kubeclientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
 	}
-	if err := c.edgenetclientset.CoreV1alpha1().SliceClaims(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
-		c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, messageSliceClaimDeletionFailed)
+	for _, statefulset := range statefulsetList.Items {
+		value, elementExists := statefulset.GetAnnotations()[multitenancy.SuspendedReplicasAnnotation]
+		if !elementExists {
+			continue
+		}
+		statefulsetCopy := statefulset.DeepCopy()
+		if replicas, err := strconv.Atoi(value); err == nil {
+			original := int32(replicas)
+			statefulsetCopy.Spec.Replicas = &original
+		}
+		delete(statefulsetCopy.Annotations, multitenancy.SuspendedReplicasAnnotation)
+		if _, err := c.kubeclientset.AppsV1().StatefulSets(namespace).Update(context.TODO(), statefulsetCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
 	}
-	if err := c.edgenetclientset.CoreV1alpha1().SubNamespaces(tenantCopy.GetName()).DeleteCollection(context.TODO(), metav1.DeleteOptions{}, metav1.ListOptions{}); err != nil {
-		c.recorder.Event(tenantCopy, corev1.EventTypeWarning, failureDeletion, messageSubNamespaceDeletionFailed)
+	daemonsetList, err := c.kubeclientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
 	}
+	for _, daemonset := range daemonsetList.Items {
+		value, elementExists := daemonset.GetAnnotations()[multitenancy.SuspendedNodeSelectorAnnotation]
+		if !elementExists {
+			continue
+		}
+		daemonsetCopy := daemonset.DeepCopy()
+		nodeSelector := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &nodeSelector); err != nil {
+			return err
+		}
+		daemonsetCopy.Spec.Template.Spec.NodeSelector = nodeSelector
+		if len(nodeSelector) == 0 {
+			daemonsetCopy.Spec.Template.Spec.NodeSelector = nil
+		}
+		delete(daemonsetCopy.Annotations, multitenancy.SuspendedNodeSelectorAnnotation)
+		if _, err := c.kubeclientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), daemonsetCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	cronjobList, err := c.kubeclientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, cronjob := range cronjobList.Items {
+		if _, elementExists := cronjob.GetAnnotations()[multitenancy.SuspendedAnnotation]; !elementExists {
+			continue
+		}
+		cronjobCopy := cronjob.DeepCopy()
+		suspend := false
+		cronjobCopy.Spec.Suspend = &suspend
+		delete(cronjobCopy.Annotations, multitenancy.SuspendedAnnotation)
+		if _, err := c.kubeclientset.BatchV1().CronJobs(namespace).Update(context.TODO(), cronjobCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	jobList, err := c.kubeclientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, job := range jobList.Items {
+		if _, elementExists := job.GetAnnotations()[multitenancy.SuspendedAnnotation]; !elementExists {
+			continue
+		}
+		jobCopy := job.DeepCopy()
+		suspend := false
+		jobCopy.Spec.Suspend = &suspend
+		delete(jobCopy.Annotations, multitenancy.SuspendedAnnotation)
+		if _, err := c.kubeclientset.BatchV1().Jobs(namespace).Update(context.TODO(), jobCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeRoleBindings empties the subjects of the tenant owner, admin, and collaborator role bindings.
+// The role bindings themselves are kept to hold the subjects until the tenant is resumed.
+func (c *Controller) revokeRoleBindings(namespace string) error {
+	roleBindingList, err := c.kubeclientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		if !isSuspendableRoleBinding(roleBinding) || len(roleBinding.Subjects) == 0 {
+			continue
+		}
+		if _, elementExists := roleBinding.GetAnnotations()[multitenancy.SuspendedSubjectsAnnotation]; elementExists {
+			continue
+		}
+		subjects, err := json.Marshal(roleBinding.Subjects)
+		if err != nil {
+			return err
+		}
+		roleBindingCopy := roleBinding.DeepCopy()
+		roleBindingCopy.SetAnnotations(withAnnotation(roleBindingCopy.GetAnnotations(), multitenancy.SuspendedSubjectsAnnotation, string(subjects)))
+		roleBindingCopy.Subjects = nil
+		if _, err := c.kubeclientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), roleBindingCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// updateStatus calls the API to update the tenant status.
+// restoreRoleBindings puts back the subjects that the suspension removed
+func (c *Controller) restoreRoleBindings(namespace string) error {
+	roleBindingList, err := c.kubeclientset.RbacV1().RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		value, elementExists := roleBinding.GetAnnotations()[multitenancy.SuspendedSubjectsAnnotation]
+		if !isSuspendableRoleBinding(roleBinding) || !elementExists {
+			continue
+		}
+		roleBindingCopy := roleBinding.DeepCopy()
+		subjects := []rbacv1.Subject{}
+		if err := json.Unmarshal([]byte(value), &subjects); err != nil {
+			return err
+		}
+		roleBindingCopy.Subjects = append(subjects, roleBindingCopy.Subjects...)
+		delete(roleBindingCopy.Annotations, multitenancy.SuspendedSubjectsAnnotation)
+		if _, err := c.kubeclientset.RbacV1().RoleBindings(namespace).Update(context.TODO(), roleBindingCopy, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeOwnership empties the subjects of the cluster role binding that lets the owner update the tenant object,
+// so a suspended owner cannot enable the tenant again
+func (c *Controller) revokeOwnership(tenantCopy *corev1alpha1.Tenant) error {
+	clusterRoleBinding, err := c.kubeclientset.RbacV1().ClusterRoleBindings().Get(context.TODO(), fmt.Sprintf("edgenet:tenants:%s-owner", tenantCopy.GetName()), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, elementExists := clusterRoleBinding.GetAnnotations()[multitenancy.SuspendedSubjectsAnnotation]; elementExists || len(clusterRoleBinding.Subjects) == 0 {
+		return nil
+	}
+	subjects, err := json.Marshal(clusterRoleBinding.Subjects)
+	if err != nil {
+		return err
+	}
+	clusterRoleBindingCopy := clusterRoleBinding.DeepCopy()
+	clusterRoleBindingCopy.SetAnnotations(withAnnotation(clusterRoleBindingCopy.GetAnnotations(), multitenancy.SuspendedSubjectsAnnotation, string(subjects)))
+	clusterRoleBindingCopy.Subjects = nil
+	_, err = c.kubeclientset.RbacV1().ClusterRoleBindings().Update(context.TODO(), clusterRoleBindingCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// restoreOwnership gives the owner the ownership of the tenant object back
+func (c *Controller) restoreOwnership(tenantCopy *corev1alpha1.Tenant) error {
+	clusterRoleBinding, err := c.kubeclientset.RbacV1().ClusterRoleBindings().Get(context.TODO(), fmt.Sprintf("edgenet:tenants:%s-owner", tenantCopy.GetName()), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	value, elementExists := clusterRoleBinding.GetAnnotations()[multitenancy.SuspendedSubjectsAnnotation]
+	if !elementExists {
+		return nil
+	}
+	subjects := []rbacv1.Subject{}
+	if err := json.Unmarshal([]byte(value), &subjects); err != nil {
+		return err
+	}
+	clusterRoleBindingCopy := clusterRoleBinding.DeepCopy()
+	clusterRoleBindingCopy.Subjects = append(subjects, clusterRoleBindingCopy.Subjects...)
+	delete(clusterRoleBindingCopy.Annotations, multitenancy.SuspendedSubjectsAnnotation)
+	_, err = c.kubeclientset.RbacV1().ClusterRoleBindings().Update(context.TODO(), clusterRoleBindingCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// setContributedNodeScheduling cordons the nodes that the tenant contributes, or brings them back to
+// the scheduling their node contributions ask for
+func (c *Controller) setContributedNodeScheduling(tenantCopy *corev1alpha1.Tenant, suspended bool) error {
+	nodeList, err := c.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, nil, nil)
+	for _, node := range nodeList.Items {
+		if _, owned := withoutOwner(node.GetOwnerReferences(), tenantCopy); !owned {
+			continue
+		}
+		unschedulable := suspended
+		if !suspended {
+			if ownerRef := metav1.GetControllerOf(&node); ownerRef != nil && ownerRef.Kind == "NodeContribution" {
+				if nodeContribution, err := c.edgenetclientset.CoreV1alpha1().NodeContributions().Get(context.TODO(), ownerRef.Name, metav1.GetOptions{}); err == nil {
+					unschedulable = !nodeContribution.Spec.Enabled
+				}
+			}
+		}
+		if node.Spec.Unschedulable == unschedulable {
+			continue
+		}
+		if err := multiproviderManager.SetNodeScheduling(node.GetName(), unschedulable); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSuspendableRoleBinding(roleBinding rbacv1.RoleBinding) bool {
+	if roleBinding.RoleRef.Kind != "ClusterRole" {
+		return false
+	}
+	switch roleBinding.RoleRef.Name {
+	case corev1alpha1.TenantOwnerClusterRoleName, corev1alpha1.TenantAdminClusterRoleName, corev1alpha1.TenantCollaboratorClusterRoleName:
+		return true
+	}
+	return false
+}
+
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	return annotations
+}
+
+// replicasOf returns the replica count that the API server assumes when it is not set
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// retryLater reports the step at which the tenant got stuck and schedules another attempt
+func (c *Controller) retryLater(tenantCopy *corev1alpha1.Tenant, state, reason, message string) {
+	c.recorder.Event(tenantCopy, corev1.EventTypeWarning, reason, message)
+	if tenantCopy.Status.State != state || tenantCopy.Status.Message != message {
+		tenantCopy.Status.State = state
+		tenantCopy.Status.Message = message
+		c.updateStatus(context.TODO(), tenantCopy)
+	}
+	c.enqueueTenantAfter(tenantCopy, 30*time.Second)
+}
+
+// releaseNodes drops the tenant from the owner references of the contributed nodes, including those that have no
+// other owner, so that the garbage collector does not delete them along with the tenant.
+func (c *Controller) releaseNodes(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	nodeList, err := c.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, nil, nil)
+	for _, node := range nodeList.Items {
+		if ownerReferences, owned := withoutOwner(node.GetOwnerReferences(), tenantCopy); owned {
+			if err := multiproviderManager.SetOwnerReferences(node.GetName(), ownerReferences); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// releaseVPNPeers drops the tenant from the owner references of the VPN peers of the contributed nodes, including
+// those that have no other owner, so that the garbage collector does not delete them along with the tenant.
+func (c *Controller) releaseVPNPeers(tenantCopy *corev1alpha1.Tenant, clusterUID string) error {
+	vpnPeerList, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, vpnPeer := range vpnPeerList.Items {
+		if ownerReferences, owned := withoutOwner(vpnPeer.GetOwnerReferences(), tenantCopy); owned {
+			vpnPeerCopy := vpnPeer.DeepCopy()
+			vpnPeerCopy.SetOwnerReferences(ownerReferences)
+			if _, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Update(context.TODO(), vpnPeerCopy, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// withoutOwner returns the owner references except the ones pointing to the tenant, and whether the tenant was among them
+func withoutOwner(ownerReferences []metav1.OwnerReference, tenantCopy *corev1alpha1.Tenant) ([]metav1.OwnerReference, bool) {
+	owned := false
+	remaining := []metav1.OwnerReference{}
+	for _, ownerReference := range ownerReferences {
+		if ownerReference.Kind == "Tenant" && ownerReference.UID == tenantCopy.GetUID() {
+			owned = true
+			continue
+		}
+		remaining = append(remaining, ownerReference)
+	}
+	return remaining, owned
+}
+
+func hasFinalizer(finalizers []string, finalizer string) bool {
+	for _, item := range finalizers {
+		if item == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	remaining := []string{}
+	for _, item := range finalizers {
+		if item != finalizer {
+			remaining = append(remaining, item)
+		}
+	}
+	return remaining
+}
+
+// updateStatus calls the API to update the tenant status, and keeps the updated object so that the writes that follow
+// do not conflict with this one.
 func (c *Controller) updateStatus(ctx context.Context, tenantCopy *corev1alpha1.Tenant) {
 	if tenantCopy.Status.State == corev1alpha1.StatusFailed {
 		tenantCopy.Status.Failed++
 	}
-	if _, err := c.edgenetclientset.CoreV1alpha1().Tenants().UpdateStatus(ctx, tenantCopy, metav1.UpdateOptions{}); err != nil {
+	if tenantUpdated, err := c.edgenetclientset.CoreV1alpha1().Tenants().UpdateStatus(ctx, tenantCopy, metav1.UpdateOptions{}); err != nil {
 		klog.Infoln(err)
+	} else {
+		tenantUpdated.DeepCopyInto(tenantCopy)
 	}
 }
@@ -1,6 +1,8 @@
 package tenant
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
@@ -8,18 +10,23 @@ import (
 
 	corev1alpha "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
 	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
 	edgenetfake "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
 	edgeinformers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/multitenancy"
 
 	antreav1alpha1 "antrea.io/antrea/pkg/apis/crd/v1alpha1"
 	antreafake "antrea.io/antrea/pkg/client/clientset/versioned/fake"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
@@ -73,12 +80,14 @@ func newTenant(name string, cnp, enabled bool) *corev1alpha1.Tenant {
 	return &corev1alpha1.Tenant{
 		TypeMeta: metav1.TypeMeta{APIVersion: corev1alpha1.SchemeGroupVersion.String()},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:       name,
+			Finalizers: []string{tenantFinalizer},
 		},
 		Spec: corev1alpha1.TenantSpec{
-			FullName:  fmt.Sprintf("EdgeNet %s", name),
-			ShortName: name,
-			URL:       fmt.Sprintf("https://%s.org", name),
+			FullName:       fmt.Sprintf("EdgeNet %s", name),
+			ShortName:      name,
+			URL:            fmt.Sprintf("https://%s.org", name),
+			NetworkProfile: corev1alpha1.NetworkProfileBaseline,
 			Address: corev1alpha.Address{
 				City:    "Paris",
 				Country: "France",
@@ -168,21 +177,37 @@ func newRoleBinding(name, namespace, email string, labels map[string]string) *rb
 		},
 	}
 }
+func newDeployment(name, namespace string, replicas int32, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+	}
+}
 func newNetworkPolicy(name, namespace string, labelSelector metav1.LabelSelector) *networkingv1.NetworkPolicy {
 	port := intstr.IntOrString{IntVal: 1}
 	endPort := int32(32768)
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	publicPeer := networkingv1.NetworkPolicyPeer{
+		IPBlock: &networkingv1.IPBlock{
+			CIDR:   "0.0.0.0/0",
+			Except: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+		},
+	}
 	ingressRules := []networkingv1.NetworkPolicyIngressRule{
 		{
 			From: []networkingv1.NetworkPolicyPeer{
 				{
 					NamespaceSelector: &labelSelector,
 				},
-				{
-					IPBlock: &networkingv1.IPBlock{
-						CIDR:   "0.0.0.0/0",
-						Except: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
-					},
-				},
+				publicPeer,
 			},
 			Ports: []networkingv1.NetworkPolicyPort{
 				{
@@ -192,14 +217,45 @@ func newNetworkPolicy(name, namespace string, labelSelector metav1.LabelSelector
 			},
 		},
 	}
+	egressRules := []networkingv1.NetworkPolicyEgressRule{
+		{
+			To: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &labelSelector,
+				},
+			},
+		},
+		{
+			To: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+				},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{
+					Protocol: &udp,
+					Port:     &dnsPort,
+				},
+				{
+					Protocol: &tcp,
+					Port:     &dnsPort,
+				},
+			},
+		},
+		{
+			To: []networkingv1.NetworkPolicyPeer{publicPeer},
+		},
+	}
 	return &networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
+			Labels:    map[string]string{"edge-net.io/network-profile": name},
 		},
 		Spec: networkingv1.NetworkPolicySpec{
-			PolicyTypes: []networkingv1.PolicyType{"Ingress"},
+			PolicyTypes: []networkingv1.PolicyType{"Ingress", "Egress"},
 			Ingress:     ingressRules,
+			Egress:      egressRules,
 		},
 	}
 }
@@ -208,6 +264,39 @@ func newClusterNetworkPolicy(name string, labelSelector metav1.LabelSelector, ow
 	allow := antreav1alpha1.RuleActionAllow
 	port := intstr.IntOrString{IntVal: 1}
 	endPort := int32(32768)
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	ports := []antreav1alpha1.NetworkPolicyPort{
+		{
+			Port:    &port,
+			EndPort: &endPort,
+		},
+	}
+	privatePeers := []antreav1alpha1.NetworkPolicyPeer{
+		{
+			IPBlock: &antreav1alpha1.IPBlock{
+				CIDR: "10.0.0.0/8",
+			},
+		},
+		{
+			IPBlock: &antreav1alpha1.IPBlock{
+				CIDR: "172.16.0.0/12",
+			},
+		},
+		{
+			IPBlock: &antreav1alpha1.IPBlock{
+				CIDR: "192.168.0.0/16",
+			},
+		},
+	}
+	publicPeers := []antreav1alpha1.NetworkPolicyPeer{
+		{
+			IPBlock: &antreav1alpha1.IPBlock{
+				CIDR: "0.0.0.0/0",
+			},
+		},
+	}
 	ingressRules := []antreav1alpha1.Rule{
 		{
 			Action: &allow,
@@ -216,55 +305,54 @@ func newClusterNetworkPolicy(name string, labelSelector metav1.LabelSelector, ow
 					NamespaceSelector: &labelSelector,
 				},
 			},
-			Ports: []antreav1alpha1.NetworkPolicyPort{
-				{
-					Port:    &port,
-					EndPort: &endPort,
-				},
-			},
+			Ports: ports,
 		},
 		{
 			Action: &drop,
-			From: []antreav1alpha1.NetworkPolicyPeer{
-				{
-					IPBlock: &antreav1alpha1.IPBlock{
-						CIDR: "10.0.0.0/8",
-					},
-				},
-				{
-					IPBlock: &antreav1alpha1.IPBlock{
-						CIDR: "172.16.0.0/12",
-					},
-				},
-				{
-					IPBlock: &antreav1alpha1.IPBlock{
-						CIDR: "192.168.0.0/16",
-					},
-				},
-			},
-			Ports: []antreav1alpha1.NetworkPolicyPort{
+			From:   privatePeers,
+			Ports:  ports,
+		},
+		{
+			Action: &allow,
+			From:   publicPeers,
+			Ports:  ports,
+		},
+	}
+	egressRules := []antreav1alpha1.Rule{
+		{
+			Action: &allow,
+			To: []antreav1alpha1.NetworkPolicyPeer{
 				{
-					Port:    &port,
-					EndPort: &endPort,
+					NamespaceSelector: &labelSelector,
 				},
 			},
 		},
 		{
 			Action: &allow,
-			From: []antreav1alpha1.NetworkPolicyPeer{
+			To: []antreav1alpha1.NetworkPolicyPeer{
 				{
-					IPBlock: &antreav1alpha1.IPBlock{
-						CIDR: "0.0.0.0/0",
-					},
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
 				},
 			},
 			Ports: []antreav1alpha1.NetworkPolicyPort{
 				{
-					Port:    &port,
-					EndPort: &endPort,
+					Protocol: &udp,
+					Port:     &dnsPort,
+				},
+				{
+					Protocol: &tcp,
+					Port:     &dnsPort,
 				},
 			},
 		},
+		{
+			Action: &drop,
+			To:     privatePeers,
+		},
+		{
+			Action: &allow,
+			To:     publicPeers,
+		},
 	}
 	appliedTo := []antreav1alpha1.NetworkPolicyPeer{
 		{
@@ -274,6 +362,7 @@ func newClusterNetworkPolicy(name string, labelSelector metav1.LabelSelector, ow
 	return &antreav1alpha1.ClusterNetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            name,
+			Labels:          map[string]string{"edge-net.io/network-profile": corev1alpha1.NetworkProfileBaseline},
 			OwnerReferences: ownerReferences,
 		},
 		Spec: antreav1alpha1.ClusterNetworkPolicySpec{
@@ -281,6 +370,7 @@ func newClusterNetworkPolicy(name string, labelSelector metav1.LabelSelector, ow
 			Priority:  5,
 			AppliedTo: appliedTo,
 			Ingress:   ingressRules,
+			Egress:    egressRules,
 		},
 	}
 }
@@ -294,7 +384,7 @@ func (f *fixture) newController() (*Controller, edgeinformers.SharedInformerFact
 	//kubeinformer := kubeinformers.NewSharedInformerFactory(f.kubeclientset, noResyncPeriodFunc())
 
 	controller := NewController(f.kubeclientset, f.edgenetclientset, f.antreaclientset,
-		edgeinformer.Core().V1alpha1().Tenants())
+		edgeinformer.Core().V1alpha1().Tenants(), false)
 
 	controller.tenantsSynced = alwaysReady
 	controller.recorder = &record.FakeRecorder{}
@@ -448,6 +538,15 @@ func checkAction(expected, actual core.Action, t *testing.T) {
 					a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expObject, object))
 			}
 		}
+	case core.ListActionImpl:
+		e, _ := expected.(core.ListActionImpl)
+		expNamespace := e.GetNamespace()
+		namespace := a.GetNamespace()
+
+		if expNamespace != namespace {
+			t.Errorf("Action %s %s has wrong namespace\nDiff:\n %s",
+				a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expNamespace, namespace))
+		}
 	case core.PatchActionImpl:
 		e, _ := expected.(core.PatchActionImpl)
 		expPatch := e.GetPatch()
@@ -513,12 +612,21 @@ func (f *fixture) expectUpdateRoleBindingAction(rolebinding *rbacv1.RoleBinding)
 func (f *fixture) expectCreateNetworkPolicyAction(networkpolicy *networkingv1.NetworkPolicy) {
 	f.kubeactions = append(f.kubeactions, core.NewCreateAction(schema.GroupVersionResource{Resource: "networkpolicies"}, networkpolicy.GetNamespace(), networkpolicy))
 }
+func (f *fixture) expectUpdateNetworkPolicyAction(networkpolicy *networkingv1.NetworkPolicy) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "networkpolicies"}, networkpolicy.GetNamespace(), networkpolicy))
+}
+func (f *fixture) expectDeleteNetworkPolicyAction(name, namespace string) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "networkpolicies"}, namespace, name))
+}
 func (f *fixture) expectCreateClusterNetworkPolicyAction(clusternetworkpolicy *antreav1alpha1.ClusterNetworkPolicy) {
 	f.antreaactions = append(f.antreaactions, core.NewRootCreateAction(schema.GroupVersionResource{Resource: "clusternetworkpolicies"}, clusternetworkpolicy))
 }
 func (f *fixture) expectDeleteClusterNetworkPolicyAction(name string) {
 	f.antreaactions = append(f.antreaactions, core.NewRootDeleteAction(schema.GroupVersionResource{Resource: "clusternetworkpolicies"}, name))
 }
+func (f *fixture) expectUpdateTenantAction(tenant *corev1alpha1.Tenant) {
+	f.edgenetactions = append(f.edgenetactions, core.NewRootUpdateAction(schema.GroupVersionResource{Resource: "tenants"}, tenant))
+}
 func (f *fixture) expectUpdateTenantStatusAction(tenant *corev1alpha1.Tenant) {
 	f.edgenetactions = append(f.edgenetactions, core.NewRootUpdateSubresourceAction(schema.GroupVersionResource{Resource: "tenants"}, "status", tenant))
 }
@@ -533,6 +641,46 @@ func (f *fixture) expectDeleteCollectionAction(namespace, resource, kind string)
 		f.edgenetactions = append(f.edgenetactions, core.NewDeleteCollectionAction(schema.GroupVersionResource{Resource: resource}, namespace, metav1.ListOptions{}))
 	}
 }
+func (f *fixture) expectDeleteAction(name, resource, kind string) {
+	switch kind {
+	case "kube":
+		f.kubeactions = append(f.kubeactions, core.NewRootDeleteAction(schema.GroupVersionResource{Resource: resource}, name))
+	default:
+		f.edgenetactions = append(f.edgenetactions, core.NewRootDeleteAction(schema.GroupVersionResource{Resource: resource}, name))
+	}
+}
+func (f *fixture) expectListAction(resource, kind string) {
+	switch kind {
+	case "kube":
+		f.kubeactions = append(f.kubeactions, core.NewRootListAction(schema.GroupVersionResource{Resource: resource}, schema.GroupVersionKind{}, metav1.ListOptions{}))
+	default:
+		f.edgenetactions = append(f.edgenetactions, core.NewRootListAction(schema.GroupVersionResource{Resource: resource}, schema.GroupVersionKind{}, metav1.ListOptions{}))
+	}
+}
+func (f *fixture) expectListNamespacedAction(namespace, resource string) {
+	f.kubeactions = append(f.kubeactions, core.NewListAction(schema.GroupVersionResource{Resource: resource}, schema.GroupVersionKind{}, namespace, metav1.ListOptions{}))
+}
+func (f *fixture) expectUpdateDeploymentAction(deployment *appsv1.Deployment) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "deployments"}, deployment.GetNamespace(), deployment))
+}
+func (f *fixture) expectUpdateDaemonSetAction(daemonset *appsv1.DaemonSet) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "daemonsets"}, daemonset.GetNamespace(), daemonset))
+}
+func (f *fixture) expectUpdateJobAction(job *batchv1.Job) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "jobs"}, job.GetNamespace(), job))
+}
+func (f *fixture) expectDeletePodAction(name, namespace string) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "pods"}, namespace, name))
+}
+func (f *fixture) expectGetNodeContributionAction(name string) {
+	f.edgenetactions = append(f.edgenetactions, core.NewRootGetAction(schema.GroupVersionResource{Resource: "nodecontributions"}, name))
+}
+func (f *fixture) expectPatchNodeAction(name string, patch []byte) {
+	f.kubeactions = append(f.kubeactions, core.NewRootPatchAction(schema.GroupVersionResource{Resource: "nodes"}, name, types.JSONPatchType, patch))
+}
+func (f *fixture) expectUpdateVPNPeerAction(vpnpeer *networkingv1alpha1.VPNPeer) {
+	f.edgenetactions = append(f.edgenetactions, core.NewRootUpdateAction(schema.GroupVersionResource{Resource: "vpnpeers"}, vpnpeer))
+}
 func (f *fixture) expectRootDeleteCollectionAction(resource string, listOptions metav1.ListOptions) {
 	f.kubeactions = append(f.kubeactions, core.NewRootDeleteCollectionAction(schema.GroupVersionResource{Group: rbacv1.SchemeGroupVersion.Group, Version: rbacv1.SchemeGroupVersion.Version, Resource: resource}, listOptions))
 }
@@ -549,6 +697,9 @@ func getKey(tenant *corev1alpha1.Tenant, t *testing.T) string {
 func TestCreateTenant(t *testing.T) {
 	f := newFixture(t)
 	tenant := newTenant("tenant1", true, true)
+	tenant.SetFinalizers(nil)
+	tenantFinalized := tenant.DeepCopy()
+	tenantFinalized.SetFinalizers([]string{tenantFinalizer})
 
 	kubenamespace := newNamespace("kube-system", nil, nil, nil)
 	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
@@ -564,6 +715,7 @@ func TestCreateTenant(t *testing.T) {
 	f.kubeobjects = append(f.kubeobjects, kubenamespace)
 
 	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectUpdateTenantAction(tenantFinalized)
 	f.expectCreateNamespaceAction(namespace)
 	f.expectCreateClusterRoleAction(clusterrole)
 	f.expectCreateClusterRoleBindingAction(clusterrolebinding)
@@ -582,7 +734,7 @@ func TestTenantEstablishment(t *testing.T) {
 	kubenamespace := newNamespace("kube-system", nil, nil, nil)
 	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
-	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/subtenant": "false", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": string(kubenamespace.GetUID())}}
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
 	networkpolicy := newNetworkPolicy("baseline", tenant.GetName(), labelSelector)
 	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 
@@ -597,6 +749,8 @@ func TestTenantEstablishment(t *testing.T) {
 
 	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
 	f.expectCreateNetworkPolicyAction(networkpolicy)
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfileRestricted, tenant.GetName())
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfilePrivileged, tenant.GetName())
 	f.expectCreateClusterNetworkPolicyAction(clusternetworkpolicy)
 	f.expectCreateRoleBindingAction(rolebinding)
 	f.expectUpdateTenantStatusAction(tenant)
@@ -607,39 +761,166 @@ func TestTenantEstablishment(t *testing.T) {
 func TestTenantDisabled(t *testing.T) {
 	f := newFixture(t)
 	tenant := newTenant("tenant3", true, false)
+	tenant.SetUID("tenant3-uid")
 	tenant.Status.Failed = 0
 	tenant.Status.State = corev1alpha1.StatusEstablished
 	tenant.Status.Message = messageEstablished
 
 	kubenamespace := newNamespace("kube-system", nil, nil, nil)
 	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
-	clusterrole := newClusterRole(tenant.GetName(), tenant.GetName(), []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
-	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
-	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/subtenant": "false", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": string(kubenamespace.GetUID())}}
-
-	networkpolicy := newNetworkPolicy("baseline", tenant.GetName(), labelSelector)
-	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	clusterrolebindingRevoked := clusterrolebinding.DeepCopy()
+	clusterrolebindingRevoked.SetAnnotations(map[string]string{multitenancy.SuspendedSubjectsAnnotation: fmt.Sprintf(`[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"%s"}]`, tenant.Spec.Contact.Email)})
+	clusterrolebindingRevoked.Subjects = nil
+	ownerrolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
+	ownerrolebindingRevoked := ownerrolebinding.DeepCopy()
+	ownerrolebindingRevoked.SetAnnotations(map[string]string{multitenancy.SuspendedSubjectsAnnotation: fmt.Sprintf(`[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"%s"}]`, tenant.Spec.Contact.Email)})
+	ownerrolebindingRevoked.Subjects = nil
+	adminrolebinding := newRoleBinding("admin", tenant.GetName(), fmt.Sprintf("jane.doe@%s.org", tenant.GetName()), nil)
+	adminrolebinding.RoleRef.Name = corev1alpha1.TenantAdminClusterRoleName
+	adminrolebindingRevoked := adminrolebinding.DeepCopy()
+	adminrolebindingRevoked.SetAnnotations(map[string]string{multitenancy.SuspendedSubjectsAnnotation: fmt.Sprintf(`[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"jane.doe@%s.org"}]`, tenant.GetName())})
+	adminrolebindingRevoked.Subjects = nil
+	deployment := newDeployment("web", tenant.GetName(), 3, nil)
+	deploymentScaled := newDeployment("web", tenant.GetName(), 0, map[string]string{multitenancy.SuspendedReplicasAnnotation: "3"})
+	daemonset := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: tenant.GetName()}}
+	daemonset.Spec.Template.Spec.NodeSelector = map[string]string{"kubernetes.io/os": "linux"}
+	daemonsetSuspended := daemonset.DeepCopy()
+	daemonsetSuspended.SetAnnotations(map[string]string{multitenancy.SuspendedNodeSelectorAnnotation: `{"kubernetes.io/os":"linux"}`})
+	daemonsetSuspended.Spec.Template.Spec.NodeSelector = map[string]string{multitenancy.SuspendedAnnotation: "true"}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: tenant.GetName()}}
+	jobSuspended := job.DeepCopy()
+	jobSuspended.SetAnnotations(map[string]string{multitenancy.SuspendedAnnotation: "true"})
+	suspend := true
+	jobSuspended.Spec.Suspend = &suspend
+	barePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: tenant.GetName()}}
+	controlledPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: tenant.GetName(), OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1.edge-net.io", OwnerReferences: []metav1.OwnerReference{tenant.MakeOwnerReference()}}}
 
 	f.tenantLister = append(f.tenantLister, tenant)
 	f.edgenetobjects = append(f.edgenetobjects, tenant)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, clusterrolebinding, ownerrolebinding, adminrolebinding, deployment, daemonset, job, barePod, controlledPod, node)
 
-	f.namespaceLister = append(f.namespaceLister, kubenamespace, namespace)
-	f.clusterroleLister = append(f.clusterroleLister, clusterrole)
-	f.clusterrolebindingLister = append(f.clusterrolebindingLister, clusterrolebinding)
-	f.networkpolicyLister = append(f.networkpolicyLister, networkpolicy)
-	f.clusternetworkpolicyLister = append(f.clusternetworkpolicyLister, clusternetworkpolicy)
-	f.rolebindingLister = append(f.rolebindingLister, rolebinding)
-	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, clusterrole, clusterrolebinding, rolebinding, networkpolicy)
-	f.antreaobjects = append(f.antreaobjects, clusternetworkpolicy)
+	nodePatch, _ := json.Marshal([]interface{}{map[string]interface{}{"op": "replace", "path": "/spec/unschedulable", "value": true}})
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectGetRootAction(clusterrolebinding.GetName(), "clusterrolebindings", "kube")
+	f.expectUpdateClusterRoleBindingAction(clusterrolebindingRevoked)
+	f.expectListNamespacedAction(tenant.GetName(), "deployments")
+	f.expectUpdateDeploymentAction(deploymentScaled)
+	f.expectListNamespacedAction(tenant.GetName(), "statefulsets")
+	f.expectListNamespacedAction(tenant.GetName(), "daemonsets")
+	f.expectUpdateDaemonSetAction(daemonsetSuspended)
+	f.expectListNamespacedAction(tenant.GetName(), "cronjobs")
+	f.expectListNamespacedAction(tenant.GetName(), "jobs")
+	f.expectUpdateJobAction(jobSuspended)
+	f.expectListNamespacedAction(tenant.GetName(), "pods")
+	f.expectDeletePodAction(barePod.GetName(), tenant.GetName())
+	f.expectListNamespacedAction(tenant.GetName(), "rolebindings")
+	f.expectUpdateRoleBindingAction(adminrolebindingRevoked)
+	f.expectUpdateRoleBindingAction(ownerrolebindingRevoked)
+	f.expectListAction("nodes", "kube")
+	f.expectPatchNodeAction(node.GetName(), nodePatch)
+	f.expectUpdateTenantStatusAction(tenant)
 
-	listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenant.GetName(), string(tenant.GetUID()), string(kubenamespace.GetUID()))}
+	f.run(getKey(tenant, t))
+}
+
+func TestTenantResumed(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant9", true, true)
+	tenant.SetUID("tenant9-uid")
+	tenant.Status.Failed = 0
+	tenant.Status.State = corev1alpha1.StatusSuspended
+	tenant.Status.Message = messageSuspended
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	adminrolebinding := newRoleBinding("admin", tenant.GetName(), fmt.Sprintf("jane.doe@%s.org", tenant.GetName()), nil)
+	adminrolebinding.RoleRef.Name = corev1alpha1.TenantAdminClusterRoleName
+	adminrolebindingRevoked := adminrolebinding.DeepCopy()
+	adminrolebindingRevoked.SetAnnotations(map[string]string{multitenancy.SuspendedSubjectsAnnotation: fmt.Sprintf(`[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"jane.doe@%s.org"}]`, tenant.GetName())})
+	adminrolebindingRevoked.Subjects = nil
+	adminrolebindingRestored := adminrolebinding.DeepCopy()
+	adminrolebindingRestored.SetAnnotations(map[string]string{})
+	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	clusterrolebindingRevoked := clusterrolebinding.DeepCopy()
+	clusterrolebindingRevoked.SetAnnotations(map[string]string{multitenancy.SuspendedSubjectsAnnotation: fmt.Sprintf(`[{"kind":"User","apiGroup":"rbac.authorization.k8s.io","name":"%s"}]`, tenant.Spec.Contact.Email)})
+	clusterrolebindingRevoked.Subjects = nil
+	clusterrolebindingRestored := clusterrolebinding.DeepCopy()
+	clusterrolebindingRestored.SetAnnotations(map[string]string{})
+	deployment := newDeployment("web", tenant.GetName(), 3, map[string]string{})
+	deploymentScaled := newDeployment("web", tenant.GetName(), 0, map[string]string{multitenancy.SuspendedReplicasAnnotation: "3"})
+	daemonset := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: tenant.GetName(), Annotations: map[string]string{}}}
+	daemonsetSuspended := daemonset.DeepCopy()
+	daemonsetSuspended.SetAnnotations(map[string]string{multitenancy.SuspendedNodeSelectorAnnotation: "null"})
+	daemonsetSuspended.Spec.Template.Spec.NodeSelector = map[string]string{multitenancy.SuspendedAnnotation: "true"}
+	suspend, resume := true, false
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: tenant.GetName(), Annotations: map[string]string{}}, Spec: batchv1.JobSpec{Suspend: &resume}}
+	jobSuspended := job.DeepCopy()
+	jobSuspended.SetAnnotations(map[string]string{multitenancy.SuspendedAnnotation: "true"})
+	jobSuspended.Spec.Suspend = &suspend
+	nodecontribution := &corev1alpha1.NodeContribution{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "node-1-uid"}, Spec: corev1alpha1.NodeContributionSpec{Enabled: true}}
+	contributionReference := nodecontribution.MakeOwnerReference()
+	takeControl := true
+	contributionReference.Controller = &takeControl
+	tenantReference := tenant.MakeOwnerReference()
+	tenantReference.Controller = nil
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1.edge-net.io", OwnerReferences: []metav1.OwnerReference{contributionReference, tenantReference}}, Spec: corev1.NodeSpec{Unschedulable: true}}
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant, nodecontribution)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, clusterrolebindingRevoked, adminrolebindingRevoked, deploymentScaled, daemonsetSuspended, jobSuspended, node)
+
+	nodePatch, _ := json.Marshal([]interface{}{map[string]interface{}{"op": "replace", "path": "/spec/unschedulable", "value": false}})
 	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
-	f.expectRootDeleteCollectionAction("clusterroles", listOptions)
-	f.expectRootDeleteCollectionAction("clusterrolebindings", listOptions)
-	f.expectDeleteCollectionAction(tenant.GetName(), "rolebindings", "kube")
-	f.expectDeleteCollectionAction(tenant.GetName(), "sliceclaims", "edgenet")
-	f.expectDeleteCollectionAction(tenant.GetName(), "subnamespaces", "edgenet")
+	f.expectListNamespacedAction(tenant.GetName(), "deployments")
+	f.expectUpdateDeploymentAction(deployment)
+	f.expectListNamespacedAction(tenant.GetName(), "statefulsets")
+	f.expectListNamespacedAction(tenant.GetName(), "daemonsets")
+	f.expectUpdateDaemonSetAction(daemonset)
+	f.expectListNamespacedAction(tenant.GetName(), "cronjobs")
+	f.expectListNamespacedAction(tenant.GetName(), "jobs")
+	f.expectUpdateJobAction(job)
+	f.expectListNamespacedAction(tenant.GetName(), "rolebindings")
+	f.expectUpdateRoleBindingAction(adminrolebindingRestored)
+	f.expectGetRootAction(clusterrolebinding.GetName(), "clusterrolebindings", "kube")
+	f.expectUpdateClusterRoleBindingAction(clusterrolebindingRestored)
+	f.expectListAction("nodes", "kube")
+	f.expectGetNodeContributionAction(nodecontribution.GetName())
+	f.expectPatchNodeAction(node.GetName(), nodePatch)
+	f.expectUpdateTenantStatusAction(tenant)
+
+	f.run(getKey(tenant, t))
+}
+
+func TestTenantResumedFromPartialSuspension(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant10", true, true)
+	tenant.SetUID("tenant10-uid")
+	tenant.Status.Failed = 0
+	tenant.Status.State = corev1alpha1.StatusSuspending
+	tenant.Status.Message = messageRoleBindingRevocationFailed
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	deployment := newDeployment("web", tenant.GetName(), 3, map[string]string{})
+	deploymentScaled := newDeployment("web", tenant.GetName(), 0, map[string]string{multitenancy.SuspendedReplicasAnnotation: "3"})
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, deploymentScaled)
+
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectListNamespacedAction(tenant.GetName(), "deployments")
+	f.expectUpdateDeploymentAction(deployment)
+	f.expectListNamespacedAction(tenant.GetName(), "statefulsets")
+	f.expectListNamespacedAction(tenant.GetName(), "daemonsets")
+	f.expectListNamespacedAction(tenant.GetName(), "cronjobs")
+	f.expectListNamespacedAction(tenant.GetName(), "jobs")
+	f.expectListNamespacedAction(tenant.GetName(), "rolebindings")
+	f.expectGetRootAction(fmt.Sprintf("edgenet:tenants:%s-owner", tenant.GetName()), "clusterrolebindings", "kube")
+	f.expectListAction("nodes", "kube")
+	f.expectUpdateTenantStatusAction(tenant)
 
 	f.run(getKey(tenant, t))
 }
@@ -656,7 +937,7 @@ func TestReconcileDoNothing(t *testing.T) {
 	clusterrole := newClusterRole(tenant.GetName(), tenant.GetName(), []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
-	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/subtenant": "false", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": string(kubenamespace.GetUID())}}
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
 
 	networkpolicy := newNetworkPolicy("baseline", tenant.GetName(), labelSelector)
 	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
@@ -694,7 +975,7 @@ func TestReconcile(t *testing.T) {
 	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
-	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/subtenant": "false", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": string(kubenamespace.GetUID())}}
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
 	networkpolicy := newNetworkPolicy("baseline", tenant.GetName(), labelSelector)
 	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 
@@ -726,7 +1007,7 @@ func TestReconcileThroughStatusCoreNamespaceCreated(t *testing.T) {
 	clusterrole := newClusterRole(tenant.GetName(), tenant.GetName(), []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
 	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
-	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/subtenant": "false", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": string(kubenamespace.GetUID())}}
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
 
 	networkpolicy := newNetworkPolicy("baseline", tenant.GetName(), labelSelector)
 	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
@@ -745,6 +1026,10 @@ func TestReconcileThroughStatusCoreNamespaceCreated(t *testing.T) {
 
 	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
 	f.expectCreateNetworkPolicyAction(networkpolicy)
+	f.expectGetAction(networkpolicy.GetName(), networkpolicy.GetNamespace(), "networkpolicies")
+	f.expectUpdateNetworkPolicyAction(networkpolicy)
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfileRestricted, tenant.GetName())
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfilePrivileged, tenant.GetName())
 	f.expectDeleteClusterNetworkPolicyAction(clusternetworkpolicy.GetName())
 	f.expectCreateRoleBindingAction(rolebinding)
 	f.expectGetAction(rolebinding.GetName(), rolebinding.GetNamespace(), "rolebindings")
@@ -754,6 +1039,125 @@ func TestReconcileThroughStatusCoreNamespaceCreated(t *testing.T) {
 	f.run(getKey(tenant, t))
 }
 
+func TestReconcileNetworkProfileChange(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant10", true, true)
+	tenant.Spec.NetworkProfile = corev1alpha1.NetworkProfileRestricted
+	tenant.Status.Failed = 0
+	tenant.Status.State = corev1alpha1.StatusEstablished
+	tenant.Status.Message = messageEstablished
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
+	networkpolicy := newNetworkPolicy(corev1alpha1.NetworkProfileBaseline, tenant.GetName(), labelSelector)
+	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, clusterrolebinding, rolebinding, networkpolicy)
+	f.antreaobjects = append(f.antreaobjects, clusternetworkpolicy)
+
+	// The policies of the former profile are left in place until the tenant gets back to the network policy step
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectGetAction(rolebinding.GetName(), rolebinding.GetNamespace(), "rolebindings")
+	f.expectGetAction(corev1alpha1.NetworkProfileRestricted, tenant.GetName(), "networkpolicies")
+	f.expectGetRootAction(clusternetworkpolicy.GetName(), "clusternetworkpolicies", "antrea")
+	f.expectGetRootAction(clusterrolebinding.GetName(), "clusterrolebindings", "kube")
+	f.expectGetRootAction(namespace.GetName(), "namespaces", "kube")
+	f.expectUpdateTenantStatusAction(tenant)
+
+	f.run(getKey(tenant, t))
+}
+
+func TestReconcileNetworkPolicyDrift(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant12", true, true)
+	tenant.Status.Failed = 0
+	tenant.Status.State = corev1alpha1.StatusEstablished
+	tenant.Status.Message = messageEstablished
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	clusterrolebinding := newClusterRoleBinding(tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
+	labelSelector := *multitenancy.TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), "")
+	networkpolicy := newNetworkPolicy(corev1alpha1.NetworkProfileBaseline, tenant.GetName(), labelSelector)
+	clusternetworkpolicy := newClusterNetworkPolicy(tenant.GetName(), labelSelector, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	// The policy still carries the name of the profile but someone opened its egress up
+	driftedNetworkpolicy := networkpolicy.DeepCopy()
+	driftedNetworkpolicy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{}}
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, clusterrolebinding, rolebinding, driftedNetworkpolicy)
+	f.antreaobjects = append(f.antreaobjects, clusternetworkpolicy)
+
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectGetAction(rolebinding.GetName(), rolebinding.GetNamespace(), "rolebindings")
+	f.expectGetAction(networkpolicy.GetName(), networkpolicy.GetNamespace(), "networkpolicies")
+	f.expectCreateNetworkPolicyAction(networkpolicy)
+	f.expectGetAction(networkpolicy.GetName(), networkpolicy.GetNamespace(), "networkpolicies")
+	f.expectUpdateNetworkPolicyAction(networkpolicy)
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfileRestricted, tenant.GetName())
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfilePrivileged, tenant.GetName())
+	f.expectGetRootAction(clusternetworkpolicy.GetName(), "clusternetworkpolicies", "antrea")
+	f.expectGetRootAction(clusterrolebinding.GetName(), "clusterrolebindings", "kube")
+	f.expectGetRootAction(namespace.GetName(), "namespaces", "kube")
+
+	f.run(getKey(tenant, t))
+}
+
+func TestTenantRestrictedProfile(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant11", true, true)
+	tenant.Spec.NetworkProfile = corev1alpha1.NetworkProfileRestricted
+	tenant.Status.Failed = 0
+	tenant.Status.State = corev1alpha1.StatusCoreNamespaceCreated
+	tenant.Status.Message = messageCreated
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	namespace := newNamespace(tenant.GetName(), map[string]string{"edge-net.io/kind": "core", "edge-net.io/tenant": tenant.GetName(), "edge-net.io/tenant-uid": string(tenant.GetUID()), "edge-net.io/cluster-uid": ""}, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "edge-net.io/access=public,edge-net.io/slice=none"}, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	subnamespace := newNamespace(fmt.Sprintf("%s-workspace", tenant.GetName()), map[string]string{"edge-net.io/generated": "true", "edge-net.io/kind": "sub", "edge-net.io/tenant": tenant.GetName()}, nil, nil)
+	rolebinding := newRoleBinding(corev1alpha1.TenantOwnerClusterRoleName, tenant.GetName(), tenant.Spec.Contact.Email, map[string]string{"edge-net.io/generated": "true", "edge-net.io/notification": "true"})
+	clusternetworkpolicy := makeClusterNetworkPolicy(tenant, "", true, []metav1.OwnerReference{tenant.MakeOwnerReference()})
+	multitenancyManager := multitenancy.NewManager(nil, nil)
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, namespace, subnamespace)
+
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectCreateNetworkPolicyAction(multitenancyManager.MakeNetworkPolicy(tenant.GetName(), tenant, ""))
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfileBaseline, tenant.GetName())
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfilePrivileged, tenant.GetName())
+	relabeledSubnamespace := subnamespace.DeepCopy()
+	relabeledSubnamespace.Labels["edge-net.io/tenant-uid"] = string(tenant.GetUID())
+	relabeledSubnamespace.Labels["edge-net.io/cluster-uid"] = ""
+	f.expectUpdateNamespaceAction(relabeledSubnamespace)
+	f.expectCreateNetworkPolicyAction(multitenancyManager.MakeNetworkPolicy(subnamespace.GetName(), tenant, ""))
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfileBaseline, subnamespace.GetName())
+	f.expectDeleteNetworkPolicyAction(corev1alpha1.NetworkProfilePrivileged, subnamespace.GetName())
+	f.expectCreateClusterNetworkPolicyAction(clusternetworkpolicy)
+	f.expectCreateRoleBindingAction(rolebinding)
+	f.expectUpdateTenantStatusAction(tenant)
+
+	f.run(getKey(tenant, t))
+
+	// Restricted tenants can reach nothing but their own namespaces and the cluster DNS
+	for _, rule := range clusternetworkpolicy.Spec.Ingress {
+		if *rule.Action == antreav1alpha1.RuleActionAllow && rule.From[0].NamespaceSelector == nil {
+			t.Errorf("Restricted profile allows ingress from %v", rule.From)
+		}
+	}
+	lastEgressRule := clusternetworkpolicy.Spec.Egress[len(clusternetworkpolicy.Spec.Egress)-1]
+	if *lastEgressRule.Action != antreav1alpha1.RuleActionDrop || lastEgressRule.To[0].IPBlock.CIDR != "0.0.0.0/0" {
+		t.Errorf("Restricted profile does not drop egress traffic to the public Internet: %v", lastEgressRule)
+	}
+}
+
 func TestReconcileThroughStatusReconciliation(t *testing.T) {
 	f := newFixture(t)
 	tenant := newTenant("tenant7", true, true)
@@ -788,3 +1192,119 @@ func TestReconcileThroughStatusReconciliation(t *testing.T) {
 
 	f.run(getKey(tenant, t))
 }
+
+func TestTenantDeletion(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant8", true, true)
+	tenant.SetUID("tenant8-uid")
+	tenant.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	tenant.Status.State = corev1alpha1.StatusEstablished
+	tenant.Status.Message = messageEstablished
+	tenantFinalized := tenant.DeepCopy()
+	tenantFinalized.SetFinalizers([]string{})
+	tenantFinalized.Status.State = corev1alpha1.StatusTerminating
+	tenantFinalized.Status.Message = messageNamespacesTerminating
+
+	kubenamespace := newNamespace("kube-system", nil, nil, nil)
+	contributionReference := metav1.OwnerReference{APIVersion: corev1alpha1.SchemeGroupVersion.String(), Kind: "NodeContribution", Name: "node-1", UID: "node-1-uid"}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1.edge-net.io", OwnerReferences: []metav1.OwnerReference{contributionReference, tenant.MakeOwnerReference()}}}
+	vpnpeer := &networkingv1alpha1.VPNPeer{ObjectMeta: metav1.ObjectMeta{Name: "node-1", OwnerReferences: []metav1.OwnerReference{contributionReference, tenant.MakeOwnerReference()}}}
+	vpnpeerReleased := vpnpeer.DeepCopy()
+	vpnpeerReleased.SetOwnerReferences([]metav1.OwnerReference{contributionReference})
+	// The node and the peer that only the tenant owns are released rather than left to the garbage collector
+	orphanNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2.edge-net.io", OwnerReferences: []metav1.OwnerReference{tenant.MakeOwnerReference()}}}
+	orphanVPNPeer := &networkingv1alpha1.VPNPeer{ObjectMeta: metav1.ObjectMeta{Name: "node-2", OwnerReferences: []metav1.OwnerReference{tenant.MakeOwnerReference()}}}
+	orphanVPNPeerReleased := orphanVPNPeer.DeepCopy()
+	orphanVPNPeerReleased.SetOwnerReferences([]metav1.OwnerReference{})
+	tenantresourcequota := &corev1alpha1.TenantResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: tenant.GetName(), OwnerReferences: []metav1.OwnerReference{tenant.MakeOwnerReference()}}}
+
+	f.tenantLister = append(f.tenantLister, tenant)
+	f.edgenetobjects = append(f.edgenetobjects, tenant, vpnpeer, orphanVPNPeer, tenantresourcequota)
+	f.kubeobjects = append(f.kubeobjects, kubenamespace, node, orphanNode)
+
+	nodePatch, _ := json.Marshal([]interface{}{map[string]interface{}{"op": "add", "path": "/metadata/ownerReferences", "value": []metav1.OwnerReference{contributionReference}}})
+	orphanNodePatch, _ := json.Marshal([]interface{}{map[string]interface{}{"op": "add", "path": "/metadata/ownerReferences", "value": []metav1.OwnerReference{}}})
+	listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("edge-net.io/tenant=%s,edge-net.io/tenant-uid=%s,edge-net.io/cluster-uid=%s", tenant.GetName(), string(tenant.GetUID()), string(kubenamespace.GetUID()))}
+	// Every step is reported in the status before it runs
+	f.expectGetRootAction(kubenamespace.GetName(), "namespaces", "kube")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteClusterNetworkPolicyAction(tenant.GetName())
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectRootDeleteCollectionAction("clusterroles", listOptions)
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectRootDeleteCollectionAction("clusterrolebindings", listOptions)
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteCollectionAction(tenant.GetName(), "rolebindings", "kube")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteCollectionAction(tenant.GetName(), "sliceclaims", "edgenet")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteCollectionAction(tenant.GetName(), "subnamespaces", "edgenet")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteAction(fmt.Sprintf("edgenet:tenants:%s-owner", tenant.GetName()), "clusterrolebindings", "kube")
+	f.expectDeleteAction(fmt.Sprintf("edgenet:tenants:%s-owner", tenant.GetName()), "clusterroles", "kube")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteCollectionAction(tenant.GetName(), "clusters", "edgenet")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectDeleteAction(tenantresourcequota.GetName(), "tenantresourcequotas", "edgenet")
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectListAction("nodes", "kube")
+	f.expectPatchNodeAction(node.GetName(), nodePatch)
+	f.expectPatchNodeAction(orphanNode.GetName(), orphanNodePatch)
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectListAction("vpnpeers", "edgenet")
+	f.expectUpdateVPNPeerAction(vpnpeerReleased)
+	f.expectUpdateVPNPeerAction(orphanVPNPeerReleased)
+	f.expectUpdateTenantStatusAction(tenant)
+	f.expectUpdateTenantAction(tenantFinalized)
+
+	f.run(getKey(tenant, t))
+}
+
+func TestTenantDeletionRetry(t *testing.T) {
+	f := newFixture(t)
+	tenant := newTenant("tenant12", true, true)
+	tenant.SetUID("tenant12-uid")
+	tenant.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	tenant.Status.State = corev1alpha1.StatusEstablished
+	tenant.Status.Message = messageEstablished
+	f.edgenetobjects = append(f.edgenetobjects, tenant)
+
+	c, _ := f.newController()
+	listNodesFails := true
+	f.kubeclientset.PrependReactor("list", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+		if listNodesFails {
+			return true, nil, fmt.Errorf("nodes unavailable")
+		}
+		return false, nil, nil
+	})
+
+	// The finalizer stays until the failed step succeeds
+	c.terminate(tenant.DeepCopy(), "")
+	tenantFailed, err := f.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), tenant.GetName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenantFailed.Status.State != corev1alpha1.StatusTerminating || tenantFailed.Status.Message != messageNodeReleaseFailed {
+		t.Errorf("Unexpected status %v", tenantFailed.Status)
+	}
+	if !hasFinalizer(tenantFailed.GetFinalizers(), tenantFinalizer) {
+		t.Error("Finalizer removed although releasing the nodes failed")
+	}
+
+	// The retry picks up from the step that failed
+	listNodesFails = false
+	f.kubeclientset.ClearActions()
+	c.terminate(tenantFailed, "")
+	for _, action := range f.kubeclientset.Actions() {
+		if action.Matches("delete-collection", "clusterroles") {
+			t.Errorf("Retry repeated a step that succeeded: %v", action)
+		}
+	}
+	tenantTerminated, err := f.edgenetclientset.CoreV1alpha1().Tenants().Get(context.TODO(), tenant.GetName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasFinalizer(tenantTerminated.GetFinalizers(), tenantFinalizer) {
+		t.Error("Finalizer kept after every step succeeded")
+	}
+}
@@ -212,9 +212,7 @@ func (c *Controller) setClusterGeolocation(clusterCopy *federationv1alpha1.Clust
 	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, c.edgenetclientset, nil)
 	klog.Infof("IP: %s", clusterCopy.Spec.Server)
 	if geoLabels, ok := multiproviderManager.GetGeolocationLabelsByIP(
-		c.maxmindURL,
-		c.maxmindAccountID,
-		c.maxmindLicenseKey,
+		multiprovider.NewMaxmindGeoIPProvider(c.maxmindURL, c.maxmindAccountID, c.maxmindLicenseKey),
 		clusterCopy.Spec.Server,
 		false); ok {
 		clusterLabels := clusterCopy.GetLabels()
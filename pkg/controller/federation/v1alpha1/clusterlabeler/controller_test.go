@@ -130,6 +130,14 @@ func TestAssigningGeoLabels(t *testing.T) {
 		"edge-net.io/isp":         "Renater",
 		"edge-net.io/as":          "Renater",
 		"edge-net.io/asn":         "1307",
+		"edge-net.io/geohash-1":   "u",
+		"edge-net.io/geohash-2":   "u0",
+		"edge-net.io/geohash-3":   "u09",
+		"edge-net.io/geohash-4":   "u09w",
+		"edge-net.io/geohash-5":   "u09wn",
+		"edge-net.io/geohash-6":   "u09wnv",
+		"edge-net.io/region":      "Europe.FR.IDF",
+		"edge-net.io/zone":        "Europe.FR.IDF.Pantin",
 	}
 
 	// Create a US Node
@@ -147,6 +155,14 @@ func TestAssigningGeoLabels(t *testing.T) {
 		"edge-net.io/isp":         "University_of_Maryland",
 		"edge-net.io/as":          "MAX-GIGAPOP",
 		"edge-net.io/asn":         "10886",
+		"edge-net.io/geohash-1":   "d",
+		"edge-net.io/geohash-2":   "dq",
+		"edge-net.io/geohash-3":   "dqc",
+		"edge-net.io/geohash-4":   "dqcm",
+		"edge-net.io/geohash-5":   "dqcmc",
+		"edge-net.io/geohash-6":   "dqcmc7",
+		"edge-net.io/region":      "North_America.US.MD",
+		"edge-net.io/zone":        "North_America.US.MD.College_Park",
 	}
 
 	cases := map[string]struct {
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodelatency
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+const (
+	// probeAttempts is the number of connections opened to measure a round-trip time
+	probeAttempts = 5
+	// probeTimeout is how long a connection can take before it counts as lost
+	probeTimeout = 2 * time.Second
+	// probeConcurrency is the number of nodes and landmarks probed at the same time
+	probeConcurrency = 16
+)
+
+// Agent measures the round-trip times from the node it runs on to the other nodes and to the landmarks,
+// and answers the probes of the agents on the other nodes
+type Agent struct {
+	// edgenetclientset is a clientset for the EdgeNet API groups
+	edgenetclientset clientset.Interface
+
+	nodesLister corelisters.NodeLister
+	nodesSynced cache.InformerSynced
+
+	// nodeName is the name of the node the agent runs on
+	nodeName  string
+	landmarks []multiprovider.Landmark
+	// port is where the agents listen for the probes
+	port     int
+	interval time.Duration
+}
+
+// NewAgent returns a new agent for the node, probing the landmarks given by the flags
+func NewAgent(
+	edgenetclientset clientset.Interface,
+	nodeInformer coreinformers.NodeInformer,
+	nodeName string) (*Agent, error) {
+
+	agent := &Agent{
+		edgenetclientset: edgenetclientset,
+		nodesLister:      nodeInformer.Lister(),
+		nodesSynced:      nodeInformer.Informer().HasSynced,
+		nodeName:         nodeName,
+		port:             7061,
+		interval:         5 * time.Minute,
+	}
+	if flag.Lookup("probe-port") != nil {
+		agent.port = flag.Lookup("probe-port").Value.(flag.Getter).Get().(int)
+	}
+	if flag.Lookup("probe-interval") != nil {
+		agent.interval = flag.Lookup("probe-interval").Value.(flag.Getter).Get().(time.Duration)
+	}
+	if flag.Lookup("landmarks") != nil {
+		landmarks, err := multiprovider.ParseLandmarks(flag.Lookup("landmarks").Value.(flag.Getter).Get().(string))
+		if err != nil {
+			return nil, err
+		}
+		agent.landmarks = landmarks
+	}
+	return agent, nil
+}
+
+// Run listens for the probes of the other agents, and measures the round-trip times at every interval
+// until stopCh is closed
+func (a *Agent) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-stopCh
+		listener.Close()
+	}()
+	go serve(listener)
+
+	klog.V(4).Infoln("Waiting for informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh, a.nodesSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	klog.V(4).Infof("Measuring round-trip times from %s every %s", a.nodeName, a.interval)
+	wait.Until(func() {
+		if err := a.measure(); err != nil {
+			klog.Infoln(err)
+		}
+	}, a.interval, stopCh)
+	return nil
+}
+
+// serve accepts the connections of the probes and closes them right away, as the time the connection takes to be
+// established is all the probes need
+func serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}
+}
+
+// measure probes the other ready nodes and the landmarks, then stores the round-trip times in the Node Latency
+// resource of the node
+func (a *Agent) measure() error {
+	node, err := a.nodesLister.Get(a.nodeName)
+	if err != nil {
+		return err
+	}
+	nodes, err := a.nodesLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	targets := []*networkingv1alpha1.LatencyMeasurement{}
+	for _, peer := range nodes {
+		if peer.GetName() == a.nodeName || multiprovider.GetConditionReadyStatus(peer) != "True" {
+			continue
+		}
+		// The internal address is the one the nodes reach each other at, be it over the VPN
+		internalIP, externalIP := multiprovider.GetNodeIPAddresses(peer)
+		address := internalIP
+		if address == "" {
+			address = externalIP
+		}
+		if address == "" {
+			continue
+		}
+		targets = append(targets, &networkingv1alpha1.LatencyMeasurement{Name: peer.GetName(), Address: net.JoinHostPort(address, strconv.Itoa(a.port))})
+	}
+	landmarks := []*networkingv1alpha1.LatencyMeasurement{}
+	for _, landmark := range a.landmarks {
+		landmarks = append(landmarks, &networkingv1alpha1.LatencyMeasurement{Name: landmark.Name, Address: landmark.Address})
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, probeConcurrency)
+	for _, measurement := range append(append([]*networkingv1alpha1.LatencyMeasurement{}, targets...), landmarks...) {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(measurement *networkingv1alpha1.LatencyMeasurement) {
+			defer wg.Done()
+			measurement.RTT, measurement.Loss = multiprovider.ProbeRTT(measurement.Address, probeAttempts, probeTimeout)
+			<-semaphore
+		}(measurement)
+	}
+	wg.Wait()
+
+	status := networkingv1alpha1.NodeLatencyStatus{UpdateTimestamp: &metav1.Time{Time: time.Now()}}
+	for _, measurement := range targets {
+		status.Nodes = append(status.Nodes, *measurement)
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool { return status.Nodes[i].Name < status.Nodes[j].Name })
+	for _, measurement := range landmarks {
+		status.Landmarks = append(status.Landmarks, *measurement)
+	}
+	return a.updateNodeLatency(node, status)
+}
+
+// updateNodeLatency writes the round-trip times to the Node Latency resource of the node, which is created if it
+// does not exist yet. The node owns the resource so that it goes away with the node.
+func (a *Agent) updateNodeLatency(node *corev1.Node, status networkingv1alpha1.NodeLatencyStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		nodelatency, err := a.edgenetclientset.NetworkingV1alpha1().NodeLatencies().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			nodelatency = new(networkingv1alpha1.NodeLatency)
+			nodelatency.SetName(node.GetName())
+			nodelatency.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(node, corev1.SchemeGroupVersion.WithKind("Node"))})
+			nodelatency.Spec.Node = node.GetName()
+			if nodelatency, err = a.edgenetclientset.NetworkingV1alpha1().NodeLatencies().Create(context.TODO(), nodelatency, metav1.CreateOptions{}); err != nil {
+				return err
+			}
+		}
+		nodelatencyCopy := nodelatency.DeepCopy()
+		nodelatencyCopy.Status = status
+		_, err = a.edgenetclientset.NetworkingV1alpha1().NodeLatencies().UpdateStatus(context.TODO(), nodelatencyCopy, metav1.UpdateOptions{})
+		return err
+	})
+}
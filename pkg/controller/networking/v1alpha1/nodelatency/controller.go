@@ -0,0 +1,285 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodelatency
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	edgenetscheme "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/scheme"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/networking/v1alpha1"
+	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+const controllerAgentName = "nodelatency-controller"
+
+// Definitions of the state of the nodelatency resource
+const (
+	successSynced  = "Synced"
+	warningExpired = "Expired"
+	failureLabel   = "Labeling Failed"
+
+	messageResourceSynced = "Node Latency synced successfully"
+	messageExpired        = "Round-trip times were last measured at %s, the latency labels of node %s are removed"
+	messageLabelFailed    = "Latency labels cannot be applied to node %s"
+)
+
+// Controller is the controller implementation for Node Latency resources
+type Controller struct {
+	// kubeclientset is a standard kubernetes clientset
+	kubeclientset kubernetes.Interface
+	// edgenetclientset is a clientset for the EdgeNet API groups
+	edgenetclientset clientset.Interface
+
+	nodelatenciesLister listers.NodeLatencyLister
+	nodelatenciesSynced cache.InformerSynced
+
+	// workqueue is a rate limited work queue. This is used to queue work to be
+	// processed instead of performing it as soon as a change happens. This
+	// means we can ensure we only process a fixed amount of resources at a
+	// time, and makes it easy to ensure we are never processing the same item
+	// simultaneously in two different workers.
+	workqueue workqueue.RateLimitingInterface
+	// recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	recorder record.EventRecorder
+}
+
+// NewController returns a new controller
+func NewController(
+	kubeclientset kubernetes.Interface,
+	edgenetclientset clientset.Interface,
+	nodelatencyInformer informers.NodeLatencyInformer) *Controller {
+
+	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
+	klog.V(4).Info("Creating event broadcaster")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
+
+	controller := &Controller{
+		kubeclientset:       kubeclientset,
+		edgenetclientset:    edgenetclientset,
+		nodelatenciesLister: nodelatencyInformer.Lister(),
+		nodelatenciesSynced: nodelatencyInformer.Informer().HasSynced,
+		workqueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "NodeLatencies"),
+		recorder:            recorder,
+	}
+
+	klog.V(4).Infoln("Setting up event handlers")
+	// Set up an event handler for when Node Latency resources change. The periodic resync of the informer
+	// also brings the resources whose measurements have expired back.
+	nodelatencyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueNodeLatency,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueNodeLatency(new)
+		},
+		DeleteFunc: controller.removeLatencyLabels,
+	})
+
+	return controller
+}
+
+// Run will set up the event handlers for the types of node latency, as well
+// as syncing informer caches and starting workers. It will block until stopCh
+// is closed, at which point it will shutdown the workqueue and wait for
+// workers to finish processing their current work items.
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.V(4).Infoln("Starting Node Latency controller")
+
+	klog.V(4).Infoln("Waiting for informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh,
+		c.nodelatenciesSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	klog.V(4).Infoln("Starting workers")
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+
+	klog.V(4).Infoln("Started workers")
+	<-stopCh
+	klog.V(4).Infoln("Shutting down workers")
+
+	return nil
+}
+
+// runWorker is a long-running function that will continually call the
+// processNextWorkItem function in order to read and process a message on the
+// workqueue.
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem will read a single work item off the workqueue and
+// attempt to process it, by calling the syncHandler.
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.workqueue.Done(obj)
+		var key string
+		var ok bool
+
+		if key, ok = obj.(string); !ok {
+			c.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.syncHandler(key); err != nil {
+			c.workqueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.workqueue.Forget(obj)
+		klog.V(4).Infof("Successfully synced '%s'", key)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+
+	return true
+}
+
+// syncHandler compares the actual state with the desired, and attempts to
+// converge the two. The desired state is the latency labels of the node
+// that the round-trip times of the Node Latency resource are measured from.
+func (c *Controller) syncHandler(key string) error {
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	nodelatency, err := c.nodelatenciesLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("nodelatency '%s' in work queue no longer exists", key))
+			return nil
+		}
+
+		return err
+	}
+
+	if err := c.processNodeLatency(nodelatency.DeepCopy()); err != nil {
+		return err
+	}
+	c.recorder.Event(nodelatency, corev1.EventTypeNormal, successSynced, messageResourceSynced)
+	return nil
+}
+
+// enqueueNodeLatency takes a NodeLatency resource and converts it into a namespace/name
+// string which is then put onto the work queue. This method should *not* be
+// passed resources of any type other than NodeLatency.
+func (c *Controller) enqueueNodeLatency(obj interface{}) {
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// removeLatencyLabels takes the latency labels off the node of a deleted Node Latency resource, unless the node
+// is gone too
+func (c *Controller) removeLatencyLabels(obj interface{}) {
+	nodelatency, ok := obj.(*networkingv1alpha1.NodeLatency)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		if nodelatency, ok = tombstone.Obj.(*networkingv1alpha1.NodeLatency); !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
+	}
+	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, nil, nil)
+	if err := multiproviderManager.SetNodeLatencyLabels(nodelatency.Spec.Node, nil); err != nil && !errors.IsNotFound(err) {
+		klog.Infoln(err)
+	}
+}
+
+// processNodeLatency classifies the round-trip times measured from the node and labels the node accordingly.
+// The labels of a node whose measurements have expired, as its agent no longer runs, are removed so that
+// selectors do not rely on them.
+func (c *Controller) processNodeLatency(nodelatencyCopy *networkingv1alpha1.NodeLatency) error {
+	low, high, expiry := getThresholds()
+	multiproviderManager := multiprovider.NewManager(c.kubeclientset, nil, nil, nil)
+
+	var labels map[string]string
+	if updated := nodelatencyCopy.Status.UpdateTimestamp; updated != nil && time.Since(updated.Time) < expiry {
+		labels = multiprovider.LatencyLabels(nodelatencyCopy.Status, low, high)
+	} else if updated != nil {
+		c.recorder.Eventf(nodelatencyCopy, corev1.EventTypeWarning, warningExpired, messageExpired, updated.Format(time.RFC3339), nodelatencyCopy.Spec.Node)
+	}
+	if err := multiproviderManager.SetNodeLatencyLabels(nodelatencyCopy.Spec.Node, labels); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.Infoln(err)
+		c.recorder.Eventf(nodelatencyCopy, corev1.EventTypeWarning, failureLabel, messageLabelFailed, nodelatencyCopy.Spec.Node)
+		return err
+	}
+	return nil
+}
+
+// getThresholds returns the round-trip times up to which a latency is low and from which it is high, and how long
+// the measurements are valid
+func getThresholds() (time.Duration, time.Duration, time.Duration) {
+	low, high, expiry := 20*time.Millisecond, 100*time.Millisecond, 30*time.Minute
+	if flag.Lookup("low-latency") != nil {
+		low = flag.Lookup("low-latency").Value.(flag.Getter).Get().(time.Duration)
+	}
+	if flag.Lookup("high-latency") != nil {
+		high = flag.Lookup("high-latency").Value.(flag.Getter).Get().(time.Duration)
+	}
+	if flag.Lookup("latency-expiry") != nil {
+		expiry = flag.Lookup("latency-expiry").Value.(flag.Getter).Get().(time.Duration)
+	}
+	return low, high, expiry
+}
@@ -0,0 +1,217 @@
+package nodelatency
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	edgenettestclient "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+	"github.com/EdgeNet-project/edgenet/pkg/signals"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubetestclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// The main structure of test group
+type TestGroup struct {
+	nodeObj        corev1.Node
+	nodelatencyObj networkingv1alpha1.NodeLatency
+}
+
+var controller *Controller
+var kubeclientset kubernetes.Interface = kubetestclient.NewSimpleClientset()
+var edgenetclientset clientset.Interface = edgenettestclient.NewSimpleClientset()
+
+func TestMain(m *testing.M) {
+	stopCh := signals.SetupSignalHandler()
+
+	go func() {
+		edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Second*30)
+
+		newController := NewController(
+			kubeclientset,
+			edgenetclientset,
+			edgenetInformerFactory.Networking().V1alpha1().NodeLatencies(),
+		)
+
+		edgenetInformerFactory.Start(stopCh)
+		controller = newController
+		if err := controller.Run(2, stopCh); err != nil {
+			klog.Fatalf("Error running controller: %s", err.Error())
+		}
+	}()
+
+	os.Exit(m.Run())
+	<-stopCh
+}
+
+func (g *TestGroup) Init() {
+	// Delete the existing nodes and node latencies
+	nodeRaw, _ := kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	for _, nodeRow := range nodeRaw.Items {
+		kubeclientset.CoreV1().Nodes().Delete(context.TODO(), nodeRow.GetName(), metav1.DeleteOptions{})
+	}
+	nodelatencyRaw, _ := edgenetclientset.NetworkingV1alpha1().NodeLatencies().List(context.TODO(), metav1.ListOptions{})
+	for _, nodelatencyRow := range nodelatencyRaw.Items {
+		edgenetclientset.NetworkingV1alpha1().NodeLatencies().Delete(context.TODO(), nodelatencyRow.GetName(), metav1.DeleteOptions{})
+	}
+
+	g.nodeObj = corev1.Node{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Node",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "fr-idf-0000.edge-net.io",
+			Labels: map[string]string{"edge-net.io/city": "Paris"},
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: "InternalIP", Address: "127.0.0.1"}},
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   "Ready",
+					Status: "True",
+				},
+			},
+		},
+	}
+	g.nodelatencyObj = networkingv1alpha1.NodeLatency{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NodeLatency",
+			APIVersion: "networking.edgenet.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fr-idf-0000.edge-net.io",
+		},
+		Spec: networkingv1alpha1.NodeLatencySpec{
+			Node: "fr-idf-0000.edge-net.io",
+		},
+		Status: networkingv1alpha1.NodeLatencyStatus{
+			Nodes: []networkingv1alpha1.LatencyMeasurement{
+				{Name: "fr-idf-0001.edge-net.io", Address: "10.183.0.3:7061", RTT: &metav1.Duration{Duration: 4 * time.Millisecond}},
+				{Name: "us-md-0000.edge-net.io", Address: "10.183.0.4:7061", RTT: &metav1.Duration{Duration: 85 * time.Millisecond}, Loss: 20},
+				{Name: "jp-tk-0000.edge-net.io", Address: "10.183.0.5:7061", Loss: 100},
+			},
+			Landmarks: []networkingv1alpha1.LatencyMeasurement{
+				{Name: "paris", Address: "192.0.2.10:443", RTT: &metav1.Duration{Duration: 2 * time.Millisecond}},
+				{Name: "tokyo", Address: "198.51.100.10:443", RTT: &metav1.Duration{Duration: 240 * time.Millisecond}},
+			},
+			UpdateTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+}
+
+func TestLabels(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	node := g.nodeObj.DeepCopy()
+	node.Labels[multiprovider.LandmarkLatencyLabelPrefix+"sydney"] = multiprovider.LatencyHigh
+	_, err := kubeclientset.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+	util.OK(t, err)
+	_, err = edgenetclientset.NetworkingV1alpha1().NodeLatencies().Create(context.TODO(), g.nodelatencyObj.DeepCopy(), metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(250 * time.Millisecond)
+
+	node, err = kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	expected := map[string]string{
+		"edge-net.io/city":                                 "Paris",
+		multiprovider.LatencyClassLabel:                    multiprovider.LatencyMedium,
+		multiprovider.LandmarkLatencyLabelPrefix + "paris": multiprovider.LatencyLow,
+		multiprovider.LandmarkLatencyLabelPrefix + "tokyo": multiprovider.LatencyHigh,
+	}
+	util.Equals(t, expected, node.Labels)
+
+	t.Run("expired", func(t *testing.T) {
+		nodelatency, err := edgenetclientset.NetworkingV1alpha1().NodeLatencies().Get(context.TODO(), g.nodelatencyObj.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		nodelatency.Status.UpdateTimestamp = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		_, err = edgenetclientset.NetworkingV1alpha1().NodeLatencies().UpdateStatus(context.TODO(), nodelatency, metav1.UpdateOptions{})
+		util.OK(t, err)
+		time.Sleep(250 * time.Millisecond)
+
+		node, err := kubeclientset.CoreV1().Nodes().Get(context.TODO(), g.nodeObj.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, map[string]string{"edge-net.io/city": "Paris"}, node.Labels)
+	})
+}
+
+func TestDeletion(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	_, err := kubeclientset.CoreV1().Nodes().Create(context.TODO(), g.nodeObj.DeepCopy(), metav1.CreateOptions{})
+	util.OK(t, err)
+	_, err = edgenetclientset.NetworkingV1alpha1().NodeLatencies().Create(context.TODO(), g.nodelatencyObj.DeepCopy(), metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(250 * time.Millisecond)
+	node, err := kubeclientset.CoreV1().Nodes().Get(context.TODO(), g.nodeObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, multiprovider.LatencyMedium, node.Labels[multiprovider.LatencyClassLabel])
+
+	err = edgenetclientset.NetworkingV1alpha1().NodeLatencies().Delete(context.TODO(), g.nodelatencyObj.GetName(), metav1.DeleteOptions{})
+	util.OK(t, err)
+	time.Sleep(250 * time.Millisecond)
+	node, err = kubeclientset.CoreV1().Nodes().Get(context.TODO(), g.nodeObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, map[string]string{"edge-net.io/city": "Paris"}, node.Labels)
+}
+
+func TestMeasure(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	util.OK(t, err)
+	defer listener.Close()
+	go serve(listener)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	source := g.nodeObj.DeepCopy()
+	source.SetName("fr-idf-0001.edge-net.io")
+	unready := g.nodeObj.DeepCopy()
+	unready.SetName("us-md-0000.edge-net.io")
+	unready.Status.Conditions[0].Status = "False"
+	for _, node := range []*corev1.Node{g.nodeObj.DeepCopy(), source, unready} {
+		_, err := kubeclientset.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+		util.OK(t, err)
+	}
+
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeclientset, 0)
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
+	agent, err := NewAgent(edgenetclientset, nodeInformer, source.GetName())
+	util.OK(t, err)
+	agent.port = listener.Addr().(*net.TCPAddr).Port
+	agent.landmarks = []multiprovider.Landmark{{Name: "local", Address: listener.Addr().String()}}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	kubeInformerFactory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, nodeInformer.Informer().HasSynced)
+
+	util.OK(t, agent.measure())
+	nodelatency, err := edgenetclientset.NetworkingV1alpha1().NodeLatencies().Get(context.TODO(), source.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, source.GetName(), nodelatency.Spec.Node)
+	util.Equals(t, source.GetName(), nodelatency.GetOwnerReferences()[0].Name)
+	util.Equals(t, 1, len(nodelatency.Status.Nodes))
+	util.Equals(t, g.nodeObj.GetName(), nodelatency.Status.Nodes[0].Name)
+	util.Equals(t, net.JoinHostPort("127.0.0.1", port), nodelatency.Status.Nodes[0].Address)
+	util.Equals(t, true, nodelatency.Status.Nodes[0].RTT != nil)
+	util.Equals(t, 0, nodelatency.Status.Nodes[0].Loss)
+	util.Equals(t, 1, len(nodelatency.Status.Landmarks))
+	util.Equals(t, true, nodelatency.Status.Landmarks[0].RTT != nil)
+	util.Equals(t, true, nodelatency.Status.UpdateTimestamp != nil)
+}
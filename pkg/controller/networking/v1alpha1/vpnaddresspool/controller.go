@@ -0,0 +1,501 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpnaddresspool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	edgenetscheme "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/scheme"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/networking/v1alpha1"
+	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+const controllerAgentName = "vpnaddresspool-controller"
+
+// Definitions of the state of the vpnaddresspool resource
+const (
+	successSynced    = "Synced"
+	successAssigned  = "Assigned"
+	successReleased  = "Released"
+	warningConflict  = "Conflict"
+	warningExhausted = "Exhausted"
+	warningNoPool    = "NoPool"
+
+	messageResourceSynced = "VPN Address Pool synced successfully"
+	messageReady          = "Address pool is ready"
+	messageAssigned       = "Addresses %s and %s assigned from pool %s"
+	messageReleased       = "Addresses %s and %s of peer %s released"
+	messageConflict       = "Address %s is claimed by peer %s, which was created earlier"
+	messageExhausted      = "Address pool is exhausted"
+	messagePeersNotListed = "Peers cannot be listed"
+	messagePoolNotFound   = "Address pool %s does not exist"
+	messageNoDefaultPool  = "Peer names no address pool and there is no default pool"
+	messageHasAddresses   = "Peer has its addresses"
+)
+
+// Controller is the controller implementation for VPN Address Pool resources
+type Controller struct {
+	// kubeclientset is a standard kubernetes clientset
+	kubeclientset kubernetes.Interface
+	// edgenetclientset is a clientset for the EdgeNet API groups
+	edgenetclientset clientset.Interface
+
+	vpnaddresspoolsLister listers.VPNAddressPoolLister
+	vpnaddresspoolsSynced cache.InformerSynced
+
+	vpnpeersLister listers.VPNPeerLister
+	vpnpeersSynced cache.InformerSynced
+
+	// allocation prevents two workers from assigning the same address
+	allocation sync.Mutex
+
+	// workqueue is a rate limited work queue. This is used to queue work to be
+	// processed instead of performing it as soon as a change happens. This
+	// means we can ensure we only process a fixed amount of resources at a
+	// time, and makes it easy to ensure we are never processing the same item
+	// simultaneously in two different workers.
+	workqueue workqueue.RateLimitingInterface
+	// peerWorkqueue holds the names of the peers to check for a pool to get their addresses from
+	peerWorkqueue workqueue.RateLimitingInterface
+	// recorder is an event recorder for recording Event resources to the
+	// Kubernetes API.
+	recorder record.EventRecorder
+}
+
+// NewController returns a new controller
+func NewController(
+	kubeclientset kubernetes.Interface,
+	edgenetclientset clientset.Interface,
+	vpnaddresspoolInformer informers.VPNAddressPoolInformer,
+	vpnpeerInformer informers.VPNPeerInformer) *Controller {
+
+	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
+	klog.V(4).Info("Creating event broadcaster")
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartStructuredLogging(0)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
+
+	controller := &Controller{
+		kubeclientset:         kubeclientset,
+		edgenetclientset:      edgenetclientset,
+		vpnaddresspoolsLister: vpnaddresspoolInformer.Lister(),
+		vpnaddresspoolsSynced: vpnaddresspoolInformer.Informer().HasSynced,
+		vpnpeersLister:        vpnpeerInformer.Lister(),
+		vpnpeersSynced:        vpnpeerInformer.Informer().HasSynced,
+		workqueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "VPNAddressPools"),
+		peerWorkqueue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "VPNPeers"),
+		recorder:              recorder,
+	}
+
+	klog.V(4).Infoln("Setting up event handlers")
+	// Set up an event handler for when VPN Address Pool resources change
+	vpnaddresspoolInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueVPNAddressPool,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueVPNAddressPool(new)
+		},
+	})
+	// The peers are few enough for every change to a peer to make all pools reconsider their addresses
+	vpnpeerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			controller.enqueueVPNAddressPools()
+			controller.enqueueVPNPeer(obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueVPNAddressPools()
+			controller.enqueueVPNPeer(new)
+		},
+		DeleteFunc: controller.releaseAddresses,
+	})
+
+	return controller
+}
+
+// Run will set up the event handlers for the types of VPN address pool and VPN peer, as well
+// as syncing informer caches and starting workers. It will block until stopCh
+// is closed, at which point it will shutdown the workqueue and wait for
+// workers to finish processing their current work items.
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer c.workqueue.ShutDown()
+	defer c.peerWorkqueue.ShutDown()
+
+	klog.V(4).Infoln("Starting VPN Address Pool controller")
+
+	klog.V(4).Infoln("Waiting for informer caches to sync")
+	if ok := cache.WaitForCacheSync(stopCh,
+		c.vpnaddresspoolsSynced,
+		c.vpnpeersSynced); !ok {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	klog.V(4).Infoln("Starting workers")
+	for i := 0; i < threadiness; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	go wait.Until(c.runPeerWorker, time.Second, stopCh)
+
+	klog.V(4).Infoln("Started workers")
+	<-stopCh
+	klog.V(4).Infoln("Shutting down workers")
+
+	return nil
+}
+
+// runWorker is a long-running function that will continually call the
+// processNextWorkItem function in order to read and process a message on the
+// workqueue.
+func (c *Controller) runWorker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+// processNextWorkItem will read a single work item off the workqueue and
+// attempt to process it, by calling the syncHandler.
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+
+	if shutdown {
+		return false
+	}
+
+	err := func(obj interface{}) error {
+		defer c.workqueue.Done(obj)
+		var key string
+		var ok bool
+
+		if key, ok = obj.(string); !ok {
+			c.workqueue.Forget(obj)
+			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
+			return nil
+		}
+		if err := c.syncHandler(key); err != nil {
+			c.workqueue.AddRateLimited(key)
+			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
+		}
+		c.workqueue.Forget(obj)
+		klog.V(4).Infof("Successfully synced '%s'", key)
+		return nil
+	}(obj)
+
+	if err != nil {
+		utilruntime.HandleError(err)
+		return true
+	}
+
+	return true
+}
+
+// syncHandler compares the actual state with the desired, and attempts to
+// converge the two. It then updates the Status block of the VPN Address Pool
+// resource with the current status of the resource.
+func (c *Controller) syncHandler(key string) error {
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	vpnaddresspool, err := c.vpnaddresspoolsLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("vpnaddresspool '%s' in work queue no longer exists", key))
+			return nil
+		}
+
+		return err
+	}
+
+	if err := c.processVPNAddressPool(vpnaddresspool.DeepCopy()); err != nil {
+		return err
+	}
+	c.recorder.Event(vpnaddresspool, corev1.EventTypeNormal, successSynced, messageResourceSynced)
+	return nil
+}
+
+// enqueueVPNAddressPool takes a VPNAddressPool resource and converts it into a namespace/name
+// string which is then put onto the work queue. This method should *not* be
+// passed resources of any type other than VPNAddressPool.
+func (c *Controller) enqueueVPNAddressPool(obj interface{}) {
+	var key string
+	var err error
+	if key, err = cache.MetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.workqueue.Add(key)
+}
+
+// enqueueVPNAddressPools puts all VPNAddressPool resources onto the work queue
+func (c *Controller) enqueueVPNAddressPools() {
+	vpnaddresspools, err := c.vpnaddresspoolsLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	for _, vpnaddresspool := range vpnaddresspools {
+		c.enqueueVPNAddressPool(vpnaddresspool)
+	}
+}
+
+// enqueueVPNPeer puts the name of a peer onto the peer work queue
+func (c *Controller) enqueueVPNPeer(obj interface{}) {
+	if peer, ok := obj.(*networkingv1alpha1.VPNPeer); ok {
+		c.peerWorkqueue.Add(peer.GetName())
+	}
+}
+
+// runPeerWorker checks the peers taken from the peer work queue
+func (c *Controller) runPeerWorker() {
+	for c.processNextPeer() {
+	}
+}
+
+// processNextPeer checks a single peer from the peer work queue and requeues it on failure
+func (c *Controller) processNextPeer() bool {
+	obj, shutdown := c.peerWorkqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.peerWorkqueue.Done(obj)
+	if err := c.checkPeerPool(obj.(string)); err != nil {
+		utilruntime.HandleError(fmt.Errorf("error checking the pool of peer '%s': %s, requeuing", obj, err.Error()))
+		c.peerWorkqueue.AddRateLimited(obj)
+		return true
+	}
+	c.peerWorkqueue.Forget(obj)
+	return true
+}
+
+// checkPeerPool warns about a peer waiting for addresses that no pool can assign, as the pools never see such a
+// peer, and reports it in the AddressesAssigned condition of the peer
+func (c *Controller) checkPeerPool(name string) error {
+	peer, err := c.vpnpeersLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	vpnaddresspools, err := c.vpnaddresspoolsLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	condition := metav1.Condition{Type: networkingv1alpha1.ConditionAddressesAssigned}
+	if peer.Spec.AddressV4 != "" && peer.Spec.AddressV6 != "" {
+		// Only the peers that were once reported get the condition back to true
+		if meta.FindStatusCondition(peer.Status.Conditions, condition.Type) == nil {
+			return nil
+		}
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionTrue, successAssigned, messageHasAddresses
+	} else if multiprovider.GetPeerPool(peer, vpnaddresspools) == "" {
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, warningNoPool, messageNoDefaultPool
+		if peer.Spec.Pool != "" {
+			condition.Message = fmt.Sprintf(messagePoolNotFound, peer.Spec.Pool)
+		}
+	} else {
+		// The pool assigns the addresses in turn
+		return nil
+	}
+	if current := meta.FindStatusCondition(peer.Status.Conditions, condition.Type); current != nil &&
+		current.Status == condition.Status && current.Message == condition.Message {
+		return nil
+	}
+	peerCopy := peer.DeepCopy()
+	meta.SetStatusCondition(&peerCopy.Status.Conditions, condition)
+	updatedPeer, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().UpdateStatus(context.TODO(), peerCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	if condition.Status == metav1.ConditionFalse {
+		c.recorder.Event(updatedPeer, corev1.EventTypeWarning, warningNoPool, condition.Message)
+	}
+	return nil
+}
+
+// releaseAddresses reports the addresses of a deleted peer as free in its pool, which then updates its status
+func (c *Controller) releaseAddresses(obj interface{}) {
+	peer, ok := obj.(*networkingv1alpha1.VPNPeer)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		if peer, ok = tombstone.Obj.(*networkingv1alpha1.VPNPeer); !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
+	}
+	vpnaddresspools, err := c.vpnaddresspoolsLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	if poolName := multiprovider.GetPeerPool(peer, vpnaddresspools); poolName != "" {
+		if vpnaddresspool, err := c.vpnaddresspoolsLister.Get(poolName); err == nil {
+			c.recorder.Eventf(vpnaddresspool, corev1.EventTypeNormal, successReleased, messageReleased, peer.Spec.AddressV4, peer.Spec.AddressV6, peer.GetName())
+		}
+	}
+	for _, vpnaddresspool := range vpnaddresspools {
+		c.enqueueVPNAddressPool(vpnaddresspool)
+	}
+}
+
+// processVPNAddressPool assigns addresses to the peers of the pool that have none, then reports the use of
+// the pool and the addresses claimed by several peers in its status
+func (c *Controller) processVPNAddressPool(vpnaddresspoolCopy *networkingv1alpha1.VPNAddressPool) error {
+	oldStatus := vpnaddresspoolCopy.Status.DeepCopy()
+	if err := multiprovider.ValidateAddressPool(vpnaddresspoolCopy); err != nil {
+		c.recorder.Event(vpnaddresspoolCopy, corev1.EventTypeWarning, networkingv1alpha1.StatusFailed, err.Error())
+		vpnaddresspoolCopy.Status.State = networkingv1alpha1.StatusFailed
+		vpnaddresspoolCopy.Status.Message = err.Error()
+		c.updateStatus(context.TODO(), vpnaddresspoolCopy, oldStatus)
+		return nil
+	}
+	vpnaddresspools, err := c.vpnaddresspoolsLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	c.allocation.Lock()
+	defer c.allocation.Unlock()
+	// Read the peers from the API rather than the cache, as the addresses assigned a moment ago must be seen
+	peerList, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Infoln(err)
+		c.recorder.Event(vpnaddresspoolCopy, corev1.EventTypeWarning, networkingv1alpha1.StatusFailed, messagePeersNotListed)
+		return err
+	}
+	peers := make([]*networkingv1alpha1.VPNPeer, len(peerList.Items))
+	for i := range peerList.Items {
+		peers[i] = &peerList.Items[i]
+	}
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].CreationTimestamp.Before(&peers[j].CreationTimestamp)
+	})
+
+	vpnaddresspoolCopy.Status.State = networkingv1alpha1.StatusReady
+	vpnaddresspoolCopy.Status.Message = messageReady
+	used := multiprovider.GetUsedAddresses(peers)
+	for i, peer := range peers {
+		if (peer.Spec.AddressV4 != "" && peer.Spec.AddressV6 != "") || multiprovider.GetPeerPool(peer, vpnaddresspools) != vpnaddresspoolCopy.GetName() {
+			continue
+		}
+		peerCopy := peer.DeepCopy()
+		var exhausted bool
+		if peerCopy.Spec.AddressV4 == "" {
+			if peerCopy.Spec.AddressV4, err = multiprovider.AllocateAddress(vpnaddresspoolCopy.Spec.CIDRV4, used, vpnaddresspoolCopy.Spec.Reserved); err != nil {
+				exhausted = true
+			}
+		}
+		if peerCopy.Spec.AddressV6 == "" {
+			if peerCopy.Spec.AddressV6, err = multiprovider.AllocateAddress(vpnaddresspoolCopy.Spec.CIDRV6, used, vpnaddresspoolCopy.Spec.Reserved); err != nil {
+				exhausted = true
+			}
+		}
+		if exhausted {
+			c.recorder.Event(vpnaddresspoolCopy, corev1.EventTypeWarning, warningExhausted, messageExhausted)
+			c.recorder.Event(peer, corev1.EventTypeWarning, warningExhausted, messageExhausted)
+			vpnaddresspoolCopy.Status.State = networkingv1alpha1.StatusFailed
+			vpnaddresspoolCopy.Status.Message = messageExhausted
+			break
+		}
+		updatedPeer, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().Update(context.TODO(), peerCopy, metav1.UpdateOptions{})
+		if err != nil {
+			klog.Infoln(err)
+			return err
+		}
+		used[peerCopy.Spec.AddressV4], used[peerCopy.Spec.AddressV6] = true, true
+		peers[i] = updatedPeer
+		c.recorder.Eventf(updatedPeer, corev1.EventTypeNormal, successAssigned, messageAssigned, updatedPeer.Spec.AddressV4, updatedPeer.Spec.AddressV6, vpnaddresspoolCopy.GetName())
+	}
+
+	vpnaddresspoolCopy.Status.AllocatedV4, vpnaddresspoolCopy.Status.AllocatedV6 = 0, 0
+	for address := range multiprovider.GetUsedAddresses(peers) {
+		if multiprovider.ContainsAddress(vpnaddresspoolCopy.Spec.CIDRV4, address) {
+			vpnaddresspoolCopy.Status.AllocatedV4++
+		} else if multiprovider.ContainsAddress(vpnaddresspoolCopy.Spec.CIDRV6, address) {
+			vpnaddresspoolCopy.Status.AllocatedV6++
+		}
+	}
+	vpnaddresspoolCopy.Status.Conflicts = nil
+	for _, conflict := range multiprovider.FindAddressConflicts(peers) {
+		if !multiprovider.ContainsAddress(vpnaddresspoolCopy.Spec.CIDRV4, conflict.Address) &&
+			!multiprovider.ContainsAddress(vpnaddresspoolCopy.Spec.CIDRV6, conflict.Address) {
+			continue
+		}
+		vpnaddresspoolCopy.Status.Conflicts = append(vpnaddresspoolCopy.Status.Conflicts, conflict)
+		if isReported(oldStatus.Conflicts, conflict) {
+			continue
+		}
+		for _, peer := range peers {
+			for _, claimant := range conflict.Peers[1:] {
+				if peer.GetName() == claimant {
+					c.recorder.Eventf(peer, corev1.EventTypeWarning, warningConflict, messageConflict, conflict.Address, conflict.Peers[0])
+				}
+			}
+		}
+		c.recorder.Eventf(vpnaddresspoolCopy, corev1.EventTypeWarning, warningConflict, messageConflict, conflict.Address, conflict.Peers[0])
+	}
+	c.updateStatus(context.TODO(), vpnaddresspoolCopy, oldStatus)
+	return nil
+}
+
+// updateStatus calls the API to update the VPN address pool status if it changed
+func (c *Controller) updateStatus(ctx context.Context, vpnaddresspoolCopy *networkingv1alpha1.VPNAddressPool, oldStatus *networkingv1alpha1.VPNAddressPoolStatus) {
+	if equality.Semantic.DeepEqual(vpnaddresspoolCopy.Status, *oldStatus) {
+		return
+	}
+	if _, err := c.edgenetclientset.NetworkingV1alpha1().VPNAddressPools().UpdateStatus(ctx, vpnaddresspoolCopy, metav1.UpdateOptions{}); err != nil {
+		klog.Infoln(err)
+	}
+}
+
+// isReported tells whether the conflict was already in the status, in which case the peers were warned about it
+func isReported(conflicts []networkingv1alpha1.AddressConflict, conflict networkingv1alpha1.AddressConflict) bool {
+	for _, reported := range conflicts {
+		if equality.Semantic.DeepEqual(reported, conflict) {
+			return true
+		}
+	}
+	return false
+}
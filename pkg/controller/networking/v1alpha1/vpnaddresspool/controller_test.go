@@ -0,0 +1,231 @@
+package vpnaddresspool
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	clientset "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned"
+	edgenettestclient "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
+	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions"
+	"github.com/EdgeNet-project/edgenet/pkg/signals"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubetestclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog"
+)
+
+// The main structure of test group
+type TestGroup struct {
+	poolObj networkingv1alpha1.VPNAddressPool
+	peerObj networkingv1alpha1.VPNPeer
+}
+
+var controller *Controller
+var kubeclientset kubernetes.Interface = kubetestclient.NewSimpleClientset()
+var edgenetclientset clientset.Interface = edgenettestclient.NewSimpleClientset()
+
+func TestMain(m *testing.M) {
+	stopCh := signals.SetupSignalHandler()
+
+	go func() {
+		edgenetInformerFactory := informers.NewSharedInformerFactory(edgenetclientset, time.Second*30)
+
+		newController := NewController(
+			kubeclientset,
+			edgenetclientset,
+			edgenetInformerFactory.Networking().V1alpha1().VPNAddressPools(),
+			edgenetInformerFactory.Networking().V1alpha1().VPNPeers(),
+		)
+
+		edgenetInformerFactory.Start(stopCh)
+		controller = newController
+		if err := controller.Run(2, stopCh); err != nil {
+			klog.Fatalf("Error running controller: %s", err.Error())
+		}
+	}()
+
+	os.Exit(m.Run())
+	<-stopCh
+}
+
+func (g *TestGroup) Init() {
+	// Delete the existing pools and peers
+	poolRaw, _ := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().List(context.TODO(), metav1.ListOptions{})
+	for _, poolRow := range poolRaw.Items {
+		edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Delete(context.TODO(), poolRow.GetName(), metav1.DeleteOptions{})
+	}
+	peerRaw, _ := edgenetclientset.NetworkingV1alpha1().VPNPeers().List(context.TODO(), metav1.ListOptions{})
+	for _, peerRow := range peerRaw.Items {
+		edgenetclientset.NetworkingV1alpha1().VPNPeers().Delete(context.TODO(), peerRow.GetName(), metav1.DeleteOptions{})
+	}
+
+	g.poolObj = networkingv1alpha1.VPNAddressPool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VPNAddressPool",
+			APIVersion: "networking.edgenet.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: networkingv1alpha1.VPNAddressPoolSpec{
+			CIDRV4:   "10.183.0.0/29",
+			CIDRV6:   "fdb4:ae86:ec99:4004::/64",
+			Reserved: []string{"10.183.0.1", "fdb4:ae86:ec99:4004::1"},
+			Default:  true,
+		},
+	}
+	g.peerObj = networkingv1alpha1.VPNPeer{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VPNPeer",
+			APIVersion: "networking.edgenet.io/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fr-idf-0000",
+		},
+		Spec: networkingv1alpha1.VPNPeerSpec{
+			PublicKey: "dGVzdC1wdWJsaWMta2V5LTAwMDAwMDAwMDAwMDAwMDA=",
+		},
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	_, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Create(context.TODO(), g.poolObj.DeepCopy(), metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(250 * time.Millisecond)
+
+	peer := g.peerObj.DeepCopy()
+	_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), peer, metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(500 * time.Millisecond)
+	peer, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), peer.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, "10.183.0.2", peer.Spec.AddressV4)
+	util.Equals(t, "fdb4:ae86:ec99:4004::2", peer.Spec.AddressV6)
+
+	// A peer set by hand keeps its addresses and the next peer gets the first free ones
+	manual := g.peerObj.DeepCopy()
+	manual.SetName("fr-idf-0001")
+	manual.Spec.AddressV4 = "10.183.0.3"
+	manual.Spec.AddressV6 = "fdb4:ae86:ec99:4004::3"
+	_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), manual, metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(250 * time.Millisecond)
+	next := g.peerObj.DeepCopy()
+	next.SetName("fr-idf-0002")
+	_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), next, metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(500 * time.Millisecond)
+	next, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), next.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, "10.183.0.4", next.Spec.AddressV4)
+	util.Equals(t, "fdb4:ae86:ec99:4004::4", next.Spec.AddressV6)
+
+	pool, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Get(context.TODO(), g.poolObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, networkingv1alpha1.StatusReady, pool.Status.State)
+	util.Equals(t, 3, pool.Status.AllocatedV4)
+	util.Equals(t, 3, pool.Status.AllocatedV6)
+
+	t.Run("release", func(t *testing.T) {
+		err := edgenetclientset.NetworkingV1alpha1().VPNPeers().Delete(context.TODO(), peer.GetName(), metav1.DeleteOptions{})
+		util.OK(t, err)
+		time.Sleep(500 * time.Millisecond)
+		pool, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Get(context.TODO(), g.poolObj.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, 2, pool.Status.AllocatedV4)
+
+		again := g.peerObj.DeepCopy()
+		again.SetName("fr-idf-0003")
+		_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), again, metav1.CreateOptions{})
+		util.OK(t, err)
+		time.Sleep(500 * time.Millisecond)
+		again, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), again.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, "10.183.0.2", again.Spec.AddressV4)
+	})
+	t.Run("exhaustion", func(t *testing.T) {
+		// 10.183.0.5 and 10.183.0.6 are the last addresses before the broadcast address
+		for _, name := range []string{"fr-idf-0004", "fr-idf-0005", "fr-idf-0006"} {
+			extra := g.peerObj.DeepCopy()
+			extra.SetName(name)
+			_, err := edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), extra, metav1.CreateOptions{})
+			util.OK(t, err)
+			time.Sleep(250 * time.Millisecond)
+		}
+		time.Sleep(250 * time.Millisecond)
+		extra, err := edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), "fr-idf-0006", metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, "", extra.Spec.AddressV4)
+		pool, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Get(context.TODO(), g.poolObj.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, networkingv1alpha1.StatusFailed, pool.Status.State)
+		util.Equals(t, messageExhausted, pool.Status.Message)
+	})
+}
+
+func TestConflict(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	_, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Create(context.TODO(), g.poolObj.DeepCopy(), metav1.CreateOptions{})
+	util.OK(t, err)
+	for _, name := range []string{"fr-idf-0000", "fr-idf-0001"} {
+		peer := g.peerObj.DeepCopy()
+		peer.SetName(name)
+		peer.Spec.AddressV4 = "10.183.0.2"
+		peer.Spec.AddressV6 = "fdb4:ae86:ec99:4004::" + name[len(name)-1:]
+		_, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), peer, metav1.CreateOptions{})
+		util.OK(t, err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	pool, err := edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Get(context.TODO(), g.poolObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, []networkingv1alpha1.AddressConflict{{Address: "10.183.0.2", Peers: []string{"fr-idf-0000", "fr-idf-0001"}}}, pool.Status.Conflicts)
+	util.Equals(t, 1, pool.Status.AllocatedV4)
+	util.Equals(t, 2, pool.Status.AllocatedV6)
+
+	// Solving the conflict clears it from the status
+	err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Delete(context.TODO(), "fr-idf-0001", metav1.DeleteOptions{})
+	util.OK(t, err)
+	time.Sleep(500 * time.Millisecond)
+	pool, err = edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Get(context.TODO(), g.poolObj.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 0, len(pool.Status.Conflicts))
+}
+
+func TestNoPool(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	peer := g.peerObj.DeepCopy()
+	peer.Spec.Pool = "edge"
+	_, err := edgenetclientset.NetworkingV1alpha1().VPNPeers().Create(context.TODO(), peer, metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(500 * time.Millisecond)
+	peer, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), peer.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 1, len(peer.Status.Conditions))
+	util.Equals(t, metav1.ConditionFalse, peer.Status.Conditions[0].Status)
+	util.Equals(t, warningNoPool, peer.Status.Conditions[0].Reason)
+
+	// The pool showing up assigns the addresses, which clears the condition
+	pool := g.poolObj.DeepCopy()
+	pool.SetName("edge")
+	pool.Spec.Default = false
+	_, err = edgenetclientset.NetworkingV1alpha1().VPNAddressPools().Create(context.TODO(), pool, metav1.CreateOptions{})
+	util.OK(t, err)
+	time.Sleep(750 * time.Millisecond)
+	peer, err = edgenetclientset.NetworkingV1alpha1().VPNPeers().Get(context.TODO(), peer.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, "10.183.0.2", peer.Spec.AddressV4)
+	util.Equals(t, 1, len(peer.Status.Conditions))
+	util.Equals(t, metav1.ConditionTrue, peer.Status.Conditions[0].Status)
+}
@@ -29,14 +29,18 @@ import (
 	edgenetscheme "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/scheme"
 	informers "github.com/EdgeNet-project/edgenet/pkg/generated/informers/externalversions/networking/v1alpha1"
 	listers "github.com/EdgeNet-project/edgenet/pkg/generated/listers/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -56,9 +60,14 @@ type Controller struct {
 	edgenetclientset clientset.Interface
 	vpnpeersLister   listers.VPNPeerLister
 	vpnpeersSynced   cache.InformerSynced
+	nodesLister      corelisters.NodeLister
+	nodesSynced      cache.InformerSynced
+	secretsLister    corelisters.SecretLister
+	secretsSynced    cache.InformerSynced
 	workqueue        workqueue.RateLimitingInterface
 	recorder         record.EventRecorder
 	linkname         string
+	nodename         string
 }
 
 // NewController returns a new VPNPeer controller
@@ -66,7 +75,10 @@ func NewController(
 	kubeclientset kubernetes.Interface,
 	edgenetclientset clientset.Interface,
 	vpnpeerInformer informers.VPNPeerInformer,
-	linkname string) *Controller {
+	nodeInformer coreinformers.NodeInformer,
+	secretInformer coreinformers.SecretInformer,
+	linkname string,
+	nodename string) *Controller {
 	utilruntime.Must(edgenetscheme.AddToScheme(scheme.Scheme))
 	klog.V(4).Info("Creating event broadcaster")
 	eventBroadcaster := record.NewBroadcaster()
@@ -79,20 +91,43 @@ func NewController(
 		edgenetclientset: edgenetclientset,
 		vpnpeersLister:   vpnpeerInformer.Lister(),
 		vpnpeersSynced:   vpnpeerInformer.Informer().HasSynced,
+		nodesLister:      nodeInformer.Lister(),
+		nodesSynced:      nodeInformer.Informer().HasSynced,
 		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "VPNPeers"),
 		recorder:         recorder,
 		linkname:         linkname,
+		nodename:         nodename,
 	}
 
 	klog.Info("Setting up event handlers")
 	vpnpeerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    controller.enqueueVPNPeer,
-		DeleteFunc: controller.enqueueVPNPeer,
+		DeleteFunc: controller.enqueueConflictingVPNPeers,
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueVPNPeer(old)
 			controller.enqueueVPNPeer(new)
 		},
 	})
+	// The labels of the nodes decide which peers a partial mesh connects
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			if !equality.Semantic.DeepEqual(old.(*corev1.Node).GetLabels(), new.(*corev1.Node).GetLabels()) {
+				controller.enqueueNodeVPNPeer(new)
+			}
+		},
+	})
+
+	// Only the head nodes configure preshared keys, so the edge nodes running the mesh agent never read the secrets
+	if getMode() == modeHub {
+		controller.secretsLister = secretInformer.Lister()
+		controller.secretsSynced = secretInformer.Informer().HasSynced
+		secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueSecretVPNPeers,
+			UpdateFunc: func(old, new interface{}) {
+				controller.enqueueSecretVPNPeers(new)
+			},
+		})
+	}
 
 	return controller
 }
@@ -108,7 +143,11 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	klog.Info("Starting VPNPeer controller")
 
 	klog.Info("Waiting for informer caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.vpnpeersSynced); !ok {
+	cacheSynced := []cache.InformerSynced{c.vpnpeersSynced, c.nodesSynced}
+	if c.secretsSynced != nil {
+		cacheSynced = append(cacheSynced, c.secretsSynced)
+	}
+	if ok := cache.WaitForCacheSync(stopCh, cacheSynced...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 
@@ -116,6 +155,9 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	for i := 0; i < threadiness; i++ {
 		go wait.Until(c.runWorker, time.Second, stopCh)
 	}
+	go wait.Until(c.reportStatus, statusInterval, stopCh)
+	go wait.Until(c.checkRotations, rotationInterval, stopCh)
+	go wait.Until(c.resync, getResyncPeriod(), stopCh)
 
 	klog.Info("Started workers")
 	<-stopCh
@@ -174,6 +216,11 @@ func (c *Controller) processNextWorkItem() bool {
 // converge the two. It then updates the Status block of the VPNPeer resource
 // with the current status of the resource.
 func (c *Controller) syncHandler(key string) error {
+	if getMode() == modeMesh {
+		// Which peers a node connects to depends on all mesh peers, so any change makes the whole interface converge
+		return c.converge()
+	}
+
 	peers, err := c.vpnpeersLister.List(labels.Everything())
 	if err != nil {
 		return err
@@ -186,14 +233,48 @@ func (c *Controller) syncHandler(key string) error {
 
 	if peer == nil {
 		// A. Deletion
+		if isDryRun() {
+			klog.Infof("Dry run: peer with public key %s would be removed from link %s", key, c.linkname)
+			return nil
+		}
 		err = removePeer(c.linkname, key)
 		if err != nil {
 			return err
 		}
 		klog.Infof("Peer with public key %s removed", key)
+	} else if peer.Spec.AddressV4 == "" || peer.Spec.AddressV6 == "" {
+		// The address pool controller has not assigned the addresses yet, the update will come back here
+		klog.Infof("Peer with public key %s is waiting for its addresses", key)
+	} else if multiprovider.HasAddressConflict(peer, peers) {
+		// The peer created first keeps the address
+		if isDryRun() {
+			klog.Infof("Dry run: peer with public key %s claims an address of another peer, would be removed from link %s", key, c.linkname)
+			return nil
+		}
+		err = removePeer(c.linkname, key)
+		if err != nil {
+			return err
+		}
+		klog.Infof("Peer with public key %s claims an address of another peer, not synced", key)
 	} else {
 		// B. Creation/Update
-		err = addPeer(c.linkname, *peer)
+		if isDryRun() {
+			klog.Infof("Dry run: peer with public key %s would be configured on link %s", key, c.linkname)
+			return nil
+		}
+		observations, err := getObservations(c.linkname)
+		if err != nil {
+			return err
+		}
+		presharedKey, err := c.getPresharedKey(peer)
+		if err != nil {
+			return err
+		}
+		peerConfigs, err := getPeerConfigs(*peer, presharedKey, observations)
+		if err != nil {
+			return err
+		}
+		err = configurePeers(c.linkname, peerConfigs)
 		if err != nil {
 			return err
 		}
@@ -210,14 +291,89 @@ func (c *Controller) enqueueVPNPeer(obj interface{}) {
 	// We enqueue the peer public key instead of the object name.
 	// This allows us to handle deletion (or public key update) easily,
 	// since a WireGuard peer on a given link is uniquely identified by its public key.
+	// The key staged for a rotation is enqueued too, so that it is removed once the rotation is over or abandoned.
 	peer := obj.(*v1alpha1.VPNPeer)
 	c.workqueue.Add(peer.Spec.PublicKey)
+	if peer.Spec.NextPublicKey != "" {
+		c.workqueue.Add(peer.Spec.NextPublicKey)
+	}
+}
+
+// enqueueConflictingVPNPeers takes a deleted VPNPeer resource, and enqueues it along with the peers that claim
+// one of its addresses, as they may now take over the address.
+func (c *Controller) enqueueConflictingVPNPeers(obj interface{}) {
+	deletedPeer, ok := obj.(*v1alpha1.VPNPeer)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if deletedPeer, ok = tombstone.Obj.(*v1alpha1.VPNPeer); !ok {
+			return
+		}
+	}
+	c.enqueueVPNPeer(deletedPeer)
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, peer := range peers {
+		if (peer.Spec.AddressV4 != "" && peer.Spec.AddressV4 == deletedPeer.Spec.AddressV4) ||
+			(peer.Spec.AddressV6 != "" && peer.Spec.AddressV6 == deletedPeer.Spec.AddressV6) {
+			c.enqueueVPNPeer(peer)
+		}
+	}
+}
+
+// enqueueNodeVPNPeer takes a Node resource and enqueues the VPNPeer of the node, if any
+func (c *Controller) enqueueNodeVPNPeer(obj interface{}) {
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	if peer := findNodePeer(peers, obj.(*corev1.Node).GetName()); peer != nil {
+		c.enqueueVPNPeer(peer)
+	}
+}
+
+// enqueueSecretVPNPeers takes a Secret resource and enqueues the VPNPeers whose preshared key it holds
+func (c *Controller) enqueueSecretVPNPeers(obj interface{}) {
+	secret := obj.(*corev1.Secret)
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	for _, peer := range peers {
+		if peer.Spec.PresharedKeySecret == secret.GetName() {
+			c.enqueueVPNPeer(peer)
+		}
+	}
+}
+
+// getNodeName returns the name of the node the peer belongs to, which is the name of the peer if its spec names none
+func getNodeName(peer *v1alpha1.VPNPeer) string {
+	if peer.Spec.NodeName != "" {
+		return peer.Spec.NodeName
+	}
+	return peer.GetName()
+}
+
+// findNodePeer returns the peer of the node, or nil if the node has none
+func findNodePeer(peers []*v1alpha1.VPNPeer, nodeName string) *v1alpha1.VPNPeer {
+	for _, peer := range peers {
+		if getNodeName(peer) == nodeName {
+			return peer
+		}
+	}
+	return nil
 }
 
 func findPeer(peers []*v1alpha1.VPNPeer, publicKey string) (*v1alpha1.VPNPeer, error) {
 	var found *v1alpha1.VPNPeer
 	for _, peer := range peers {
-		if peer.Spec.PublicKey == publicKey {
+		if peer.Spec.PublicKey == publicKey || peer.Spec.NextPublicKey == publicKey {
 			if found != nil {
 				return nil, fmt.Errorf("multiple peers found with public key %s", publicKey)
 			}
@@ -227,15 +383,13 @@ func findPeer(peers []*v1alpha1.VPNPeer, publicKey string) (*v1alpha1.VPNPeer, e
 	return found, nil
 }
 
-func addPeer(linkname string, peer v1alpha1.VPNPeer) error {
-	client, err := wgctrl.New()
-	if err != nil {
-		return fmt.Errorf("error while creating WG client: %s", err.Error())
-	}
-
-	publicKey, err := wgtypes.ParseKey(peer.Spec.PublicKey)
-	if err != nil {
-		return fmt.Errorf("error while parsing WG public key: %s", err.Error())
+// getPeerConfigs returns the configurations of the peer on the WireGuard interface, one for each of its keys. During
+// a rotation, both keys are configured so that the node can switch to the new one without dropping the tunnel, but
+// only the active key carries the allowed IPs, as an address routes to a single peer.
+func getPeerConfigs(peer v1alpha1.VPNPeer, presharedKey wgtypes.Key, observations map[wgtypes.Key]wgtypes.Peer) ([]wgtypes.PeerConfig, error) {
+	publicKeys := getPeerKeys(&peer)
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("error while parsing WG public key of %s", peer.GetName())
 	}
 
 	allowedIPs := []net.IPNet{
@@ -248,6 +402,13 @@ func addPeer(linkname string, peer v1alpha1.VPNPeer) error {
 			Mask: net.CIDRMask(128, 128),
 		},
 	}
+	for _, cidr := range peer.Spec.AllowedIPs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing allowed IPs of %s: %s", peer.GetName(), err.Error())
+		}
+		allowedIPs = append(allowedIPs, *network)
+	}
 
 	var endpoint *net.UDPAddr
 	if peer.Spec.EndpointAddress != nil && peer.Spec.EndpointPort != nil {
@@ -258,28 +419,30 @@ func addPeer(linkname string, peer v1alpha1.VPNPeer) error {
 	}
 
 	keepaliveInterval := 5 * time.Second
-
-	peerConfig := wgtypes.PeerConfig{
-		AllowedIPs:                  allowedIPs,
-		Endpoint:                    endpoint,
-		PublicKey:                   publicKey,
-		PersistentKeepaliveInterval: &keepaliveInterval,
-		Remove:                      false,
-		ReplaceAllowedIPs:           true,
-		UpdateOnly:                  false,
+	if peer.Spec.PersistentKeepalive != nil {
+		keepaliveInterval = time.Duration(*peer.Spec.PersistentKeepalive) * time.Second
 	}
 
-	deviceConfig := wgtypes.Config{
-		Peers:        []wgtypes.PeerConfig{peerConfig},
-		ReplacePeers: false,
-	}
-
-	err = client.ConfigureDevice(linkname, deviceConfig)
-	if err != nil {
-		return fmt.Errorf("error while configure WG device %s: %s", linkname, err.Error())
+	activeKey := getActiveKey(publicKeys, observations)
+	peerConfigs := []wgtypes.PeerConfig{}
+	for _, publicKey := range publicKeys {
+		peerConfig := wgtypes.PeerConfig{
+			AllowedIPs:                  []net.IPNet{},
+			Endpoint:                    endpoint,
+			PublicKey:                   publicKey,
+			PresharedKey:                &presharedKey,
+			PersistentKeepaliveInterval: &keepaliveInterval,
+			Remove:                      false,
+			ReplaceAllowedIPs:           true,
+			UpdateOnly:                  false,
+		}
+		if publicKey == activeKey {
+			peerConfig.AllowedIPs = allowedIPs
+		}
+		peerConfigs = append(peerConfigs, peerConfig)
 	}
 
-	return nil
+	return peerConfigs, nil
 }
 
 func removePeer(linkname string, publicKey string) error {
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpnpeer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PresharedKeyNamespace is the namespace of the secrets holding the preshared keys of the peers
+	PresharedKeyNamespace = "edgenet"
+	// presharedKeyData is the key of the preshared key in the secret, Base64 encoded as wg genpsk prints it
+	presharedKeyData = "presharedKey"
+)
+
+// getPeerKeys returns the public keys of the peer, the current one first, then the staged one during a rotation
+func getPeerKeys(peer *v1alpha1.VPNPeer) []wgtypes.Key {
+	publicKeys := []wgtypes.Key{}
+	publicKey, err := wgtypes.ParseKey(peer.Spec.PublicKey)
+	if err != nil {
+		return publicKeys
+	}
+	publicKeys = append(publicKeys, publicKey)
+	if nextPublicKey, err := wgtypes.ParseKey(peer.Spec.NextPublicKey); err == nil && nextPublicKey != publicKey {
+		publicKeys = append(publicKeys, nextPublicKey)
+	}
+	return publicKeys
+}
+
+// getActiveKey returns the key of the peer that carries its addresses. The staged key takes over at its first
+// handshake, as the node only shakes hands with it once it switched to it.
+func getActiveKey(publicKeys []wgtypes.Key, observations map[wgtypes.Key]wgtypes.Peer) wgtypes.Key {
+	if len(publicKeys) < 2 {
+		return publicKeys[0]
+	}
+	if next := observations[publicKeys[1]]; !next.LastHandshakeTime.IsZero() {
+		return publicKeys[1]
+	}
+	return publicKeys[0]
+}
+
+// checkRotations moves the addresses of the peers under rotation to the staged key as soon as the node shook hands
+// with it. It checks the interface at a shorter interval than the status, as the tunnel carries no traffic until then.
+func (c *Controller) checkRotations() {
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	rotating := []*v1alpha1.VPNPeer{}
+	for _, peer := range peers {
+		if len(getPeerKeys(peer)) > 1 {
+			rotating = append(rotating, peer)
+		}
+	}
+	if len(rotating) == 0 {
+		return
+	}
+	observations, err := getObservations(c.linkname)
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	for _, peer := range rotating {
+		publicKeys := getPeerKeys(peer)
+		if observed, ok := observations[publicKeys[1]]; ok && getActiveKey(publicKeys, observations) == publicKeys[1] && len(observed.AllowedIPs) == 0 {
+			c.enqueueVPNPeer(peer)
+		}
+	}
+}
+
+// getPresharedKey reads the preshared key of the peer from its secret. A peer without a secret gets the zero key,
+// which removes any preshared key the interface has for it.
+func (c *Controller) getPresharedKey(peer *v1alpha1.VPNPeer) (wgtypes.Key, error) {
+	if peer.Spec.PresharedKeySecret == "" {
+		return wgtypes.Key{}, nil
+	}
+	secret, err := c.secretsLister.Secrets(PresharedKeyNamespace).Get(peer.Spec.PresharedKeySecret)
+	if err != nil {
+		return wgtypes.Key{}, err
+	}
+	presharedKey, err := wgtypes.ParseKey(strings.TrimSpace(string(secret.Data[presharedKeyData])))
+	if err != nil {
+		return wgtypes.Key{}, fmt.Errorf("error while parsing WG preshared key of %s: %s", peer.GetName(), err.Error())
+	}
+	return presharedKey, nil
+}
+
+// getPresharedKeys returns the preshared keys of the peers by name. The peers whose secret cannot be read are left out.
+func (c *Controller) getPresharedKeys(peers []*v1alpha1.VPNPeer) map[string]wgtypes.Key {
+	presharedKeys := make(map[string]wgtypes.Key)
+	for _, peer := range peers {
+		presharedKey, err := c.getPresharedKey(peer)
+		if err != nil {
+			klog.Infoln(err)
+			continue
+		}
+		presharedKeys[peer.GetName()] = presharedKey
+	}
+	return presharedKeys
+}
@@ -0,0 +1,137 @@
+package vpnpeer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetPeerConfigs(t *testing.T) {
+	peer, publicKey := newTestPeer(t, "rotated", "10.183.0.2", "fdb4:ae86:ec99:4004::2")
+	_, nextPublicKey := newTestPeer(t, "next", "", "")
+	presharedKey, err := wgtypes.GenerateKey()
+	util.OK(t, err)
+
+	peerConfigs, err := getPeerConfigs(*peer, presharedKey, nil)
+	util.OK(t, err)
+	util.Equals(t, 1, len(peerConfigs))
+	util.Equals(t, 5*time.Second, *peerConfigs[0].PersistentKeepaliveInterval)
+	util.Equals(t, presharedKey, *peerConfigs[0].PresharedKey)
+	util.Equals(t, 2, len(peerConfigs[0].AllowedIPs))
+
+	keepalive := 25
+	peer.Spec.PersistentKeepalive = &keepalive
+	peer.Spec.AllowedIPs = []string{"192.168.1.0/24"}
+	peer.Spec.NextPublicKey = nextPublicKey.String()
+	peerConfigs, err = getPeerConfigs(*peer, presharedKey, nil)
+	util.OK(t, err)
+	util.Equals(t, 2, len(peerConfigs))
+	util.Equals(t, publicKey, peerConfigs[0].PublicKey)
+	util.Equals(t, 25*time.Second, *peerConfigs[0].PersistentKeepaliveInterval)
+	util.Equals(t, 3, len(peerConfigs[0].AllowedIPs))
+	util.Equals(t, "192.168.1.0/24", peerConfigs[0].AllowedIPs[2].String())
+	// The staged key shakes hands without carrying the addresses yet
+	util.Equals(t, nextPublicKey, peerConfigs[1].PublicKey)
+	util.Equals(t, 0, len(peerConfigs[1].AllowedIPs))
+
+	t.Run("switched", func(t *testing.T) {
+		observations := map[wgtypes.Key]wgtypes.Peer{
+			publicKey:     {PublicKey: publicKey, LastHandshakeTime: time.Now().Add(-time.Minute)},
+			nextPublicKey: {PublicKey: nextPublicKey, LastHandshakeTime: time.Now()},
+		}
+		peerConfigs, err := getPeerConfigs(*peer, presharedKey, observations)
+		util.OK(t, err)
+		util.Equals(t, 0, len(peerConfigs[0].AllowedIPs))
+		util.Equals(t, 3, len(peerConfigs[1].AllowedIPs))
+	})
+	t.Run("invalid allowed IPs", func(t *testing.T) {
+		peer := peer.DeepCopy()
+		peer.Spec.AllowedIPs = []string{"192.168.1.0"}
+		_, err := getPeerConfigs(*peer, presharedKey, nil)
+		util.Equals(t, true, err != nil)
+	})
+}
+
+func TestGetActiveKey(t *testing.T) {
+	_, publicKey := newTestPeer(t, "current", "", "")
+	_, nextPublicKey := newTestPeer(t, "next", "", "")
+	publicKeys := []wgtypes.Key{publicKey, nextPublicKey}
+	now := time.Now()
+
+	cases := map[string]struct {
+		observations map[wgtypes.Key]wgtypes.Peer
+		expected     wgtypes.Key
+	}{
+		"no handshake": {nil, publicKey},
+		"current only": {map[wgtypes.Key]wgtypes.Peer{publicKey: {LastHandshakeTime: now}}, publicKey},
+		"next only":    {map[wgtypes.Key]wgtypes.Peer{nextPublicKey: {LastHandshakeTime: now}}, nextPublicKey},
+		"next older": {map[wgtypes.Key]wgtypes.Peer{
+			publicKey:     {LastHandshakeTime: now},
+			nextPublicKey: {LastHandshakeTime: now.Add(-time.Minute)},
+		}, nextPublicKey},
+		"next newer": {map[wgtypes.Key]wgtypes.Peer{
+			publicKey:     {LastHandshakeTime: now.Add(-time.Minute)},
+			nextPublicKey: {LastHandshakeTime: now},
+		}, nextPublicKey},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			util.Equals(t, tc.expected, getActiveKey(publicKeys, tc.observations))
+		})
+	}
+	util.Equals(t, publicKey, getActiveKey([]wgtypes.Key{publicKey}, nil))
+}
+
+func TestGetPresharedKeys(t *testing.T) {
+	presharedKey, err := wgtypes.GenerateKey()
+	util.OK(t, err)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "paris-psk", Namespace: PresharedKeyNamespace},
+		Data:       map[string][]byte{presharedKeyData: []byte(presharedKey.String() + "\n")},
+	}
+	util.OK(t, indexer.Add(secret))
+	// A secret of the same name elsewhere is not taken
+	util.OK(t, indexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "default"}}))
+	c := &Controller{secretsLister: corelisters.NewSecretLister(indexer)}
+
+	paris, parisKey := newTestPeer(t, "paris", "10.183.0.2", "fdb4:ae86:ec99:4004::2")
+	paris.Spec.PresharedKeySecret = "paris-psk"
+	berlin, berlinKey := newTestPeer(t, "berlin", "10.183.0.3", "fdb4:ae86:ec99:4004::3")
+	berlin.Spec.PresharedKeySecret = "missing"
+	boston, bostonKey := newTestPeer(t, "boston", "10.183.0.4", "fdb4:ae86:ec99:4004::4")
+	peers := []*v1alpha1.VPNPeer{paris, berlin, boston}
+
+	presharedKeys := c.getPresharedKeys(peers)
+	util.Equals(t, map[string]wgtypes.Key{"paris": presharedKey, "boston": {}}, presharedKeys)
+
+	// The peer whose secret is missing is left as is rather than removed
+	desired, kept := getDesiredPeers(peers, presharedKeys, nil)
+	util.Equals(t, 2, len(desired))
+	util.Equals(t, presharedKey, *desired[parisKey].PresharedKey)
+	util.Equals(t, wgtypes.Key{}, *desired[bostonKey].PresharedKey)
+	util.Equals(t, true, kept[berlinKey])
+
+	observations := map[wgtypes.Key]wgtypes.Peer{
+		parisKey: {
+			PublicKey:                   parisKey,
+			PersistentKeepaliveInterval: 5 * time.Second,
+			AllowedIPs: []net.IPNet{
+				{IP: net.ParseIP("10.183.0.2").To4(), Mask: net.CIDRMask(32, 32)},
+				{IP: net.ParseIP("fdb4:ae86:ec99:4004::2"), Mask: net.CIDRMask(128, 128)},
+			},
+		},
+	}
+	// The interface lacks the preshared key of Paris
+	util.Equals(t, false, isConfigured(observations[parisKey], desired[parisKey]))
+}
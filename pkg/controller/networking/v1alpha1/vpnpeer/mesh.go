@@ -0,0 +1,126 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpnpeer
+
+import (
+	"flag"
+	"net"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Modes of the controller
+const (
+	// modeHub configures all peers on the interface of a head node
+	modeHub = "hub"
+	// modeMesh configures the mesh peers of the node the agent runs on
+	modeMesh = "mesh"
+)
+
+// getMode returns whether the controller runs on a head node or as a mesh agent on an edge node
+func getMode() string {
+	mode := modeHub
+	if flag.Lookup("mode") != nil {
+		mode = flag.Lookup("mode").Value.(flag.Getter).Get().(string)
+	}
+	return mode
+}
+
+// getMeshPeers returns the configurations of the peers the node connects to directly, and the peers of the
+// interface the agent leaves as is. The agent leaves the peers that route a range and that no resource describes,
+// such as the head node the bootstrap script configured, which still carries the traffic towards the peers outside
+// the mesh. As the two ends of a mesh connection would each use the preshared key of the other, mesh connections
+// go without one.
+func (c *Controller) getMeshPeers(peers []*v1alpha1.VPNPeer, observations map[wgtypes.Key]wgtypes.Peer) (map[wgtypes.Key]wgtypes.PeerConfig, map[wgtypes.Key]bool) {
+	presharedKeys := make(map[string]wgtypes.Key)
+	described := make(map[wgtypes.Key]bool)
+	for _, peer := range peers {
+		presharedKeys[peer.GetName()] = wgtypes.Key{}
+		for _, publicKey := range getPeerKeys(peer) {
+			described[publicKey] = true
+		}
+	}
+	desired, kept := getDesiredPeers(peers, presharedKeys, observations)
+	for publicKey, observation := range observations {
+		if !described[publicKey] && !hasHostRoutesOnly(observation.AllowedIPs) {
+			kept[publicKey] = true
+		}
+	}
+
+	meshPeers := make(map[wgtypes.Key]wgtypes.PeerConfig)
+	self := findNodePeer(peers, c.nodename)
+	if self == nil || self.Spec.Mesh == nil {
+		return meshPeers, kept
+	}
+	for _, peer := range peers {
+		if peer.GetName() == self.GetName() || !c.isMeshedWith(self, peer) {
+			continue
+		}
+		// One of the two must be reachable for them to shake hands
+		if !hasEndpoint(peer) && !hasEndpoint(self) {
+			continue
+		}
+		for _, publicKey := range getPeerKeys(peer) {
+			if peerConfig, ok := desired[publicKey]; ok {
+				meshPeers[publicKey] = peerConfig
+			}
+		}
+	}
+	return meshPeers, kept
+}
+
+// isMeshedWith tells whether both peers are in the mesh and select each other
+func (c *Controller) isMeshedWith(peer, other *v1alpha1.VPNPeer) bool {
+	return peer.Spec.Mesh != nil && other.Spec.Mesh != nil && c.selects(peer, other) && c.selects(other, peer)
+}
+
+// selects tells whether the topology of the peer includes the other peer
+func (c *Controller) selects(peer, other *v1alpha1.VPNPeer) bool {
+	if peer.Spec.Mesh.Topology != v1alpha1.MeshTopologyPartial {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(peer.Spec.Mesh.NodeSelector)
+	if err != nil || peer.Spec.Mesh.NodeSelector == nil {
+		return false
+	}
+	node, err := c.nodesLister.Get(getNodeName(other))
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(node.GetLabels()))
+}
+
+// hasEndpoint tells whether the spec of the peer gives the endpoint to reach it at
+func hasEndpoint(peer *v1alpha1.VPNPeer) bool {
+	return peer.Spec.EndpointAddress != nil && peer.Spec.EndpointPort != nil
+}
+
+// hasHostRoutesOnly tells whether the allowed IPs are single addresses. A peer without allowed IPs, such as the
+// old key of a rotated peer, carries no traffic.
+func hasHostRoutesOnly(allowedIPs []net.IPNet) bool {
+	for _, allowedIP := range allowedIPs {
+		if ones, bits := allowedIP.Mask.Size(); ones != bits {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package vpnpeer
+
+import (
+	"net"
+	"testing"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestGetMeshPeers(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, continent := range map[string]string{"paris": "Europe", "berlin": "Europe", "boston": "North_America", "nantes": "Europe"} {
+		indexer.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"edge-net.io/continent": continent}}})
+	}
+	c := &Controller{nodesLister: corelisters.NewNodeLister(indexer), nodename: "paris"}
+
+	europe := &v1alpha1.VPNPeerMesh{
+		Topology:     v1alpha1.MeshTopologyPartial,
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"edge-net.io/continent": "Europe"}},
+	}
+	endpoint, port := "192.0.2.10", 51820
+	paris, parisKey := newTestPeer(t, "paris", "10.183.0.2", "fdb4:ae86:ec99:4004::2")
+	paris.Spec.Mesh = europe
+	paris.Spec.EndpointAddress, paris.Spec.EndpointPort = &endpoint, &port
+	berlin, berlinKey := newTestPeer(t, "berlin", "10.183.0.3", "fdb4:ae86:ec99:4004::3")
+	berlin.Spec.Mesh = &v1alpha1.VPNPeerMesh{Topology: v1alpha1.MeshTopologyFull}
+	boston, bostonKey := newTestPeer(t, "boston", "10.183.0.4", "fdb4:ae86:ec99:4004::4")
+	boston.Spec.Mesh = &v1alpha1.VPNPeerMesh{Topology: v1alpha1.MeshTopologyFull}
+	nantes, nantesKey := newTestPeer(t, "nantes", "10.183.0.5", "fdb4:ae86:ec99:4004::5")
+	peers := []*v1alpha1.VPNPeer{paris, berlin, boston, nantes}
+
+	_, headKey := newTestPeer(t, "head", "", "")
+	_, rotatedKey := newTestPeer(t, "rotated", "", "")
+	observations := map[wgtypes.Key]wgtypes.Peer{
+		headKey:    {PublicKey: headKey, AllowedIPs: []net.IPNet{{IP: net.ParseIP("10.183.0.0").To4(), Mask: net.CIDRMask(20, 32)}}},
+		rotatedKey: {PublicKey: rotatedKey},
+		nantesKey:  {PublicKey: nantesKey, AllowedIPs: []net.IPNet{{IP: net.ParseIP("10.183.0.5").To4(), Mask: net.CIDRMask(32, 32)}}},
+	}
+
+	meshPeers, kept := c.getMeshPeers(peers, observations)
+	// Boston is out of the partial mesh of Paris, and Nantes is not in the mesh
+	util.Equals(t, 1, len(meshPeers))
+	_, ok := meshPeers[berlinKey]
+	util.Equals(t, true, ok)
+	_, ok = meshPeers[parisKey]
+	util.Equals(t, false, ok)
+	util.Equals(t, true, kept[headKey])
+	util.Equals(t, false, kept[rotatedKey])
+
+	changes := diffPeers(observations, meshPeers, kept)
+	removed := make(map[wgtypes.Key]bool)
+	for _, change := range changes {
+		if change.Remove {
+			removed[change.PublicKey] = true
+		}
+	}
+	util.Equals(t, map[wgtypes.Key]bool{rotatedKey: true, nantesKey: true}, removed)
+
+	t.Run("selected by both", func(t *testing.T) {
+		c := &Controller{nodesLister: corelisters.NewNodeLister(indexer), nodename: "boston"}
+		boston := boston.DeepCopy()
+		address, port := "203.0.113.30", 51820
+		boston.Spec.EndpointAddress, boston.Spec.EndpointPort = &address, &port
+		meshPeers, _ := c.getMeshPeers([]*v1alpha1.VPNPeer{paris, berlin, boston, nantes}, nil)
+		// Berlin selects Boston, but Paris does not
+		util.Equals(t, 1, len(meshPeers))
+		_, ok := meshPeers[berlinKey]
+		util.Equals(t, true, ok)
+		_, ok = meshPeers[bostonKey]
+		util.Equals(t, false, ok)
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		// Neither Berlin nor Boston has an endpoint to shake hands with
+		c := &Controller{nodesLister: corelisters.NewNodeLister(indexer), nodename: "berlin"}
+		meshPeers, _ := c.getMeshPeers(peers, nil)
+		util.Equals(t, 1, len(meshPeers))
+		util.Equals(t, "192.0.2.10:51820", meshPeers[parisKey].Endpoint.String())
+
+		berlin := berlin.DeepCopy()
+		address, port := "198.51.100.20", 51820
+		berlin.Spec.EndpointAddress, berlin.Spec.EndpointPort = &address, &port
+		meshPeers, _ = c.getMeshPeers([]*v1alpha1.VPNPeer{paris, berlin, boston, nantes}, nil)
+		util.Equals(t, 2, len(meshPeers))
+	})
+	t.Run("named after another resource", func(t *testing.T) {
+		// The peer of Berlin goes by another name, so its node is found through the spec
+		c := &Controller{nodesLister: corelisters.NewNodeLister(indexer), nodename: "berlin"}
+		renamed := berlin.DeepCopy()
+		renamed.SetName("de-be-0000")
+		renamed.Spec.NodeName = "berlin"
+		renamed.Spec.Mesh = europe
+		meshPeers, _ := c.getMeshPeers([]*v1alpha1.VPNPeer{paris, renamed, boston, nantes}, nil)
+		util.Equals(t, 1, len(meshPeers))
+		_, ok := meshPeers[parisKey]
+		util.Equals(t, true, ok)
+	})
+	t.Run("not in the mesh", func(t *testing.T) {
+		c := &Controller{nodesLister: corelisters.NewNodeLister(indexer), nodename: "nantes"}
+		meshPeers, _ := c.getMeshPeers(peers, nil)
+		util.Equals(t, 0, len(meshPeers))
+	})
+}
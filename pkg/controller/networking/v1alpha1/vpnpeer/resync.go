@@ -0,0 +1,211 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpnpeer
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/multiprovider"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// getResyncPeriod returns the period at which the whole WireGuard interface is compared with the VPNPeer resources
+func getResyncPeriod() time.Duration {
+	period := 5 * time.Minute
+	if flag.Lookup("resync-period") != nil {
+		period = flag.Lookup("resync-period").Value.(flag.Getter).Get().(time.Duration)
+	}
+	return period
+}
+
+// isDryRun tells whether the changes to the WireGuard interface are only reported rather than applied
+func isDryRun() bool {
+	dryRun := false
+	if flag.Lookup("dry-run") != nil {
+		dryRun = flag.Lookup("dry-run").Value.(flag.Getter).Get().(bool)
+	}
+	return dryRun
+}
+
+// resync converges the WireGuard interface to the VPNPeer resources
+func (c *Controller) resync() {
+	if err := c.converge(); err != nil {
+		klog.Infoln(err)
+	}
+}
+
+// converge removes the peers of the WireGuard interface that no resource describes, such as the ones whose deletion
+// was missed while the controller was down or whose key was rotated, and configures the peers that are missing or
+// configured differently. In mesh mode, the interface gets the mesh peers of the node rather than all peers.
+func (c *Controller) converge() error {
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	observations, err := getObservations(c.linkname)
+	if err != nil {
+		return err
+	}
+	var desired map[wgtypes.Key]wgtypes.PeerConfig
+	var kept map[wgtypes.Key]bool
+	if getMode() == modeMesh {
+		desired, kept = c.getMeshPeers(peers, observations)
+	} else {
+		desired, kept = getDesiredPeers(peers, c.getPresharedKeys(peers), observations)
+	}
+	changes := diffPeers(observations, desired, kept)
+	if len(changes) == 0 {
+		klog.V(4).Infof("Link %s is in sync with %d peers", c.linkname, len(desired))
+		return nil
+	}
+
+	for _, change := range changes {
+		action := "configured on"
+		if change.Remove {
+			action = "removed from"
+		}
+		if isDryRun() {
+			klog.Infof("Dry run: peer with public key %s would be %s link %s", change.PublicKey, action, c.linkname)
+		} else {
+			klog.Infof("Resync: peer with public key %s %s link %s", change.PublicKey, action, c.linkname)
+		}
+	}
+	if isDryRun() {
+		return nil
+	}
+	return configurePeers(c.linkname, changes)
+}
+
+// getDesiredPeers returns the configurations of the peers the interface should have, by public key, and the keys the
+// interface keeps whatever it has for. These are the keys that several resources claim, until the claim is solved,
+// and the keys of the peers whose preshared key cannot be read, until it can.
+func getDesiredPeers(peers []*v1alpha1.VPNPeer, presharedKeys map[string]wgtypes.Key, observations map[wgtypes.Key]wgtypes.Peer) (map[wgtypes.Key]wgtypes.PeerConfig, map[wgtypes.Key]bool) {
+	desired := make(map[wgtypes.Key]wgtypes.PeerConfig)
+	kept := make(map[wgtypes.Key]bool)
+	claimed := make(map[wgtypes.Key]bool)
+	for _, peer := range peers {
+		publicKeys := getPeerKeys(peer)
+		ambiguous := false
+		for _, publicKey := range publicKeys {
+			if claimed[publicKey] {
+				ambiguous = true
+			}
+		}
+		if ambiguous {
+			for _, publicKey := range publicKeys {
+				kept[publicKey] = true
+				delete(desired, publicKey)
+			}
+			continue
+		}
+		for _, publicKey := range publicKeys {
+			claimed[publicKey] = true
+		}
+		if len(publicKeys) == 0 || peer.Spec.AddressV4 == "" || peer.Spec.AddressV6 == "" || multiprovider.HasAddressConflict(peer, peers) {
+			continue
+		}
+		presharedKey, ok := presharedKeys[peer.GetName()]
+		if !ok {
+			for _, publicKey := range publicKeys {
+				kept[publicKey] = true
+			}
+			continue
+		}
+		if peerConfigs, err := getPeerConfigs(*peer, presharedKey, observations); err == nil {
+			for _, peerConfig := range peerConfigs {
+				desired[peerConfig.PublicKey] = peerConfig
+			}
+		}
+	}
+	return desired, kept
+}
+
+// diffPeers returns the changes that bring the peers of the interface to the desired ones, sorted by public key.
+// The kept peers and the endpoint the kernel learnt from a peer roaming are left as is.
+func diffPeers(observations map[wgtypes.Key]wgtypes.Peer, desired map[wgtypes.Key]wgtypes.PeerConfig, kept map[wgtypes.Key]bool) []wgtypes.PeerConfig {
+	changes := []wgtypes.PeerConfig{}
+	for publicKey := range observations {
+		if _, ok := desired[publicKey]; !ok && !kept[publicKey] {
+			changes = append(changes, wgtypes.PeerConfig{PublicKey: publicKey, Remove: true})
+		}
+	}
+	for publicKey, peerConfig := range desired {
+		observation, ok := observations[publicKey]
+		if !ok || !isConfigured(observation, peerConfig) {
+			changes = append(changes, peerConfig)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].PublicKey.String() < changes[j].PublicKey.String()
+	})
+	return changes
+}
+
+// isConfigured tells whether the peer of the interface matches the configuration
+func isConfigured(observation wgtypes.Peer, peerConfig wgtypes.PeerConfig) bool {
+	if peerConfig.Endpoint != nil && observation.Endpoint == nil {
+		return false
+	}
+	if peerConfig.PersistentKeepaliveInterval != nil && observation.PersistentKeepaliveInterval != *peerConfig.PersistentKeepaliveInterval {
+		return false
+	}
+	if peerConfig.PresharedKey != nil && observation.PresharedKey != *peerConfig.PresharedKey {
+		return false
+	}
+	if len(observation.AllowedIPs) != len(peerConfig.AllowedIPs) {
+		return false
+	}
+	allowedIPs := make(map[string]bool)
+	for _, allowedIP := range observation.AllowedIPs {
+		allowedIPs[allowedIP.String()] = true
+	}
+	for _, allowedIP := range peerConfig.AllowedIPs {
+		if !allowedIPs[allowedIP.String()] {
+			return false
+		}
+	}
+	return true
+}
+
+func configurePeers(linkname string, peerConfigs []wgtypes.PeerConfig) error {
+	client, err := wgctrl.New()
+	if err != nil {
+		return fmt.Errorf("error while creating WG client: %s", err.Error())
+	}
+	defer client.Close()
+
+	deviceConfig := wgtypes.Config{
+		Peers:        peerConfigs,
+		ReplacePeers: false,
+	}
+
+	err = client.ConfigureDevice(linkname, deviceConfig)
+	if err != nil {
+		return fmt.Errorf("error while configure WG device %s: %s", linkname, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package vpnpeer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPeer(t *testing.T, name, addressV4, addressV6 string) (*v1alpha1.VPNPeer, wgtypes.Key) {
+	privateKey, err := wgtypes.GeneratePrivateKey()
+	util.OK(t, err)
+	publicKey := privateKey.PublicKey()
+	return &v1alpha1.VPNPeer{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.VPNPeerSpec{AddressV4: addressV4, AddressV6: addressV6, PublicKey: publicKey.String()},
+	}, publicKey
+}
+
+func noPresharedKeys(peers ...*v1alpha1.VPNPeer) map[string]wgtypes.Key {
+	presharedKeys := make(map[string]wgtypes.Key)
+	for _, peer := range peers {
+		presharedKeys[peer.GetName()] = wgtypes.Key{}
+	}
+	return presharedKeys
+}
+
+func TestGetDesiredPeers(t *testing.T) {
+	synced, syncedKey := newTestPeer(t, "synced", "10.183.0.2", "fdb4:ae86:ec99:4004::2")
+	waiting, waitingKey := newTestPeer(t, "waiting", "", "")
+	duplicate := synced.DeepCopy()
+	duplicate.SetName("duplicate")
+	duplicate.Spec.AddressV4, duplicate.Spec.AddressV6 = "10.183.0.3", "fdb4:ae86:ec99:4004::3"
+	other, otherKey := newTestPeer(t, "other", "10.183.0.4", "fdb4:ae86:ec99:4004::4")
+
+	desired, ambiguous := getDesiredPeers([]*v1alpha1.VPNPeer{synced, waiting, other}, noPresharedKeys(synced, waiting, other), nil)
+	util.Equals(t, 2, len(desired))
+	_, ok := desired[syncedKey]
+	util.Equals(t, true, ok)
+	_, ok = desired[waitingKey]
+	util.Equals(t, false, ok)
+	_, ok = desired[otherKey]
+	util.Equals(t, true, ok)
+	util.Equals(t, 0, len(ambiguous))
+
+	desired, ambiguous = getDesiredPeers([]*v1alpha1.VPNPeer{synced, duplicate, other}, noPresharedKeys(synced, duplicate, other), nil)
+	util.Equals(t, 1, len(desired))
+	util.Equals(t, true, ambiguous[syncedKey])
+}
+
+func TestDiffPeers(t *testing.T) {
+	synced, syncedKey := newTestPeer(t, "synced", "10.183.0.2", "fdb4:ae86:ec99:4004::2")
+	moved, movedKey := newTestPeer(t, "moved", "10.183.0.3", "fdb4:ae86:ec99:4004::3")
+	missing, missingKey := newTestPeer(t, "missing", "10.183.0.4", "fdb4:ae86:ec99:4004::4")
+	_, staleKey := newTestPeer(t, "stale", "10.183.0.5", "fdb4:ae86:ec99:4004::5")
+	_, ambiguousKey := newTestPeer(t, "ambiguous", "10.183.0.6", "fdb4:ae86:ec99:4004::6")
+
+	observe := func(publicKey wgtypes.Key, addressV4, addressV6 string) wgtypes.Peer {
+		return wgtypes.Peer{
+			PublicKey:                   publicKey,
+			Endpoint:                    &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 51820},
+			PersistentKeepaliveInterval: 5 * time.Second,
+			AllowedIPs: []net.IPNet{
+				{IP: net.ParseIP(addressV4).To4(), Mask: net.CIDRMask(32, 32)},
+				{IP: net.ParseIP(addressV6), Mask: net.CIDRMask(128, 128)},
+			},
+		}
+	}
+	observations := map[wgtypes.Key]wgtypes.Peer{
+		syncedKey:    observe(syncedKey, "10.183.0.2", "fdb4:ae86:ec99:4004::2"),
+		movedKey:     observe(movedKey, "10.183.0.30", "fdb4:ae86:ec99:4004::3"),
+		staleKey:     observe(staleKey, "10.183.0.5", "fdb4:ae86:ec99:4004::5"),
+		ambiguousKey: observe(ambiguousKey, "10.183.0.6", "fdb4:ae86:ec99:4004::6"),
+	}
+	desired, _ := getDesiredPeers([]*v1alpha1.VPNPeer{synced, moved, missing}, noPresharedKeys(synced, moved, missing), nil)
+
+	changes := diffPeers(observations, desired, map[wgtypes.Key]bool{ambiguousKey: true})
+	util.Equals(t, 3, len(changes))
+	byKey := make(map[wgtypes.Key]wgtypes.PeerConfig)
+	for _, change := range changes {
+		byKey[change.PublicKey] = change
+	}
+	util.Equals(t, true, byKey[staleKey].Remove)
+	util.Equals(t, false, byKey[movedKey].Remove)
+	util.Equals(t, false, byKey[missingKey].Remove)
+	_, ok := byKey[syncedKey]
+	util.Equals(t, false, ok)
+
+	t.Run("in sync", func(t *testing.T) {
+		observations := map[wgtypes.Key]wgtypes.Peer{
+			syncedKey: observe(syncedKey, "10.183.0.2", "fdb4:ae86:ec99:4004::2"),
+		}
+		desired, _ := getDesiredPeers([]*v1alpha1.VPNPeer{synced}, noPresharedKeys(synced), nil)
+		util.Equals(t, 0, len(diffPeers(observations, desired, nil)))
+	})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpnpeer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// statusInterval is the period at which the status of the peers is refreshed from the WireGuard interface
+	statusInterval = time.Minute
+	// rotationInterval is the period at which the interface is checked for the peers that switched to their staged key
+	rotationInterval = 5 * time.Second
+	// handshakeTimeout is how old the latest handshake can be for the peer to be connected. WireGuard renews
+	// the session every two minutes while packets flow, which the keepalive guarantees.
+	handshakeTimeout = 3 * time.Minute
+
+	reasonHandshakeRecent  = "HandshakeRecent"
+	reasonHandshakeStale   = "HandshakeStale"
+	reasonNoHandshake      = "NoHandshake"
+	warningDisconnected    = "Disconnected"
+	messageHandshakeRecent = "Latest handshake at %s"
+	messageHandshakeStale  = "No handshake since %s"
+	messageNoHandshake     = "No handshake with the peer yet"
+	messageDisconnected    = "Peer lost its tunnel, no handshake since %s"
+)
+
+// reportStatus reads the peers of the WireGuard interface and reports in the status of each VPNPeer resource when the
+// head node last shook hands with the peer, how much traffic its tunnel carried, and the endpoint the peer comes from.
+// Only the hub reports, as the edge nodes running the mesh agent see a part of the peers. During a rotation, the
+// figures are those of the key that carries the addresses.
+func (c *Controller) reportStatus() {
+	if getMode() != modeHub {
+		return
+	}
+	observations, err := getObservations(c.linkname)
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	peers, err := c.vpnpeersLister.List(labels.Everything())
+	if err != nil {
+		klog.Infoln(err)
+		return
+	}
+	now := time.Now()
+	for _, peer := range peers {
+		var observation *wgtypes.Peer
+		if publicKeys := getPeerKeys(peer); len(publicKeys) > 0 {
+			if observed, ok := observations[getActiveKey(publicKeys, observations)]; ok {
+				observation = &observed
+			}
+		}
+		if err := c.updateStatus(peer, observation, now); err != nil {
+			klog.Infoln(err)
+		}
+	}
+}
+
+// updateStatus merges the observation of the interface into the status of the peer, starting from the cached copy,
+// and calls the API only if the status changed. A conflict is settled at the next refresh.
+func (c *Controller) updateStatus(peer *v1alpha1.VPNPeer, observation *wgtypes.Peer, now time.Time) error {
+	peerCopy := peer.DeepCopy()
+	observePeer(&peerCopy.Status, observation, now)
+	if equality.Semantic.DeepEqual(peer.Status, peerCopy.Status) {
+		return nil
+	}
+	if _, err := c.edgenetclientset.NetworkingV1alpha1().VPNPeers().UpdateStatus(context.TODO(), peerCopy, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	if meta.IsStatusConditionTrue(peer.Status.Conditions, v1alpha1.ConditionConnected) &&
+		!meta.IsStatusConditionTrue(peerCopy.Status.Conditions, v1alpha1.ConditionConnected) {
+		c.recorder.Eventf(peerCopy, corev1.EventTypeWarning, warningDisconnected, messageDisconnected, peerCopy.Status.LatestHandshake.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// observePeer takes over the figures of the observation unless another head node reported a later handshake, then
+// evaluates the Connected condition from the latest handshake, which gets old when the tunnel is lost
+func observePeer(status *v1alpha1.VPNPeerStatus, observation *wgtypes.Peer, now time.Time) {
+	if observation != nil && !observation.LastHandshakeTime.IsZero() {
+		// The API stores times with a precision of a second
+		handshake := metav1.NewTime(observation.LastHandshakeTime).Rfc3339Copy()
+		if status.LatestHandshake == nil || !handshake.Before(status.LatestHandshake) {
+			status.LatestHandshake = &handshake
+			status.ReceiveBytes = observation.ReceiveBytes
+			status.TransmitBytes = observation.TransmitBytes
+			status.Endpoint = ""
+			if observation.Endpoint != nil {
+				status.Endpoint = observation.Endpoint.String()
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    v1alpha1.ConditionConnected,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonNoHandshake,
+		Message: messageNoHandshake,
+	}
+	if status.LatestHandshake != nil {
+		latestHandshake := status.LatestHandshake.Format(time.RFC3339)
+		if now.Sub(status.LatestHandshake.Time) <= handshakeTimeout {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = reasonHandshakeRecent
+			condition.Message = fmt.Sprintf(messageHandshakeRecent, latestHandshake)
+		} else {
+			condition.Reason = reasonHandshakeStale
+			condition.Message = fmt.Sprintf(messageHandshakeStale, latestHandshake)
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// getObservations returns the peers of the WireGuard interface by public key
+func getObservations(linkname string) (map[wgtypes.Key]wgtypes.Peer, error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return nil, fmt.Errorf("error while creating WG client: %s", err.Error())
+	}
+	defer client.Close()
+
+	device, err := client.Device(linkname)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading WG device %s: %s", linkname, err.Error())
+	}
+
+	observations := make(map[wgtypes.Key]wgtypes.Peer)
+	for _, peer := range device.Peers {
+		observations[peer.PublicKey] = peer
+	}
+	return observations, nil
+}
@@ -0,0 +1,68 @@
+package vpnpeer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObservePeer(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	observation := &wgtypes.Peer{
+		Endpoint:          &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 51820},
+		LastHandshakeTime: now.Add(-time.Minute),
+		ReceiveBytes:      2048,
+		TransmitBytes:     1024,
+	}
+
+	status := v1alpha1.VPNPeerStatus{}
+	observePeer(&status, nil, now)
+	util.Equals(t, false, meta.IsStatusConditionTrue(status.Conditions, v1alpha1.ConditionConnected))
+	util.Equals(t, reasonNoHandshake, meta.FindStatusCondition(status.Conditions, v1alpha1.ConditionConnected).Reason)
+
+	observePeer(&status, observation, now)
+	util.Equals(t, true, now.Add(-time.Minute).Equal(status.LatestHandshake.Time))
+	util.Equals(t, int64(2048), status.ReceiveBytes)
+	util.Equals(t, int64(1024), status.TransmitBytes)
+	util.Equals(t, "192.0.2.10:51820", status.Endpoint)
+	util.Equals(t, true, meta.IsStatusConditionTrue(status.Conditions, v1alpha1.ConditionConnected))
+
+	t.Run("unchanged", func(t *testing.T) {
+		again := *status.DeepCopy()
+		observePeer(&again, observation, now)
+		util.Equals(t, status, again)
+	})
+	t.Run("older handshake of another head node", func(t *testing.T) {
+		status := *status.DeepCopy()
+		observePeer(&status, &wgtypes.Peer{LastHandshakeTime: now.Add(-2 * time.Minute), ReceiveBytes: 1, TransmitBytes: 2}, now)
+		util.Equals(t, true, now.Add(-time.Minute).Equal(status.LatestHandshake.Time))
+		util.Equals(t, int64(2048), status.ReceiveBytes)
+		util.Equals(t, "192.0.2.10:51820", status.Endpoint)
+	})
+	t.Run("later handshake of another head node", func(t *testing.T) {
+		status := *status.DeepCopy()
+		observePeer(&status, &wgtypes.Peer{LastHandshakeTime: now, ReceiveBytes: 1, TransmitBytes: 2}, now)
+		util.Equals(t, true, now.Equal(status.LatestHandshake.Time))
+		util.Equals(t, int64(1), status.ReceiveBytes)
+		util.Equals(t, int64(2), status.TransmitBytes)
+		util.Equals(t, "", status.Endpoint)
+	})
+	t.Run("stale handshake", func(t *testing.T) {
+		status := *status.DeepCopy()
+		// The tunnel is lost and the interface has not shaken hands since, which keeps the latest known handshake
+		observePeer(&status, nil, now.Add(handshakeTimeout))
+		util.Equals(t, true, now.Add(-time.Minute).Equal(status.LatestHandshake.Time))
+		util.Equals(t, "192.0.2.10:51820", status.Endpoint)
+		condition := meta.FindStatusCondition(status.Conditions, v1alpha1.ConditionConnected)
+		util.Equals(t, metav1.ConditionFalse, condition.Status)
+		util.Equals(t, reasonHandshakeStale, condition.Reason)
+	})
+}
@@ -31,6 +31,7 @@ type CoreV1alpha1Interface interface {
 	NodeContributionsGetter
 	SlicesGetter
 	SliceClaimsGetter
+	SliceClassesGetter
 	SubNamespacesGetter
 	TenantsGetter
 	TenantResourceQuotasGetter
@@ -53,6 +54,10 @@ func (c *CoreV1alpha1Client) SliceClaims(namespace string) SliceClaimInterface {
 	return newSliceClaims(c, namespace)
 }
 
+func (c *CoreV1alpha1Client) SliceClasses() SliceClassInterface {
+	return newSliceClasses(c)
+}
+
 func (c *CoreV1alpha1Client) SubNamespaces(namespace string) SubNamespaceInterface {
 	return newSubNamespaces(c, namespace)
 }
@@ -40,6 +40,10 @@ func (c *FakeCoreV1alpha1) SliceClaims(namespace string) v1alpha1.SliceClaimInte
 	return &FakeSliceClaims{c, namespace}
 }
 
+func (c *FakeCoreV1alpha1) SliceClasses() v1alpha1.SliceClassInterface {
+	return &FakeSliceClasses{c}
+}
+
 func (c *FakeCoreV1alpha1) SubNamespaces(namespace string) v1alpha1.SubNamespaceInterface {
 	return &FakeSubNamespaces{c, namespace}
 }
@@ -24,6 +24,8 @@ type SliceExpansion interface{}
 
 type SliceClaimExpansion interface{}
 
+type SliceClassExpansion interface{}
+
 type SubNamespaceExpansion interface{}
 
 type TenantExpansion interface{}
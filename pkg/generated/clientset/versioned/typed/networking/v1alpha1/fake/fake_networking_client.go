@@ -28,6 +28,14 @@ type FakeNetworkingV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeNetworkingV1alpha1) NodeLatencies() v1alpha1.NodeLatencyInterface {
+	return &FakeNodeLatencies{c}
+}
+
+func (c *FakeNetworkingV1alpha1) VPNAddressPools() v1alpha1.VPNAddressPoolInterface {
+	return &FakeVPNAddressPools{c}
+}
+
 func (c *FakeNetworkingV1alpha1) VPNPeers() v1alpha1.VPNPeerInterface {
 	return &FakeVPNPeers{c}
 }
@@ -95,6 +95,17 @@ func (c *FakeVPNPeers) Update(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, op
 	return obj.(*v1alpha1.VPNPeer), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVPNPeers) UpdateStatus(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v1.UpdateOptions) (*v1alpha1.VPNPeer, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(vpnpeersResource, "status", vPNPeer), &v1alpha1.VPNPeer{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VPNPeer), err
+}
+
 // Delete takes name of the vPNPeer and deletes it. Returns an error if one occurs.
 func (c *FakeVPNPeers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -18,4 +18,8 @@ limitations under the License.
 
 package v1alpha1
 
+type NodeLatencyExpansion interface{}
+
+type VPNAddressPoolExpansion interface{}
+
 type VPNPeerExpansion interface{}
@@ -28,6 +28,8 @@ import (
 
 type NetworkingV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	NodeLatenciesGetter
+	VPNAddressPoolsGetter
 	VPNPeersGetter
 }
 
@@ -36,6 +38,14 @@ type NetworkingV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *NetworkingV1alpha1Client) NodeLatencies() NodeLatencyInterface {
+	return newNodeLatencies(c)
+}
+
+func (c *NetworkingV1alpha1Client) VPNAddressPools() VPNAddressPoolInterface {
+	return newVPNAddressPools(c)
+}
+
 func (c *NetworkingV1alpha1Client) VPNPeers() VPNPeerInterface {
 	return newVPNPeers(c)
 }
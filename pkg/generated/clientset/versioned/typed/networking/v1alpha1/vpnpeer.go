@@ -40,6 +40,7 @@ type VPNPeersGetter interface {
 type VPNPeerInterface interface {
 	Create(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v1.CreateOptions) (*v1alpha1.VPNPeer, error)
 	Update(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v1.UpdateOptions) (*v1alpha1.VPNPeer, error)
+	UpdateStatus(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v1.UpdateOptions) (*v1alpha1.VPNPeer, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.VPNPeer, error)
@@ -128,6 +129,21 @@ func (c *vPNPeers) Update(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *vPNPeers) UpdateStatus(ctx context.Context, vPNPeer *v1alpha1.VPNPeer, opts v1.UpdateOptions) (result *v1alpha1.VPNPeer, err error) {
+	result = &v1alpha1.VPNPeer{}
+	err = c.client.Put().
+		Resource("vpnpeers").
+		Name(vPNPeer.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(vPNPeer).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the vPNPeer and deletes it. Returns an error if one occurs.
 func (c *vPNPeers) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -30,6 +30,8 @@ type Interface interface {
 	Slices() SliceInformer
 	// SliceClaims returns a SliceClaimInformer.
 	SliceClaims() SliceClaimInformer
+	// SliceClasses returns a SliceClassInformer.
+	SliceClasses() SliceClassInformer
 	// SubNamespaces returns a SubNamespaceInformer.
 	SubNamespaces() SubNamespaceInformer
 	// Tenants returns a TenantInformer.
@@ -64,6 +66,11 @@ func (v *version) SliceClaims() SliceClaimInformer {
 	return &sliceClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// SliceClasses returns a SliceClassInformer.
+func (v *version) SliceClasses() SliceClassInformer {
+	return &sliceClassInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // SubNamespaces returns a SubNamespaceInformer.
 func (v *version) SubNamespaces() SubNamespaceInformer {
 	return &subNamespaceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -77,6 +77,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha1().Slices().Informer()}, nil
 	case corev1alpha1.SchemeGroupVersion.WithResource("sliceclaims"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha1().SliceClaims().Informer()}, nil
+	case corev1alpha1.SchemeGroupVersion.WithResource("sliceclasses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha1().SliceClasses().Informer()}, nil
 	case corev1alpha1.SchemeGroupVersion.WithResource("subnamespaces"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Core().V1alpha1().SubNamespaces().Informer()}, nil
 	case corev1alpha1.SchemeGroupVersion.WithResource("tenants"):
@@ -93,6 +95,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Federation().V1alpha1().SelectiveDeploymentAnchors().Informer()}, nil
 
 		// Group=networking.edgenet.io, Version=v1alpha1
+	case networkingv1alpha1.SchemeGroupVersion.WithResource("nodelatencies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().NodeLatencies().Informer()}, nil
+	case networkingv1alpha1.SchemeGroupVersion.WithResource("vpnaddresspools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().VPNAddressPools().Informer()}, nil
 	case networkingv1alpha1.SchemeGroupVersion.WithResource("vpnpeers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1alpha1().VPNPeers().Informer()}, nil
 
@@ -24,6 +24,10 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// NodeLatencies returns a NodeLatencyInformer.
+	NodeLatencies() NodeLatencyInformer
+	// VPNAddressPools returns a VPNAddressPoolInformer.
+	VPNAddressPools() VPNAddressPoolInformer
 	// VPNPeers returns a VPNPeerInformer.
 	VPNPeers() VPNPeerInformer
 }
@@ -39,6 +43,16 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// NodeLatencies returns a NodeLatencyInformer.
+func (v *version) NodeLatencies() NodeLatencyInformer {
+	return &nodeLatencyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// VPNAddressPools returns a VPNAddressPoolInformer.
+func (v *version) VPNAddressPools() VPNAddressPoolInformer {
+	return &vPNAddressPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // VPNPeers returns a VPNPeerInformer.
 func (v *version) VPNPeers() VPNPeerInformer {
 	return &vPNPeerInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
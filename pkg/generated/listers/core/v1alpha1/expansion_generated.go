@@ -34,6 +34,10 @@ type SliceClaimListerExpansion interface{}
 // SliceClaimNamespaceLister.
 type SliceClaimNamespaceListerExpansion interface{}
 
+// SliceClassListerExpansion allows custom methods to be added to
+// SliceClassLister.
+type SliceClassListerExpansion interface{}
+
 // SubNamespaceListerExpansion allows custom methods to be added to
 // SubNamespaceLister.
 type SubNamespaceListerExpansion interface{}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// NodeLatencyListerExpansion allows custom methods to be added to
+// NodeLatencyLister.
+type NodeLatencyListerExpansion interface{}
+
+// VPNAddressPoolListerExpansion allows custom methods to be added to
+// VPNAddressPoolLister.
+type VPNAddressPoolListerExpansion interface{}
+
 // VPNPeerListerExpansion allows custom methods to be added to
 // VPNPeerLister.
 type VPNPeerListerExpansion interface{}
@@ -0,0 +1,326 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	namecheap "github.com/billputer/go-namecheap"
+	"k8s.io/klog"
+)
+
+// Names of the DNS providers that can be selected to register the hostnames of contributed nodes
+const (
+	DNSProviderRoute53   = "route53"
+	DNSProviderNamecheap = "namecheap"
+	DNSProviderRFC2136   = "rfc2136"
+	DNSProviderMemory    = "memory"
+)
+
+// DNSProvider manages the DNS records that point the hostnames of contributed nodes to their addresses
+type DNSProvider interface {
+	// SetRecord creates the record or overwrites the address of an existing one
+	SetRecord(hostname, address, recordType string) error
+	// DeleteRecord removes the record, a missing record is not an error
+	DeleteRecord(hostname, address, recordType string) error
+}
+
+// DNSConfig holds the settings the DNS providers are built from
+type DNSConfig struct {
+	Provider string
+	// Domain is the zone apex under which node hostnames are registered
+	Domain string
+	// TTL of the records in seconds, 60 if not set
+	TTL uint32
+
+	Route53HostedZone    string
+	Route53Region        string
+	Route53IDPath        string
+	Route53SecretPath    string
+	RFC2136Server        string
+	RFC2136Zone          string
+	RFC2136KeyName       string
+	RFC2136KeyAlgorithm  string
+	RFC2136KeySecretPath string
+}
+
+// NewDNSProvider returns the DNS provider selected in the configuration
+func NewDNSProvider(config DNSConfig) (DNSProvider, error) {
+	ttl := config.TTL
+	if ttl == 0 {
+		ttl = 60
+	}
+	switch config.Provider {
+	case DNSProviderRoute53, "":
+		if config.Route53HostedZone == "" {
+			return nil, fmt.Errorf("route53 provider requires a hosted zone")
+		}
+		region := config.Route53Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &Route53DNSProvider{
+			hostedZone: config.Route53HostedZone,
+			region:     region,
+			idPath:     config.Route53IDPath,
+			secretPath: config.Route53SecretPath,
+			ttl:        int64(ttl),
+		}, nil
+	case DNSProviderNamecheap:
+		client, err := bootstrap.CreateNamecheapClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewNamecheapDNSProvider(client, config.Domain)
+	case DNSProviderRFC2136:
+		zone := config.RFC2136Zone
+		if zone == "" {
+			zone = config.Domain
+		}
+		if config.RFC2136Server == "" || zone == "" {
+			return nil, fmt.Errorf("rfc2136 provider requires a server and a zone")
+		}
+		return &RFC2136DNSProvider{
+			server:        config.RFC2136Server,
+			zone:          zone,
+			keyName:       config.RFC2136KeyName,
+			keyAlgorithm:  config.RFC2136KeyAlgorithm,
+			keySecretPath: config.RFC2136KeySecretPath,
+			ttl:           ttl,
+		}, nil
+	case DNSProviderMemory:
+		return NewMemoryDNSProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown DNS provider %s", config.Provider)
+}
+
+// Route53DNSProvider keeps the records in an AWS Route53 hosted zone.
+// Credentials are read at each call so that a rotated secret is picked up without a restart.
+type Route53DNSProvider struct {
+	hostedZone string
+	region     string
+	idPath     string
+	secretPath string
+	ttl        int64
+}
+
+// SetRecord upserts the record in the hosted zone
+func (r *Route53DNSProvider) SetRecord(hostname, address, recordType string) error {
+	return r.changeRecord("UPSERT", hostname, address, recordType)
+}
+
+// DeleteRecord deletes the record from the hosted zone
+func (r *Route53DNSProvider) DeleteRecord(hostname, address, recordType string) error {
+	err := r.changeRecord("DELETE", hostname, address, recordType)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == route53.ErrCodeInvalidChangeBatch && strings.Contains(aerr.Error(), "not found") {
+		return nil
+	}
+	return err
+}
+
+func (r *Route53DNSProvider) changeRecord(action, hostname, address, recordType string) error {
+	id, err := os.ReadFile(r.idPath)
+	if err != nil {
+		return err
+	}
+	key, err := os.ReadFile(r.secretPath)
+	if err != nil {
+		return err
+	}
+	config := aws.Config{Region: aws.String(r.region)}
+	config.Credentials = credentials.NewStaticCredentials(strings.TrimSpace(string(id)), strings.TrimSpace(string(key)), "")
+	input := &route53.ChangeResourceRecordSetsInput{
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{
+				{
+					Action: aws.String(action),
+					ResourceRecordSet: &route53.ResourceRecordSet{
+						Name: aws.String(hostname),
+						ResourceRecords: []*route53.ResourceRecord{
+							{
+								Value: aws.String(address),
+							},
+						},
+						TTL:  aws.Int64(r.ttl),
+						Type: aws.String(recordType),
+					},
+				},
+			},
+			Comment: aws.String("Node contribution for EdgeNet"),
+		},
+		HostedZoneId: aws.String(r.hostedZone),
+	}
+	newSession, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+	if _, err := route53.New(newSession, &config).ChangeResourceRecordSets(input); err != nil {
+		klog.Infof("Route53 %s of %s failed: %s", action, hostname, err)
+		return err
+	}
+	return nil
+}
+
+// NamecheapDNSProvider keeps the records of a domain registered at Namecheap.
+// The API only allows setting the whole host list of the domain at once, hence each change is a read-modify-write.
+type NamecheapDNSProvider struct {
+	client *namecheap.Client
+	domain string
+	sld    string
+	tld    string
+	// mux serializes the read-modify-write cycles on the host list
+	mux sync.Mutex
+}
+
+// NewNamecheapDNSProvider returns a Namecheap provider for the domain, such as edge-net.io
+func NewNamecheapDNSProvider(client *namecheap.Client, domain string) (*NamecheapDNSProvider, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	labels := strings.SplitN(domain, ".", 2)
+	if len(labels) != 2 {
+		return nil, fmt.Errorf("namecheap provider requires a domain with a TLD, got %q", domain)
+	}
+	return &NamecheapDNSProvider{client: client, domain: domain, sld: labels[0], tld: labels[1]}, nil
+}
+
+// SetRecord overwrites the host having the same name or address, or appends a new one
+func (n *NamecheapDNSProvider) SetRecord(hostname, address, recordType string) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	hostRecord := namecheap.DomainDNSHost{Name: n.hostName(hostname), Type: recordType, Address: address}
+	hosts, err := n.getHosts()
+	if err != nil {
+		return err
+	}
+	exist := false
+	for key, host := range hosts {
+		if host.Name == hostRecord.Name || host.Address == hostRecord.Address {
+			hosts[key] = hostRecord
+			exist = true
+			break
+		}
+	}
+	if !exist {
+		hosts = append(hosts, hostRecord)
+	}
+	return n.setHosts(hosts)
+}
+
+// DeleteRecord drops the host from the list of the domain
+func (n *NamecheapDNSProvider) DeleteRecord(hostname, address, recordType string) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	hosts, err := n.getHosts()
+	if err != nil {
+		return err
+	}
+	name := n.hostName(hostname)
+	remaining := make([]namecheap.DomainDNSHost, 0, len(hosts))
+	for _, host := range hosts {
+		if host.Name == name && host.Type == recordType {
+			continue
+		}
+		remaining = append(remaining, host)
+	}
+	if len(remaining) == len(hosts) {
+		return nil
+	}
+	return n.setHosts(remaining)
+}
+
+// hostName strips the domain from the hostname since Namecheap records are relative to the domain
+func (n *NamecheapDNSProvider) hostName(hostname string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(hostname, "."), "."+n.domain)
+}
+
+func (n *NamecheapDNSProvider) getHosts() ([]namecheap.DomainDNSHost, error) {
+	hostsResponse, err := n.client.DomainsDNSGetHosts(n.sld, n.tld)
+	if err != nil {
+		return nil, err
+	}
+	responseJSON, err := json.Marshal(hostsResponse)
+	if err != nil {
+		return nil, err
+	}
+	hostList := namecheap.DomainDNSGetHostsResult{}
+	if err := json.Unmarshal(responseJSON, &hostList); err != nil {
+		return nil, err
+	}
+	return hostList.Hosts, nil
+}
+
+func (n *NamecheapDNSProvider) setHosts(hosts []namecheap.DomainDNSHost) error {
+	setResponse, err := n.client.DomainDNSSetHosts(n.sld, n.tld, hosts)
+	if err != nil {
+		return err
+	}
+	if !setResponse.IsSuccess {
+		return fmt.Errorf("namecheap refused the host list of %s", n.domain)
+	}
+	return nil
+}
+
+// MemoryDNSProvider keeps the records in memory, which suits test environments and clusters without public DNS
+type MemoryDNSProvider struct {
+	records map[string]string
+	mux     sync.RWMutex
+}
+
+// NewMemoryDNSProvider returns an empty in-memory provider
+func NewMemoryDNSProvider() *MemoryDNSProvider {
+	return &MemoryDNSProvider{records: make(map[string]string)}
+}
+
+// SetRecord stores the address of the record
+func (m *MemoryDNSProvider) SetRecord(hostname, address, recordType string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.records[memoryRecordKey(hostname, recordType)] = address
+	klog.V(4).Infof("DNS record set in memory: %s %s %s", hostname, recordType, address)
+	return nil
+}
+
+// DeleteRecord removes the record
+func (m *MemoryDNSProvider) DeleteRecord(hostname, address, recordType string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.records, memoryRecordKey(hostname, recordType))
+	klog.V(4).Infof("DNS record deleted from memory: %s %s", hostname, recordType)
+	return nil
+}
+
+// Lookup returns the address of the record if it exists
+func (m *MemoryDNSProvider) Lookup(hostname, recordType string) (string, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	address, exists := m.records[memoryRecordKey(hostname, recordType)]
+	return address, exists
+}
+
+func memoryRecordKey(hostname, recordType string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(strings.ToLower(hostname), "."), recordType)
+}
@@ -0,0 +1,245 @@
+package multiprovider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestNewDNSProvider(t *testing.T) {
+	cases := map[string]struct {
+		config   DNSConfig
+		expected interface{}
+		fails    bool
+	}{
+		"default":         {DNSConfig{Route53HostedZone: "Z0000000000000"}, &Route53DNSProvider{}, false},
+		"route53":         {DNSConfig{Provider: DNSProviderRoute53, Route53HostedZone: "Z0000000000000"}, &Route53DNSProvider{}, false},
+		"route53 no zone": {DNSConfig{Provider: DNSProviderRoute53}, nil, true},
+		"memory":          {DNSConfig{Provider: DNSProviderMemory}, &MemoryDNSProvider{}, false},
+		"rfc2136":         {DNSConfig{Provider: DNSProviderRFC2136, Domain: "edge-net.io", RFC2136Server: "127.0.0.1"}, &RFC2136DNSProvider{}, false},
+		"rfc2136 no zone": {DNSConfig{Provider: DNSProviderRFC2136, RFC2136Server: "127.0.0.1"}, nil, true},
+		"unknown":         {DNSConfig{Provider: "bind"}, nil, true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			provider, err := NewDNSProvider(tc.config)
+			if tc.fails {
+				util.NotEquals(t, nil, err)
+				return
+			}
+			util.OK(t, err)
+			util.Equals(t, true, provider != nil)
+			switch tc.expected.(type) {
+			case *Route53DNSProvider:
+				_, ok := provider.(*Route53DNSProvider)
+				util.Equals(t, true, ok)
+			case *MemoryDNSProvider:
+				_, ok := provider.(*MemoryDNSProvider)
+				util.Equals(t, true, ok)
+			case *RFC2136DNSProvider:
+				rfc2136, ok := provider.(*RFC2136DNSProvider)
+				util.Equals(t, true, ok)
+				util.Equals(t, "edge-net.io", rfc2136.zone)
+				util.Equals(t, uint32(60), rfc2136.ttl)
+			}
+		})
+	}
+}
+
+func TestMemoryDNSProvider(t *testing.T) {
+	provider := NewMemoryDNSProvider()
+	util.OK(t, provider.SetRecord("fr-idf-0000.edge-net.io", "10.0.0.1", "A"))
+	address, exists := provider.Lookup("fr-idf-0000.edge-net.io.", "A")
+	util.Equals(t, true, exists)
+	util.Equals(t, "10.0.0.1", address)
+	_, exists = provider.Lookup("fr-idf-0000.edge-net.io", "AAAA")
+	util.Equals(t, false, exists)
+
+	util.OK(t, provider.SetRecord("fr-idf-0000.edge-net.io", "10.0.0.2", "A"))
+	address, _ = provider.Lookup("fr-idf-0000.edge-net.io", "A")
+	util.Equals(t, "10.0.0.2", address)
+
+	util.OK(t, provider.DeleteRecord("fr-idf-0000.edge-net.io", "10.0.0.2", "A"))
+	_, exists = provider.Lookup("fr-idf-0000.edge-net.io", "A")
+	util.Equals(t, false, exists)
+	util.OK(t, provider.DeleteRecord("fr-idf-0000.edge-net.io", "10.0.0.2", "A"))
+}
+
+func TestNamecheapHostName(t *testing.T) {
+	provider, err := NewNamecheapDNSProvider(nil, "edge-net.io")
+	util.OK(t, err)
+	util.Equals(t, "edge-net", provider.sld)
+	util.Equals(t, "io", provider.tld)
+	util.Equals(t, "fr-idf-0000", provider.hostName("fr-idf-0000.edge-net.io"))
+	util.Equals(t, "fr-idf-0000", provider.hostName("fr-idf-0000.edge-net.io."))
+	_, err = NewNamecheapDNSProvider(nil, "localhost")
+	util.NotEquals(t, nil, err)
+}
+
+func TestBuildUpdateMessage(t *testing.T) {
+	cases := map[string]struct {
+		hostname   string
+		address    string
+		recordType string
+		add        bool
+		classes    []dnsmessage.Class
+		fails      bool
+	}{
+		"set A":            {"fr-idf-0000.edge-net.io", "10.0.0.1", "A", true, []dnsmessage.Class{dnsmessage.ClassANY, dnsmessage.ClassINET}, false},
+		"set AAAA":         {"fr-idf-0000.edge-net.io", "2001:db8::1", "AAAA", true, []dnsmessage.Class{dnsmessage.ClassANY, dnsmessage.ClassINET}, false},
+		"delete A":         {"fr-idf-0000.edge-net.io", "10.0.0.1", "A", false, []dnsmessage.Class{classNone}, false},
+		"type mismatch":    {"fr-idf-0000.edge-net.io", "2001:db8::1", "A", true, nil, true},
+		"invalid address":  {"fr-idf-0000.edge-net.io", "edgenet", "A", true, nil, true},
+		"outside the zone": {"fr-idf-0000.example.org", "10.0.0.1", "A", true, nil, true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			msg, err := buildUpdateMessage(42, "edge-net.io", tc.hostname, tc.address, tc.recordType, 60, tc.add)
+			if tc.fails {
+				util.NotEquals(t, nil, err)
+				return
+			}
+			util.OK(t, err)
+			var parser dnsmessage.Parser
+			header, err := parser.Start(msg)
+			util.OK(t, err)
+			util.Equals(t, uint16(42), header.ID)
+			util.Equals(t, opCodeUpdate, header.OpCode)
+			questions, err := parser.AllQuestions()
+			util.OK(t, err)
+			util.Equals(t, 1, len(questions))
+			util.Equals(t, "edge-net.io.", questions[0].Name.String())
+			util.Equals(t, dnsmessage.TypeSOA, questions[0].Type)
+			util.OK(t, parser.SkipAllAnswers())
+			updates, err := parser.AllAuthorities()
+			util.OK(t, err)
+			util.Equals(t, len(tc.classes), len(updates))
+			for i, update := range updates {
+				util.Equals(t, tc.hostname+".", update.Header.Name.String())
+				util.Equals(t, tc.classes[i], update.Header.Class)
+			}
+			last := updates[len(updates)-1]
+			if tc.recordType == "A" {
+				util.Equals(t, dnsmessage.TypeA, last.Header.Type)
+				util.Equals(t, []byte(net.ParseIP(tc.address).To4()), last.Body.(*dnsmessage.AResource).A[:])
+			} else {
+				util.Equals(t, dnsmessage.TypeAAAA, last.Header.Type)
+				util.Equals(t, []byte(net.ParseIP(tc.address)), last.Body.(*dnsmessage.AAAAResource).AAAA[:])
+			}
+		})
+	}
+}
+
+func TestSignTSIG(t *testing.T) {
+	msg, err := buildUpdateMessage(42, "edge-net.io", "fr-idf-0000.edge-net.io", "10.0.0.1", "A", 60, true)
+	util.OK(t, err)
+	secret := []byte("edgenet-secret")
+	signedAt := time.Unix(1700000000, 0)
+	signed, err := signTSIG(msg, "edgenet-key.", "hmac-sha256", secret, signedAt)
+	util.OK(t, err)
+	util.Equals(t, msg[12:], signed[12:len(msg)])
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(signed)
+	util.OK(t, err)
+	util.Equals(t, uint16(42), header.ID)
+	util.OK(t, parser.SkipAllQuestions())
+	util.OK(t, parser.SkipAllAnswers())
+	util.OK(t, parser.SkipAllAuthorities())
+	additionals, err := parser.AllAdditionals()
+	util.OK(t, err)
+	util.Equals(t, 1, len(additionals))
+	tsig := additionals[0]
+	util.Equals(t, "edgenet-key.", tsig.Header.Name.String())
+	util.Equals(t, typeTSIG, tsig.Header.Type)
+	util.Equals(t, dnsmessage.ClassANY, tsig.Header.Class)
+
+	// Algorithm name, time signed, fudge, MAC size, MAC, original ID, error, other length
+	algorithm := []byte("\x0bhmac-sha256\x00")
+	rdata := tsig.Body.(*dnsmessage.UnknownResource).Data
+	util.Equals(t, algorithm, rdata[:len(algorithm)])
+	rdata = rdata[len(algorithm):]
+	util.Equals(t, uint64(signedAt.Unix()), uint64(binary.BigEndian.Uint16(rdata[0:2]))<<32|uint64(binary.BigEndian.Uint32(rdata[2:6])))
+	util.Equals(t, uint16(tsigFudge), binary.BigEndian.Uint16(rdata[6:8]))
+	util.Equals(t, uint16(sha256.Size), binary.BigEndian.Uint16(rdata[8:10]))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg)
+	mac.Write([]byte("\x0bedgenet-key\x00\x00\xff\x00\x00\x00\x00"))
+	mac.Write(algorithm)
+	mac.Write(rdata[0:8])
+	mac.Write([]byte{0, 0, 0, 0})
+	util.Equals(t, mac.Sum(nil), rdata[10:10+sha256.Size])
+	util.Equals(t, []byte{0, 42, 0, 0, 0, 0}, rdata[10+sha256.Size:])
+
+	_, err = signTSIG(msg, "edgenet-key", "hmac-md5", secret, signedAt)
+	util.NotEquals(t, nil, err)
+}
+
+func TestRFC2136DNSProvider(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	util.OK(t, err)
+	defer listener.Close()
+	requests := make(chan []byte, 1)
+	rcodes := make(chan dnsmessage.RCode, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			length := make([]byte, 2)
+			io.ReadFull(conn, length)
+			request := make([]byte, binary.BigEndian.Uint16(length))
+			io.ReadFull(conn, request)
+			requests <- request
+			builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: binary.BigEndian.Uint16(request[0:2]), Response: true, OpCode: opCodeUpdate, RCode: <-rcodes})
+			response, _ := builder.Finish()
+			conn.Write(append([]byte{byte(len(response) >> 8), byte(len(response))}, response...))
+			conn.Close()
+		}
+	}()
+
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	util.OK(t, os.WriteFile(secretPath, []byte("ZWRnZW5ldC1zZWNyZXQ=\n"), 0600))
+	provider, err := NewDNSProvider(DNSConfig{
+		Provider:             DNSProviderRFC2136,
+		Domain:               "edge-net.io",
+		RFC2136Server:        listener.Addr().String(),
+		RFC2136KeyName:       "edgenet-key",
+		RFC2136KeySecretPath: secretPath,
+	})
+	util.OK(t, err)
+
+	t.Run("set record", func(t *testing.T) {
+		rcodes <- dnsmessage.RCodeSuccess
+		util.OK(t, provider.SetRecord("fr-idf-0000.edge-net.io", "10.0.0.1", "A"))
+		request := <-requests
+		util.Equals(t, uint16(2), binary.BigEndian.Uint16(request[8:10]))
+		util.Equals(t, uint16(1), binary.BigEndian.Uint16(request[10:12]))
+	})
+	t.Run("delete record", func(t *testing.T) {
+		rcodes <- dnsmessage.RCodeSuccess
+		util.OK(t, provider.DeleteRecord("fr-idf-0000.edge-net.io", "10.0.0.1", "A"))
+		request := <-requests
+		util.Equals(t, uint16(1), binary.BigEndian.Uint16(request[8:10]))
+	})
+	t.Run("refused", func(t *testing.T) {
+		rcodes <- dnsmessage.RCodeRefused
+		util.NotEquals(t, nil, provider.SetRecord("fr-idf-0000.edge-net.io", "10.0.0.1", "A"))
+		<-requests
+	})
+	t.Run("invalid secret", func(t *testing.T) {
+		util.OK(t, os.WriteFile(secretPath, []byte("not base64"), 0600))
+		util.NotEquals(t, nil, provider.SetRecord("fr-idf-0000.edge-net.io", "10.0.0.1", "A"))
+	})
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DrainNode evicts the pods running on the node through the eviction API, so that pod disruption budgets are
+// respected. Pods of daemon sets and static pods are left in place as they go away with the node.
+// It returns the number of pods still on the node and whether a disruption budget blocked an eviction.
+func (m *Manager) DrainNode(hostname string) (int, bool, error) {
+	pods, err := m.getEvictablePods(hostname)
+	if err != nil {
+		return 0, false, err
+	}
+	blocked := false
+	for _, pod := range pods {
+		if pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.GetName(), Namespace: pod.GetNamespace()}}
+		if err := m.kubeclientset.CoreV1().Pods(pod.GetNamespace()).EvictV1(context.TODO(), eviction); err != nil {
+			if errors.IsTooManyRequests(err) {
+				blocked = true
+				continue
+			}
+			if !errors.IsNotFound(err) {
+				return 0, false, err
+			}
+		}
+	}
+	pods, err = m.getEvictablePods(hostname)
+	return len(pods), blocked, err
+}
+
+func (m *Manager) getEvictablePods(hostname string) ([]corev1.Pod, error) {
+	podList, err := m.kubeclientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("spec.nodeName=%s", hostname)})
+	if err != nil {
+		return nil, err
+	}
+	pods := []corev1.Pod{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != hostname || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, isMirror := pod.GetAnnotations()[corev1.MirrorPodAnnotationKey]; isMirror {
+			continue
+		}
+		if ownerRef := metav1.GetControllerOf(&pod); ownerRef != nil && ownerRef.Kind == "DaemonSet" {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
@@ -0,0 +1,77 @@
+package multiprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDrainNode(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	kubeclientset := g.multiproviderManager.kubeclientset.(*testclient.Clientset)
+	budgetExhausted := true
+	kubeclientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(metav1.Object)
+		if budgetExhausted && eviction.GetName() == "guarded" {
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		}
+		return true, nil, kubeclientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.GetNamespace(), eviction.GetName())
+	})
+
+	controller := true
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "drain.edge-net.io"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "guarded", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "drain.edge-net.io"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "other.edge-net.io"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "completed", Namespace: "default"}, Spec: corev1.PodSpec{NodeName: "drain.edge-net.io"}, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "static", Namespace: "kube-system", Annotations: map[string]string{corev1.MirrorPodAnnotationKey: "hash"}}, Spec: corev1.PodSpec{NodeName: "drain.edge-net.io"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system", OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", Controller: &controller}}}, Spec: corev1.PodSpec{NodeName: "drain.edge-net.io"}},
+	}
+	for _, pod := range pods {
+		kubeclientset.CoreV1().Pods(pod.GetNamespace()).Create(context.TODO(), pod, metav1.CreateOptions{})
+	}
+
+	remaining, blocked, err := g.multiproviderManager.DrainNode("drain.edge-net.io")
+	util.OK(t, err)
+	util.Equals(t, 1, remaining)
+	util.Equals(t, true, blocked)
+	_, err = kubeclientset.CoreV1().Pods("default").Get(context.TODO(), "workload", metav1.GetOptions{})
+	util.Equals(t, true, errors.IsNotFound(err))
+	_, err = kubeclientset.CoreV1().Pods("default").Get(context.TODO(), "elsewhere", metav1.GetOptions{})
+	util.OK(t, err)
+
+	budgetExhausted = false
+	remaining, blocked, err = g.multiproviderManager.DrainNode("drain.edge-net.io")
+	util.OK(t, err)
+	util.Equals(t, 0, remaining)
+	util.Equals(t, false, blocked)
+	_, err = kubeclientset.CoreV1().Pods("kube-system").Get(context.TODO(), "agent", metav1.GetOptions{})
+	util.OK(t, err)
+}
+
+func TestRevokeBootstrapTokens(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	_, err := g.multiproviderManager.createBootstrapToken(time.Hour, "revoke.edge-net.io", []string{"system:bootstrappers:kubeadm:default-node-token"})
+	util.OK(t, err)
+	_, err = g.multiproviderManager.createBootstrapToken(time.Hour, "keep.edge-net.io", []string{"system:bootstrappers:kubeadm:default-node-token"})
+	util.OK(t, err)
+	util.OK(t, g.multiproviderManager.RevokeBootstrapTokens("revoke.edge-net.io"))
+	secretList, err := g.multiproviderManager.kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).List(context.TODO(), metav1.ListOptions{})
+	util.OK(t, err)
+	util.Equals(t, 1, len(secretList.Items))
+	util.Equals(t, "EdgeNet token for adding node called keep.edge-net.io", string(secretList.Items[0].Data["description"]))
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GeohashMaxPrecision is the length of the longest geohash label of a node, a cell of about 1.2 km by 0.6 km.
+	// The node labeler attaches one label per length from 1, a cell of about 5000 km, on.
+	GeohashMaxPrecision = 6
+	// earthRadius is the mean radius of the Earth in kilometers
+	earthRadius = 6371.0
+
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// GeohashLabel returns the key of the node label holding the geohash of the precision
+func GeohashLabel(precision int) string {
+	return fmt.Sprintf("edge-net.io/geohash-%d", precision)
+}
+
+// Geohash encodes the coordinates into a geohash of the precision, the number of characters
+func Geohash(latitude, longitude float64, precision int) string {
+	latitudeRange, longitudeRange := [2]float64{-90, 90}, [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	bits, character, even := 0, 0, true
+	for len(hash) < precision {
+		// The bits alternate between the longitude and the latitude, starting with the longitude
+		value, interval := latitude, &latitudeRange
+		if even {
+			value, interval = longitude, &longitudeRange
+		}
+		middle := (interval[0] + interval[1]) / 2
+		character <<= 1
+		if value >= middle {
+			character |= 1
+			interval[0] = middle
+		} else {
+			interval[1] = middle
+		}
+		even = !even
+		if bits++; bits == 5 {
+			hash = append(hash, geohashAlphabet[character])
+			bits, character = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// geohashCellSize returns the height and the width in degrees of the cells of the precision
+func geohashCellSize(precision int) (float64, float64) {
+	longitudeBits := (5*precision + 1) / 2
+	latitudeBits := 5 * precision / 2
+	return 180 / math.Pow(2, float64(latitudeBits)), 360 / math.Pow(2, float64(longitudeBits))
+}
+
+// GeohashCover returns the precision and the geohashes of the cells covering the circle of the radius in
+// kilometers around the point. There are at most nine cells, of the longest precision this allows. The precision is
+// 0 and there are no cells when the circle is too large for the geohashes to narrow it down, or when it reaches a pole
+// or the antimeridian.
+func GeohashCover(latitude, longitude, radius float64) (int, []string) {
+	latitudeDelta := radius / earthRadius * 180 / math.Pi
+	if latitude+latitudeDelta >= 90 || latitude-latitudeDelta <= -90 {
+		return 0, nil
+	}
+	// The circle is the widest on the side nearest to the pole
+	farthest := math.Max(math.Abs(latitude+latitudeDelta), math.Abs(latitude-latitudeDelta))
+	longitudeDelta := latitudeDelta / math.Cos(farthest*math.Pi/180)
+	if longitude+longitudeDelta >= 180 || longitude-longitudeDelta < -180 {
+		return 0, nil
+	}
+
+	for precision := GeohashMaxPrecision; precision > 0; precision-- {
+		height, width := geohashCellSize(precision)
+		if latitudeDelta > height || longitudeDelta > width {
+			continue
+		}
+		cells := []string{}
+		for _, pointLatitude := range geohashSteps(latitude-latitudeDelta, latitude+latitudeDelta, height) {
+			for _, pointLongitude := range geohashSteps(longitude-longitudeDelta, longitude+longitudeDelta, width) {
+				if cell := Geohash(pointLatitude, pointLongitude, precision); !containsString(cells, cell) {
+					cells = append(cells, cell)
+				}
+			}
+		}
+		sort.Strings(cells)
+		return precision, cells
+	}
+	return 0, nil
+}
+
+// geohashSteps returns points from the minimum to the maximum that are no farther apart than the step, so that
+// no cell of that size between the two is skipped
+func geohashSteps(minimum, maximum, step float64) []float64 {
+	points := []float64{}
+	for point := minimum; point < maximum; point += step {
+		points = append(points, point)
+	}
+	return append(points, maximum)
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Distance returns the great-circle distance in kilometers between two points, with the haversine formula
+func Distance(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	latitudeDelta := toRadians(latitude2 - latitude1)
+	longitudeDelta := toRadians(longitude2 - longitude1)
+	a := math.Sin(latitudeDelta/2)*math.Sin(latitudeDelta/2) +
+		math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Sin(longitudeDelta/2)*math.Sin(longitudeDelta/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GetNodeCoordinates returns the latitude and the longitude in the geolabels of a node
+func GetNodeCoordinates(labels map[string]string) (float64, float64, bool) {
+	// Because of alphanumeric limitations of Kubernetes on the labels we use "w", "e", "n", and "s" prefixes
+	// at the labels of latitude and longitude. Here is the place those prefixes are dropped away.
+	latitudeLabel, longitudeLabel := labels["edge-net.io/lat"], labels["edge-net.io/lon"]
+	if latitudeLabel == "" || longitudeLabel == "" {
+		return 0, 0, false
+	}
+	latitude, err := strconv.ParseFloat(latitudeLabel[1:], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	longitude, err := strconv.ParseFloat(longitudeLabel[1:], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return latitude, longitude, true
+}
+
+// DistanceSelector selects the nodes within a radius around a point
+type DistanceSelector struct {
+	Latitude  float64
+	Longitude float64
+	// Radius in kilometers
+	Radius float64
+	// Precision and Cells are the geohash cells covering the circle, see GeohashCover
+	Precision int
+	Cells     []string
+}
+
+// ParseDistanceSelector parses the value of a distance selector, which is the latitude and the longitude of the
+// point, then the radius in kilometers, separated by commas, such as "48.8566,2.3522,50"
+func ParseDistanceSelector(value string) (*DistanceSelector, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("distance %q is not made of a latitude, a longitude and a radius", value)
+	}
+	numbers := make([]float64, len(fields))
+	for i, field := range fields {
+		number, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("distance %q has an invalid number %q", value, field)
+		}
+		numbers[i] = number
+	}
+	selector := &DistanceSelector{Latitude: numbers[0], Longitude: numbers[1], Radius: numbers[2]}
+	if selector.Latitude < -90 || selector.Latitude > 90 || selector.Longitude < -180 || selector.Longitude > 180 || selector.Radius <= 0 {
+		return nil, fmt.Errorf("distance %q is out of range", value)
+	}
+	selector.Precision, selector.Cells = GeohashCover(selector.Latitude, selector.Longitude, selector.Radius)
+	return selector, nil
+}
+
+// Matches tells whether the node with the labels is within the radius. The geohash labels of the node rule out the
+// nodes far away before the distance is computed, nodes labeled before these labels existed are only measured.
+func (d *DistanceSelector) Matches(labels map[string]string) bool {
+	if d.Precision != 0 {
+		if cell, ok := labels[GeohashLabel(d.Precision)]; ok && !containsString(d.Cells, cell) {
+			return false
+		}
+	}
+	latitude, longitude, ok := GetNodeCoordinates(labels)
+	if !ok {
+		return false
+	}
+	return Distance(d.Latitude, d.Longitude, latitude, longitude) <= d.Radius
+}
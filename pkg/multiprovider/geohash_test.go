@@ -0,0 +1,78 @@
+package multiprovider
+
+import (
+	"testing"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+)
+
+func TestGeohash(t *testing.T) {
+	cases := map[string]struct {
+		latitude  float64
+		longitude float64
+		precision int
+		expected  string
+	}{
+		"jutland":  {57.64911, 10.40744, 11, "u4pruydqqvj"},
+		"pantin":   {48.8958, 2.4064, 6, "u09wnv"},
+		"maryland": {38.9965, -76.934, 6, "dqcmc7"},
+		"coarse":   {36.62, -121.79, 2, "9q"},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			util.Equals(t, tc.expected, Geohash(tc.latitude, tc.longitude, tc.precision))
+		})
+	}
+}
+
+func TestGeohashCover(t *testing.T) {
+	cases := map[string]struct {
+		latitude  float64
+		longitude float64
+		radius    float64
+		precision int
+		cells     []string
+	}{
+		"block":        {48.8566, 2.3522, 0.5, 6, []string{"u09tvm", "u09tvq", "u09tvt", "u09tvw"}},
+		"city":         {48.8566, 2.3522, 1, 5, []string{"u09tv"}},
+		"region":       {48.8566, 2.3522, 50, 3, []string{"u09", "u0c", "u0d", "u0f"}},
+		"continent":    {48.8566, 2.3522, 2000, 0, nil},
+		"antimeridian": {0, 179.9, 50, 0, nil},
+		"pole":         {89.9, 0, 50, 0, nil},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			precision, cells := GeohashCover(tc.latitude, tc.longitude, tc.radius)
+			util.Equals(t, tc.precision, precision)
+			util.Equals(t, tc.cells, cells)
+		})
+	}
+}
+
+func TestDistanceSelector(t *testing.T) {
+	util.Equals(t, 343, int(Distance(48.8566, 2.3522, 51.5074, -0.1278)))
+
+	paris := map[string]string{"edge-net.io/lat": "n48.860000", "edge-net.io/lon": "e2.340000", "edge-net.io/geohash-3": "u09"}
+	london := map[string]string{"edge-net.io/lat": "n51.507400", "edge-net.io/lon": "w-0.127800", "edge-net.io/geohash-3": "gcp"}
+	unlabeled := map[string]string{"edge-net.io/lat": "n48.900000", "edge-net.io/lon": "e2.400000"}
+
+	selector, err := ParseDistanceSelector("48.8566, 2.3522, 50")
+	util.OK(t, err)
+	util.Equals(t, true, selector.Matches(paris))
+	util.Equals(t, false, selector.Matches(london))
+	util.Equals(t, true, selector.Matches(unlabeled))
+	util.Equals(t, false, selector.Matches(map[string]string{}))
+
+	// A geohash label outside of the cover rules the node out without measuring the distance
+	misplaced := map[string]string{"edge-net.io/lat": "n48.860000", "edge-net.io/lon": "e2.340000", "edge-net.io/geohash-3": "gcp"}
+	util.Equals(t, false, selector.Matches(misplaced))
+
+	selector, err = ParseDistanceSelector("48.8566,2.3522,400")
+	util.OK(t, err)
+	util.Equals(t, true, selector.Matches(london))
+
+	for _, value := range []string{"48.8566,2.3522", "north,2.3522,50", "91,2.3522,50", "48.8566,2.3522,-5"} {
+		_, err := ParseDistanceSelector(value)
+		util.Equals(t, true, err != nil)
+	}
+}
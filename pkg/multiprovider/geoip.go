@@ -0,0 +1,467 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This code includes GeoLite2 data created by MaxMind, available from
+// https://www.maxmind.com.
+
+package multiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+// Names of the geolocation providers that can be selected to locate the nodes
+const (
+	GeoIPProviderMaxmind = "maxmind"
+	GeoIPProviderMMDB    = "mmdb"
+	GeoIPProviderStatic  = "static"
+)
+
+// GeoIPCacheConfigMap is the config map in the edgenet namespace that keeps the locations found by the web service
+const GeoIPCacheConfigMap = "nodelabeler-geoip-cache"
+
+// GeoLocation tells where an address is and which network it belongs to
+type GeoLocation struct {
+	Continent  string  `json:"continent"`
+	CountryISO string  `json:"countryISO"`
+	StateISO   string  `json:"stateISO,omitempty"`
+	City       string  `json:"city"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	ISP        string  `json:"isp,omitempty"`
+	AS         string  `json:"as,omitempty"`
+	ASN        int     `json:"asn,omitempty"`
+}
+
+// GeoIPProvider locates the addresses of nodes and clusters
+type GeoIPProvider interface {
+	// Locate returns the location of the address, or nil if the provider knows nothing about it
+	Locate(address string) (*GeoLocation, error)
+}
+
+// GeoIPConfig holds the settings the geolocation providers are built from
+type GeoIPConfig struct {
+	Provider string
+
+	MaxmindURL        string
+	MaxmindAccountID  string
+	MaxmindLicenseKey string
+	// CacheTTL is how long the locations found by the web service are kept, 0 disables the cache
+	CacheTTL time.Duration
+	// CityDBPath and ASNDBPath are the GeoLite2 City and ASN databases in MaxMind DB format
+	CityDBPath string
+	ASNDBPath  string
+	// StaticPath is a JSON file mapping addresses or ranges to locations, looked up before the provider
+	StaticPath string
+}
+
+// NewGeoIPProvider returns the geolocation provider selected in the configuration
+func NewGeoIPProvider(kubeclientset kubernetes.Interface, config GeoIPConfig) (GeoIPProvider, error) {
+	var provider GeoIPProvider
+	switch config.Provider {
+	case GeoIPProviderMaxmind, "":
+		provider = NewMaxmindGeoIPProvider(config.MaxmindURL, config.MaxmindAccountID, config.MaxmindLicenseKey)
+		if config.CacheTTL > 0 {
+			provider = NewCachedGeoIPProvider(kubeclientset, provider, config.CacheTTL)
+		}
+	case GeoIPProviderMMDB:
+		mmdbProvider, err := NewMMDBGeoIPProvider(config.CityDBPath, config.ASNDBPath)
+		if err != nil {
+			return nil, err
+		}
+		provider = mmdbProvider
+	case GeoIPProviderStatic:
+		if config.StaticPath == "" {
+			return nil, fmt.Errorf("static provider requires a file of locations")
+		}
+	default:
+		return nil, fmt.Errorf("unknown geolocation provider %s", config.Provider)
+	}
+	if config.StaticPath != "" {
+		return NewStaticGeoIPProvider(config.StaticPath, provider)
+	}
+	return provider, nil
+}
+
+// MaxmindGeoIPProvider locates addresses with the MaxMind GeoIP2 precision web service
+type MaxmindGeoIPProvider struct {
+	url        string
+	accountID  string
+	licenseKey string
+}
+
+// NewMaxmindGeoIPProvider returns a provider calling the web service at the URL with the credentials
+func NewMaxmindGeoIPProvider(url, accountID, licenseKey string) *MaxmindGeoIPProvider {
+	return &MaxmindGeoIPProvider{url: url, accountID: accountID, licenseKey: licenseKey}
+}
+
+// Locate fetches the location of the address from the web service
+func (m *MaxmindGeoIPProvider) Locate(address string) (*GeoLocation, error) {
+	record, err := getMaxmindLocation(m.url, m.accountID, m.licenseKey, address)
+	if err != nil {
+		return nil, err
+	}
+	location := &GeoLocation{
+		Continent:  record.Continent.Names["en"],
+		CountryISO: record.Country.IsoCode,
+		City:       record.City.Names["en"],
+		Latitude:   record.Location.Latitude,
+		Longitude:  record.Location.Longitude,
+		ISP:        record.Traits.Isp,
+		AS:         record.Traits.AutonomousSystemOrganization,
+		ASN:        record.Traits.AutonomousSystemNumber,
+	}
+	if len(record.Subdivisions) > 0 {
+		location.StateISO = record.Subdivisions[0].IsoCode
+	}
+	return location, nil
+}
+
+// MMDBGeoIPProvider locates addresses offline with the GeoLite2 City and ASN databases
+type MMDBGeoIPProvider struct {
+	city *maxminddb.Reader
+	asn  *maxminddb.Reader
+}
+
+// mmdbCityRecord holds the fields of a GeoLite2 City record the labels come from
+type mmdbCityRecord struct {
+	Continent struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// mmdbASNRecord holds the fields of a GeoLite2 ASN record
+type mmdbASNRecord struct {
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// NewMMDBGeoIPProvider loads the databases. Either can be left out, and the labels it provides are then empty.
+func NewMMDBGeoIPProvider(cityDBPath, asnDBPath string) (*MMDBGeoIPProvider, error) {
+	if cityDBPath == "" && asnDBPath == "" {
+		return nil, fmt.Errorf("mmdb provider requires a city or an ASN database")
+	}
+	provider := new(MMDBGeoIPProvider)
+	var err error
+	if cityDBPath != "" {
+		if provider.city, err = maxminddb.Open(cityDBPath); err != nil {
+			return nil, err
+		}
+	}
+	if asnDBPath != "" {
+		if provider.asn, err = maxminddb.Open(asnDBPath); err != nil {
+			return nil, err
+		}
+	}
+	return provider, nil
+}
+
+// Locate looks the address up in the databases. As GeoLite2 has no ISP data, the AS organization stands in for it.
+func (m *MMDBGeoIPProvider) Locate(address string) (*GeoLocation, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %s", address)
+	}
+	var cityRecord mmdbCityRecord
+	var asnRecord mmdbASNRecord
+	var cityFound, asnFound bool
+	var err error
+	if m.city != nil {
+		if _, cityFound, err = m.city.LookupNetwork(ip, &cityRecord); err != nil {
+			return nil, err
+		}
+	}
+	if m.asn != nil {
+		if _, asnFound, err = m.asn.LookupNetwork(ip, &asnRecord); err != nil {
+			return nil, err
+		}
+	}
+	if !cityFound && !asnFound {
+		return nil, nil
+	}
+
+	location := &GeoLocation{
+		Continent:  cityRecord.Continent.Names["en"],
+		CountryISO: cityRecord.Country.IsoCode,
+		City:       cityRecord.City.Names["en"],
+		Latitude:   cityRecord.Location.Latitude,
+		Longitude:  cityRecord.Location.Longitude,
+		AS:         asnRecord.AutonomousSystemOrganization,
+		ISP:        asnRecord.AutonomousSystemOrganization,
+		ASN:        int(asnRecord.AutonomousSystemNumber),
+	}
+	if len(cityRecord.Subdivisions) > 0 {
+		location.StateISO = cityRecord.Subdivisions[0].IsoCode
+	}
+	return location, nil
+}
+
+// StaticGeoIPProvider locates addresses from a fixed map of addresses or ranges, the most specific range first,
+// and falls back to another provider for the addresses it does not know
+type StaticGeoIPProvider struct {
+	networks  []*net.IPNet
+	locations []GeoLocation
+	fallback  GeoIPProvider
+}
+
+// NewStaticGeoIPProvider loads the JSON file mapping addresses or ranges in CIDR notation to locations
+func NewStaticGeoIPProvider(path string, fallback GeoIPProvider) (*StaticGeoIPProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]GeoLocation)
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	provider := &StaticGeoIPProvider{fallback: fallback}
+	for key, location := range entries {
+		network, err := parseNetwork(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		provider.networks = append(provider.networks, network)
+		provider.locations = append(provider.locations, location)
+	}
+	return provider, nil
+}
+
+// Locate returns the location of the most specific range containing the address
+func (s *StaticGeoIPProvider) Locate(address string) (*GeoLocation, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %s", address)
+	}
+	var found *GeoLocation
+	longest := -1
+	for i, network := range s.networks {
+		if ones, _ := network.Mask.Size(); network.Contains(ip) && ones > longest {
+			location := s.locations[i]
+			found, longest = &location, ones
+		}
+	}
+	if found == nil && s.fallback != nil {
+		return s.fallback.Locate(address)
+	}
+	return found, nil
+}
+
+// parseNetwork parses a range in CIDR notation, or a single address
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %s", value)
+		}
+		if ip.To4() != nil {
+			return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
+
+// CachedGeoIPProvider keeps the locations another provider found by address, in memory and in a config map, so that
+// restarting the node labeler does not call a metered web service again for every node
+type CachedGeoIPProvider struct {
+	kubeclientset kubernetes.Interface
+	provider      GeoIPProvider
+	ttl           time.Duration
+
+	mutex   sync.Mutex
+	entries map[string]geoIPCacheEntry
+}
+
+type geoIPCacheEntry struct {
+	Location GeoLocation `json:"location"`
+	Expiry   time.Time   `json:"expiry"`
+}
+
+// NewCachedGeoIPProvider returns a provider that caches the locations found for the time to live
+func NewCachedGeoIPProvider(kubeclientset kubernetes.Interface, provider GeoIPProvider, ttl time.Duration) *CachedGeoIPProvider {
+	return &CachedGeoIPProvider{kubeclientset: kubeclientset, provider: provider, ttl: ttl}
+}
+
+// Locate returns the cached location of the address if it has not expired, otherwise asks the provider
+func (c *CachedGeoIPProvider) Locate(address string) (*GeoLocation, error) {
+	c.mutex.Lock()
+	if c.entries == nil {
+		c.entries = c.loadEntries()
+	}
+	entry, ok := c.entries[address]
+	c.mutex.Unlock()
+	if ok && time.Now().Before(entry.Expiry) {
+		location := entry.Location
+		return &location, nil
+	}
+
+	location, err := c.provider.Locate(address)
+	if err != nil || location == nil {
+		return location, err
+	}
+	entry = geoIPCacheEntry{Location: *location, Expiry: time.Now().Add(c.ttl)}
+	c.mutex.Lock()
+	c.entries[address] = entry
+	c.mutex.Unlock()
+	if err := c.saveEntry(address, entry); err != nil {
+		klog.Infof("Failed to cache the location of %s: %s", address, err)
+	}
+	return location, nil
+}
+
+// loadEntries reads the cache from the config map, an unreadable cache is started over
+func (c *CachedGeoIPProvider) loadEntries() map[string]geoIPCacheEntry {
+	entries := make(map[string]geoIPCacheEntry)
+	configMap, err := c.kubeclientset.CoreV1().ConfigMaps("edgenet").Get(context.TODO(), GeoIPCacheConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Infof("Failed to read the geolocation cache: %s", err)
+		}
+		return entries
+	}
+	for key, value := range configMap.Data {
+		entry := geoIPCacheEntry{}
+		if err := json.Unmarshal([]byte(value), &entry); err == nil {
+			entries[geoIPCacheAddress(key)] = entry
+		}
+	}
+	return entries
+}
+
+// saveEntry writes the entry to the config map, and drops the expired ones
+func (c *CachedGeoIPProvider) saveEntry(address string, entry geoIPCacheEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := c.kubeclientset.CoreV1().ConfigMaps("edgenet").Get(context.TODO(), GeoIPCacheConfigMap, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return err
+			}
+			configMap = new(corev1.ConfigMap)
+			configMap.SetName(GeoIPCacheConfigMap)
+			configMap.SetNamespace("edgenet")
+			configMap.Data = map[string]string{geoIPCacheKey(address): string(value)}
+			_, err = c.kubeclientset.CoreV1().ConfigMaps("edgenet").Create(context.TODO(), configMap, metav1.CreateOptions{})
+			return err
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		for key, value := range configMap.Data {
+			cached := geoIPCacheEntry{}
+			if err := json.Unmarshal([]byte(value), &cached); err != nil || time.Now().After(cached.Expiry) {
+				delete(configMap.Data, key)
+			}
+		}
+		configMap.Data[geoIPCacheKey(address)] = string(value)
+		_, err = c.kubeclientset.CoreV1().ConfigMaps("edgenet").Update(context.TODO(), configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// geoIPCacheKey turns an address into a config map key, which cannot contain the colons of IPv6 addresses
+func geoIPCacheKey(address string) string {
+	return strings.ReplaceAll(address, ":", "_")
+}
+
+func geoIPCacheAddress(key string) string {
+	return strings.ReplaceAll(key, "_", ":")
+}
+
+// geoLabels returns the labels telling the location, with the key prefix escaped or not for a JSON patch
+func geoLabels(location *GeoLocation, keyPrefix string) map[string]string {
+	state := location.StateISO
+	if state == "" {
+		state = location.CountryISO
+	}
+	var lon string
+	var lat string
+	if location.Longitude >= 0 {
+		lon = fmt.Sprintf("e%.6f", location.Longitude)
+	} else {
+		lon = fmt.Sprintf("w%.6f", location.Longitude)
+	}
+	if location.Latitude >= 0 {
+		lat = fmt.Sprintf("n%.6f", location.Latitude)
+	} else {
+		lat = fmt.Sprintf("s%.6f", location.Latitude)
+	}
+	labels := map[string]string{
+		keyPrefix + "continent":   sanitizeNodeLabel(location.Continent),
+		keyPrefix + "country-iso": location.CountryISO,
+		keyPrefix + "state-iso":   state,
+		keyPrefix + "city":        sanitizeNodeLabel(location.City),
+		keyPrefix + "lon":         lon,
+		keyPrefix + "lat":         lat,
+		keyPrefix + "isp":         sanitizeNodeLabel(location.ISP),
+		keyPrefix + "as":          sanitizeNodeLabel(location.AS),
+		keyPrefix + "asn":         strconv.Itoa(location.ASN),
+	}
+	// The geohashes of the location, from the coarsest to the finest, let selectors pick nearby nodes
+	geohash := Geohash(location.Latitude, location.Longitude, GeohashMaxPrecision)
+	for precision := 1; precision <= GeohashMaxPrecision; precision++ {
+		labels[fmt.Sprintf("%sgeohash-%d", keyPrefix, precision)] = geohash[:precision]
+	}
+	// The region and the zone nest like the topology labels of cloud providers, so that they can serve as the
+	// topology key to spread workloads
+	region := topologyLabel(location.Continent, location.CountryISO, location.StateISO)
+	labels[keyPrefix+"region"] = region
+	labels[keyPrefix+"zone"] = topologyLabel(region, location.City)
+	return labels
+}
+
+// topologyLabel joins the parts of a topology label with dots, and keeps it within the length of a label value
+func topologyLabel(parts ...string) string {
+	value := sanitizeNodeLabel(strings.Join(parts, "."))
+	if len(value) > 63 {
+		value = strings.TrimRight(value[:63], "-_.")
+	}
+	return value
+}
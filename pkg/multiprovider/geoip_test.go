@@ -0,0 +1,153 @@
+package multiprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubetestclient "k8s.io/client-go/kubernetes/fake"
+)
+
+// countingGeoIPProvider locates every address in Paris and counts the calls
+type countingGeoIPProvider struct {
+	calls int
+}
+
+func (c *countingGeoIPProvider) Locate(address string) (*GeoLocation, error) {
+	c.calls++
+	return &GeoLocation{Continent: "Europe", CountryISO: "FR", City: "Paris", Latitude: 48.8582, Longitude: 2.3387}, nil
+}
+
+func TestMMDBGeoIPProvider(t *testing.T) {
+	// The city database holds 132.227.0.0/16 in Pantin and 206.196.160.0/19 in the United States without a state
+	// or a city, and the ASN database only holds 132.227.0.0/16, announced by Renater
+	cityDBPath, asnDBPath := filepath.Join("testdata", "GeoLite2-City-Test.mmdb"), filepath.Join("testdata", "GeoLite2-ASN-Test.mmdb")
+
+	provider, err := NewGeoIPProvider(nil, GeoIPConfig{Provider: GeoIPProviderMMDB, CityDBPath: cityDBPath, ASNDBPath: asnDBPath})
+	util.OK(t, err)
+	location, err := provider.Locate("132.227.123.51")
+	util.OK(t, err)
+	util.Equals(t, map[string]string{
+		"edge-net.io/continent":   "Europe",
+		"edge-net.io/country-iso": "FR",
+		"edge-net.io/state-iso":   "IDF",
+		"edge-net.io/city":        "Pantin",
+		"edge-net.io/lat":         "n48.895800",
+		"edge-net.io/lon":         "e2.406400",
+		"edge-net.io/isp":         "Renater",
+		"edge-net.io/as":          "Renater",
+		"edge-net.io/asn":         "1307",
+		"edge-net.io/geohash-1":   "u",
+		"edge-net.io/geohash-2":   "u0",
+		"edge-net.io/geohash-3":   "u09",
+		"edge-net.io/geohash-4":   "u09w",
+		"edge-net.io/geohash-5":   "u09wn",
+		"edge-net.io/geohash-6":   "u09wnv",
+		"edge-net.io/region":      "Europe.FR.IDF",
+		"edge-net.io/zone":        "Europe.FR.IDF.Pantin",
+	}, geoLabels(location, "edge-net.io/"))
+
+	// The state falls back to the country, and the AS is unknown
+	location, err = provider.Locate("206.196.180.220")
+	util.OK(t, err)
+	labels := geoLabels(location, "edge-net.io/")
+	util.Equals(t, "North_America", labels["edge-net.io/continent"])
+	util.Equals(t, "US", labels["edge-net.io/state-iso"])
+	util.Equals(t, "w-97.822000", labels["edge-net.io/lon"])
+	util.Equals(t, "0", labels["edge-net.io/asn"])
+	util.Equals(t, "North_America.US", labels["edge-net.io/region"])
+	util.Equals(t, "North_America.US", labels["edge-net.io/zone"])
+
+	location, err = provider.Locate("192.0.2.1")
+	util.OK(t, err)
+	util.Equals(t, (*GeoLocation)(nil), location)
+	_, err = provider.Locate("not-an-address")
+	util.Equals(t, true, err != nil)
+
+	_, err = NewGeoIPProvider(nil, GeoIPConfig{Provider: GeoIPProviderMMDB, CityDBPath: filepath.Join(t.TempDir(), "missing.mmdb")})
+	util.Equals(t, true, err != nil)
+}
+
+func TestStaticGeoIPProvider(t *testing.T) {
+	staticPath := filepath.Join(t.TempDir(), "locations.json")
+	util.OK(t, os.WriteFile(staticPath, []byte(`{
+		"10.0.0.0/8": {"continent": "Europe", "countryISO": "FR", "city": "Paris", "latitude": 48.8582, "longitude": 2.3387},
+		"10.1.0.0/16": {"continent": "Europe", "countryISO": "DE", "city": "Berlin", "latitude": 52.52, "longitude": 13.405},
+		"2001:db8::5": {"continent": "Asia", "countryISO": "JP", "city": "Tokyo", "latitude": 35.6762, "longitude": 139.6503}
+	}`), 0644))
+	fallback := &countingGeoIPProvider{}
+	provider, err := NewStaticGeoIPProvider(staticPath, fallback)
+	util.OK(t, err)
+
+	cases := map[string]struct {
+		address string
+		city    string
+		calls   int
+	}{
+		"range":         {"10.2.3.4", "Paris", 0},
+		"most specific": {"10.1.3.4", "Berlin", 0},
+		"address":       {"2001:db8::5", "Tokyo", 0},
+		"fallback":      {"192.0.2.1", "Paris", 1},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			fallback.calls = 0
+			location, err := provider.Locate(tc.address)
+			util.OK(t, err)
+			util.Equals(t, tc.city, location.City)
+			util.Equals(t, tc.calls, fallback.calls)
+		})
+	}
+
+	provider, err = NewStaticGeoIPProvider(staticPath, nil)
+	util.OK(t, err)
+	location, err := provider.Locate("192.0.2.1")
+	util.OK(t, err)
+	util.Equals(t, (*GeoLocation)(nil), location)
+
+	_, err = NewGeoIPProvider(nil, GeoIPConfig{Provider: GeoIPProviderStatic})
+	util.Equals(t, true, err != nil)
+	_, err = NewGeoIPProvider(nil, GeoIPConfig{Provider: "unknown"})
+	util.Equals(t, true, err != nil)
+}
+
+func TestCachedGeoIPProvider(t *testing.T) {
+	kubeclientset := kubetestclient.NewSimpleClientset()
+	counting := &countingGeoIPProvider{}
+	provider := NewCachedGeoIPProvider(kubeclientset, counting, time.Hour)
+
+	for _, address := range []string{"132.227.123.51", "132.227.123.51", "2001:db8::1"} {
+		location, err := provider.Locate(address)
+		util.OK(t, err)
+		util.Equals(t, "Paris", location.City)
+	}
+	util.Equals(t, 2, counting.calls)
+
+	configMap, err := kubeclientset.CoreV1().ConfigMaps("edgenet").Get(context.TODO(), GeoIPCacheConfigMap, metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, 2, len(configMap.Data))
+	_, ok := configMap.Data["2001_db8__1"]
+	util.Equals(t, true, ok)
+
+	// A restarted labeler reads the cache rather than calling the web service again
+	restarted := NewCachedGeoIPProvider(kubeclientset, counting, time.Hour)
+	location, err := restarted.Locate("2001:db8::1")
+	util.OK(t, err)
+	util.Equals(t, "Paris", location.City)
+	util.Equals(t, 2, counting.calls)
+
+	t.Run("expired", func(t *testing.T) {
+		provider := NewCachedGeoIPProvider(kubeclientset, counting, time.Hour)
+		provider.Locate("132.227.123.51")
+		provider.entries["132.227.123.51"] = geoIPCacheEntry{Expiry: time.Now().Add(-time.Minute)}
+		location, err := provider.Locate("132.227.123.51")
+		util.OK(t, err)
+		util.Equals(t, "Paris", location.City)
+		util.Equals(t, 3, counting.calls)
+	})
+}
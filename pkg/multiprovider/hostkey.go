@@ -0,0 +1,171 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+)
+
+// KnownHostsSecret is the secret in the edgenet namespace that keeps the host keys of contributed nodes in known_hosts format
+const KnownHostsSecret = "nodecontribution-known-hosts"
+
+const knownHostsKey = "known_hosts"
+
+var (
+	errHostKeyMismatch = errors.New("host key does not match the fingerprint")
+	errHostKeyChanged  = errors.New("host key has changed since the first connection")
+)
+
+// IsHostKeyMismatch tells whether the connection failed because the host presented a key other than the pinned fingerprint
+func IsHostKeyMismatch(err error) bool {
+	return errors.Is(err, errHostKeyMismatch)
+}
+
+// IsHostKeyChanged tells whether the connection failed because the host presented a key other than the one
+// recorded at the first connection
+func IsHostKeyChanged(err error) bool {
+	return errors.Is(err, errHostKeyChanged)
+}
+
+// HostKeyCallback verifies the host key of a contributed node. If a fingerprint is pinned, the key must match it
+// and replaces whatever is known about the host. Otherwise, the key is trusted on first use and recorded in
+// the known_hosts secret, then any later connection must present the same key.
+func (m *Manager) HostKeyCallback(fingerprint string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		address := knownhosts.Normalize(hostname)
+		if fingerprint != "" {
+			if NormalizeFingerprint(fingerprint) != ssh.FingerprintSHA256(key) {
+				return errHostKeyMismatch
+			}
+			return m.updateKnownHosts(address, key)
+		}
+		knownKeys, err := m.getKnownHostKeys(address)
+		if err != nil {
+			return err
+		}
+		if len(knownKeys) == 0 {
+			klog.Infof("Trusting host key of %s on first use: %s", address, ssh.FingerprintSHA256(key))
+			return m.updateKnownHosts(address, key)
+		}
+		for _, knownKey := range knownKeys {
+			if bytes.Equal(knownKey.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+		return errHostKeyChanged
+	}
+}
+
+// NormalizeFingerprint brings a SHA256 fingerprint to the format ssh-keygen prints
+func NormalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimRight(strings.TrimSpace(fingerprint), "=")
+	if !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+	return fingerprint
+}
+
+// DeleteKnownHostKeys forgets the keys of the host, so that the next connection trusts the key on first use again
+func (m *Manager) DeleteKnownHostKeys(hostname string) error {
+	return m.updateKnownHosts(knownhosts.Normalize(hostname), nil)
+}
+
+func (m *Manager) getKnownHostKeys(address string) ([]ssh.PublicKey, error) {
+	secret, err := m.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), KnownHostsSecret, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	keys := []ssh.PublicKey{}
+	for _, line := range bytes.Split(secret.Data[knownHostsKey], []byte("\n")) {
+		if key, matches := parseKnownHost(line, address); matches {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// updateKnownHosts drops the lines of the address and appends the key if given
+func (m *Manager) updateKnownHosts(address string, key ssh.PublicKey) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := m.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), KnownHostsSecret, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return err
+			}
+			if key == nil {
+				return nil
+			}
+			secret = new(corev1.Secret)
+			secret.SetName(KnownHostsSecret)
+			secret.SetNamespace("edgenet")
+			secret.Data = map[string][]byte{knownHostsKey: []byte(knownhosts.Line([]string{address}, key) + "\n")}
+			_, err = m.kubeclientset.CoreV1().Secrets("edgenet").Create(context.TODO(), secret, metav1.CreateOptions{})
+			return err
+		}
+		var knownHosts bytes.Buffer
+		for _, line := range bytes.Split(secret.Data[knownHostsKey], []byte("\n")) {
+			if _, matches := parseKnownHost(line, address); matches || len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			knownHosts.Write(line)
+			knownHosts.WriteByte('\n')
+		}
+		if key != nil {
+			knownHosts.WriteString(knownhosts.Line([]string{address}, key) + "\n")
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[knownHostsKey] = knownHosts.Bytes()
+		_, err = m.kubeclientset.CoreV1().Secrets("edgenet").Update(context.TODO(), secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// parseKnownHost returns the key of a known_hosts line if the line is about the address.
+// Comments, markers and lines that cannot be parsed never match.
+func parseKnownHost(line []byte, address string) (ssh.PublicKey, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return nil, false
+	}
+	marker, hosts, key, _, _, err := ssh.ParseKnownHosts(line)
+	if err != nil || marker != "" {
+		return nil, false
+	}
+	for _, host := range hosts {
+		if host == address {
+			return key, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package multiprovider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	util.OK(t, err)
+	key, err := ssh.NewPublicKey(publicKey)
+	util.OK(t, err)
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+	hostKey := newHostKey(t)
+	otherHostKey := newHostKey(t)
+
+	t.Run("trust on first use", func(t *testing.T) {
+		util.OK(t, g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, hostKey))
+		keys, err := g.multiproviderManager.getKnownHostKeys("10.0.0.1")
+		util.OK(t, err)
+		util.Equals(t, 1, len(keys))
+		util.Equals(t, hostKey.Marshal(), keys[0].Marshal())
+		util.OK(t, g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, hostKey))
+	})
+	t.Run("changed key", func(t *testing.T) {
+		err := g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, otherHostKey)
+		util.Equals(t, true, IsHostKeyChanged(err))
+		util.Equals(t, false, IsHostKeyMismatch(err))
+	})
+	t.Run("other host", func(t *testing.T) {
+		util.OK(t, g.multiproviderManager.HostKeyCallback("")("10.0.0.2:2222", remote, otherHostKey))
+		keys, err := g.multiproviderManager.getKnownHostKeys("[10.0.0.2]:2222")
+		util.OK(t, err)
+		util.Equals(t, 1, len(keys))
+	})
+	t.Run("pinned fingerprint mismatch", func(t *testing.T) {
+		err := g.multiproviderManager.HostKeyCallback(ssh.FingerprintSHA256(hostKey))("10.0.0.1:22", remote, otherHostKey)
+		util.Equals(t, true, IsHostKeyMismatch(err))
+	})
+	t.Run("pinned fingerprint replaces the known key", func(t *testing.T) {
+		fingerprint := strings.TrimPrefix(ssh.FingerprintSHA256(otherHostKey), "SHA256:")
+		util.OK(t, g.multiproviderManager.HostKeyCallback(fingerprint)("10.0.0.1:22", remote, otherHostKey))
+		util.OK(t, g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, otherHostKey))
+		err := g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, hostKey)
+		util.Equals(t, true, IsHostKeyChanged(err))
+	})
+	t.Run("forget host", func(t *testing.T) {
+		util.OK(t, g.multiproviderManager.DeleteKnownHostKeys("10.0.0.1:22"))
+		keys, err := g.multiproviderManager.getKnownHostKeys("10.0.0.1")
+		util.OK(t, err)
+		util.Equals(t, 0, len(keys))
+		keys, err = g.multiproviderManager.getKnownHostKeys("[10.0.0.2]:2222")
+		util.OK(t, err)
+		util.Equals(t, 1, len(keys))
+	})
+	t.Run("keep unmanaged lines", func(t *testing.T) {
+		secret, err := g.multiproviderManager.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), KnownHostsSecret, metav1.GetOptions{})
+		util.OK(t, err)
+		secret.Data[knownHostsKey] = append([]byte("# added by hand\n@revoked * "+strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey)))+"\n"), secret.Data[knownHostsKey]...)
+		g.multiproviderManager.kubeclientset.CoreV1().Secrets("edgenet").Update(context.TODO(), secret, metav1.UpdateOptions{})
+		util.OK(t, g.multiproviderManager.HostKeyCallback("")("10.0.0.1:22", remote, hostKey))
+		secret, err = g.multiproviderManager.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), KnownHostsSecret, metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, true, strings.HasPrefix(string(secret.Data[knownHostsKey]), "# added by hand\n@revoked"))
+	})
+}
+
+func TestNormalizeFingerprint(t *testing.T) {
+	cases := map[string]string{
+		"SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8": "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+		"nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8":        "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+		" nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8= ":     "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+	}
+	for input, expected := range cases {
+		util.Equals(t, expected, NormalizeFingerprint(input))
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+)
+
+// ValidateAddressPool checks that the ranges of the pool are an IPv4 and an IPv6 CIDR, and its reserved
+// addresses are valid
+func ValidateAddressPool(pool *networkingv1alpha1.VPNAddressPool) error {
+	if ip, _, err := net.ParseCIDR(pool.Spec.CIDRV4); err != nil || ip.To4() == nil {
+		return fmt.Errorf("%q is not an IPv4 range", pool.Spec.CIDRV4)
+	}
+	if ip, _, err := net.ParseCIDR(pool.Spec.CIDRV6); err != nil || ip.To4() != nil {
+		return fmt.Errorf("%q is not an IPv6 range", pool.Spec.CIDRV6)
+	}
+	for _, address := range pool.Spec.Reserved {
+		if net.ParseIP(address) == nil {
+			return fmt.Errorf("%q is not an IP address", address)
+		}
+	}
+	return nil
+}
+
+// GetPeerPool returns the name of the pool the peer gets its addresses from, which is the default pool if the
+// peer names none. It returns an empty string if there is no such pool.
+func GetPeerPool(peer *networkingv1alpha1.VPNPeer, pools []*networkingv1alpha1.VPNAddressPool) string {
+	if peer.Spec.Pool != "" {
+		for _, pool := range pools {
+			if pool.GetName() == peer.Spec.Pool {
+				return pool.GetName()
+			}
+		}
+		return ""
+	}
+	// The oldest default pool prevails if there are several
+	var defaultPool *networkingv1alpha1.VPNAddressPool
+	for _, pool := range pools {
+		if pool.Spec.Default && (defaultPool == nil || isCreatedBefore(pool.ObjectMeta.CreationTimestamp.Time, pool.GetName(),
+			defaultPool.ObjectMeta.CreationTimestamp.Time, defaultPool.GetName())) {
+			defaultPool = pool
+		}
+	}
+	if defaultPool == nil {
+		return ""
+	}
+	return defaultPool.GetName()
+}
+
+// AllocateAddress returns the first address of the range that is neither used nor reserved. The network address,
+// and the broadcast address of IPv4 ranges, are never allocated.
+func AllocateAddress(cidr string, used map[string]bool, reserved []string) (string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	excluded := make(map[string]bool)
+	for _, address := range reserved {
+		if ip := net.ParseIP(address); ip != nil {
+			excluded[ip.String()] = true
+		}
+	}
+	ones, bits := network.Mask.Size()
+	isIPv4 := network.IP.To4() != nil
+	for ip := nextIP(network.IP); network.Contains(ip); ip = nextIP(ip) {
+		if isIPv4 && ones < bits-1 && !network.Contains(nextIP(ip)) {
+			// Broadcast address
+			break
+		}
+		if address := ip.String(); !used[address] && !excluded[address] {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("address range %s is exhausted", cidr)
+}
+
+// GetUsedAddresses returns the addresses the peers claim
+func GetUsedAddresses(peers []*networkingv1alpha1.VPNPeer) map[string]bool {
+	used := make(map[string]bool)
+	for _, peer := range peers {
+		for _, address := range []string{peer.Spec.AddressV4, peer.Spec.AddressV6} {
+			if ip := net.ParseIP(address); ip != nil {
+				used[ip.String()] = true
+			}
+		}
+	}
+	return used
+}
+
+// FindAddressConflicts returns the addresses that more than one peer claims. The peers claiming an address are
+// sorted by creation time, so that the first one is the one keeping the address.
+func FindAddressConflicts(peers []*networkingv1alpha1.VPNPeer) []networkingv1alpha1.AddressConflict {
+	sortedPeers := make([]*networkingv1alpha1.VPNPeer, len(peers))
+	copy(sortedPeers, peers)
+	sort.SliceStable(sortedPeers, func(i, j int) bool {
+		return isCreatedBefore(sortedPeers[i].ObjectMeta.CreationTimestamp.Time, sortedPeers[i].GetName(),
+			sortedPeers[j].ObjectMeta.CreationTimestamp.Time, sortedPeers[j].GetName())
+	})
+	claims := make(map[string][]string)
+	for _, peer := range sortedPeers {
+		for _, address := range []string{peer.Spec.AddressV4, peer.Spec.AddressV6} {
+			if ip := net.ParseIP(address); ip != nil {
+				claims[ip.String()] = append(claims[ip.String()], peer.GetName())
+			}
+		}
+	}
+	conflicts := []networkingv1alpha1.AddressConflict{}
+	for address, claimants := range claims {
+		if len(claimants) > 1 {
+			conflicts = append(conflicts, networkingv1alpha1.AddressConflict{Address: address, Peers: claimants})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Address < conflicts[j].Address })
+	return conflicts
+}
+
+// HasAddressConflict tells whether a peer created earlier claims an address of the peer
+func HasAddressConflict(peer *networkingv1alpha1.VPNPeer, peers []*networkingv1alpha1.VPNPeer) bool {
+	for _, conflict := range FindAddressConflicts(peers) {
+		for _, claimant := range conflict.Peers[1:] {
+			if claimant == peer.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsAddress tells whether the address is in the range
+func ContainsAddress(cidr, address string) bool {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && network.Contains(ip)
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func isCreatedBefore(created time.Time, name string, otherCreated time.Time, otherName string) bool {
+	if !created.Equal(otherCreated) {
+		return created.Before(otherCreated)
+	}
+	return name < otherName
+}
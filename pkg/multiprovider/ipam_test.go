@@ -0,0 +1,94 @@
+package multiprovider
+
+import (
+	"testing"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateAddressPool(t *testing.T) {
+	cases := map[string]struct {
+		spec  networkingv1alpha1.VPNAddressPoolSpec
+		fails bool
+	}{
+		"valid":           {networkingv1alpha1.VPNAddressPoolSpec{CIDRV4: "10.183.0.0/20", CIDRV6: "fdb4:ae86:ec99:4004::/64", Reserved: []string{"10.183.0.1"}}, false},
+		"swapped ranges":  {networkingv1alpha1.VPNAddressPoolSpec{CIDRV4: "fdb4:ae86:ec99:4004::/64", CIDRV6: "10.183.0.0/20"}, true},
+		"invalid range":   {networkingv1alpha1.VPNAddressPoolSpec{CIDRV4: "10.183.0.0", CIDRV6: "fdb4:ae86:ec99:4004::/64"}, true},
+		"invalid reserve": {networkingv1alpha1.VPNAddressPoolSpec{CIDRV4: "10.183.0.0/20", CIDRV6: "fdb4:ae86:ec99:4004::/64", Reserved: []string{"vpn"}}, true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			err := ValidateAddressPool(&networkingv1alpha1.VPNAddressPool{Spec: tc.spec})
+			util.Equals(t, tc.fails, err != nil)
+		})
+	}
+}
+
+func TestAllocateAddress(t *testing.T) {
+	cases := map[string]struct {
+		cidr     string
+		used     map[string]bool
+		reserved []string
+		expected string
+		fails    bool
+	}{
+		"first":          {"10.183.0.0/24", nil, nil, "10.183.0.1", false},
+		"reserved":       {"10.183.0.0/24", nil, []string{"10.183.0.1"}, "10.183.0.2", false},
+		"used":           {"10.183.0.0/24", map[string]bool{"10.183.0.1": true, "10.183.0.2": true}, nil, "10.183.0.3", false},
+		"no broadcast":   {"10.183.0.0/30", map[string]bool{"10.183.0.1": true, "10.183.0.2": true}, nil, "", true},
+		"point to point": {"10.183.0.0/31", nil, nil, "10.183.0.1", false},
+		"ipv6":           {"fdb4:ae86:ec99:4004::/64", nil, []string{"fdb4:ae86:ec99:4004::1"}, "fdb4:ae86:ec99:4004::2", false},
+		"ipv6 last":      {"fdb4:ae86:ec99:4004::/127", nil, nil, "fdb4:ae86:ec99:4004::1", false},
+		"invalid":        {"10.183.0.0", nil, nil, "", true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			address, err := AllocateAddress(tc.cidr, tc.used, tc.reserved)
+			util.Equals(t, tc.fails, err != nil)
+			util.Equals(t, tc.expected, address)
+		})
+	}
+}
+
+func TestGetPeerPool(t *testing.T) {
+	now := time.Now()
+	pools := []*networkingv1alpha1.VPNAddressPool{
+		{ObjectMeta: metav1.ObjectMeta{Name: "newer", CreationTimestamp: metav1.NewTime(now)}, Spec: networkingv1alpha1.VPNAddressPoolSpec{Default: true}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "older", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}, Spec: networkingv1alpha1.VPNAddressPoolSpec{Default: true}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "lab", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))}},
+	}
+	peer := &networkingv1alpha1.VPNPeer{}
+	util.Equals(t, "older", GetPeerPool(peer, pools))
+	peer.Spec.Pool = "lab"
+	util.Equals(t, "lab", GetPeerPool(peer, pools))
+	peer.Spec.Pool = "missing"
+	util.Equals(t, "", GetPeerPool(peer, pools))
+	util.Equals(t, "", GetPeerPool(&networkingv1alpha1.VPNPeer{}, pools[2:]))
+}
+
+func TestFindAddressConflicts(t *testing.T) {
+	now := time.Now()
+	peers := []*networkingv1alpha1.VPNPeer{
+		{ObjectMeta: metav1.ObjectMeta{Name: "late", CreationTimestamp: metav1.NewTime(now)},
+			Spec: networkingv1alpha1.VPNPeerSpec{AddressV4: "10.183.0.2", AddressV6: "fdb4:ae86:ec99:4004::3"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "early", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec: networkingv1alpha1.VPNPeerSpec{AddressV4: "10.183.0.2", AddressV6: "fdb4:ae86:ec99:4004::2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "alone", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec: networkingv1alpha1.VPNPeerSpec{AddressV4: "10.183.0.4", AddressV6: "fdb4:ae86:ec99:4004::4"}},
+	}
+	conflicts := FindAddressConflicts(peers)
+	util.Equals(t, []networkingv1alpha1.AddressConflict{{Address: "10.183.0.2", Peers: []string{"early", "late"}}}, conflicts)
+	util.Equals(t, true, HasAddressConflict(peers[0], peers))
+	util.Equals(t, false, HasAddressConflict(peers[1], peers))
+	util.Equals(t, false, HasAddressConflict(peers[2], peers))
+
+	// The same address written differently is still the same address
+	peers[2].Spec.AddressV6 = "fdb4:ae86:ec99:4004:0::2"
+	conflicts = FindAddressConflicts(peers)
+	util.Equals(t, 2, len(conflicts))
+	util.Equals(t, []string{"alone", "early"}, conflicts[1].Peers)
+}
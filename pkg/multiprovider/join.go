@@ -93,6 +93,36 @@ func getServerOfCurrentContext() (string, error) {
 // CreateToken creates the token to be used to add node
 // and return the token
 func (m *Manager) createToken(duration time.Duration, hostname string) (string, error) {
+	tokenStr, err := m.createBootstrapToken(duration, hostname, []string{"system:bootstrappers:kubeadm:default-node-token"})
+	if err != nil || tokenStr == "" {
+		return "", err
+	}
+	// This is to get server info
+	server, err := getAPIServerURL()
+	if err != nil {
+		return "", err
+	}
+	server = strings.Trim(server, "https://")
+	server = strings.Trim(server, "http://")
+	certs, err := cert.CertsFromFile(getCAPath())
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+	var CA string
+	for i, cert := range certs {
+		if i == 0 {
+			hashedCA := sha256.Sum256([]byte(cert.RawSubjectPublicKeyInfo))
+			CA = fmt.Sprintf("sha256:%x", hashedCA)
+		}
+	}
+
+	joinCommand := fmt.Sprintf("kubeadm join %s --token %s --discovery-token-ca-cert-hash %s", server, tokenStr, CA)
+	return joinCommand, nil
+}
+
+// createBootstrapToken stores a new bootstrap token granting the groups given and returns it
+func (m *Manager) createBootstrapToken(duration time.Duration, hostname string, groups []string) (string, error) {
 	tokenStr, err := bootstraputil.GenerateBootstrapToken()
 	if err != nil {
 		log.Printf("Error generating token to upload certs: %s", err)
@@ -109,7 +139,7 @@ func (m *Manager) createToken(duration time.Duration, hostname string) (string,
 		Duration: duration,
 	}
 	bootstrapToken.Usages = []string{"authentication", "signing"}
-	bootstrapToken.Groups = []string{"system:bootstrappers:kubeadm:default-node-token"}
+	bootstrapToken.Groups = groups
 	bootstrapToken.Token = token
 
 	secret, err := m.kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).Get(context.TODO(), token.ID, metav1.GetOptions{})
@@ -134,8 +164,29 @@ func (m *Manager) createToken(duration time.Duration, hostname string) (string,
 			return "", err
 		}
 	}
+	return tokenStr, nil
+}
 
-	// This is to get server info
+// RevokeBootstrapTokens deletes the bootstrap tokens created for adding the node, whether they are used or not
+func (m *Manager) RevokeBootstrapTokens(hostname string) error {
+	secretList, err := m.kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).List(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("type=%s", bootstrapapi.SecretTypeBootstrapToken)})
+	if err != nil {
+		return err
+	}
+	description := fmt.Sprintf("EdgeNet token for adding node called %s", hostname)
+	for _, secret := range secretList.Items {
+		if secret.Type != corev1.SecretType(bootstrapapi.SecretTypeBootstrapToken) || string(secret.Data[bootstrapapi.BootstrapTokenDescriptionKey]) != description {
+			continue
+		}
+		if err := m.kubeclientset.CoreV1().Secrets(metav1.NamespaceSystem).Delete(context.TODO(), secret.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAPIServerURL returns the address of the API server nodes join to
+func getAPIServerURL() (string, error) {
 	kubeconfigPath := "/edgenet/.kube/config"
 	if flag.Lookup("kubeconfig-path") != nil {
 		kubeconfigPath = flag.Lookup("kubeconfig-path").Value.(flag.Getter).Get().(string)
@@ -145,29 +196,16 @@ func (m *Manager) createToken(duration time.Duration, hostname string) (string,
 		log.Println(err.Error())
 		return "", err
 	}
-	server := config.Host
-	server = strings.Trim(server, "https://")
-	server = strings.Trim(server, "http://")
+	return config.Host, nil
+}
+
+// getCAPath returns the path of the cluster CA certificate
+func getCAPath() string {
 	pathCA := "/etc/kubernetes/pki/ca.crt"
 	if flag.Lookup("ca-path") != nil {
 		pathCA = flag.Lookup("ca-path").Value.(flag.Getter).Get().(string)
 	}
-	// This reads CA cert to be hashed
-	certs, err := cert.CertsFromFile(pathCA)
-	if err != nil {
-		log.Println(err)
-		return "", err
-	}
-	var CA string
-	for i, cert := range certs {
-		if i == 0 {
-			hashedCA := sha256.Sum256([]byte(cert.RawSubjectPublicKeyInfo))
-			CA = fmt.Sprintf("sha256:%x", hashedCA)
-		}
-	}
-
-	joinCommand := fmt.Sprintf("kubeadm join %s --token %s --discovery-token-ca-cert-hash %s", server, tokenStr, CA)
-	return joinCommand, nil
+	return pathCA
 }
 
 // encodeTokenSecretData takes the token discovery object and an optional duration and returns the .Data for the Secret
@@ -0,0 +1,211 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// LatencyClassLabel holds the latency class of the median round-trip time from a node to the other nodes
+	LatencyClassLabel = "edge-net.io/latency-class"
+	// LandmarkLatencyLabelPrefix prefixes the labels holding the latency class from a node to each landmark
+	LandmarkLatencyLabelPrefix = "latency.edge-net.io/"
+)
+
+// Latency classes of the round-trip times
+const (
+	LatencyLow         = "low"
+	LatencyMedium      = "medium"
+	LatencyHigh        = "high"
+	LatencyUnreachable = "unreachable"
+)
+
+// landmarkName is the part of a label key that a landmark name makes up
+var landmarkName = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+// Landmark is a well-known host outside of the cluster that the nodes measure their round-trip times to
+type Landmark struct {
+	Name    string
+	Address string
+}
+
+// ParseLandmarks parses a list of landmarks separated by commas, each a name and a host with a port joined by an
+// equal sign, such as "paris=ping.example.org:443,tokyo=203.0.113.10:80"
+func ParseLandmarks(value string) ([]Landmark, error) {
+	landmarks := []Landmark{}
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || !landmarkName.MatchString(parts[0]) {
+			return nil, fmt.Errorf("landmark %q is not made of a valid name and an address", field)
+		}
+		if _, _, err := net.SplitHostPort(parts[1]); err != nil {
+			return nil, fmt.Errorf("landmark %q has an invalid address: %s", field, err)
+		}
+		landmarks = append(landmarks, Landmark{Name: parts[0], Address: parts[1]})
+	}
+	return landmarks, nil
+}
+
+// ProbeRTT opens the number of TCP connections to the address one after the other and returns the median time the
+// connections took to be established, which is a round-trip time, and the percentage of connections that failed.
+// The round-trip time is nil if no connection succeeded.
+func ProbeRTT(address string, attempts int, timeout time.Duration) (*metav1.Duration, int) {
+	rtts := []time.Duration{}
+	for i := 0; i < attempts; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", address, timeout)
+		if err != nil {
+			continue
+		}
+		rtts = append(rtts, time.Since(start))
+		conn.Close()
+	}
+	if attempts == 0 || len(rtts) == 0 {
+		return nil, 100
+	}
+	loss := 100 * (attempts - len(rtts)) / attempts
+	return &metav1.Duration{Duration: medianDuration(rtts)}, loss
+}
+
+func medianDuration(durations []time.Duration) time.Duration {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	middle := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[middle-1] + durations[middle]) / 2
+	}
+	return durations[middle]
+}
+
+// ClassifyLatency returns the latency class of the round-trip time given the thresholds of the low and high classes
+func ClassifyLatency(rtt *metav1.Duration, low, high time.Duration) string {
+	switch {
+	case rtt == nil:
+		return LatencyUnreachable
+	case rtt.Duration <= low:
+		return LatencyLow
+	case rtt.Duration < high:
+		return LatencyMedium
+	default:
+		return LatencyHigh
+	}
+}
+
+// LatencyLabels returns the labels telling the latency classes of the node the measurements come from. The latency
+// class of the node is that of the median round-trip time to the nodes that answered, and each landmark gets one.
+func LatencyLabels(status networkingv1alpha1.NodeLatencyStatus, low, high time.Duration) map[string]string {
+	labels := make(map[string]string)
+	if len(status.Nodes) != 0 {
+		rtts := []time.Duration{}
+		for _, measurement := range status.Nodes {
+			if measurement.RTT != nil {
+				rtts = append(rtts, measurement.RTT.Duration)
+			}
+		}
+		var median *metav1.Duration
+		if len(rtts) != 0 {
+			median = &metav1.Duration{Duration: medianDuration(rtts)}
+		}
+		labels[LatencyClassLabel] = ClassifyLatency(median, low, high)
+	}
+	for _, measurement := range status.Landmarks {
+		if landmarkName.MatchString(measurement.Name) {
+			labels[LandmarkLatencyLabelPrefix+measurement.Name] = ClassifyLatency(measurement.RTT, low, high)
+		}
+	}
+	return labels
+}
+
+// SetNodeLatencyLabels replaces the latency labels of the node with the ones given, so that the classes of the
+// landmarks no longer measured go away. Nil labels remove them all.
+func (m *Manager) SetNodeLatencyLabels(hostname string, labels map[string]string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := m.kubeclientset.CoreV1().Nodes().Get(context.TODO(), hostname, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if LatencyLabelsApplied(node, labels) {
+			return nil
+		}
+		nodeCopy := node.DeepCopy()
+		applyLatencyLabels(nodeCopy, labels)
+		_, err = m.kubeclientset.CoreV1().Nodes().Update(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// LatencyLabelsApplied tells whether the latency labels of the node are the ones given
+func LatencyLabelsApplied(node *corev1.Node, labels map[string]string) bool {
+	nodeCopy := node.DeepCopy()
+	applyLatencyLabels(nodeCopy, labels)
+	return equality.Semantic.DeepEqual(node.GetLabels(), nodeCopy.GetLabels())
+}
+
+// applyLatencyLabels replaces the latency labels of the node, leaving the others in place
+func applyLatencyLabels(node *corev1.Node, latencyLabels map[string]string) {
+	var labels map[string]string
+	for key, value := range node.GetLabels() {
+		if key != LatencyClassLabel && !strings.HasPrefix(key, LandmarkLatencyLabelPrefix) {
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[key] = value
+		}
+	}
+	for key, value := range latencyLabels {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+	}
+	node.SetLabels(labels)
+}
+
+// ParseLatencySelector parses the value of a latency selector and returns the key of the node label it targets and
+// the latency class. The value is either a class, such as "low", which targets the latency class of the nodes, or
+// a landmark and a class joined by an equal sign, such as "paris=low".
+func ParseLatencySelector(value string) (string, string, error) {
+	key, class := LatencyClassLabel, strings.TrimSpace(value)
+	if parts := strings.SplitN(class, "=", 2); len(parts) == 2 {
+		landmark := strings.TrimSpace(parts[0])
+		if !landmarkName.MatchString(landmark) {
+			return "", "", fmt.Errorf("latency %q has an invalid landmark %q", value, landmark)
+		}
+		key, class = LandmarkLatencyLabelPrefix+landmark, strings.TrimSpace(parts[1])
+	}
+	switch class {
+	case LatencyLow, LatencyMedium, LatencyHigh, LatencyUnreachable:
+		return key, class, nil
+	}
+	return "", "", fmt.Errorf("latency %q has an unknown class %q", value, class)
+}
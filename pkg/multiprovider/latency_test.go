@@ -0,0 +1,131 @@
+package multiprovider
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	networkingv1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/networking/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseLandmarks(t *testing.T) {
+	landmarks, err := ParseLandmarks("paris=ping.example.org:443, tokyo=[2001:db8::1]:80,")
+	util.OK(t, err)
+	util.Equals(t, []Landmark{{Name: "paris", Address: "ping.example.org:443"}, {Name: "tokyo", Address: "[2001:db8::1]:80"}}, landmarks)
+
+	landmarks, err = ParseLandmarks("")
+	util.OK(t, err)
+	util.Equals(t, []Landmark{}, landmarks)
+
+	for _, value := range []string{"paris", "paris=ping.example.org", "-paris=ping.example.org:443", "new york=203.0.113.10:80"} {
+		_, err := ParseLandmarks(value)
+		util.Equals(t, true, err != nil)
+	}
+}
+
+func TestProbeRTT(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	util.OK(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	rtt, loss := ProbeRTT(listener.Addr().String(), 3, time.Second)
+	util.Equals(t, true, rtt != nil)
+	util.Equals(t, 0, loss)
+
+	address := listener.Addr().String()
+	listener.Close()
+	rtt, loss = ProbeRTT(address, 3, time.Second)
+	util.Equals(t, (*metav1.Duration)(nil), rtt)
+	util.Equals(t, 100, loss)
+}
+
+func TestLatencyLabels(t *testing.T) {
+	rtt := func(milliseconds int) *metav1.Duration {
+		return &metav1.Duration{Duration: time.Duration(milliseconds) * time.Millisecond}
+	}
+	low, high := 20*time.Millisecond, 100*time.Millisecond
+	util.Equals(t, LatencyLow, ClassifyLatency(rtt(20), low, high))
+	util.Equals(t, LatencyMedium, ClassifyLatency(rtt(50), low, high))
+	util.Equals(t, LatencyHigh, ClassifyLatency(rtt(100), low, high))
+	util.Equals(t, LatencyUnreachable, ClassifyLatency(nil, low, high))
+
+	status := networkingv1alpha1.NodeLatencyStatus{
+		Nodes: []networkingv1alpha1.LatencyMeasurement{
+			{Name: "fr-idf-0001", RTT: rtt(5)},
+			{Name: "fr-idf-0002", RTT: rtt(40)},
+			{Name: "us-md-0001", RTT: rtt(90)},
+			{Name: "jp-tk-0001", Loss: 100},
+		},
+		Landmarks: []networkingv1alpha1.LatencyMeasurement{
+			{Name: "paris", RTT: rtt(3)},
+			{Name: "tokyo", RTT: rtt(250)},
+			{Name: "sydney", Loss: 100},
+		},
+	}
+	expected := map[string]string{
+		LatencyClassLabel:                     LatencyMedium,
+		LandmarkLatencyLabelPrefix + "paris":  LatencyLow,
+		LandmarkLatencyLabelPrefix + "tokyo":  LatencyHigh,
+		LandmarkLatencyLabelPrefix + "sydney": LatencyUnreachable,
+	}
+	util.Equals(t, expected, LatencyLabels(status, low, high))
+	util.Equals(t, map[string]string{}, LatencyLabels(networkingv1alpha1.NodeLatencyStatus{}, low, high))
+}
+
+func TestSetNodeLatencyLabels(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	node := g.nodeObj
+	node.SetName("measured.edge-net.io")
+	node.SetLabels(map[string]string{"edge-net.io/city": "paris", LandmarkLatencyLabelPrefix + "sydney": LatencyHigh})
+	g.multiproviderManager.kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+
+	labels := map[string]string{LatencyClassLabel: LatencyLow, LandmarkLatencyLabelPrefix + "paris": LatencyLow}
+	util.OK(t, g.multiproviderManager.SetNodeLatencyLabels(node.GetName(), labels))
+	measuredNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, true, LatencyLabelsApplied(measuredNode, labels))
+	util.Equals(t, map[string]string{"edge-net.io/city": "paris", LatencyClassLabel: LatencyLow, LandmarkLatencyLabelPrefix + "paris": LatencyLow}, measuredNode.Labels)
+
+	t.Run("remove labels", func(t *testing.T) {
+		util.OK(t, g.multiproviderManager.SetNodeLatencyLabels(node.GetName(), nil))
+		unmeasuredNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, map[string]string{"edge-net.io/city": "paris"}, unmeasuredNode.Labels)
+	})
+}
+
+func TestParseLatencySelector(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		key   string
+		class string
+	}{
+		"class":    {"low", LatencyClassLabel, LatencyLow},
+		"landmark": {"paris = medium", LandmarkLatencyLabelPrefix + "paris", LatencyMedium},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			key, class, err := ParseLatencySelector(tc.value)
+			util.OK(t, err)
+			util.Equals(t, tc.key, key)
+			util.Equals(t, tc.class, class)
+		})
+	}
+	for _, value := range []string{"fast", "paris=fast", "new york=low"} {
+		_, _, err := ParseLatencySelector(value)
+		util.Equals(t, true, err != nil)
+	}
+}
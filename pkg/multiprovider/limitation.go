@@ -0,0 +1,129 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"context"
+	"strings"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// LimitationTaintKey is the key of the taint keeping workloads away from a node whose contribution has limitations
+	LimitationTaintKey = "edge-net.io/limited"
+	// TenantLimitationLabelPrefix prefixes the labels naming the tenants allowed on a node with limitations
+	TenantLimitationLabelPrefix = "tenant.limitation.edge-net.io/"
+	// NamespaceLimitationLabelPrefix prefixes the labels naming the namespaces allowed on a node with limitations
+	NamespaceLimitationLabelPrefix = "namespace.limitation.edge-net.io/"
+)
+
+// SetNodeLimitations translates the limitations of a node contribution into a taint and labels on the node.
+// The taint keeps away workloads that do not tolerate it, and the labels name the tenants and namespaces
+// that can run on the node. A node without limitations has neither.
+func (m *Manager) SetNodeLimitations(hostname string, limitations []corev1alpha1.Limitations) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := m.kubeclientset.CoreV1().Nodes().Get(context.TODO(), hostname, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if LimitationsApplied(node, limitations) {
+			return nil
+		}
+		nodeCopy := node.DeepCopy()
+		applyLimitations(nodeCopy, limitations)
+		_, err = m.kubeclientset.CoreV1().Nodes().Update(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// LimitationsApplied tells whether the taint and labels of the node reflect the limitations
+func LimitationsApplied(node *corev1.Node, limitations []corev1alpha1.Limitations) bool {
+	nodeCopy := node.DeepCopy()
+	applyLimitations(nodeCopy, limitations)
+	return equality.Semantic.DeepEqual(node.GetLabels(), nodeCopy.GetLabels()) && equality.Semantic.DeepEqual(node.Spec.Taints, nodeCopy.Spec.Taints)
+}
+
+// applyLimitations replaces the limitation taint and labels of the node, leaving the others in place
+func applyLimitations(node *corev1.Node, limitations []corev1alpha1.Limitations) {
+	var labels map[string]string
+	for key, value := range node.GetLabels() {
+		if !strings.HasPrefix(key, TenantLimitationLabelPrefix) && !strings.HasPrefix(key, NamespaceLimitationLabelPrefix) {
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[key] = value
+		}
+	}
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != LimitationTaintKey {
+			taints = append(taints, taint)
+		}
+	}
+	for _, limitation := range limitations {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		switch limitation.Kind {
+		case "Tenant":
+			labels[TenantLimitationLabelPrefix+limitation.Indentifier] = "true"
+		case "Namespace":
+			labels[NamespaceLimitationLabelPrefix+limitation.Indentifier] = "true"
+		}
+	}
+	if len(limitations) != 0 {
+		taints = append(taints, corev1.Taint{Key: LimitationTaintKey, Value: "true", Effect: corev1.TaintEffectNoSchedule})
+	}
+	node.SetLabels(labels)
+	node.Spec.Taints = taints
+}
+
+// IsAllowedOnNode tells whether the workloads of the namespace, which belongs to the tenant, can run on the node
+func IsAllowedOnNode(node *corev1.Node, namespace, tenant string) bool {
+	if !IsLimited(node) {
+		return true
+	}
+	labels := node.GetLabels()
+	if _, ok := labels[NamespaceLimitationLabelPrefix+namespace]; ok {
+		return true
+	}
+	if _, ok := labels[TenantLimitationLabelPrefix+tenant]; ok && tenant != "" {
+		return true
+	}
+	return false
+}
+
+// IsLimited tells whether the node carries the taint of a contribution with limitations
+func IsLimited(node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == LimitationTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitationToleration returns the toleration that lets pods onto the nodes with limitations allowing them
+func LimitationToleration() corev1.Toleration {
+	return corev1.Toleration{Key: LimitationTaintKey, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+}
@@ -0,0 +1,50 @@
+package multiprovider
+
+import (
+	"context"
+	"testing"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetNodeLimitations(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	node := g.nodeObj
+	node.SetName("limited.edge-net.io")
+	node.SetLabels(map[string]string{"edge-net.io/city": "paris"})
+	node.Spec.Taints = []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoExecute}}
+	g.multiproviderManager.kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+
+	limitations := []corev1alpha1.Limitations{{Kind: "Tenant", Indentifier: "lip6"}, {Kind: "Namespace", Indentifier: "lab"}}
+	util.OK(t, g.multiproviderManager.SetNodeLimitations(node.GetName(), limitations))
+	limitedNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, true, LimitationsApplied(limitedNode, limitations))
+	util.Equals(t, "paris", limitedNode.Labels["edge-net.io/city"])
+	util.Equals(t, "true", limitedNode.Labels[TenantLimitationLabelPrefix+"lip6"])
+	util.Equals(t, "true", limitedNode.Labels[NamespaceLimitationLabelPrefix+"lab"])
+	util.Equals(t, 2, len(limitedNode.Spec.Taints))
+
+	t.Run("allowed", func(t *testing.T) {
+		util.Equals(t, true, IsLimited(limitedNode))
+		util.Equals(t, true, IsAllowedOnNode(limitedNode, "lip6", "lip6"))
+		util.Equals(t, true, IsAllowedOnNode(limitedNode, "lab", "edgenet"))
+		util.Equals(t, false, IsAllowedOnNode(limitedNode, "edgenet", "edgenet"))
+		util.Equals(t, false, IsAllowedOnNode(limitedNode, "default", ""))
+	})
+	t.Run("lift limitations", func(t *testing.T) {
+		util.Equals(t, false, LimitationsApplied(limitedNode, nil))
+		util.OK(t, g.multiproviderManager.SetNodeLimitations(node.GetName(), nil))
+		unlimitedNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, map[string]string{"edge-net.io/city": "paris"}, unlimitedNode.Labels)
+		util.Equals(t, node.Spec.Taints, unlimitedNode.Spec.Taints)
+		util.Equals(t, false, IsLimited(unlimitedNode))
+		util.Equals(t, true, IsAllowedOnNode(unlimitedNode, "edgenet", "edgenet"))
+	})
+}
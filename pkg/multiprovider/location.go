@@ -0,0 +1,136 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// LocationAnnotation holds the location declared for a node, in the JSON form of a node contribution location.
+	// The node labeler uses it rather than locating the addresses of the node.
+	LocationAnnotation = "edge-net.io/location"
+	// LocationSourceLabel tells whether the location labels of a node were declared or measured
+	LocationSourceLabel = "edge-net.io/location-source"
+	// LocationSourceAnnotation tells who wrote the location annotation of a node, which is left out when set by hand
+	LocationSourceAnnotation = "edge-net.io/location-source"
+)
+
+// Sources of the location labels of a node
+const (
+	LocationSourceDeclared = "declared"
+	LocationSourceMeasured = "measured"
+)
+
+// LocationSourceContribution marks the location annotation written from a node contribution
+const LocationSourceContribution = "nodecontribution"
+
+// SetNodeLocation writes the location declared by a node contribution to the annotation of the node, or removes
+// the annotation if the contribution wrote it and declares none. An annotation set by hand is replaced as well, the
+// contribution prevails, but it is kept when the contribution declares no location.
+func (m *Manager) SetNodeLocation(hostname string, location *corev1alpha1.NodeLocation) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := m.kubeclientset.CoreV1().Nodes().Get(context.TODO(), hostname, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if LocationApplied(node, location) {
+			return nil
+		}
+		nodeCopy := node.DeepCopy()
+		annotations := nodeCopy.GetAnnotations()
+		if location == nil {
+			delete(annotations, LocationAnnotation)
+			delete(annotations, LocationSourceAnnotation)
+		} else {
+			value, err := json.Marshal(location)
+			if err != nil {
+				return err
+			}
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[LocationAnnotation] = string(value)
+			annotations[LocationSourceAnnotation] = LocationSourceContribution
+		}
+		nodeCopy.SetAnnotations(annotations)
+		_, err = m.kubeclientset.CoreV1().Nodes().Update(context.TODO(), nodeCopy, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// LocationApplied tells whether the annotation of the node reflects the location declared by a node contribution.
+// Without a declared location, only an annotation the contribution wrote is to be removed.
+func LocationApplied(node *corev1.Node, location *corev1alpha1.NodeLocation) bool {
+	value, ok := node.GetAnnotations()[LocationAnnotation]
+	written := node.GetAnnotations()[LocationSourceAnnotation] == LocationSourceContribution
+	if location == nil {
+		return !ok || !written
+	}
+	if !ok || !written {
+		return false
+	}
+	declared := corev1alpha1.NodeLocation{}
+	if err := json.Unmarshal([]byte(value), &declared); err != nil {
+		return false
+	}
+	return declared == *location
+}
+
+// GetDeclaredLocation returns the location declared in the annotation of the node, or nil if there is none
+func GetDeclaredLocation(node *corev1.Node) (*GeoLocation, error) {
+	value, ok := node.GetAnnotations()[LocationAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	declared := corev1alpha1.NodeLocation{}
+	if err := json.Unmarshal([]byte(value), &declared); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %s", LocationAnnotation, err)
+	}
+	latitude, err := strconv.ParseFloat(declared.Latitude, 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("invalid latitude %q in the %s annotation", declared.Latitude, LocationAnnotation)
+	}
+	longitude, err := strconv.ParseFloat(declared.Longitude, 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid longitude %q in the %s annotation", declared.Longitude, LocationAnnotation)
+	}
+	return &GeoLocation{
+		Continent:  declared.Continent,
+		CountryISO: declared.CountryISO,
+		StateISO:   declared.StateISO,
+		City:       declared.City,
+		Latitude:   latitude,
+		Longitude:  longitude,
+	}, nil
+}
+
+// SetNodeGeolocation attaches the geolabels of the location to the node, along with the label telling their source
+func (m *Manager) SetNodeGeolocation(hostname string, location *GeoLocation, source string) bool {
+	labels := geoLabels(location, "edge-net.io~1")
+	labels["edge-net.io~1location-source"] = source
+	return m.setNodeLabels(hostname, labels)
+}
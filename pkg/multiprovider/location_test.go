@@ -0,0 +1,72 @@
+package multiprovider
+
+import (
+	"context"
+	"testing"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetNodeLocation(t *testing.T) {
+	g := testGroup{}
+	g.Init()
+	node := g.nodeObj
+	node.SetName("located.edge-net.io")
+	node.SetAnnotations(map[string]string{"other": "value"})
+	g.multiproviderManager.kubeclientset.CoreV1().Nodes().Create(context.TODO(), node.DeepCopy(), metav1.CreateOptions{})
+
+	location := &corev1alpha1.NodeLocation{Latitude: "48.8466", Longitude: "2.3572", City: "Paris", CountryISO: "FR", StateISO: "IDF", Continent: "Europe"}
+	util.OK(t, g.multiproviderManager.SetNodeLocation(node.GetName(), location))
+	locatedNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+	util.OK(t, err)
+	util.Equals(t, true, LocationApplied(locatedNode, location))
+	util.Equals(t, false, LocationApplied(locatedNode, nil))
+	util.Equals(t, "value", locatedNode.Annotations["other"])
+	util.Equals(t, LocationSourceContribution, locatedNode.Annotations[LocationSourceAnnotation])
+
+	declared, err := GetDeclaredLocation(locatedNode)
+	util.OK(t, err)
+	util.Equals(t, &GeoLocation{Continent: "Europe", CountryISO: "FR", StateISO: "IDF", City: "Paris", Latitude: 48.8466, Longitude: 2.3572}, declared)
+
+	t.Run("remove location", func(t *testing.T) {
+		util.OK(t, g.multiproviderManager.SetNodeLocation(node.GetName(), nil))
+		unlocatedNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), node.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, map[string]string{"other": "value"}, unlocatedNode.Annotations)
+		declared, err := GetDeclaredLocation(unlocatedNode)
+		util.OK(t, err)
+		util.Equals(t, (*GeoLocation)(nil), declared)
+	})
+	t.Run("location set by hand", func(t *testing.T) {
+		handNode := g.nodeObj.DeepCopy()
+		handNode.SetName("hand-located.edge-net.io")
+		handNode.SetAnnotations(map[string]string{LocationAnnotation: `{"latitude": "48.8466", "longitude": "2.3572"}`})
+		g.multiproviderManager.kubeclientset.CoreV1().Nodes().Create(context.TODO(), handNode.DeepCopy(), metav1.CreateOptions{})
+		util.Equals(t, true, LocationApplied(handNode, nil))
+		util.Equals(t, false, LocationApplied(handNode, &corev1alpha1.NodeLocation{Latitude: "48.8466", Longitude: "2.3572"}))
+
+		// A contribution without a location leaves the annotation set by hand
+		util.OK(t, g.multiproviderManager.SetNodeLocation(handNode.GetName(), nil))
+		handLocatedNode, err := g.multiproviderManager.kubeclientset.CoreV1().Nodes().Get(context.TODO(), handNode.GetName(), metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, handNode.Annotations, handLocatedNode.Annotations)
+	})
+	t.Run("invalid location", func(t *testing.T) {
+		cases := map[string]string{
+			"json":      `{"latitude": 48.8466}`,
+			"latitude":  `{"latitude": "91", "longitude": "2.3572"}`,
+			"longitude": `{"latitude": "48.8466", "longitude": "east"}`,
+		}
+		for k, value := range cases {
+			t.Run(k, func(t *testing.T) {
+				invalidNode := node.DeepCopy()
+				invalidNode.SetAnnotations(map[string]string{LocationAnnotation: value})
+				_, err := GetDeclaredLocation(invalidNode)
+				util.Equals(t, true, err != nil)
+			})
+		}
+	})
+}
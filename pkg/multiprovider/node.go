@@ -26,19 +26,11 @@ import (
 	"log"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/EdgeNet-project/edgenet/pkg/bootstrap"
 	"github.com/savaki/geoip2"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/route53"
-	namecheap "github.com/billputer/go-namecheap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -156,69 +148,6 @@ func getMaxmindLocation(url string, accountID string, licenseKey string, address
 	return response, err
 }
 
-// GetGeolocationByIP returns geolabels from the MaxMind GeoIP2 precision service
-func (m *Manager) GetGeolocationByIP(
-	maxmindURL string,
-	maxmindAccountID string,
-	maxmindLicenseKey string,
-	hostname string,
-	address string,
-) bool {
-	// Fetch geolocation information
-	record, err := getMaxmindLocation(maxmindURL, maxmindAccountID, maxmindLicenseKey, address)
-	if err != nil {
-		log.Println(err)
-		return false
-	}
-
-	continent := sanitizeNodeLabel(record.Continent.Names["en"])
-	country := record.Country.IsoCode
-	state := record.Country.IsoCode
-	city := sanitizeNodeLabel(record.City.Names["en"])
-	isp := sanitizeNodeLabel(record.Traits.Isp)
-	as := sanitizeNodeLabel(record.Traits.AutonomousSystemOrganization)
-	asn := strconv.Itoa(record.Traits.AutonomousSystemNumber)
-	var lon string
-	var lat string
-	if record.Location.Longitude >= 0 {
-		lon = fmt.Sprintf("e%.6f", record.Location.Longitude)
-	} else {
-		lon = fmt.Sprintf("w%.6f", record.Location.Longitude)
-	}
-	if record.Location.Latitude >= 0 {
-		lat = fmt.Sprintf("n%.6f", record.Location.Latitude)
-	} else {
-		lat = fmt.Sprintf("s%.6f", record.Location.Latitude)
-	}
-	if len(record.Subdivisions) > 0 {
-		state = record.Subdivisions[0].IsoCode
-	}
-
-	// Create label map to attach to the node
-	geoLabels := map[string]string{
-		"edge-net.io~1continent":   continent,
-		"edge-net.io~1country-iso": country,
-		"edge-net.io~1state-iso":   state,
-		"edge-net.io~1city":        city,
-		"edge-net.io~1lon":         lon,
-		"edge-net.io~1lat":         lat,
-		"edge-net.io~1isp":         isp,
-		"edge-net.io~1as":          as,
-		"edge-net.io~1asn":         asn,
-	}
-
-	// Attach geolabels to the node
-	result := m.setNodeLabels(hostname, geoLabels)
-
-	// If the result is different than the expected, return false
-	// The expected result is having a different longitude and latitude than zero
-	// Zero value typically means there isn't any result meaningful
-	if record.Location.Longitude == 0 && record.Location.Latitude == 0 {
-		return false
-	}
-	return result
-}
-
 func CompareAvailableResources(oldObj *corev1.Node, newObj *corev1.Node) bool {
 	if oldObj.Status.Allocatable.Cpu().Cmp(*newObj.Status.Allocatable.Cpu()) != 0 {
 		return true
@@ -277,127 +206,6 @@ func GetNodeIPAddresses(obj *corev1.Node) (string, string) {
 	return internalIP, externalIP
 }
 
-// SetHostnameNamecheap generates token to be used on adding a node onto the cluster
-func SetHostnameNamecheap(hostRecord namecheap.DomainDNSHost) (bool, string) {
-	client, err := bootstrap.CreateNamecheapClient()
-	if err != nil {
-		log.Println(err.Error())
-		return false, "Unknown"
-	}
-	result, state := setHostnamesNamecheap(client, hostRecord)
-	return result, state
-}
-
-// setHostnamesNamecheap allows comparing the current hosts with requested hostname by DNS check
-func setHostnamesNamecheap(client *namecheap.Client, hostRecord namecheap.DomainDNSHost) (bool, string) {
-	hostList := getHosts(client)
-	exist := false
-	for key, host := range hostList.Hosts {
-		if host.Name == hostRecord.Name || host.Address == hostRecord.Address {
-			// If the record exist then update it, overwrite it with new name and address
-			hostList.Hosts[key] = hostRecord
-			log.Printf("Update existing host: %s - %s \n Hostname  and ip address changed to: %s - %s", host.Name, host.Address, hostRecord.Name, hostRecord.Address)
-			exist = true
-			break
-		}
-	}
-	// In case the record is new, it is appended to the list of existing records
-	if !exist {
-		hostList.Hosts = append(hostList.Hosts, hostRecord)
-	}
-	setResponse, err := client.DomainDNSSetHosts("edge-net", "io", hostList.Hosts)
-	if err != nil {
-		log.Println(err.Error())
-		log.Printf("Set host failed: %s - %s", hostRecord.Name, hostRecord.Address)
-		return false, "failed"
-	} else if setResponse.IsSuccess == false {
-		log.Printf("Set host unknown problem: %s - %s", hostRecord.Name, hostRecord.Address)
-		return false, "unknown"
-	}
-	return true, ""
-}
-
-func getHosts(client *namecheap.Client) namecheap.DomainDNSGetHostsResult {
-	hostsResponse, err := client.DomainsDNSGetHosts("edge-net", "io")
-	if err != nil {
-		log.Println(err.Error())
-		return namecheap.DomainDNSGetHostsResult{}
-	}
-	responseJSON, err := json.Marshal(hostsResponse)
-	if err != nil {
-		log.Println(err.Error())
-		return namecheap.DomainDNSGetHostsResult{}
-	}
-	hostList := namecheap.DomainDNSGetHostsResult{}
-	json.Unmarshal([]byte(responseJSON), &hostList)
-	return hostList
-}
-
-// SetHostnameRoute53 add a DNS record to a Route53 hosted zone
-func SetHostnameRoute53(id, key []byte, hostedZone, hostname, address, recordType string) (bool, string) {
-	// TODO: Dynamically set the region
-	config := aws.Config{Region: aws.String("us-east-1")}
-	config.Credentials = credentials.NewStaticCredentials(strings.TrimSpace(string(id)), strings.TrimSpace(string(key)), "")
-	input := &route53.ChangeResourceRecordSetsInput{
-		ChangeBatch: &route53.ChangeBatch{
-			Changes: []*route53.Change{
-				{
-					Action: aws.String("CREATE"),
-					ResourceRecordSet: &route53.ResourceRecordSet{
-						Name: aws.String(hostname),
-						ResourceRecords: []*route53.ResourceRecord{
-							{
-								Value: aws.String(address),
-							},
-						},
-						TTL:  aws.Int64(60),
-						Type: aws.String(recordType),
-					},
-				},
-			},
-			Comment: aws.String("Node contribution for EdgeNet"),
-		},
-		HostedZoneId: aws.String(hostedZone),
-	}
-	result, state := addARecordRoute53(&config, input)
-	return result, state
-}
-
-// addARecordRoute53 adds a new A record to the Route53
-func addARecordRoute53(config *aws.Config, input *route53.ChangeResourceRecordSetsInput) (bool, string) {
-	newSession := session.Must(session.NewSession())
-	svc := route53.New(newSession, config)
-	_, err := svc.ChangeResourceRecordSets(input)
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case route53.ErrCodeNoSuchHostedZone:
-				log.Println(route53.ErrCodeNoSuchHostedZone, aerr.Error())
-			case route53.ErrCodeNoSuchHealthCheck:
-				log.Println(route53.ErrCodeNoSuchHealthCheck, aerr.Error())
-			case route53.ErrCodeInvalidChangeBatch:
-				// TODO: Better error handling here
-				if strings.Contains(aerr.Error(), "it already exists") {
-					return true, ""
-				}
-				log.Println(route53.ErrCodeInvalidChangeBatch, aerr.Error())
-			case route53.ErrCodeInvalidInput:
-				log.Println(route53.ErrCodeInvalidInput, aerr.Error())
-			case route53.ErrCodePriorRequestNotComplete:
-				log.Println(route53.ErrCodePriorRequestNotComplete, aerr.Error())
-			default:
-				log.Println(aerr.Error())
-			}
-		} else {
-			// Print the error, cast err to awserr.Error to get the Code and
-			// Message from an error.
-			log.Println(err.Error())
-		}
-		return false, "failed"
-	}
-	return true, ""
-}
-
 // CreateJoinToken generates token to be used on adding a node onto the cluster
 func (m *Manager) CreateJoinToken(ttl string, hostname string) string {
 	duration, _ := time.ParseDuration(ttl)
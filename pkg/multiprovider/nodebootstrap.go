@@ -0,0 +1,204 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JoinSecretPrefix prefixes the name of the secrets in the edgenet namespace that hold the join command of nodes in pull mode
+const JoinSecretPrefix = "join-"
+
+// The k3s agent reads its bootstrap token from a file on the node
+const (
+	k3sTokenDir  = "/etc/edgenet"
+	k3sTokenPath = k3sTokenDir + "/k3s-token"
+)
+
+// BootstrapCommand is a step of a node bootstrap. The command runs on the node over SSH with root privileges, and
+// reads Stdin, which keeps secrets off the command line. A step without command waits for the node to join the
+// cluster by itself.
+type BootstrapCommand struct {
+	Name    string
+	Command string
+	Stdin   string
+	Timeout time.Duration
+}
+
+// NodeBootstrapper makes a host join the cluster
+type NodeBootstrapper interface {
+	// Prepare creates the credentials the host needs to join and returns the steps to go through
+	Prepare(nodeName string) ([]BootstrapCommand, error)
+	// Cleanup removes what Prepare left behind once the bootstrap is over
+	Cleanup(nodeName string) error
+	// Reset returns the step undoing the join on the host
+	Reset() BootstrapCommand
+}
+
+// NewNodeBootstrapper returns the bootstrapper of the strategy
+func (m *Manager) NewNodeBootstrapper(strategy string) (NodeBootstrapper, error) {
+	switch strategy {
+	case corev1alpha1.BootstrapKubeadm, "":
+		return &kubeadmBootstrapper{m}, nil
+	case corev1alpha1.BootstrapK3s:
+		// The API server of a kubeadm cluster cannot take k3s agents
+		if isK3s, err := m.isK3sCluster(); err != nil || !isK3s {
+			return nil, fmt.Errorf("bootstrap strategy %s requires the control plane to run k3s", strategy)
+		}
+		return &k3sBootstrapper{m}, nil
+	case corev1alpha1.BootstrapPull:
+		return &pullBootstrapper{m}, nil
+	}
+	return nil, fmt.Errorf("unknown bootstrap strategy %s", strategy)
+}
+
+// kubeadmBootstrapper resets the host and runs kubeadm join over SSH
+type kubeadmBootstrapper struct {
+	m *Manager
+}
+
+func (b *kubeadmBootstrapper) Prepare(nodeName string) ([]BootstrapCommand, error) {
+	joinCommand, err := b.m.createToken(30*time.Minute, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if joinCommand == "" {
+		return nil, fmt.Errorf("bootstrap token could not be created for %s", nodeName)
+	}
+	steps := []BootstrapCommand{
+		b.Reset(),
+		{Name: "join", Command: joinCommand, Timeout: 5 * time.Minute},
+	}
+	return steps, nil
+}
+
+func (b *kubeadmBootstrapper) Cleanup(nodeName string) error {
+	return nil
+}
+
+func (b *kubeadmBootstrapper) Reset() BootstrapCommand {
+	return BootstrapCommand{Name: "reset", Command: "kubeadm reset -f", Timeout: 2 * time.Minute}
+}
+
+// k3sBootstrapper installs the k3s agent over SSH, which joins the API server of the cluster when its control plane
+// runs k3s. The agent authenticates with a bootstrap token that the step writes to a file from its standard input.
+type k3sBootstrapper struct {
+	m *Manager
+}
+
+func (b *k3sBootstrapper) Prepare(nodeName string) ([]BootstrapCommand, error) {
+	token, err := b.m.createBootstrapToken(30*time.Minute, nodeName, []string{"system:bootstrappers:k3s:default-node-token"})
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("bootstrap token could not be created for %s", nodeName)
+	}
+	server, err := getAPIServerURL()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(getCAPath())
+	if err != nil {
+		return nil, err
+	}
+	// The secure token format pins the hash of the CA bundle the server hands out
+	secureToken := fmt.Sprintf("K10%x::%s", sha256.Sum256(ca), token)
+	install := fmt.Sprintf("mkdir -p %s && umask 077 && cat > %s && curl -sfL https://get.k3s.io | K3S_URL=%s K3S_TOKEN_FILE=%s sh -s - agent --node-name %s",
+		k3sTokenDir, k3sTokenPath, server, k3sTokenPath, nodeName)
+	steps := []BootstrapCommand{
+		b.Reset(),
+		{Name: "install-agent", Command: install, Stdin: secureToken + "\n", Timeout: 10 * time.Minute},
+	}
+	return steps, nil
+}
+
+func (b *k3sBootstrapper) Cleanup(nodeName string) error {
+	return nil
+}
+
+func (b *k3sBootstrapper) Reset() BootstrapCommand {
+	return BootstrapCommand{Name: "uninstall", Command: fmt.Sprintf("if [ -x /usr/local/bin/k3s-agent-uninstall.sh ]; then /usr/local/bin/k3s-agent-uninstall.sh; fi; rm -f %s", k3sTokenPath), Timeout: 2 * time.Minute}
+}
+
+// pullBootstrapper publishes the join command in a secret that the agent on the node fetches, nothing runs over SSH.
+// The bootstrap token of the join command expires along with the step waiting for the node to register.
+type pullBootstrapper struct {
+	m *Manager
+}
+
+func (b *pullBootstrapper) Prepare(nodeName string) ([]BootstrapCommand, error) {
+	joinCommand, err := b.m.createToken(time.Hour, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if joinCommand == "" {
+		return nil, fmt.Errorf("bootstrap token could not be created for %s", nodeName)
+	}
+	secret := new(corev1.Secret)
+	secret.SetName(JoinSecretPrefix + nodeName)
+	secret.SetNamespace("edgenet")
+	secret.SetLabels(map[string]string{"edge-net.io/node": nodeName})
+	secret.StringData = map[string]string{"join-command": joinCommand}
+	if _, err := b.m.kubeclientset.CoreV1().Secrets("edgenet").Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		if _, err := b.m.kubeclientset.CoreV1().Secrets("edgenet").Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	return []BootstrapCommand{{Name: "wait-for-node", Timeout: time.Hour}}, nil
+}
+
+func (b *pullBootstrapper) Cleanup(nodeName string) error {
+	err := b.m.kubeclientset.CoreV1().Secrets("edgenet").Delete(context.TODO(), JoinSecretPrefix+nodeName, metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Reset runs kubeadm reset, as the agent on the node joins with the kubeadm join command
+func (b *pullBootstrapper) Reset() BootstrapCommand {
+	return BootstrapCommand{Name: "reset", Command: "kubeadm reset -f", Timeout: 2 * time.Minute}
+}
+
+// isK3sCluster tells whether the control plane nodes of the cluster run k3s
+func (m *Manager) isK3sCluster() (bool, error) {
+	nodeList, err := m.kubeclientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/control-plane"})
+	if err != nil {
+		return false, err
+	}
+	for _, node := range nodeList.Items {
+		if strings.Contains(node.Status.NodeInfo.KubeletVersion, "+k3s") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package multiprovider
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeBootstrapper(t *testing.T) {
+	if flag.Lookup("kubeconfig-path") == nil {
+		flag.String("kubeconfig-path", "../../configs/public.cfg", "Set kubeconfig path.")
+	}
+	g := testGroup{}
+	g.Init()
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := g.multiproviderManager.NewNodeBootstrapper("ansible")
+		util.NotEquals(t, nil, err)
+	})
+	t.Run("kubeadm", func(t *testing.T) {
+		bootstrapper, err := g.multiproviderManager.NewNodeBootstrapper(corev1alpha1.BootstrapKubeadm)
+		util.OK(t, err)
+		steps, err := bootstrapper.Prepare("kubeadm.edge-net.io")
+		util.OK(t, err)
+		util.Equals(t, 2, len(steps))
+		util.Equals(t, true, strings.HasPrefix(steps[1].Command, "kubeadm join"))
+	})
+	t.Run("k3s", func(t *testing.T) {
+		// The kubeadm control plane cannot take k3s agents
+		_, err := g.multiproviderManager.NewNodeBootstrapper(corev1alpha1.BootstrapK3s)
+		util.NotEquals(t, nil, err)
+
+		server := g.nodeObj.DeepCopy()
+		server.SetName("server.edge-net.io")
+		server.SetLabels(map[string]string{"node-role.kubernetes.io/control-plane": "true"})
+		server.Status.NodeInfo.KubeletVersion = "v1.27.4+k3s1"
+		_, err = g.multiproviderManager.kubeclientset.CoreV1().Nodes().Create(context.TODO(), server, metav1.CreateOptions{})
+		util.OK(t, err)
+		defer g.multiproviderManager.kubeclientset.CoreV1().Nodes().Delete(context.TODO(), server.GetName(), metav1.DeleteOptions{})
+		bootstrapper, err := g.multiproviderManager.NewNodeBootstrapper(corev1alpha1.BootstrapK3s)
+		util.OK(t, err)
+		steps, err := bootstrapper.Prepare("k3s.edge-net.io")
+		util.OK(t, err)
+		util.Equals(t, 2, len(steps))
+		// The token goes through the standard input rather than the command line
+		util.Equals(t, true, strings.HasPrefix(steps[1].Stdin, "K10"))
+		util.Equals(t, false, strings.Contains(steps[1].Command, "K10"))
+		util.Equals(t, true, strings.Contains(steps[1].Command, "K3S_TOKEN_FILE="+k3sTokenPath))
+	})
+	t.Run("pull", func(t *testing.T) {
+		bootstrapper, err := g.multiproviderManager.NewNodeBootstrapper(corev1alpha1.BootstrapPull)
+		util.OK(t, err)
+		steps, err := bootstrapper.Prepare("pull.edge-net.io")
+		util.OK(t, err)
+		util.Equals(t, 1, len(steps))
+		util.Equals(t, "", steps[0].Command)
+		secret, err := g.multiproviderManager.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), JoinSecretPrefix+"pull.edge-net.io", metav1.GetOptions{})
+		util.OK(t, err)
+		util.Equals(t, true, strings.HasPrefix(secret.StringData["join-command"], "kubeadm join"))
+		util.OK(t, bootstrapper.Cleanup("pull.edge-net.io"))
+		_, err = g.multiproviderManager.kubeclientset.CoreV1().Secrets("edgenet").Get(context.TODO(), JoinSecretPrefix+"pull.edge-net.io", metav1.GetOptions{})
+		util.Equals(t, true, errors.IsNotFound(err))
+		util.OK(t, bootstrapper.Cleanup("pull.edge-net.io"))
+	})
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multiprovider
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	opCodeUpdate = dnsmessage.OpCode(5)
+	classNone    = dnsmessage.Class(254)
+	typeTSIG     = dnsmessage.Type(250)
+	tsigFudge    = 300
+)
+
+// RFC2136DNSProvider sends dynamic updates to an authoritative server such as BIND.
+// Updates are signed with TSIG when a key name is configured, and the secret is read at each call.
+type RFC2136DNSProvider struct {
+	server        string
+	zone          string
+	keyName       string
+	keyAlgorithm  string
+	keySecretPath string
+	ttl           uint32
+}
+
+// SetRecord replaces the records of the hostname having the given type by the address
+func (r *RFC2136DNSProvider) SetRecord(hostname, address, recordType string) error {
+	return r.update(hostname, address, recordType, true)
+}
+
+// DeleteRecord removes the record pointing the hostname to the address, the server ignores missing records
+func (r *RFC2136DNSProvider) DeleteRecord(hostname, address, recordType string) error {
+	return r.update(hostname, address, recordType, false)
+}
+
+func (r *RFC2136DNSProvider) update(hostname, address, recordType string, add bool) error {
+	id := uint16(rand.Intn(1 << 16))
+	msg, err := buildUpdateMessage(id, r.zone, hostname, address, recordType, r.ttl, add)
+	if err != nil {
+		return err
+	}
+	if r.keyName != "" {
+		secret, err := os.ReadFile(r.keySecretPath)
+		if err != nil {
+			return err
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(secret)))
+		if err != nil {
+			return fmt.Errorf("TSIG secret must be base64 encoded: %s", err)
+		}
+		if msg, err = signTSIG(msg, r.keyName, r.keyAlgorithm, key, time.Now()); err != nil {
+			return err
+		}
+	}
+	return exchangeTCP(r.server, id, msg)
+}
+
+// buildUpdateMessage composes an UPDATE message for the zone. Setting a record first deletes the RRset of the
+// hostname so that a changed address does not pile up, deleting a record only targets the given address.
+func buildUpdateMessage(id uint16, zone, hostname, address, recordType string, ttl uint32, add bool) ([]byte, error) {
+	zoneName, err := dnsmessage.NewName(fqdn(zone))
+	if err != nil {
+		return nil, err
+	}
+	name, err := dnsmessage.NewName(fqdn(hostname))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(strings.ToLower(name.String()), "."+strings.ToLower(zoneName.String())) {
+		return nil, fmt.Errorf("%s is not in zone %s", hostname, zone)
+	}
+	ip := net.ParseIP(address)
+	var recordData []byte
+	var rrType dnsmessage.Type
+	switch {
+	case recordType == "A" && ip != nil && ip.To4() != nil:
+		rrType, recordData = dnsmessage.TypeA, ip.To4()
+	case recordType == "AAAA" && ip != nil && ip.To4() == nil:
+		rrType, recordData = dnsmessage.TypeAAAA, ip.To16()
+	default:
+		return nil, fmt.Errorf("%s is not a valid %s record address", address, recordType)
+	}
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opCodeUpdate})
+	// The zone section of an update uses the layout of the question section
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(dnsmessage.Question{Name: zoneName, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	// The update section of an update uses the layout of the authority section
+	if err := builder.StartAuthorities(); err != nil {
+		return nil, err
+	}
+	if add {
+		// Class ANY with empty data deletes the whole RRset
+		if err := builder.UnknownResource(dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassANY}, dnsmessage.UnknownResource{Type: rrType}); err != nil {
+			return nil, err
+		}
+		if err := builder.UnknownResource(dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: ttl}, dnsmessage.UnknownResource{Type: rrType, Data: recordData}); err != nil {
+			return nil, err
+		}
+	} else {
+		// Class NONE deletes the record matching the data
+		if err := builder.UnknownResource(dnsmessage.ResourceHeader{Name: name, Class: classNone}, dnsmessage.UnknownResource{Type: rrType, Data: recordData}); err != nil {
+			return nil, err
+		}
+	}
+	return builder.Finish()
+}
+
+// signTSIG appends a TSIG record to the message as described in RFC 8945
+func signTSIG(msg []byte, keyName, algorithm string, secret []byte, signedAt time.Time) ([]byte, error) {
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	algorithm = strings.ToLower(strings.TrimSuffix(algorithm, "."))
+	var hashFunc func() hash.Hash
+	switch algorithm {
+	case "hmac-sha1":
+		hashFunc = sha1.New
+	case "hmac-sha256":
+		hashFunc = sha256.New
+	case "hmac-sha512":
+		hashFunc = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported TSIG algorithm %s", algorithm)
+	}
+	keyNameWire, err := packName(keyName)
+	if err != nil {
+		return nil, err
+	}
+	algorithmWire, err := packName(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	timeSigned := make([]byte, 8)
+	binary.BigEndian.PutUint64(timeSigned, uint64(signedAt.Unix()))
+	timeSigned = timeSigned[2:]
+
+	// The MAC covers the message followed by the TSIG variables
+	mac := hmac.New(hashFunc, secret)
+	mac.Write(msg)
+	mac.Write(keyNameWire)
+	mac.Write([]byte{0, byte(dnsmessage.ClassANY), 0, 0, 0, 0})
+	mac.Write(algorithmWire)
+	mac.Write(timeSigned)
+	mac.Write([]byte{tsigFudge >> 8, tsigFudge & 0xff, 0, 0, 0, 0})
+	digest := mac.Sum(nil)
+
+	rdata := append([]byte{}, algorithmWire...)
+	rdata = append(rdata, timeSigned...)
+	rdata = append(rdata, tsigFudge>>8, tsigFudge&0xff, byte(len(digest)>>8), byte(len(digest)))
+	rdata = append(rdata, digest...)
+	// Original ID, error and other length follow the MAC
+	rdata = append(rdata, msg[0], msg[1], 0, 0, 0, 0)
+
+	signed := append([]byte{}, msg...)
+	signed = append(signed, keyNameWire...)
+	signed = append(signed, byte(typeTSIG>>8), byte(typeTSIG), 0, byte(dnsmessage.ClassANY), 0, 0, 0, 0, byte(len(rdata)>>8), byte(len(rdata)))
+	signed = append(signed, rdata...)
+	binary.BigEndian.PutUint16(signed[10:12], binary.BigEndian.Uint16(signed[10:12])+1)
+	return signed, nil
+}
+
+// exchangeTCP sends the message to the server and checks the response code
+func exchangeTCP(server string, id uint16, msg []byte) error {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	conn, err := net.DialTimeout("tcp", server, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(15 * time.Second))
+
+	// Messages over TCP are prefixed with their length
+	if _, err := conn.Write(append([]byte{byte(len(msg) >> 8), byte(len(msg))}, msg...)); err != nil {
+		return err
+	}
+	length := make([]byte, 2)
+	if _, err := io.ReadFull(conn, length); err != nil {
+		return err
+	}
+	response := make([]byte, binary.BigEndian.Uint16(length))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return err
+	}
+	var parser dnsmessage.Parser
+	header, err := parser.Start(response)
+	if err != nil {
+		return err
+	}
+	if header.ID != id {
+		return fmt.Errorf("response id %d does not match the update %d", header.ID, id)
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return fmt.Errorf("dynamic update refused by %s: %s", server, header.RCode)
+	}
+	return nil
+}
+
+// packName encodes the name in canonical wire format, lower case and uncompressed
+func packName(name string) ([]byte, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	wire := []byte{}
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, fmt.Errorf("invalid label in %s", name)
+			}
+			wire = append(wire, byte(len(label)))
+			wire = append(wire, label...)
+		}
+	}
+	return append(wire, 0), nil
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
@@ -37,12 +37,20 @@ type Manager struct {
 	kubeclientset kubernetes.Interface
 	// edgenetclientset is a clientset for the EdgeNet API groups
 	edgenetclientset clientset.Interface
+	// baselineEgress extends the egress restrictions of the baseline profile to the tenants with no profile set
+	baselineEgress bool
 }
 
 // NewManager returns a new multitenancy manager
 func NewManager(kubeclientset kubernetes.Interface,
 	edgenetclientset clientset.Interface) *Manager {
-	return &Manager{kubeclientset, edgenetclientset}
+	return &Manager{kubeclientset: kubeclientset, edgenetclientset: edgenetclientset}
+}
+
+// SetBaselineEgress sets whether the baseline profile restricts the egress of the tenants that have no network
+// profile set
+func (m *Manager) SetBaselineEgress(baselineEgress bool) {
+	m.baselineEgress = baselineEgress
 }
 
 // CreateTenant function is for being used by other resources to create a tenant
@@ -56,6 +64,11 @@ func (m *Manager) CreateTenant(tenantRequest *registrationv1alpha1.TenantRequest
 	tenant.Spec.ShortName = tenantRequest.Spec.ShortName
 	tenant.Spec.URL = tenantRequest.Spec.URL
 	tenant.Spec.ClusterNetworkPolicy = tenantRequest.Spec.ClusterNetworkPolicy
+	tenant.Spec.NetworkProfile = tenantRequest.Spec.NetworkProfile
+	if tenant.Spec.NetworkProfile == "" {
+		// New tenants pick the baseline profile explicitly, which restricts their egress as well
+		tenant.Spec.NetworkProfile = corev1alpha1.NetworkProfileBaseline
+	}
 	tenant.Spec.Description = tenantRequest.Spec.Description
 	tenant.Spec.Enabled = true
 	tenant.SetLabels(map[string]string{"edge-net.io/request-uid": string(tenantRequest.GetUID())})
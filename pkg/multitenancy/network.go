@@ -0,0 +1,204 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitenancy
+
+import (
+	"context"
+	"fmt"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+)
+
+// NetworkProfileLabel marks the network policies that enforce a tenant network profile
+const NetworkProfileLabel = "edge-net.io/network-profile"
+
+// PrivateCIDRs are the address blocks that tenants cannot reach out of their own namespaces
+var PrivateCIDRs = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+var networkProfiles = []string{corev1alpha1.NetworkProfileRestricted, corev1alpha1.NetworkProfileBaseline, corev1alpha1.NetworkProfilePrivileged}
+
+// IsValidNetworkProfile checks whether the profile is one of the known network profiles, empty meaning the default
+func IsValidNetworkProfile(profile string) bool {
+	if profile == "" {
+		return true
+	}
+	for _, networkProfile := range networkProfiles {
+		if profile == networkProfile {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantNamespaceSelector selects the namespaces of the tenant by its name and UID within the cluster, leaving out
+// the namespaces that belong to subtenants
+func TenantNamespaceSelector(tenant, tenantUID, clusterUID string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"edge-net.io/tenant":      tenant,
+			"edge-net.io/tenant-uid":  tenantUID,
+			"edge-net.io/cluster-uid": clusterUID,
+		},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      "edge-net.io/subtenant",
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"true"},
+			},
+		},
+	}
+}
+
+// RestrictsEgress tells whether the network profile of the tenant restricts outgoing traffic. The baseline profile
+// only does so for the tenants that picked it explicitly, which new tenants do by default, so that the tenants that
+// predate network profiles keep their egress unless the manager extends it to them with SetBaselineEgress.
+func (m *Manager) RestrictsEgress(tenant *corev1alpha1.Tenant) bool {
+	return tenant.Spec.NetworkProfile != "" || m.baselineEgress
+}
+
+// ApplyNetworkProfile creates or updates the network policy enforcing the profile of the tenant in the namespace,
+// and removes the policies of the other profiles so that only one of them is in effect
+func (m *Manager) ApplyNetworkProfile(namespace string, tenant *corev1alpha1.Tenant, clusterUID string) error {
+	profile := tenant.GetNetworkProfile()
+	if !IsValidNetworkProfile(profile) {
+		return fmt.Errorf("unknown network profile %s", profile)
+	}
+	networkPolicy := m.MakeNetworkPolicy(namespace, tenant, clusterUID)
+	if _, err := m.kubeclientset.NetworkingV1().NetworkPolicies(namespace).Create(context.TODO(), networkPolicy, metav1.CreateOptions{}); err != nil {
+		if !k8serrors.IsAlreadyExists(err) {
+			klog.Infof("Couldn't create network policy %s in %s: %s", profile, namespace, err)
+			return err
+		}
+		current, err := m.kubeclientset.NetworkingV1().NetworkPolicies(namespace).Get(context.TODO(), profile, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		labels := current.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[NetworkProfileLabel] = profile
+		current.SetLabels(labels)
+		current.Spec = networkPolicy.Spec
+		if _, err := m.kubeclientset.NetworkingV1().NetworkPolicies(namespace).Update(context.TODO(), current, metav1.UpdateOptions{}); err != nil {
+			klog.Infof("Couldn't update network policy %s in %s: %s", profile, namespace, err)
+			return err
+		}
+	}
+	for _, networkProfile := range networkProfiles {
+		if networkProfile == profile {
+			continue
+		}
+		if err := m.kubeclientset.NetworkingV1().NetworkPolicies(namespace).Delete(context.TODO(), networkProfile, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			klog.Infof("Couldn't delete network policy %s in %s: %s", networkProfile, namespace, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// MakeNetworkPolicy returns the network policy that enforces the profile of the tenant in one of its namespaces.
+// Restricted only allows intra-tenant communication and DNS resolution.
+// Baseline additionally allows traffic from and to the public Internet, private ranges excluded.
+// Privileged allows all kind of traffic.
+func (m *Manager) MakeNetworkPolicy(namespace string, tenant *corev1alpha1.Tenant, clusterUID string) *networkingv1.NetworkPolicy {
+	profile := tenant.GetNetworkProfile()
+	networkPolicy := new(networkingv1.NetworkPolicy)
+	networkPolicy.SetName(profile)
+	networkPolicy.SetNamespace(namespace)
+	networkPolicy.SetLabels(map[string]string{NetworkProfileLabel: profile})
+	networkPolicy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+
+	if profile == corev1alpha1.NetworkProfilePrivileged {
+		networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{}}
+		networkPolicy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{}}
+		return networkPolicy
+	}
+
+	tenantPeer := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: TenantNamespaceSelector(tenant.GetName(), string(tenant.GetUID()), clusterUID),
+	}
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	dnsRule := networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+			},
+		},
+		Ports: []networkingv1.NetworkPolicyPort{
+			{
+				Protocol: &udp,
+				Port:     &dnsPort,
+			},
+			{
+				Protocol: &tcp,
+				Port:     &dnsPort,
+			},
+		},
+	}
+	networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{tenantPeer},
+		},
+	}
+	networkPolicy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{
+		{
+			To: []networkingv1.NetworkPolicyPeer{tenantPeer},
+		},
+		dnsRule,
+	}
+
+	if profile == corev1alpha1.NetworkProfileBaseline {
+		port := intstr.IntOrString{IntVal: 1}
+		endPort := int32(32768)
+		publicPeer := networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{
+				CIDR:   "0.0.0.0/0",
+				Except: PrivateCIDRs,
+			},
+		}
+		networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+			{
+				From: []networkingv1.NetworkPolicyPeer{tenantPeer, publicPeer},
+				Ports: []networkingv1.NetworkPolicyPort{
+					{
+						Port:    &port,
+						EndPort: &endPort,
+					},
+				},
+			},
+		}
+		if !m.RestrictsEgress(tenant) {
+			networkPolicy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+			networkPolicy.Spec.Egress = nil
+			return networkPolicy
+		}
+		networkPolicy.Spec.Egress = append(networkPolicy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{publicPeer},
+		})
+	}
+	return networkPolicy
+}
@@ -0,0 +1,115 @@
+package multitenancy
+
+import (
+	"context"
+	"testing"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestMakeNetworkPolicy(t *testing.T) {
+	cases := map[string]struct {
+		profile        string
+		baselineEgress bool
+		policyTypes    []networkingv1.PolicyType
+		ingressRules   int
+		egressRules    int
+		publicIngress  bool
+		publicEgress   bool
+		allowEverybody bool
+	}{
+		"restricted":                  {corev1alpha1.NetworkProfileRestricted, false, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, 1, 2, false, false, false},
+		"baseline":                    {corev1alpha1.NetworkProfileBaseline, false, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, 1, 3, true, true, false},
+		"legacy baseline":             {"", false, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, 1, 0, true, false, false},
+		"legacy baseline with egress": {"", true, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, 1, 3, true, true, false},
+		"privileged":                  {corev1alpha1.NetworkProfilePrivileged, false, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, 1, 1, false, false, true},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			tenant := &corev1alpha1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "edgenet", UID: "edgenet-uid"}}
+			tenant.Spec.NetworkProfile = tc.profile
+			multitenancyManager := NewManager(nil, nil)
+			multitenancyManager.SetBaselineEgress(tc.baselineEgress)
+			networkPolicy := multitenancyManager.MakeNetworkPolicy("edgenet-workspace", tenant, "cluster-uid")
+			util.Equals(t, tenant.GetNetworkProfile(), networkPolicy.GetName())
+			util.Equals(t, tenant.GetNetworkProfile(), networkPolicy.GetLabels()[NetworkProfileLabel])
+			util.Equals(t, tc.policyTypes, networkPolicy.Spec.PolicyTypes)
+			util.Equals(t, tc.ingressRules, len(networkPolicy.Spec.Ingress))
+			util.Equals(t, tc.egressRules, len(networkPolicy.Spec.Egress))
+			if tc.allowEverybody {
+				util.Equals(t, networkingv1.NetworkPolicyIngressRule{}, networkPolicy.Spec.Ingress[0])
+				util.Equals(t, networkingv1.NetworkPolicyEgressRule{}, networkPolicy.Spec.Egress[0])
+				return
+			}
+			util.Equals(t, TenantNamespaceSelector("edgenet", "edgenet-uid", "cluster-uid"), networkPolicy.Spec.Ingress[0].From[0].NamespaceSelector)
+			util.Equals(t, tc.publicIngress, len(networkPolicy.Spec.Ingress[0].From) == 2 && networkPolicy.Spec.Ingress[0].From[1].IPBlock != nil)
+			if tc.egressRules == 0 {
+				return
+			}
+			util.Equals(t, TenantNamespaceSelector("edgenet", "edgenet-uid", "cluster-uid"), networkPolicy.Spec.Egress[0].To[0].NamespaceSelector)
+			util.Equals(t, "kube-system", networkPolicy.Spec.Egress[1].To[0].NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+			util.Equals(t, tc.publicEgress, len(networkPolicy.Spec.Egress) == 3 && networkPolicy.Spec.Egress[2].To[0].IPBlock.CIDR == "0.0.0.0/0")
+		})
+	}
+}
+
+func TestTenantNamespaceSelector(t *testing.T) {
+	selector, err := metav1.LabelSelectorAsSelector(TenantNamespaceSelector("edgenet", "edgenet-uid", "cluster-uid"))
+	util.OK(t, err)
+	tenantLabels := labels.Set{"edge-net.io/kind": "core", "edge-net.io/tenant": "edgenet", "edge-net.io/tenant-uid": "edgenet-uid", "edge-net.io/cluster-uid": "cluster-uid"}
+	util.Equals(t, true, selector.Matches(tenantLabels))
+	util.Equals(t, false, selector.Matches(labels.Set{"edge-net.io/tenant": "edgenet"}))
+	util.Equals(t, false, selector.Matches(labels.Merge(tenantLabels, labels.Set{"edge-net.io/tenant-uid": "recreated-uid"})))
+	util.Equals(t, false, selector.Matches(labels.Merge(tenantLabels, labels.Set{"edge-net.io/subtenant": "true"})))
+	util.Equals(t, true, selector.Matches(labels.Merge(tenantLabels, labels.Set{"edge-net.io/subtenant": "false"})))
+
+	// A subtenant is a tenant of its own, whose core namespace carries its own name and UID
+	subtenantLabels := labels.Set{"edge-net.io/kind": "core", "edge-net.io/tenant": "edgenet-lab", "edge-net.io/tenant-uid": "edgenet-lab-uid", "edge-net.io/cluster-uid": "cluster-uid",
+		"edge-net.io/owner": "lab", "edge-net.io/parent-namespace": "edgenet"}
+	util.Equals(t, false, selector.Matches(subtenantLabels))
+	subtenantSelector, err := metav1.LabelSelectorAsSelector(TenantNamespaceSelector("edgenet-lab", "edgenet-lab-uid", "cluster-uid"))
+	util.OK(t, err)
+	util.Equals(t, true, subtenantSelector.Matches(subtenantLabels))
+	util.Equals(t, false, subtenantSelector.Matches(tenantLabels))
+}
+
+func TestApplyNetworkProfile(t *testing.T) {
+	g := TestGroup{}
+	g.Init()
+
+	tenant := g.tenant.DeepCopy()
+	err := g.multitenancyManager.ApplyNetworkProfile(g.namespace.GetName(), tenant, "")
+	util.OK(t, err)
+	_, err = g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Get(context.TODO(), corev1alpha1.NetworkProfileBaseline, metav1.GetOptions{})
+	util.OK(t, err)
+
+	t.Run("switch profile", func(t *testing.T) {
+		tenant.Spec.NetworkProfile = corev1alpha1.NetworkProfileRestricted
+		err := g.multitenancyManager.ApplyNetworkProfile(g.namespace.GetName(), tenant, "")
+		util.OK(t, err)
+		_, err = g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Get(context.TODO(), corev1alpha1.NetworkProfileRestricted, metav1.GetOptions{})
+		util.OK(t, err)
+		_, err = g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Get(context.TODO(), corev1alpha1.NetworkProfileBaseline, metav1.GetOptions{})
+		util.Equals(t, true, errors.IsNotFound(err))
+	})
+	t.Run("overwrite tampered policy", func(t *testing.T) {
+		networkPolicy, _ := g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Get(context.TODO(), corev1alpha1.NetworkProfileRestricted, metav1.GetOptions{})
+		networkPolicy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{}}
+		g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Update(context.TODO(), networkPolicy, metav1.UpdateOptions{})
+		err := g.multitenancyManager.ApplyNetworkProfile(g.namespace.GetName(), tenant, "")
+		util.OK(t, err)
+		networkPolicy, _ = g.client.NetworkingV1().NetworkPolicies(g.namespace.GetName()).Get(context.TODO(), corev1alpha1.NetworkProfileRestricted, metav1.GetOptions{})
+		util.Equals(t, g.multitenancyManager.MakeNetworkPolicy(g.namespace.GetName(), tenant, "").Spec, networkPolicy.Spec)
+	})
+	t.Run("unknown profile", func(t *testing.T) {
+		tenant.Spec.NetworkProfile = "open"
+		err := g.multitenancyManager.ApplyNetworkProfile(g.namespace.GetName(), tenant, "")
+		util.NotEquals(t, nil, err)
+	})
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitenancy
+
+import (
+	"context"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetSliceClass returns the slice class of the given name. The 'Node' and 'Resource' classes that slices have used
+// before slice classes exist resolve to an exclusive class without limits, which renews its slices as often as asked,
+// unless a slice class overrides them.
+func (m *Manager) GetSliceClass(name string) (*corev1alpha1.SliceClass, error) {
+	sliceClass, err := m.edgenetclientset.CoreV1alpha1().SliceClasses().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) && (name == corev1alpha1.SliceClassNode || name == corev1alpha1.SliceClassResource) {
+			sliceClass = new(corev1alpha1.SliceClass)
+			sliceClass.SetName(name)
+			sliceClass.Spec.Isolation = corev1alpha1.IsolationExclusive
+			sliceClass.Spec.MaxRenewals = corev1alpha1.SliceClassBuiltInMaxRenewals
+			return sliceClass, nil
+		}
+		return nil, err
+	}
+	return sliceClass, nil
+}
@@ -0,0 +1,62 @@
+package multitenancy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1alpha1 "github.com/EdgeNet-project/edgenet/pkg/apis/core/v1alpha1"
+	edgenettestclient "github.com/EdgeNet-project/edgenet/pkg/generated/clientset/versioned/fake"
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetSliceClass(t *testing.T) {
+	edgenetclient := edgenettestclient.NewSimpleClientset()
+	multitenancyManager := NewManager(testclient.NewSimpleClientset(), edgenetclient)
+
+	gold := &corev1alpha1.SliceClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+		Spec: corev1alpha1.SliceClassSpec{
+			Isolation:      corev1alpha1.IsolationExclusive,
+			MaxDuration:    &metav1.Duration{Duration: 24 * time.Hour},
+			AllowedTenants: []string{"edgenet"},
+			MaxRenewals:    2,
+		},
+	}
+	_, err := edgenetclient.CoreV1alpha1().SliceClasses().Create(context.TODO(), gold, metav1.CreateOptions{})
+	util.OK(t, err)
+
+	t.Run("slice class", func(t *testing.T) {
+		sliceClass, err := multitenancyManager.GetSliceClass("gold")
+		util.OK(t, err)
+		util.Equals(t, gold.Spec, sliceClass.Spec)
+		util.Equals(t, true, sliceClass.IsAllowed("EdgeNet"))
+		util.Equals(t, false, sliceClass.IsAllowed("lip6"))
+		creation := time.Now()
+		util.Equals(t, creation.Add(24*time.Hour), sliceClass.GetMaxExpiry(creation).Time)
+		util.Equals(t, creation.Add(48*time.Hour), sliceClass.GetMaxRenewedExpiry(creation, 0).Time)
+		util.Equals(t, creation.Add(72*time.Hour), sliceClass.GetMaxRenewedExpiry(creation, 1).Time)
+		util.Equals(t, true, sliceClass.IsRenewable(1))
+		util.Equals(t, false, sliceClass.IsRenewable(2))
+	})
+	t.Run("built-in", func(t *testing.T) {
+		for _, name := range []string{corev1alpha1.SliceClassNode, corev1alpha1.SliceClassResource} {
+			sliceClass, err := multitenancyManager.GetSliceClass(name)
+			util.OK(t, err)
+			util.Equals(t, name, sliceClass.GetName())
+			util.Equals(t, true, sliceClass.IsExclusive())
+			util.Equals(t, true, sliceClass.IsAllowed("lip6"))
+			util.Equals(t, true, sliceClass.GetMaxExpiry(time.Now()) == nil)
+			util.Equals(t, true, sliceClass.GetMaxRenewedExpiry(time.Now(), 0) == nil)
+			util.Equals(t, true, sliceClass.IsRenewable(0))
+			util.Equals(t, true, sliceClass.IsRenewable(100))
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		_, err := multitenancyManager.GetSliceClass("silver")
+		util.Equals(t, true, err != nil)
+	})
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2023 Contributors to the EdgeNet project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multitenancy
+
+// Annotations that keep what the suspension of a tenant has taken from its workloads and role bindings
+const (
+	SuspendedReplicasAnnotation     = "edge-net.io/suspended-replicas"
+	SuspendedSubjectsAnnotation     = "edge-net.io/suspended-subjects"
+	SuspendedNodeSelectorAnnotation = "edge-net.io/suspended-node-selector"
+	SuspendedAnnotation             = "edge-net.io/suspended"
+)
+
+// IsSuspended tells whether the suspension of a tenant has scaled down or stopped the workload
+func IsSuspended(annotations map[string]string) bool {
+	for _, key := range []string{SuspendedReplicasAnnotation, SuspendedNodeSelectorAnnotation, SuspendedAnnotation} {
+		if _, elementExists := annotations[key]; elementExists {
+			return true
+		}
+	}
+	return false
+}
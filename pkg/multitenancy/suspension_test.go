@@ -0,0 +1,16 @@
+package multitenancy
+
+import (
+	"testing"
+
+	"github.com/EdgeNet-project/edgenet/pkg/util"
+)
+
+func TestIsSuspended(t *testing.T) {
+	util.Equals(t, false, IsSuspended(nil))
+	util.Equals(t, false, IsSuspended(map[string]string{"edge-net.io/generated": "true"}))
+	util.Equals(t, false, IsSuspended(map[string]string{SuspendedSubjectsAnnotation: "[]"}))
+	util.Equals(t, true, IsSuspended(map[string]string{SuspendedReplicasAnnotation: "3"}))
+	util.Equals(t, true, IsSuspended(map[string]string{SuspendedNodeSelectorAnnotation: "{}"}))
+	util.Equals(t, true, IsSuspended(map[string]string{SuspendedAnnotation: "true"}))
+}
@@ -27,6 +27,7 @@ type Content struct {
 	RoleRequest        *RoleRequest
 	TenantRequest      *TenantRequest
 	ClusterRoleRequest *ClusterRoleRequest
+	SliceExpiry        *SliceExpiry
 }
 
 // RoleRequest is the structure for the role request
@@ -45,6 +46,15 @@ type TenantRequest struct {
 	Tenant string
 }
 
+// SliceExpiry is the structure for the expiry of a slice
+type SliceExpiry struct {
+	Slice      string
+	SliceClaim string
+	Namespace  string
+	Expiry     string
+	Release    string
+}
+
 // Init is the function to initialize info for the notification content
 func (c *Content) Init(firstname, lastname, email, subject, clusterUID string, recipient []string) {
 	c.Cluster = clusterUID
@@ -59,7 +69,7 @@ func (c *Content) Init(firstname, lastname, email, subject, clusterUID string, r
 func (c *Content) SendNotification(purpose string) error {
 	var err error
 	err = c.email(purpose)
-	if c.RoleRequest == nil {
+	if c.RoleRequest == nil && c.SliceExpiry == nil {
 		err = c.slack(purpose)
 	}
 	return err